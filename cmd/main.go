@@ -2,9 +2,14 @@ package main
 
 import (
 	"log"
+	"log/slog"
+	"os"
+	// The runtime image has no zoneinfo, user timezones are validated against the embedded copy.
+	_ "time/tzdata"
 
 	"time-capsule/config"
 	"time-capsule/internal/app"
+	"time-capsule/internal/logging"
 )
 
 // @title TimeCapsule
@@ -19,10 +24,52 @@ import (
 // @name Authorization
 
 func main() {
-	cfg, err := config.New()
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("failed to initilize a config: %v", err)
+		log.Fatalf("failed to read the config: %v", err)
+	}
+
+	// The config is inspected before it's validated, so that an invalid one can be printed.
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err = app.Config(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("config:\n%v", err)
+		}
+
+		return
+	}
+
+	if err = cfg.Validate(); err != nil {
+		log.Fatalf("invalid config:\n%v", err)
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("failed to configure logging: %v", err)
+	}
+
+	slog.SetDefault(logger)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			if err = app.Import(cfg, os.Args[2:]); err != nil {
+				fatal("failed to import", err)
+			}
+
+			return
+		case "role":
+			if err = app.Role(cfg, os.Args[2:]); err != nil {
+				fatal("failed to update the role", err)
+			}
+
+			return
+		}
 	}
 
 	app.Run(cfg)
 }
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -1,33 +1,133 @@
 package config
 
 import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"time-capsule/internal/oidc"
+
 	"github.com/ilyakaznacheev/cleanenv"
 	_ "github.com/joho/godotenv/autoload"
 )
 
+// fileEnv is the environment variable naming the optional config file, in YAML (.yaml, .yml) or TOML (.toml).
+// Environment variables override the values of the file, and defaults apply to the values set in neither.
+const fileEnv = "CONFIG_FILE"
+
+// Config is the configuration of the service. Fields tagged secret are redacted when the config is printed.
 type Config struct {
-	HttpAddr string `env:"HTTP_ADDR"`
-
-	MongoHost     string `env:"MONGO_HOST"`
-	MongoPort     string `env:"MONGO_PORT"`
-	MongoUsername string `env:"MONGO_USERNAME"`
-	MongoPassword string `env:"MONGO_PASSWORD"`
-	MongoDBName   string `env:"MONGO_DBNAME"`
-
-	SMTPHost     string `env:"SMTP_HOST"`
-	SMTPPort     string `env:"SMTP_PORT"`
-	SMTPUsername string `env:"SMTP_USERNAME"`
-	SMTPPassword string `env:"SMTP_PASSWORD"`
-
-	MinioHost       string `env:"MINIO_HOST"`
-	MinioPort       string `env:"MINIO_PORT"`
-	MinioUsername   string `env:"MINIO_USERNAME"`
-	MinioPassword   string `env:"MINIO_PASSWORD"`
-	MinioBucketName string `env:"BUCKET_NAME"`
+	// HttpAddr is the port the HTTP server listens on.
+	HttpAddr         string        `yaml:"http_addr" toml:"http_addr" env:"HTTP_ADDR" env-default:"8080"`
+	HTTPReadTimeout  time.Duration `yaml:"http_read_timeout" toml:"http_read_timeout" env:"HTTP_READ_TIMEOUT" env-default:"5s"`
+	HTTPWriteTimeout time.Duration `yaml:"http_write_timeout" toml:"http_write_timeout" env:"HTTP_WRITE_TIMEOUT" env-default:"5s"`
+	// ShutdownDelay is how long the service keeps serving requests while reporting it isn't ready, once told to shut down.
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" toml:"shutdown_delay" env:"SHUTDOWN_DELAY" env-default:"0s"`
+	// PublicURL is the base URL the service is reachable at, e.g. https://time-capsule.example.com.
+	// Links in emails point to it.
+	PublicURL string `yaml:"public_url" toml:"public_url" env:"PUBLIC_URL" env-default:"http://localhost:8080"`
+
+	MongoHost     string `yaml:"mongo_host" toml:"mongo_host" env:"MONGO_HOST" env-default:"localhost"`
+	MongoPort     string `yaml:"mongo_port" toml:"mongo_port" env:"MONGO_PORT" env-default:"27017"`
+	MongoUsername string `yaml:"mongo_username" toml:"mongo_username" env:"MONGO_USERNAME"`
+	MongoPassword string `yaml:"mongo_password" toml:"mongo_password" env:"MONGO_PASSWORD" secret:"true"`
+	MongoDBName   string `yaml:"mongo_dbname" toml:"mongo_dbname" env:"MONGO_DBNAME" env-default:"time-capsule"`
+
+	SMTPHost     string `yaml:"smtp_host" toml:"smtp_host" env:"SMTP_HOST" env-default:"localhost"`
+	SMTPPort     string `yaml:"smtp_port" toml:"smtp_port" env:"SMTP_PORT" env-default:"587"`
+	SMTPUsername string `yaml:"smtp_username" toml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" toml:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+	// SMTPFrom is the address emails are sent from, SMTPUsername by default.
+	SMTPFrom string `yaml:"smtp_from" toml:"smtp_from" env:"SMTP_FROM"`
+	// SMTPTLSMode is "starttls" (the default), "tls" for implicit TLS or "none".
+	SMTPTLSMode string `yaml:"smtp_tls_mode" toml:"smtp_tls_mode" env:"SMTP_TLS_MODE" env-default:"starttls"`
+
+	// MailTemplatesDir optionally overrides the embedded email templates.
+	MailTemplatesDir string `yaml:"mail_templates_dir" toml:"mail_templates_dir" env:"MAIL_TEMPLATES_DIR"`
+	// MailAttachThumbnails attaches thumbnails of the capsule's images to the opening emails.
+	MailAttachThumbnails bool `yaml:"mail_attach_thumbnails" toml:"mail_attach_thumbnails" env:"MAIL_ATTACH_THUMBNAILS"`
+
+	// ExportSealedCapsules includes the message and images of capsules that haven't opened yet in data exports.
+	// By default, only their metadata is exported, so that exports don't unseal them early.
+	ExportSealedCapsules bool `yaml:"export_sealed_capsules" toml:"export_sealed_capsules" env:"EXPORT_SEALED_CAPSULES"`
+
+	MinioHost       string `yaml:"minio_host" toml:"minio_host" env:"MINIO_HOST" env-default:"localhost"`
+	MinioPort       string `yaml:"minio_port" toml:"minio_port" env:"MINIO_PORT" env-default:"9000"`
+	MinioUsername   string `yaml:"minio_username" toml:"minio_username" env:"MINIO_USERNAME"`
+	MinioPassword   string `yaml:"minio_password" toml:"minio_password" env:"MINIO_PASSWORD" secret:"true"`
+	MinioBucketName string `yaml:"bucket_name" toml:"bucket_name" env:"BUCKET_NAME"`
+	// StorageTimeout bounds each operation on the object storage.
+	StorageTimeout time.Duration `yaml:"storage_timeout" toml:"storage_timeout" env:"STORAGE_TIMEOUT" env-default:"5s"`
+	// MaxUploadSize is the size of the largest image users can upload, in bytes.
+	MaxUploadSize int64 `yaml:"max_upload_size" toml:"max_upload_size" env:"MAX_UPLOAD_SIZE" env-default:"5242880"`
+
+	// WorkerInterval is how long the worker waits between its cycles.
+	WorkerInterval time.Duration `yaml:"worker_interval" toml:"worker_interval" env:"WORKER_INTERVAL" env-default:"5s"`
+	// WorkerMaxCycleDuration is how long a cycle of the worker may take before the service isn't ready.
+	// A cycle builds every pending export, which may take up to 10 minutes each.
+	WorkerMaxCycleDuration time.Duration `yaml:"worker_max_cycle_duration" toml:"worker_max_cycle_duration" env:"WORKER_MAX_CYCLE_DURATION" env-default:"30m"`
+
+	// RateLimitStore is where the rate limit counters are kept: "memory" (the default) or "mongodb"
+	// to share the limits between replicas.
+	RateLimitStore string `yaml:"rate_limit_store" toml:"rate_limit_store" env:"RATE_LIMIT_STORE" env-default:"memory"`
+	// RateLimitTrustProxy takes the client IP from the rightmost X-Forwarded-For entry, or the X-Real-IP header,
+	// enable it only behind a single proxy that sets them.
+	RateLimitTrustProxy bool `yaml:"rate_limit_trust_proxy" toml:"rate_limit_trust_proxy" env:"RATE_LIMIT_TRUST_PROXY"`
+
+	// JWTSigningKeyFile is the PEM encoded RSA or Ed25519 private key tokens are signed with.
+	// It's required, unless JWTGenerateKey is set.
+	JWTSigningKeyFile string `yaml:"jwt_signing_key_file" toml:"jwt_signing_key_file" env:"JWT_SIGNING_KEY_FILE"`
+	// JWTGenerateKey signs tokens with a key generated on startup instead, for development only:
+	// tokens don't survive restarts and aren't valid on other replicas.
+	JWTGenerateKey bool `yaml:"jwt_generate_key" toml:"jwt_generate_key" env:"JWT_GENERATE_KEY"`
+	// JWTVerificationKeyFiles are previous keys tokens are still verified with, while rotating keys.
+	JWTVerificationKeyFiles []string `yaml:"jwt_verification_key_files" toml:"jwt_verification_key_files" env:"JWT_VERIFICATION_KEY_FILES" env-separator:","`
+	// TokenTTL is how long access tokens are valid.
+	TokenTTL time.Duration `yaml:"token_ttl" toml:"token_ttl" env:"TOKEN_TTL" env-default:"168h"`
+	// BcryptCost is the cost passwords are hashed with, between 4 and 31.
+	BcryptCost int `yaml:"bcrypt_cost" toml:"bcrypt_cost" env:"BCRYPT_COST" env-default:"10"`
+
+	// OIDCProviders are the OpenID Connect identity providers users can sign in with, as a JSON array, e.g.
+	// [{"name":"google","issuer":"https://accounts.google.com","clientID":"...","clientSecret":"..."}].
+	// Their redirect URI is PublicURL + /api/v1/oidc/{name}/callback.
+	OIDCProviders OIDCProviders `yaml:"oidc_providers" toml:"oidc_providers" env:"OIDC_PROVIDERS"`
+
+	// LogLevel is the lowest level of the logged records: "debug", "info" (the default), "warn" or "error".
+	LogLevel string `yaml:"log_level" toml:"log_level" env:"LOG_LEVEL" env-default:"info"`
+
+	// TracingExporter is where spans are exported: "otlp", configured with the standard OTEL_EXPORTER_OTLP_*
+	// variables, "stdout", or "none" (the default) to disable tracing.
+	TracingExporter string `yaml:"tracing_exporter" toml:"tracing_exporter" env:"TRACING_EXPORTER" env-default:"none"`
+
+	// CapsuleMinMessageLength is the length of the shortest capsule message.
+	CapsuleMinMessageLength int `yaml:"capsule_min_message_length" toml:"capsule_min_message_length" env:"CAPSULE_MIN_MESSAGE_LENGTH" env-default:"5"`
+	// CapsuleMinOpenDelay is how long after their creation capsules open at the earliest.
+	CapsuleMinOpenDelay time.Duration `yaml:"capsule_min_open_delay" toml:"capsule_min_open_delay" env:"CAPSULE_MIN_OPEN_DELAY" env-default:"24h"`
+	// CapsuleEditWindow is how long after their creation capsules can be updated.
+	CapsuleEditWindow        time.Duration `yaml:"capsule_edit_window" toml:"capsule_edit_window" env:"CAPSULE_EDIT_WINDOW" env-default:"30m"`
+	CapsuleMaxRecipients     int           `yaml:"capsule_max_recipients" toml:"capsule_max_recipients" env:"CAPSULE_MAX_RECIPIENTS" env-default:"10"`
+	CapsuleMinInactivityDays int           `yaml:"capsule_min_inactivity_days" toml:"capsule_min_inactivity_days" env:"CAPSULE_MIN_INACTIVITY_DAYS" env-default:"7"`
+	CapsuleMaxInactivityDays int           `yaml:"capsule_max_inactivity_days" toml:"capsule_max_inactivity_days" env:"CAPSULE_MAX_INACTIVITY_DAYS" env-default:"3650"`
 }
 
-func New() (*Config, error) {
+type OIDCProviders []oidc.Config
+
+// SetValue parses the providers from JSON.
+func (p *OIDCProviders) SetValue(s string) error {
+	if s == "" {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(s), p)
+}
+
+// Load reads the config file named by CONFIG_FILE, if any, and the environment. The config isn't validated.
+func Load() (*Config, error) {
 	cfg := &Config{}
 
+	if path := os.Getenv(fileEnv); path != "" {
+		return cfg, cleanenv.ReadConfig(path, cfg)
+	}
+
 	return cfg, cleanenv.ReadEnv(cfg)
 }
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"time-capsule/internal/oidc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validConfig returns the default config with the values that have no default set.
+func validConfig(t *testing.T) *Config {
+	t.Setenv("SMTP_USERNAME", "time-capsule@example.com")
+	t.Setenv("MINIO_USERNAME", "minio")
+	t.Setenv("MINIO_PASSWORD", "minio-password")
+	t.Setenv("BUCKET_NAME", "capsules")
+	t.Setenv("JWT_GENERATE_KEY", "true")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	return cfg
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg := validConfig(t)
+
+	assert.Equal(t, "8080", cfg.HttpAddr)
+	assert.Equal(t, 5*time.Second, cfg.HTTPReadTimeout)
+	assert.Equal(t, "starttls", cfg.SMTPTLSMode)
+	assert.Equal(t, int64(5<<20), cfg.MaxUploadSize)
+	assert.Equal(t, 7*24*time.Hour, cfg.TokenTTL)
+	assert.Equal(t, 10, cfg.BcryptCost)
+	assert.Equal(t, 24*time.Hour, cfg.CapsuleMinOpenDelay)
+	assert.Equal(t, 30*time.Minute, cfg.CapsuleEditWindow)
+	assert.Equal(t, 30*time.Minute, cfg.WorkerMaxCycleDuration)
+
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoad_File(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "YAML",
+			file: "config.yaml",
+			content: `
+http_addr: "9090"
+token_ttl: 1h
+capsule_max_recipients: 3
+oidc_providers:
+  - name: google
+    issuer: https://accounts.google.com
+    clientID: client
+    clientSecret: secret
+`,
+		},
+		{
+			name: "TOML",
+			file: "config.toml",
+			content: `
+http_addr = "9090"
+token_ttl = "1h"
+capsule_max_recipients = 3
+
+[[oidc_providers]]
+name = "google"
+issuer = "https://accounts.google.com"
+clientID = "client"
+clientSecret = "secret"
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(fileEnv, writeFile(t, test.file, test.content))
+			t.Setenv("CAPSULE_MAX_RECIPIENTS", "5")
+
+			cfg := validConfig(t)
+
+			// The file overrides the defaults, and the environment overrides the file.
+			assert.Equal(t, "9090", cfg.HttpAddr)
+			assert.Equal(t, time.Hour, cfg.TokenTTL)
+			assert.Equal(t, 5, cfg.CapsuleMaxRecipients)
+			assert.Equal(t, 10, cfg.BcryptCost)
+			assert.Equal(t, OIDCProviders{{
+				Name:         "google",
+				Issuer:       "https://accounts.google.com",
+				ClientID:     "client",
+				ClientSecret: "secret",
+			}}, cfg.OIDCProviders)
+
+			assert.NoError(t, cfg.Validate())
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name           string
+		modify         func(cfg *Config)
+		expectedErrors []string
+	}{
+		{
+			name: "Empty-HTTP-Addr",
+			modify: func(cfg *Config) {
+				cfg.HttpAddr = ""
+			},
+			expectedErrors: []string{`HTTP_ADDR must be a port between 1 and 65535, got ""`},
+		},
+		{
+			name: "Invalid-Public-URL",
+			modify: func(cfg *Config) {
+				cfg.PublicURL = "time-capsule.example.com"
+			},
+			expectedErrors: []string{`PUBLIC_URL must be an http or https URL, got "time-capsule.example.com"`},
+		},
+		{
+			name: "Missing-Storage-Credentials",
+			modify: func(cfg *Config) {
+				cfg.MinioUsername = ""
+				cfg.MinioPassword = ""
+			},
+			expectedErrors: []string{"MINIO_USERNAME is required", "MINIO_PASSWORD is required"},
+		},
+		{
+			name: "Missing-Sender",
+			modify: func(cfg *Config) {
+				cfg.SMTPUsername = ""
+			},
+			expectedErrors: []string{`SMTP_FROM must be an email address, or SMTP_USERNAME one, got ""`},
+		},
+		{
+			name: "Unknown-Values",
+			modify: func(cfg *Config) {
+				cfg.SMTPTLSMode = "ssl"
+				cfg.RateLimitStore = "redis"
+				cfg.LogLevel = "trace"
+				cfg.TracingExporter = "jaeger"
+			},
+			expectedErrors: []string{
+				`SMTP_TLS_MODE must be one of ["starttls" "tls" "none"], got "ssl"`,
+				`RATE_LIMIT_STORE must be one of ["memory" "mongodb"], got "redis"`,
+				`LOG_LEVEL must be debug, info, warn or error, got "trace"`,
+				`TRACING_EXPORTER must be one of ["none" "otlp" "stdout"], got "jaeger"`,
+			},
+		},
+		{
+			name: "Invalid-Durations",
+			modify: func(cfg *Config) {
+				cfg.WorkerInterval = 0
+				cfg.WorkerMaxCycleDuration = -time.Minute
+				cfg.ShutdownDelay = -time.Second
+			},
+			expectedErrors: []string{
+				"SHUTDOWN_DELAY must not be negative",
+				"WORKER_INTERVAL must be positive, got 0s",
+				"WORKER_MAX_CYCLE_DURATION must be positive, got -1m0s",
+			},
+		},
+		{
+			name: "Invalid-Bcrypt-Cost",
+			modify: func(cfg *Config) {
+				cfg.BcryptCost = 64
+			},
+			expectedErrors: []string{"BCRYPT_COST must be between 4 and 31, got 64"},
+		},
+		{
+			name: "Missing-Signing-Key",
+			modify: func(cfg *Config) {
+				cfg.JWTGenerateKey = false
+			},
+			expectedErrors: []string{"JWT_SIGNING_KEY_FILE is required, or JWT_GENERATE_KEY for development"},
+		},
+		{
+			name: "Signing-Key-And-Generated-Key",
+			modify: func(cfg *Config) {
+				cfg.JWTSigningKeyFile = "/nonexistent/key.pem"
+			},
+			expectedErrors: []string{"JWT_GENERATE_KEY must not be set along with JWT_SIGNING_KEY_FILE"},
+		},
+		{
+			name: "Verification-Keys-Without-Signing-Key",
+			modify: func(cfg *Config) {
+				cfg.JWTVerificationKeyFiles = []string{"/nonexistent/key.pem"}
+			},
+			expectedErrors: []string{
+				"JWT_VERIFICATION_KEY_FILES must be set along with JWT_SIGNING_KEY_FILE",
+				"JWT_VERIFICATION_KEY_FILES must be a readable file: stat /nonexistent/key.pem: no such file or directory",
+			},
+		},
+		{
+			name: "Invalid-OIDC-Providers",
+			modify: func(cfg *Config) {
+				cfg.OIDCProviders = OIDCProviders{
+					{Name: "google", Issuer: "https://accounts.google.com", ClientID: "client"},
+					{Name: "google", Issuer: "https://accounts.google.com"},
+				}
+			},
+			expectedErrors: []string{
+				"OIDC_PROVIDERS provider 1 must have a name, an issuer and a client ID",
+				`OIDC_PROVIDERS provider "google" is listed twice`,
+			},
+		},
+		{
+			name: "Invalid-Capsule-Rules",
+			modify: func(cfg *Config) {
+				cfg.CapsuleMinInactivityDays = 30
+				cfg.CapsuleMaxInactivityDays = 7
+			},
+			expectedErrors: []string{"CAPSULE_MAX_INACTIVITY_DAYS must be at least CAPSULE_MIN_INACTIVITY_DAYS"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			test.modify(cfg)
+
+			err := cfg.Validate()
+			require.Error(t, err)
+
+			for _, expected := range test.expectedErrors {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
+
+func TestConfig_Print(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.OIDCProviders = OIDCProviders{{Name: "google", ClientSecret: "client-secret"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, cfg.Print(&buf))
+
+	out := buf.String()
+
+	assert.Contains(t, out, `http_addr: "8080"`)
+	assert.Contains(t, out, "token_ttl: 168h0m0s")
+	assert.Contains(t, out, "minio_password: '[REDACTED]'")
+	assert.Contains(t, out, "clientSecret: '[REDACTED]'")
+	assert.Contains(t, out, `mongo_password: ""`)
+	assert.NotContains(t, out, "minio-password")
+	assert.NotContains(t, out, "client-secret")
+
+	// The config itself is left as is.
+	assert.Equal(t, "minio-password", cfg.MinioPassword)
+	assert.Equal(t, []oidc.Config{{Name: "google", ClientSecret: "client-secret"}}, []oidc.Config(cfg.OIDCProviders))
+}
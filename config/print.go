@@ -0,0 +1,52 @@
+package config
+
+import (
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Print writes the config to w as YAML, in the format of the config file, with its secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// Redacted returns a copy of the config with the values of the fields tagged secret, and of the OIDC client secrets,
+// replaced so that it can be shown.
+func (c *Config) Redacted() *Config {
+	cfg := *c
+
+	v := reflect.ValueOf(&cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+
+		if v.Type().Field(i).Tag.Get("secret") == "true" && field.String() != "" {
+			field.SetString(redacted)
+		}
+	}
+
+	if c.OIDCProviders == nil {
+		return &cfg
+	}
+
+	cfg.OIDCProviders = make(OIDCProviders, len(c.OIDCProviders))
+	for i, provider := range c.OIDCProviders {
+		if provider.ClientSecret != "" {
+			provider.ClientSecret = redacted
+		}
+
+		cfg.OIDCProviders[i] = provider
+	}
+
+	return &cfg
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"time-capsule/internal/logging"
+	mailer "time-capsule/internal/mail"
+	"time-capsule/internal/ratelimit"
+	"time-capsule/internal/tracing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Validate checks the config, returning an error listing every invalid value by its environment variable.
+func (c *Config) Validate() error {
+	var v validator
+
+	v.port("HTTP_ADDR", c.HttpAddr)
+	v.positive("HTTP_READ_TIMEOUT", c.HTTPReadTimeout)
+	v.positive("HTTP_WRITE_TIMEOUT", c.HTTPWriteTimeout)
+	v.check("SHUTDOWN_DELAY", c.ShutdownDelay >= 0, "must not be negative")
+	v.check("PUBLIC_URL", validURL(c.PublicURL), "must be an http or https URL, got %q", c.PublicURL)
+
+	v.required("MONGO_HOST", c.MongoHost)
+	v.port("MONGO_PORT", c.MongoPort)
+	v.required("MONGO_DBNAME", c.MongoDBName)
+	v.check("MONGO_PASSWORD", (c.MongoUsername == "") == (c.MongoPassword == ""),
+		"must be set along with MONGO_USERNAME")
+
+	v.required("SMTP_HOST", c.SMTPHost)
+	v.port("SMTP_PORT", c.SMTPPort)
+	v.oneOf("SMTP_TLS_MODE", c.SMTPTLSMode, mailer.TLSModeStartTLS, mailer.TLSModeImplicit, mailer.TLSModeNone)
+
+	from := c.SMTPFrom
+	if from == "" {
+		from = c.SMTPUsername
+	}
+	_, err := mail.ParseAddress(from)
+	v.check("SMTP_FROM", err == nil, "must be an email address, or SMTP_USERNAME one, got %q", from)
+
+	v.required("MINIO_HOST", c.MinioHost)
+	v.port("MINIO_PORT", c.MinioPort)
+	v.required("MINIO_USERNAME", c.MinioUsername)
+	v.required("MINIO_PASSWORD", c.MinioPassword)
+	v.required("BUCKET_NAME", c.MinioBucketName)
+	v.positive("STORAGE_TIMEOUT", c.StorageTimeout)
+	v.check("MAX_UPLOAD_SIZE", c.MaxUploadSize > 0, "must be positive")
+
+	v.positive("WORKER_INTERVAL", c.WorkerInterval)
+	v.positive("WORKER_MAX_CYCLE_DURATION", c.WorkerMaxCycleDuration)
+
+	v.oneOf("RATE_LIMIT_STORE", c.RateLimitStore, ratelimit.StoreMemory, ratelimit.StoreMongoDB)
+
+	switch {
+	case c.JWTSigningKeyFile == "":
+		v.check("JWT_SIGNING_KEY_FILE", c.JWTGenerateKey, "is required, or JWT_GENERATE_KEY for development")
+	case c.JWTGenerateKey:
+		v.check("JWT_GENERATE_KEY", false, "must not be set along with JWT_SIGNING_KEY_FILE")
+	default:
+		v.file("JWT_SIGNING_KEY_FILE", c.JWTSigningKeyFile)
+	}
+	for _, path := range c.JWTVerificationKeyFiles {
+		v.check("JWT_VERIFICATION_KEY_FILES", c.JWTSigningKeyFile != "", "must be set along with JWT_SIGNING_KEY_FILE")
+		v.file("JWT_VERIFICATION_KEY_FILES", path)
+	}
+	v.positive("TOKEN_TTL", c.TokenTTL)
+	v.check("BCRYPT_COST", c.BcryptCost >= bcrypt.MinCost && c.BcryptCost <= bcrypt.MaxCost,
+		"must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.BcryptCost)
+
+	names := make(map[string]bool, len(c.OIDCProviders))
+	for i, provider := range c.OIDCProviders {
+		v.check("OIDC_PROVIDERS", provider.Name != "" && provider.Issuer != "" && provider.ClientID != "",
+			"provider %d must have a name, an issuer and a client ID", i)
+		v.check("OIDC_PROVIDERS", !names[provider.Name], "provider %q is listed twice", provider.Name)
+		names[provider.Name] = true
+	}
+
+	_, err = logging.ParseLevel(c.LogLevel)
+	v.check("LOG_LEVEL", err == nil, "must be debug, info, warn or error, got %q", c.LogLevel)
+
+	v.oneOf("TRACING_EXPORTER", c.TracingExporter, tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout)
+
+	v.check("CAPSULE_MIN_MESSAGE_LENGTH", c.CapsuleMinMessageLength >= 0, "must not be negative")
+	v.check("CAPSULE_MIN_OPEN_DELAY", c.CapsuleMinOpenDelay >= 0, "must not be negative")
+	v.check("CAPSULE_EDIT_WINDOW", c.CapsuleEditWindow >= 0, "must not be negative")
+	v.check("CAPSULE_MAX_RECIPIENTS", c.CapsuleMaxRecipients >= 0, "must not be negative")
+	v.check("CAPSULE_MIN_INACTIVITY_DAYS", c.CapsuleMinInactivityDays > 0, "must be positive")
+	v.check("CAPSULE_MAX_INACTIVITY_DAYS", c.CapsuleMaxInactivityDays >= c.CapsuleMinInactivityDays,
+		"must be at least CAPSULE_MIN_INACTIVITY_DAYS")
+
+	return errors.Join(v.errs...)
+}
+
+// validator collects the errors of the invalid values.
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(name string, ok bool, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s %s", name, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (v *validator) required(name, value string) {
+	v.check(name, value != "", "is required")
+}
+
+func (v *validator) port(name, value string) {
+	port, err := strconv.Atoi(value)
+	v.check(name, err == nil && port > 0 && port <= 65535, "must be a port between 1 and 65535, got %q", value)
+}
+
+func (v *validator) positive(name string, d time.Duration) {
+	v.check(name, d > 0, "must be positive, got %s", d)
+}
+
+func (v *validator) oneOf(name, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+
+	v.check(name, false, "must be one of %q, got %q", allowed, value)
+}
+
+func (v *validator) file(name, path string) {
+	_, err := os.Stat(path)
+	v.check(name, err == nil, "must be a readable file: %v", err)
+}
+
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
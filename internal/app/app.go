@@ -2,67 +2,145 @@ package app
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"time-capsule/config"
+	"time-capsule/internal/events"
 	"time-capsule/internal/handler"
+	"time-capsule/internal/jwks"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/metrics"
+	"time-capsule/internal/ratelimit"
 	"time-capsule/internal/repository"
 	"time-capsule/internal/service"
 	"time-capsule/internal/storage"
+	"time-capsule/internal/tracing"
 	"time-capsule/internal/worker"
 	"time-capsule/pkg/httpserver"
 	"time-capsule/pkg/minio"
 	"time-capsule/pkg/mongodb"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func Run(cfg *config.Config) {
 	ctx := context.Background()
 
-	db, err := mongodb.New(ctx, cfg)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracingExporter, os.Stdout)
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
+
+	db, err := mongodb.New(ctx, cfg, options.Client().SetMonitor(mongodb.Monitors(
+		metrics.MongoMonitor(),
+		tracing.MongoMonitor(),
+	)))
 	if err != nil {
-		log.Fatalf("failed to create a mongodb connection: %v", err)
+		fatal("failed to create a mongodb connection", err)
 	}
 
 	minioStorage, err := minio.New(cfg)
 	if err != nil {
-		log.Fatalf("failed to create a minio connection: %v", err)
+		fatal("failed to create a minio connection", err)
+	}
+
+	renderer, err := mail.NewRenderer(cfg.MailTemplatesDir)
+	if err != nil {
+		fatal("failed to load email templates", err)
+	}
+
+	sender, err := mail.NewSender(mail.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+		TLSMode:  cfg.SMTPTLSMode,
+	})
+	if err != nil {
+		fatal("failed to configure smtp", err)
+	}
+
+	var keys *jwks.KeySet
+
+	if cfg.JWTGenerateKey {
+		slog.Warn("JWT_GENERATE_KEY is set, signing tokens with a generated key: they won't survive restarts")
+		keys, err = jwks.Generate()
+	} else {
+		keys, err = jwks.Load(cfg.JWTSigningKeyFile, cfg.JWTVerificationKeyFiles)
+	}
+	if err != nil {
+		fatal("failed to load jwt keys", err)
+	}
+
+	var rateLimitStore ratelimit.Store
+
+	switch cfg.RateLimitStore {
+	case "", ratelimit.StoreMemory:
+		rateLimitStore = ratelimit.NewMemoryStore()
+	case ratelimit.StoreMongoDB:
+		rateLimitStore = ratelimit.NewMongoStore(db)
+	default:
+		fatal("failed to configure rate limiting", ratelimit.ErrUnknownStore)
 	}
 
 	var (
-		rpstry = repository.NewRepository(db)
-		strge  = storage.NewMinioStorage(minioStorage, cfg.MinioBucketName)
-		svc    = service.NewService(rpstry, strge)
-		hndlr  = handler.NewHandler(svc, strge)
-		srvr   = httpserver.NewServer()
+		broker  = events.NewBroker()
+		limiter = ratelimit.NewLimiter(rateLimitStore)
+		rpstry  = repository.NewRepository(db)
+		strge   = storage.NewMinioStorage(minioStorage, cfg.MinioBucketName, cfg.StorageTimeout)
+		svc     = service.NewService(cfg, rpstry, strge, renderer, broker, keys, limiter)
+		wrkr    = worker.New(cfg, rpstry, strge, renderer, sender, broker, svc)
+		hlth    = newHealthChecker(cfg, db, minioStorage, wrkr)
+		hndlr   = handler.NewHandler(cfg, svc, strge, limiter, hlth)
+		srvr    = httpserver.NewServer()
 	)
 
-	go worker.Run(ctx, cfg, rpstry)
+	go wrkr.Run(ctx)
 
 	go func() {
 		if err = srvr.Run(cfg, hndlr.Router()); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("failed to listen on tcp network: %v", err)
+			fatal("failed to listen on tcp network", err)
 		}
 	}()
 
-	log.Printf("time capsule service is up and running on port %s\n", cfg.HttpAddr)
+	slog.Info("time capsule service is up and running", "addr", cfg.HttpAddr)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT, os.Interrupt)
 	<-quit
 
-	log.Println("received shutdown signal. initiating graceful shutdown...")
+	slog.Info("received shutdown signal, initiating graceful shutdown")
+
+	// Readiness fails from now on, the delay gives load balancers time to notice before connections are refused.
+	hlth.Shutdown()
+	time.Sleep(cfg.ShutdownDelay)
+
+	// Event streams last until the client goes away, closing the broker ends them so that the server can shut down.
+	broker.Close()
 
 	if err = srvr.Shutdown(ctx); err != nil {
-		log.Printf("error occurred while shutting down http server: %v\n", err)
+		slog.Error("failed to shut down the http server", "error", err)
 	}
 
 	if err = db.Client().Disconnect(ctx); err != nil {
-		log.Printf("error occurred while disconneting from mongodb: %v\n", err)
+		slog.Error("failed to disconnect from mongodb", "error", err)
+	}
+
+	if err = shutdownTracing(ctx); err != nil {
+		slog.Error("failed to flush spans", "error", err)
 	}
 
-	log.Println("have a nice day!")
+	slog.Info("have a nice day!")
+}
+
+// fatal logs the error the service can't start with and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
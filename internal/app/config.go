@@ -0,0 +1,28 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"time-capsule/config"
+)
+
+// Config inspects the config the service would run with, read from the config file and the environment.
+//
+//	app config print     prints it as YAML, with its secrets redacted
+//	app config validate  reports its invalid values
+func Config(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("a command is required: print or validate")
+	}
+
+	switch args[0] {
+	case "print":
+		return cfg.Print(os.Stdout)
+	case "validate":
+		return cfg.Validate()
+	default:
+		return fmt.Errorf("unknown command %q: print or validate", args[0])
+	}
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"time-capsule/config"
+	"time-capsule/internal/health"
+	"time-capsule/internal/worker"
+
+	"github.com/minio/minio-go/v7"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// newHealthChecker checks MongoDB, the MinIO bucket and the worker. The SMTP server is optional,
+// emails wait in the outbox while it's down. The worker beats when it finishes a cycle, so it may go
+// without beating for an interval and the longest a cycle may take.
+func newHealthChecker(cfg *config.Config, db *mongo.Database, minioClient *minio.Client, wrkr *worker.Worker) *health.Checker {
+	checker := health.NewChecker()
+
+	checker.Add("mongodb", func(ctx context.Context) error {
+		return db.Client().Ping(ctx, readpref.Primary())
+	})
+
+	checker.Add("minio", func(ctx context.Context) error {
+		exists, err := minioClient.BucketExists(ctx, cfg.MinioBucketName)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return fmt.Errorf("bucket %q doesn't exist", cfg.MinioBucketName)
+		}
+
+		return nil
+	})
+
+	if cfg.SMTPHost != "" {
+		checker.AddOptional("smtp", health.Dial(net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort)))
+	}
+
+	checker.Add("worker", health.Heartbeat(wrkr.Heartbeat, cfg.WorkerInterval+cfg.WorkerMaxCycleDuration))
+
+	return checker
+}
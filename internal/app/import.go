@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/export"
+	"time-capsule/internal/repository"
+	"time-capsule/internal/service"
+	"time-capsule/internal/storage"
+	"time-capsule/pkg/minio"
+	"time-capsule/pkg/mongodb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Import imports an export archive or JSON manifest into the account of a user, e.g. when migrating
+// users between instances. The report is printed to stdout as JSON.
+//
+//	app import -user <email or ID> <archive>
+func Import(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	user := flags.String("user", "", "email or ID of the user to import the capsules for")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *user == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("a user and an archive are required")
+	}
+
+	b, err := readArchive(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to read the archive: %w", err)
+	}
+
+	archive, err := export.Read(b)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	db, err := mongodb.New(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create a mongodb connection: %w", err)
+	}
+	defer db.Client().Disconnect(ctx)
+
+	minioStorage, err := minio.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create a minio connection: %w", err)
+	}
+
+	rpstry := repository.NewRepository(db)
+
+	owner, err := findUser(ctx, rpstry, *user)
+	if err != nil {
+		return err
+	}
+
+	svc := service.NewImportService(rpstry.CapsuleRepository, rpstry.CollectionRepository, rpstry.AuditRepository,
+		storage.NewMinioStorage(minioStorage, cfg.MinioBucketName, cfg.StorageTimeout), service.NewCapsuleRules(cfg))
+
+	report, err := svc.ImportCapsules(ctx, owner.ID, archive)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+// readArchive reads the archive at path, "-" reads it from stdin.
+func readArchive(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+
+	return os.ReadFile(path)
+}
+
+// findUser finds the user by email or ID.
+func findUser(ctx context.Context, rpstry *repository.Repository, user string) (*domain.User, error) {
+	filter := bson.M{"email": user}
+	if id, err := primitive.ObjectIDFromHex(user); err == nil {
+		filter = bson.M{"_id": id}
+	}
+
+	res, err := rpstry.GetUser(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the user: %w", err)
+	}
+
+	return res, nil
+}
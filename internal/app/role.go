@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/repository"
+	"time-capsule/internal/service"
+	"time-capsule/pkg/mongodb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Role grants a user a role, or revokes it with an empty one. It's how the first admin is appointed,
+// the other staff can then be granted their roles with the admin API.
+//
+//	app role -user <email or ID> <support|admin|"">
+func Role(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("role", flag.ContinueOnError)
+	user := flags.String("user", "", "email or ID of the user to grant the role to")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *user == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("a user and a role are required")
+	}
+
+	ctx := context.Background()
+
+	db, err := mongodb.New(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create a mongodb connection: %w", err)
+	}
+	defer db.Client().Disconnect(ctx)
+
+	rpstry := repository.NewRepository(db)
+
+	owner, err := findUser(ctx, rpstry, *user)
+	if err != nil {
+		return err
+	}
+
+	svc := service.NewAdminService(rpstry.UserRepository, rpstry.CapsuleRepository, rpstry.OutboxRepository,
+		rpstry.ExportRepository, rpstry.AuditRepository)
+
+	return svc.UpdateRole(ctx, primitive.NilObjectID, owner.ID, domain.UpdateRoleDTO{Role: flags.Arg(0)})
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// RoleSupport can look up users and their capsules, and resend notifications.
+	RoleSupport = "support"
+	// RoleAdmin can do everything support can, disable accounts and grant roles.
+	RoleAdmin = "admin"
+)
+
+type UpdateRoleDTO struct {
+	// Role is RoleSupport, RoleAdmin or empty to revoke the user's role.
+	Role string `json:"role"`
+}
+
+// CapsuleMetadata is what staff can see of a capsule: everything but its message and images.
+type CapsuleMetadata struct {
+	ID        primitive.ObjectID `json:"id"`
+	UserID    primitive.ObjectID `json:"userID"`
+	OpenAt    time.Time          `json:"openAt"`
+	CreatedAt time.Time          `json:"createdAt"`
+	// Opened is set once the owner was notified of the last occurrence of the capsule.
+	Opened bool `json:"opened"`
+	// Images is the number of images of the capsule.
+	Images int `json:"images"`
+
+	Tags             []string             `json:"tags,omitempty"`
+	Collections      []primitive.ObjectID `json:"collections,omitempty"`
+	Recurrence       *Recurrence          `json:"recurrence,omitempty"`
+	Occurrences      int                  `json:"occurrences,omitempty"`
+	NextOccurrenceAt *time.Time           `json:"nextOccurrenceAt,omitempty"`
+	Recipients       []string             `json:"recipients,omitempty"`
+	Reminders        []int                `json:"reminders"`
+	RemindersSent    []int                `json:"remindersSent,omitempty"`
+	Mode             string               `json:"mode,omitempty"`
+	InactivityDays   int                  `json:"inactivityDays,omitempty"`
+}
+
+// WorkerStatus describes the background worker running in this instance and the work waiting for it.
+type WorkerStatus struct {
+	// LastCycleStartedAt and LastCycleFinishedAt are unset until the worker ran once.
+	LastCycleStartedAt  *time.Time `json:"lastCycleStartedAt,omitempty"`
+	LastCycleFinishedAt *time.Time `json:"lastCycleFinishedAt,omitempty"`
+
+	// DueCapsules are the capsules whose time came that weren't opened yet.
+	DueCapsules    int64 `json:"dueCapsules"`
+	PendingEmails  int64 `json:"pendingEmails"`
+	FailedEmails   int64 `json:"failedEmails"`
+	PendingExports int64 `json:"pendingExports"`
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditSignIn             = "sign_in"
+	AuditSignInFailed       = "sign_in_failed"
+	AuditAccountLocked      = "account_locked"
+	AuditAccountUnlocked    = "account_unlocked"
+	AuditTwoFactorEnabled   = "two_factor_enabled"
+	AuditTwoFactorDisabled  = "two_factor_disabled"
+	AuditAccessTokenCreated = "access_token_created"
+	AuditAccessTokenRevoked = "access_token_revoked"
+	AuditIdentityLinked     = "identity_linked"
+	AuditEmailChanged       = "email_changed"
+	AuditDeletionScheduled  = "account_deletion_scheduled"
+	AuditDeletionCanceled   = "account_deletion_canceled"
+	AuditAccountDeleted     = "account_deleted"
+	AuditExportRequested    = "export_requested"
+	AuditCapsulesImported   = "capsules_imported"
+	AuditAccountDisabled    = "account_disabled"
+	AuditAccountEnabled     = "account_enabled"
+	AuditRoleChanged        = "role_changed"
+
+	AuditCapsuleCreated = "capsule_created"
+	AuditCapsuleUpdated = "capsule_updated"
+	AuditCapsuleDeleted = "capsule_deleted"
+	AuditImageAdded     = "image_added"
+	AuditImageRemoved   = "image_removed"
+
+	AuditOutboxEntryReplayed = "outbox_entry_replayed"
+	AuditNotificationsResent = "notifications_resent"
+)
+
+// AuditEvent records a security-relevant action. Events are only ever appended.
+type AuditEvent struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Action string             `json:"action" bson:"action"`
+	// UserID is the user the action is about, if it's known.
+	UserID primitive.ObjectID `json:"userID" bson:"userID,omitempty"`
+	// ActorID is the signed-in user who took the action, the user themself or a staff member.
+	// It's missing for actions taken before signing in or on the command line.
+	ActorID   primitive.ObjectID `json:"actorID" bson:"actorID,omitempty"`
+	RequestID string             `json:"requestID,omitempty" bson:"requestID,omitempty"`
+	IP        string             `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent string             `json:"userAgent,omitempty" bson:"userAgent,omitempty"`
+	Details   map[string]string  `json:"details,omitempty" bson:"details,omitempty"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// AuditFilter narrows down audit events. Events are returned newest first, Before pages through them.
+type AuditFilter struct {
+	UserID    primitive.ObjectID
+	ActorID   primitive.ObjectID
+	Action    string
+	RequestID string
+	// Before is the ID of the last event of the previous page.
+	Before primitive.ObjectID
+	Limit  int64
+}
@@ -6,9 +6,29 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	CapsuleModeScheduled  = "scheduled"
+	CapsuleModeInactivity = "inactivity"
+
+	// MaxReminderDays is the furthest ahead of the opening a reminder can be sent.
+	MaxReminderDays = 30
+)
+
 type CreateCapsuleDTO struct {
-	Message string    `json:"message"`
-	OpenAt  time.Time `json:"openAt"`
+	Message    string      `json:"message"`
+	OpenAt     time.Time   `json:"openAt"`
+	Tags       []string    `json:"tags,omitempty"`
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	Recipients []string    `json:"recipients,omitempty"`
+
+	// Reminders are the days before the opening at which the owner is reminded about the capsule.
+	// When omitted the owner's default reminders apply, an empty list disables them.
+	Reminders []int `json:"reminders"`
+
+	// Mode "inactivity" opens the capsule once the owner hasn't checked in for InactivityDays.
+	// OpenAt is ignored in that mode.
+	Mode           string `json:"mode,omitempty"`
+	InactivityDays int    `json:"inactivityDays,omitempty"`
 }
 
 type UpdateCapsuleDTO struct {
@@ -24,4 +44,45 @@ type Capsule struct {
 	OpenAt    time.Time          `json:"openAt" bson:"openAt"`
 	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
 	Notified  bool               `json:"-" bson:"notified"`
+
+	Tags        []string             `json:"tags,omitempty" bson:"tags,omitempty"`
+	Collections []primitive.ObjectID `json:"collections,omitempty" bson:"collections,omitempty"`
+
+	Recurrence       *Recurrence `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+	Occurrences      int         `json:"occurrences,omitempty" bson:"occurrences,omitempty"`
+	NextOccurrenceAt *time.Time  `json:"nextOccurrenceAt,omitempty" bson:"nextOccurrenceAt,omitempty"`
+
+	// Recipients are notified by email, in addition to the owner, when the capsule opens.
+	Recipients []string `json:"recipients,omitempty" bson:"recipients,omitempty"`
+
+	// Reminders is null when the owner's default reminders apply and empty when they are turned off.
+	// RemindersSent is reset on every occurrence.
+	Reminders     []int `json:"reminders" bson:"reminders"`
+	RemindersSent []int `json:"-" bson:"remindersSent,omitempty"`
+
+	// For inactivity capsules OpenAt is the check-in deadline. It moves forward on every check-in.
+	Mode                 string `json:"mode,omitempty" bson:"mode,omitempty"`
+	InactivityDays       int    `json:"inactivityDays,omitempty" bson:"inactivityDays,omitempty"`
+	CheckInRemindersSent []int  `json:"-" bson:"checkInRemindersSent,omitempty"`
+}
+
+// Recurrence describes a repeating open schedule anchored at the capsule's OpenAt.
+// It can be given either field by field or as an RRULE string, e.g. "FREQ=YEARLY;COUNT=10".
+type Recurrence struct {
+	Frequency string     `json:"frequency,omitempty" bson:"frequency"`
+	Interval  int        `json:"interval,omitempty" bson:"interval,omitempty"`
+	Count     int        `json:"count,omitempty" bson:"count,omitempty"`
+	Until     *time.Time `json:"until,omitempty" bson:"until,omitempty"`
+	RRule     string     `json:"rrule,omitempty" bson:"-"`
+}
+
+type CapsuleFilter struct {
+	Tag          string
+	CollectionID primitive.ObjectID
+}
+
+type CapsuleSearchResult struct {
+	Capsule *Capsule `json:"capsule"`
+	Score   float64  `json:"score"`
+	Snippet string   `json:"snippet"`
 }
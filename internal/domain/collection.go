@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CreateCollectionDTO struct {
+	Name string `json:"name"`
+}
+
+type UpdateCollectionDTO struct {
+	Name string `json:"name"`
+}
+
+type Collection struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"userID" bson:"userID"`
+	Name      string             `json:"name" bson:"name"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
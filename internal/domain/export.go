@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ExportStatusPending = "pending"
+	ExportStatusReady   = "ready"
+	// ExportStatusFailed marks exports that ran out of attempts to build.
+	ExportStatusFailed = "failed"
+	// ExportStatusExpired marks exports whose archive was deleted after it expired.
+	ExportStatusExpired = "expired"
+
+	ImportItemCapsule    = "capsule"
+	ImportItemCollection = "collection"
+)
+
+// Export is a ZIP archive of all the data of a user, built in the background.
+type Export struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID primitive.ObjectID `json:"-" bson:"userID"`
+	Status string             `json:"status" bson:"status"`
+
+	// File is the name of the archive in the storage, once it's ready.
+	File string `json:"-" bson:"file,omitempty"`
+	Size int64  `json:"size,omitempty" bson:"size,omitempty"`
+	// DownloadTokenHash is the hash of the token of the download link sent to the user.
+	DownloadTokenHash string `json:"-" bson:"downloadTokenHash,omitempty"`
+
+	Attempts      int        `json:"-" bson:"attempts"`
+	NextAttemptAt time.Time  `json:"-" bson:"nextAttemptAt"`
+	CreatedAt     time.Time  `json:"createdAt" bson:"createdAt"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
+	// ExpiresAt is when the archive is deleted and the download link stops working.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+}
+
+// ImportReport is the outcome of an import. Items that couldn't be imported are listed
+// in Errors, the rest of the import goes on without them.
+type ImportReport struct {
+	Capsules    int           `json:"capsules"`
+	Collections int           `json:"collections"`
+	Images      int           `json:"images"`
+	Errors      []ImportError `json:"errors"`
+}
+
+type ImportError struct {
+	// Type is either ImportItemCapsule or ImportItemCollection.
+	Type string `json:"type"`
+	// Index is the position of the item in the manifest.
+	Index int `json:"index"`
+	// ID is the ID of the item in the manifest, if it has one.
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
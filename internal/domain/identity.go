@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Identity links the user to their account at an OpenID Connect identity provider.
+type Identity struct {
+	Provider string    `json:"provider" bson:"provider"`
+	Subject  string    `json:"-" bson:"subject"`
+	LinkedAt time.Time `json:"linkedAt" bson:"linkedAt"`
+}
+
+// OIDCAuthorization is where to send the user to sign in at the identity provider.
+type OIDCAuthorization struct {
+	URL string
+	// StateToken binds the sign-in to the browser that started it. It's kept in a cookie until the callback.
+	StateToken string
+	ExpiresAt  time.Time
+}
+
+// OIDCCallback is what the identity provider redirects back with.
+type OIDCCallback struct {
+	Code       string
+	State      string
+	StateToken string
+	// Error is set when the sign-in failed or was denied at the provider.
+	Error string
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	NotificationCapsuleOpened   = "capsule_opened"
+	NotificationCapsuleReminder = "capsule_reminder"
+	NotificationCheckInReminder = "check_in_reminder"
+	NotificationExportReady     = "export_ready"
+)
+
+// Notification is an in-app notification of the user. Key identifies the event it's about,
+// like the key of the corresponding email, so that it's stored once.
+type Notification struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Key       string             `json:"-" bson:"key"`
+	UserID    primitive.ObjectID `json:"userID" bson:"userID"`
+	Type      string             `json:"type" bson:"type"`
+	CapsuleID primitive.ObjectID `json:"capsuleID" bson:"capsuleID"`
+	// Days is the number of days left for reminders.
+	Days int `json:"days,omitempty" bson:"days,omitempty"`
+	// URL links to what the notification is about, e.g. the download link of an export.
+	URL       string     `json:"url,omitempty" bson:"url,omitempty"`
+	CreatedAt time.Time  `json:"createdAt" bson:"createdAt"`
+	ReadAt    *time.Time `json:"readAt,omitempty" bson:"readAt,omitempty"`
+}
+
+type NotificationList struct {
+	Notifications []*Notification `json:"notifications"`
+	Unread        int64           `json:"unread"`
+}
+
+// MarkNotificationsReadDTO lists the notifications to mark as read, all of them when empty.
+type MarkNotificationsReadDTO struct {
+	IDs []primitive.ObjectID `json:"ids"`
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	// OutboxStatusFailed marks dead-lettered entries that ran out of delivery attempts.
+	OutboxStatusFailed = "failed"
+)
+
+// OutboxEntry is an email waiting to be delivered, rendered when it was queued.
+// Key identifies the notification, e.g. a capsule's occurrence and recipient, so that it's queued once.
+type OutboxEntry struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Key       string             `json:"key" bson:"key"`
+	UserID    primitive.ObjectID `json:"userID" bson:"userID"`
+	CapsuleID primitive.ObjectID `json:"capsuleID,omitempty" bson:"capsuleID,omitempty"`
+	Recipient string             `json:"recipient" bson:"recipient"`
+	Template  string             `json:"template" bson:"template"`
+
+	Subject string `json:"subject" bson:"subject"`
+	HTML    string `json:"-" bson:"html"`
+	Text    string `json:"-" bson:"text"`
+	// Images are the stored images attached as inline thumbnails, in the order the email references them.
+	Images []string `json:"-" bson:"images,omitempty"`
+
+	Status        string     `json:"status" bson:"status"`
+	Attempts      int        `json:"attempts" bson:"attempts"`
+	LastError     string     `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" bson:"nextAttemptAt"`
+	CreatedAt     time.Time  `json:"createdAt" bson:"createdAt"`
+	SentAt        *time.Time `json:"sentAt,omitempty" bson:"sentAt,omitempty"`
+}
+
+// OutboxKey joins the parts identifying a notification.
+func OutboxKey(parts ...any) string {
+	s := make([]string, len(parts))
+	for i, part := range parts {
+		s[i] = fmt.Sprint(part)
+	}
+
+	return strings.Join(s, ":")
+}
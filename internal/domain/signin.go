@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SignInFailures counts the recent failed sign-ins of an account or an IP, identified by Key.
+// The counter is dropped once ExpiresAt passes without further failures.
+type SignInFailures struct {
+	Key           string    `bson:"_id"`
+	Failures      int       `bson:"failures"`
+	LastFailureAt time.Time `bson:"lastFailureAt"`
+	ExpiresAt     time.Time `bson:"expiresAt"`
+
+	// LockedUntil is set when the account was locked, until then signing in is refused
+	// unless the account is unlocked with the token emailed to the user.
+	LockedUntil     *time.Time         `bson:"lockedUntil,omitempty"`
+	UserID          primitive.ObjectID `bson:"userID,omitempty"`
+	UnlockTokenHash string             `bson:"unlockTokenHash,omitempty"`
+}
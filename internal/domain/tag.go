@@ -0,0 +1,19 @@
+package domain
+
+type AddTagsDTO struct {
+	Tags []string `json:"tags"`
+}
+
+type RenameTagDTO struct {
+	Name string `json:"name"`
+}
+
+type MergeTagsDTO struct {
+	Tags []string `json:"tags"`
+	Into string   `json:"into"`
+}
+
+type TagCount struct {
+	Name  string `json:"name" bson:"_id"`
+	Count int    `json:"count" bson:"count"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Scopes of personal access tokens. Sessions signed in with a password aren't limited by scopes.
+const (
+	ScopeCapsulesRead  = "capsules:read"
+	ScopeCapsulesWrite = "capsules:write"
+	ScopeImagesWrite   = "images:write"
+)
+
+var Scopes = []string{ScopeCapsulesRead, ScopeCapsulesWrite, ScopeImagesWrite}
+
+type CreateAccessTokenDTO struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is optional, tokens without it are valid until revoked.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// AccessToken is a personal access token, for scripts and integrations to use instead of signing in.
+type AccessToken struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID primitive.ObjectID `json:"-" bson:"userID"`
+	Name   string             `json:"name" bson:"name"`
+	// Token is only returned once, when the token is created. Only its hash is stored.
+	Token string `json:"token,omitempty" bson:"-"`
+	// Prefix is the start of the token, to tell tokens apart.
+	Prefix     string     `json:"prefix" bson:"prefix"`
+	Hash       string     `json:"-" bson:"hash"`
+	Scopes     []string   `json:"scopes" bson:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" bson:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt" bson:"createdAt"`
+}
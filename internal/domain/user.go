@@ -15,6 +15,62 @@ type CreateUserDTO struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Language string `json:"language,omitempty"`
+}
+
+// SignInResult holds the access token, or when the user has two-factor authentication enabled,
+// the token of the challenge to complete the sign-in with.
+type SignInResult struct {
+	Token       string `json:"token,omitempty"`
+	MFARequired bool   `json:"mfaRequired,omitempty"`
+	MFAToken    string `json:"mfaToken,omitempty"`
+}
+
+type MFASignInDTO struct {
+	MFAToken string `json:"mfaToken"`
+	// Code is a code of the authenticator app or a recovery code.
+	Code string `json:"code"`
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth:// URI to show as a QR code for authenticator apps to scan.
+	URI string `json:"uri"`
+}
+
+type TwoFactorCodeDTO struct {
+	Code string `json:"code"`
+}
+
+type RecoveryCodes struct {
+	// Codes are shown once, each of them can be used once instead of a code of the authenticator app.
+	Codes []string `json:"recoveryCodes"`
+}
+
+type UpdateRemindersDTO struct {
+	// Days before a capsule opens at which to send a reminder. Null restores the default reminders.
+	Days []int `json:"days"`
+}
+
+// UpdateProfileDTO holds the fields to change, empty fields are left as they are.
+// A new email takes effect once it's verified with the link sent to it.
+type UpdateProfileDTO struct {
+	Username    string `json:"username"`
+	Email       string `json:"email"`
+	DisplayName string `json:"displayName"`
+	// Timezone is an IANA time zone, e.g. "Europe/Berlin".
+	Timezone string `json:"timezone"`
+	Language string `json:"language"`
+}
+
+type DeleteAccountDTO struct {
+	// Password confirms the deletion, users signing in only with identity providers have none.
+	Password string `json:"password"`
+}
+
+// AccountDeletion is when the account and all its data are purged. Until then, the deletion can be canceled.
+type AccountDeletion struct {
+	ScheduledAt time.Time `json:"scheduledAt"`
 }
 
 type User struct {
@@ -23,4 +79,37 @@ type User struct {
 	Email        string             `json:"email"`
 	PasswordHash string             `json:"-"`
 	RegisteredAt time.Time          `json:"registeredAt"`
+
+	// Role is RoleSupport or RoleAdmin for staff, empty for everyone else.
+	Role string `json:"role,omitempty" bson:"role,omitempty"`
+	// DisabledAt is set while the account is disabled by staff, it can't be used until it's enabled again.
+	DisabledAt *time.Time `json:"disabledAt,omitempty" bson:"disabledAt,omitempty"`
+
+	DisplayName string `json:"displayName,omitempty" bson:"displayName,omitempty"`
+	// Timezone is the IANA time zone of the user, e.g. "Europe/Berlin".
+	Timezone string `json:"timezone,omitempty" bson:"timezone,omitempty"`
+	// Language is the preferred language of emails, e.g. "en".
+	Language string `json:"language,omitempty" bson:"language,omitempty"`
+
+	// PendingEmail replaces Email once it's verified with the token whose hash is EmailVerificationHash.
+	PendingEmail               string     `json:"pendingEmail,omitempty" bson:"pendingEmail,omitempty"`
+	EmailVerificationHash      string     `json:"-" bson:"emailVerificationHash,omitempty"`
+	EmailVerificationExpiresAt *time.Time `json:"-" bson:"emailVerificationExpiresAt,omitempty"`
+
+	// DeletionScheduledAt is when the account is purged, if its deletion was requested.
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty" bson:"deletionScheduledAt,omitempty"`
+
+	LastCheckInAt time.Time `json:"-" bson:"lastCheckInAt,omitempty"`
+	ReminderDays  []int     `json:"reminderDays,omitempty" bson:"reminderDays"`
+
+	// TOTPSecret is set once two-factor authentication is confirmed, TOTPPendingSecret while it's being enrolled.
+	TOTPSecret        string `json:"-" bson:"totpSecret,omitempty"`
+	TOTPPendingSecret string `json:"-" bson:"totpPendingSecret,omitempty"`
+	// TOTPLastCounter is the period of the last accepted code, codes can't be used twice.
+	TOTPLastCounter int64 `json:"-" bson:"totpLastCounter,omitempty"`
+	// RecoveryCodes are the hashes of the unused recovery codes.
+	RecoveryCodes []string `json:"-" bson:"recoveryCodes,omitempty"`
+
+	// Identities are the accounts at identity providers the user signs in with.
+	Identities []Identity `json:"identities,omitempty" bson:"identities,omitempty"`
 }
@@ -0,0 +1,114 @@
+package events
+
+import (
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	TypeNotification      = "notification"
+	TypeNotificationsRead = "notifications_read"
+
+	// subscriberBuffer is the number of events buffered for a subscriber before further ones are dropped.
+	subscriberBuffer = 16
+)
+
+// Event is pushed to the user's subscribers, e.g. over Server-Sent Events.
+type Event struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// Broker fans events out to the subscribers of each user. It's in-process,
+// so only the subscribers connected to the same instance receive the events.
+type Broker interface {
+	Publish(userID primitive.ObjectID, event Event)
+	// Subscribe returns the user's events and a function to unsubscribe. The channel is closed
+	// once unsubscribed or when the broker is closed.
+	Subscribe(userID primitive.ObjectID) (<-chan Event, func())
+	// Close closes every subscription, e.g. to let streaming requests finish on shutdown.
+	Close()
+}
+
+type memoryBroker struct {
+	mu          sync.Mutex
+	subscribers map[primitive.ObjectID]map[chan Event]struct{}
+	closed      bool
+}
+
+func NewBroker() Broker {
+	return &memoryBroker{
+		subscribers: make(map[primitive.ObjectID]map[chan Event]struct{}),
+	}
+}
+
+// Publish delivers the event to every subscriber of the user. Slow subscribers
+// with a full buffer miss the event rather than block the publisher.
+func (b *memoryBroker) Publish(userID primitive.ObjectID, event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (b *memoryBroker) Subscribe(userID primitive.ObjectID) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			b.unsubscribe(userID, ch)
+		})
+	}
+}
+
+func (b *memoryBroker) unsubscribe(userID primitive.ObjectID, ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[userID][ch]; !ok {
+		return
+	}
+
+	delete(b.subscribers[userID], ch)
+	if len(b.subscribers[userID]) == 0 {
+		delete(b.subscribers, userID)
+	}
+
+	close(ch)
+}
+
+func (b *memoryBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	for userID, subscribers := range b.subscribers {
+		for ch := range subscribers {
+			close(ch)
+		}
+
+		delete(b.subscribers, userID)
+	}
+}
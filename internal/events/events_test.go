@@ -0,0 +1,70 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBroker(t *testing.T) {
+	var (
+		broker = NewBroker()
+		alice  = primitive.NewObjectID()
+		bob    = primitive.NewObjectID()
+	)
+
+	aliceCh, unsubscribeAlice := broker.Subscribe(alice)
+	bobCh, unsubscribeBob := broker.Subscribe(bob)
+	defer unsubscribeBob()
+
+	broker.Publish(alice, Event{Type: TypeNotification, Data: "hello"})
+
+	assert.Equal(t, Event{Type: TypeNotification, Data: "hello"}, <-aliceCh)
+	assert.Empty(t, bobCh)
+
+	unsubscribeAlice()
+	unsubscribeAlice()
+
+	_, ok := <-aliceCh
+	assert.False(t, ok)
+
+	broker.Publish(alice, Event{Type: TypeNotification})
+}
+
+func TestBroker_SlowSubscriber(t *testing.T) {
+	var (
+		broker = NewBroker()
+		userID = primitive.NewObjectID()
+	)
+
+	ch, unsubscribe := broker.Subscribe(userID)
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer+5; i++ {
+		broker.Publish(userID, Event{Type: TypeNotification, Data: i})
+	}
+
+	assert.Len(t, ch, subscriberBuffer)
+}
+
+func TestBroker_Close(t *testing.T) {
+	var (
+		broker = NewBroker()
+		userID = primitive.NewObjectID()
+	)
+
+	ch, unsubscribe := broker.Subscribe(userID)
+
+	broker.Close()
+
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	unsubscribe()
+
+	ch, _ = broker.Subscribe(userID)
+
+	_, ok = <-ch
+	assert.False(t, ok)
+}
@@ -0,0 +1,127 @@
+// Package export writes the archive of all the data of a user: a JSON manifest of the user's profile,
+// collections and capsules, and the images of the capsules. Archives are read back to import them.
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/storage"
+)
+
+const (
+	ManifestFile = "manifest.json"
+	imagesDir    = "images"
+)
+
+// extensions are the file extensions of the image types capsules store.
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Data is what gets exported.
+type Data struct {
+	User        *domain.User
+	Capsules    []*domain.Capsule
+	Collections []*domain.Collection
+}
+
+// Manifest is the JSON manifest of the archive.
+type Manifest struct {
+	ExportedAt  time.Time            `json:"exportedAt"`
+	User        *domain.User         `json:"user"`
+	Collections []*domain.Collection `json:"collections"`
+	Capsules    []Capsule            `json:"capsules"`
+}
+
+// Capsule is an exported capsule. The message and images of sealed capsules are left out,
+// unless they're exported too.
+type Capsule struct {
+	*domain.Capsule
+	Sealed bool `json:"sealed"`
+	// Files are the paths of the capsule's images in the archive.
+	Files []string `json:"files,omitempty"`
+}
+
+// Write writes the ZIP archive of the data to w, with the images read from files.
+// Capsules that open after now are sealed, their content is only exported with includeSealed.
+func Write(ctx context.Context, w io.Writer, files storage.Storage, data Data, now time.Time, includeSealed bool) error {
+	var (
+		archive  = zip.NewWriter(w)
+		manifest = Manifest{
+			ExportedAt:  now,
+			User:        data.User,
+			Collections: data.Collections,
+			Capsules:    make([]Capsule, 0, len(data.Capsules)),
+		}
+	)
+
+	if manifest.Collections == nil {
+		manifest.Collections = []*domain.Collection{}
+	}
+
+	for _, c := range data.Capsules {
+		capsule := Capsule{
+			Capsule: c,
+			Sealed:  c.OpenAt.After(now),
+		}
+
+		if capsule.Sealed && !includeSealed {
+			metadata := *c
+			metadata.Message = ""
+			metadata.Images = nil
+			capsule.Capsule = &metadata
+		}
+
+		for i, image := range capsule.Images {
+			file, err := files.Get(ctx, image)
+			if err != nil {
+				return fmt.Errorf("get image %s: %w", image, err)
+			}
+
+			name := path.Join(imagesDir, c.ID.Hex(), fmt.Sprintf("%d%s", i+1, extensions[http.DetectContentType(file.Bytes)]))
+			if err = writeFile(archive, name, file.Bytes, now); err != nil {
+				return err
+			}
+
+			capsule.Files = append(capsule.Files, name)
+		}
+
+		manifest.Capsules = append(manifest.Capsules, capsule)
+	}
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = writeFile(archive, ManifestFile, b, now); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func writeFile(archive *zip.Writer, name string, b []byte, modified time.Time) error {
+	f, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(b)
+	return err
+}
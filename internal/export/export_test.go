@@ -0,0 +1,140 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	mock_storage "time-capsule/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+// png is the signature of a PNG file, enough to detect its type.
+var png = []byte("\x89PNG\r\n\x1a\n")
+
+func TestWrite(t *testing.T) {
+	var (
+		now    = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		opened = &domain.Capsule{
+			ID:      primitive.NewObjectID(),
+			Message: "Happy new year!",
+			Images:  []string{"opened-image"},
+			OpenAt:  now.Add(-time.Hour),
+		}
+		sealed = &domain.Capsule{
+			ID:      primitive.NewObjectID(),
+			Message: "See you in ten years",
+			Images:  []string{"sealed-image"},
+			OpenAt:  now.AddDate(10, 0, 0),
+			Tags:    []string{"future"},
+		}
+		data = Data{
+			User:     &domain.User{Username: "johndoe", Email: "foo@example.com"},
+			Capsules: []*domain.Capsule{opened, sealed},
+		}
+	)
+
+	tests := []struct {
+		name             string
+		includeSealed    bool
+		expectedFiles    []string
+		expectedMessages []string
+	}{
+		{
+			name:             "Sealed-Metadata-Only",
+			includeSealed:    false,
+			expectedFiles:    []string{"images/" + opened.ID.Hex() + "/1.png", ManifestFile},
+			expectedMessages: []string{"Happy new year!", ""},
+		},
+		{
+			name:          "Include-Sealed",
+			includeSealed: true,
+			expectedFiles: []string{
+				"images/" + opened.ID.Hex() + "/1.png",
+				"images/" + sealed.ID.Hex() + "/1.png",
+				ManifestFile,
+			},
+			expectedMessages: []string{"Happy new year!", "See you in ten years"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			files := mock_storage.NewMockStorage(c)
+			files.EXPECT().Get(gomock.Any(), "opened-image").Return(&domain.File{Bytes: png}, nil).Times(1)
+			if test.includeSealed {
+				files.EXPECT().Get(gomock.Any(), "sealed-image").Return(&domain.File{Bytes: png}, nil).Times(1)
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, Write(context.Background(), &buf, files, data, now, test.includeSealed))
+
+			archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			require.NoError(t, err)
+
+			var names []string
+			for _, f := range archive.File {
+				names = append(names, f.Name)
+			}
+			assert.Equal(t, test.expectedFiles, names)
+
+			f, err := archive.Open(ManifestFile)
+			require.NoError(t, err)
+			defer f.Close()
+
+			b, err := io.ReadAll(f)
+			require.NoError(t, err)
+
+			var manifest struct {
+				User     domain.User `json:"user"`
+				Capsules []struct {
+					Message string   `json:"message"`
+					Sealed  bool     `json:"sealed"`
+					Tags    []string `json:"tags"`
+					Files   []string `json:"files"`
+				} `json:"capsules"`
+			}
+			require.NoError(t, json.Unmarshal(b, &manifest))
+
+			assert.Equal(t, "johndoe", manifest.User.Username)
+			require.Len(t, manifest.Capsules, 2)
+			assert.False(t, manifest.Capsules[0].Sealed)
+			assert.True(t, manifest.Capsules[1].Sealed)
+			assert.Equal(t, []string{"future"}, manifest.Capsules[1].Tags)
+
+			for i, capsule := range manifest.Capsules {
+				assert.Equal(t, test.expectedMessages[i], capsule.Message)
+			}
+
+			// The capsule itself is left untouched.
+			assert.Equal(t, "See you in ten years", sealed.Message)
+		})
+	}
+}
+
+func TestWrite_StorageFailure(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	files := mock_storage.NewMockStorage(c)
+	files.EXPECT().Get(gomock.Any(), "image").Return(nil, errors.New("some error")).Times(1)
+
+	err := Write(context.Background(), io.Discard, files, Data{
+		User:     &domain.User{},
+		Capsules: []*domain.Capsule{{Images: []string{"image"}}},
+	}, time.Now(), false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"time-capsule/internal/domain"
+)
+
+const (
+	// MaxFileSize is the largest image read from an archive, the same as an uploaded one.
+	MaxFileSize     = 5 << 20
+	maxManifestSize = 32 << 20
+)
+
+var (
+	ErrInvalidArchive  = errors.New("not an export archive or manifest")
+	ErrMissingManifest = errors.New("the archive has no " + ManifestFile)
+	ErrFileNotFound    = errors.New("file not found in the archive")
+	ErrFileTooLarge    = errors.New("file is too large")
+)
+
+// Archive is a read export, either a ZIP archive or a bare JSON manifest without images.
+type Archive struct {
+	Manifest Manifest
+	files    map[string]*zip.File
+}
+
+// Read parses b as a ZIP archive written by Write, or as a JSON manifest in the same format.
+// A JSON array is read as the list of capsules of a manifest.
+func Read(b []byte) (*Archive, error) {
+	archive := &Archive{}
+
+	switch trimmed := bytes.TrimSpace(b); {
+	case bytes.HasPrefix(b, []byte("PK\x03\x04")):
+		r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		archive.files = make(map[string]*zip.File, len(r.File))
+		for _, f := range r.File {
+			archive.files[f.Name] = f
+		}
+
+		manifest, ok := archive.files[ManifestFile]
+		if !ok {
+			return nil, ErrMissingManifest
+		}
+
+		b, err = readFile(manifest, maxManifestSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		if err = json.Unmarshal(b, &archive.Manifest); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		if err := json.Unmarshal(trimmed, &archive.Manifest); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		if err := json.Unmarshal(trimmed, &archive.Manifest.Capsules); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+	default:
+		return nil, ErrInvalidArchive
+	}
+
+	for i := range archive.Manifest.Capsules {
+		if archive.Manifest.Capsules[i].Capsule == nil {
+			archive.Manifest.Capsules[i].Capsule = &domain.Capsule{}
+		}
+	}
+
+	return archive, nil
+}
+
+// Open returns the content of the file at name, at most MaxFileSize bytes.
+func (a *Archive) Open(name string) ([]byte, error) {
+	f, ok := a.files[name]
+	if !ok {
+		return nil, ErrFileNotFound
+	}
+
+	return readFile(f, MaxFileSize)
+}
+
+// readFile reads f, failing if it's larger than limit. The size in the header isn't trusted.
+func readFile(f *zip.File, limit int64) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(limit) {
+		return nil, ErrFileTooLarge
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(b)) > limit {
+		return nil, ErrFileTooLarge
+	}
+
+	return b, nil
+}
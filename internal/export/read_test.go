@@ -0,0 +1,137 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	mock_storage "time-capsule/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRead_Archive(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	var (
+		now     = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		capsule = &domain.Capsule{
+			ID:        primitive.NewObjectID(),
+			Message:   "Happy new year!",
+			Images:    []string{"image"},
+			OpenAt:    now.Add(-time.Hour),
+			CreatedAt: now.AddDate(-1, 0, 0),
+		}
+	)
+
+	files := mock_storage.NewMockStorage(c)
+	files.EXPECT().Get(gomock.Any(), "image").Return(&domain.File{Bytes: png}, nil).Times(1)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(context.Background(), &buf, files, Data{
+		User:     &domain.User{},
+		Capsules: []*domain.Capsule{capsule},
+	}, now, false))
+
+	archive, err := Read(buf.Bytes())
+	require.NoError(t, err)
+
+	require.Len(t, archive.Manifest.Capsules, 1)
+	read := archive.Manifest.Capsules[0]
+	assert.Equal(t, capsule.ID, read.ID)
+	assert.Equal(t, capsule.Message, read.Message)
+	assert.Equal(t, capsule.CreatedAt, read.CreatedAt)
+	assert.False(t, read.Sealed)
+	require.Len(t, read.Files, 1)
+
+	b, err := archive.Open(read.Files[0])
+	require.NoError(t, err)
+	assert.Equal(t, png, b)
+
+	_, err = archive.Open("images/unknown.png")
+	assert.ErrorIs(t, err, ErrFileNotFound)
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            []byte
+		expectedCapsules int
+		expectedError    error
+	}{
+		{
+			name:             "JSON-Manifest",
+			input:            []byte(` {"capsules":[{"message":"Hello, world!","openAt":"2030-01-01T00:00:00Z"},{}]}`),
+			expectedCapsules: 2,
+		},
+		{
+			name:             "JSON-Array",
+			input:            []byte(`[{"message":"Hello, world!","openAt":"2030-01-01T00:00:00Z"}]`),
+			expectedCapsules: 1,
+		},
+		{
+			name:          "Invalid-JSON",
+			input:         []byte(`{"capsules":`),
+			expectedError: ErrInvalidArchive,
+		},
+		{
+			name:          "Unknown-Format",
+			input:         []byte("hello"),
+			expectedError: ErrInvalidArchive,
+		},
+		{
+			name:          "Missing-Manifest",
+			input:         zipOf(t, map[string][]byte{"images/1.png": png}),
+			expectedError: ErrMissingManifest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			archive, err := Read(test.input)
+			if test.expectedError != nil {
+				assert.ErrorIs(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, archive.Manifest.Capsules, test.expectedCapsules)
+
+			for _, capsule := range archive.Manifest.Capsules {
+				assert.NotNil(t, capsule.Capsule)
+			}
+		})
+	}
+}
+
+func TestArchive_Open_TooLarge(t *testing.T) {
+	archive, err := Read(zipOf(t, map[string][]byte{
+		ManifestFile:   []byte("{}"),
+		"images/1.png": make([]byte, MaxFileSize+1),
+	}))
+	require.NoError(t, err)
+
+	_, err = archive.Open("images/1.png")
+	assert.ErrorIs(t, err, ErrFileTooLarge)
+}
+
+func zipOf(t *testing.T, files map[string][]byte) []byte {
+	var (
+		buf bytes.Buffer
+		w   = zip.NewWriter(&buf)
+	)
+
+	for name, b := range files {
+		require.NoError(t, writeFile(w, name, b, time.Now()))
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
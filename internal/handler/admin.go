@@ -0,0 +1,365 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"time-capsule/internal/domain"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// PreviewEmail | Renders An Email Template With Sample Data
+//
+//	@Summary      PreviewEmail
+//	@Security     ApiKeyAuth
+//	@Description  Renders an email template (opened, recipient, reminder, check_in_reminder, account_locked) with sample data
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        template path      string true  "template"
+//	@Param        lang     query     string false "language, e.g. en"
+//	@Success      200      {object}  mail.Message
+//	@Failure      403      {object}  errorResponse
+//	@Failure      404      {object}  errorResponse
+//	@Failure      500      {object}  errorResponse
+//	@Router       /api/v1/admin/emails/{template}/preview [get]
+func (h *handler) previewEmail(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	msg, err := h.svc.PreviewEmail(params.ByName(pathTemplate), r.URL.Query().Get("lang"))
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, msg)
+	return
+}
+
+// GetOutbox | Lists Queued Emails
+//
+//	@Summary      GetOutbox
+//	@Security     ApiKeyAuth
+//	@Description  Lists the latest outbox entries with the given status (pending, sent, failed), failed by default
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        status query     string false "status"
+//	@Success      200    {array}   domain.OutboxEntry
+//	@Failure      400    {object}  errorResponse
+//	@Failure      403    {object}  errorResponse
+//	@Failure      500    {object}  errorResponse
+//	@Router       /api/v1/admin/outbox [get]
+func (h *handler) getOutbox(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	entries, err := h.svc.GetOutbox(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, entries)
+	return
+}
+
+// ReplayOutboxEntry | Queues A Failed Email Again
+//
+//	@Summary      ReplayOutboxEntry
+//	@Security     ApiKeyAuth
+//	@Description  Queues a dead-lettered email again, with a fresh set of delivery attempts
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        entryID path      string true "entryID"
+//	@Success      204
+//	@Failure      400     {object}  errorResponse
+//	@Failure      403     {object}  errorResponse
+//	@Failure      404     {object}  errorResponse
+//	@Failure      500     {object}  errorResponse
+//	@Router       /api/v1/admin/outbox/{entryID}/replay [post]
+func (h *handler) replayOutboxEntry(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	id, err := parseObjectIDFromParam(params, pathOutboxEntryID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.svc.ReplayOutboxEntry(h.withClient(r), id); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// SearchUsers | Searches Users
+//
+//	@Summary      SearchUsers
+//	@Security     ApiKeyAuth
+//	@Description  Finds users by ID, or by a part of their email, username or display name. Requires the support role
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        q      query     string true "query"
+//	@Success      200    {array}   domain.User
+//	@Failure      400    {object}  errorResponse
+//	@Failure      401    {object}  errorResponse
+//	@Failure      403    {object}  errorResponse
+//	@Failure      500    {object}  errorResponse
+//	@Router       /api/v1/admin/users [get]
+func (h *handler) searchUsers(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	users, err := h.svc.SearchUsers(r.Context(), r.URL.Query().Get("q"))
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, users)
+	return
+}
+
+// GetUser | Retrieves A User
+//
+//	@Summary      GetUser
+//	@Security     ApiKeyAuth
+//	@Description  Retrieves a user. Requires the support role
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        userID path      string true "userID"
+//	@Success      200    {object}  domain.User
+//	@Failure      400    {object}  errorResponse
+//	@Failure      401    {object}  errorResponse
+//	@Failure      403    {object}  errorResponse
+//	@Failure      404    {object}  errorResponse
+//	@Failure      500    {object}  errorResponse
+//	@Router       /api/v1/admin/users/{userID} [get]
+func (h *handler) getUser(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := parseObjectIDFromParam(params, pathUserID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.svc.GetUser(r.Context(), userID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, user)
+	return
+}
+
+// GetUserCapsules | Lists The Capsules Of A User
+//
+//	@Summary      GetUserCapsules
+//	@Security     ApiKeyAuth
+//	@Description  Lists the metadata of the capsules of a user, without their messages and images. Requires the support role
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        userID path      string true "userID"
+//	@Success      200    {array}   domain.CapsuleMetadata
+//	@Failure      400    {object}  errorResponse
+//	@Failure      401    {object}  errorResponse
+//	@Failure      403    {object}  errorResponse
+//	@Failure      500    {object}  errorResponse
+//	@Router       /api/v1/admin/users/{userID}/capsules [get]
+func (h *handler) getUserCapsules(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := parseObjectIDFromParam(params, pathUserID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	capsules, err := h.svc.GetUserCapsules(r.Context(), userID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, capsules)
+	return
+}
+
+// DisableUser | Disables An Account
+//
+//	@Summary      DisableUser
+//	@Security     ApiKeyAuth
+//	@Description  Disables an account: the user can't sign in and their tokens are rejected until it's enabled again. Requires the admin role
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        userID path      string true "userID"
+//	@Success      204
+//	@Failure      400    {object}  errorResponse
+//	@Failure      401    {object}  errorResponse
+//	@Failure      403    {object}  errorResponse
+//	@Failure      404    {object}  errorResponse
+//	@Failure      500    {object}  errorResponse
+//	@Router       /api/v1/admin/users/{userID}/disable [post]
+func (h *handler) disableUser(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := parseObjectIDFromParam(params, pathUserID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.svc.DisableUser(h.withClient(r), getActorID(r), userID); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// EnableUser | Enables A Disabled Account
+//
+//	@Summary      EnableUser
+//	@Security     ApiKeyAuth
+//	@Description  Enables a disabled account. Requires the admin role
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        userID path      string true "userID"
+//	@Success      204
+//	@Failure      400    {object}  errorResponse
+//	@Failure      401    {object}  errorResponse
+//	@Failure      403    {object}  errorResponse
+//	@Failure      404    {object}  errorResponse
+//	@Failure      500    {object}  errorResponse
+//	@Router       /api/v1/admin/users/{userID}/enable [post]
+func (h *handler) enableUser(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := parseObjectIDFromParam(params, pathUserID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.svc.EnableUser(h.withClient(r), getActorID(r), userID); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// UpdateRole | Grants Or Revokes A Role
+//
+//	@Summary      UpdateRole
+//	@Security     ApiKeyAuth
+//	@Description  Grants a user the support or admin role, or revokes it with an empty role. Requires the admin role
+//	@Tags         Admin
+//	@Accept       json
+//	@Produce      json
+//	@Param        userID path      string               true "userID"
+//	@Param        input  body      domain.UpdateRoleDTO true "role"
+//	@Success      204
+//	@Failure      400    {object}  errorResponse
+//	@Failure      401    {object}  errorResponse
+//	@Failure      403    {object}  errorResponse
+//	@Failure      404    {object}  errorResponse
+//	@Failure      500    {object}  errorResponse
+//	@Router       /api/v1/admin/users/{userID}/role [put]
+func (h *handler) updateRole(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := parseObjectIDFromParam(params, pathUserID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.UpdateRoleDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	if err = h.svc.UpdateRole(h.withClient(r), getActorID(r), userID, input); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// GetCapsuleMetadata | Retrieves The Metadata Of A Capsule
+//
+//	@Summary      GetCapsuleMetadata
+//	@Security     ApiKeyAuth
+//	@Description  Retrieves the metadata of a capsule, without its message and images. Requires the support role
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        capsuleID path      string true "capsuleID"
+//	@Success      200       {object}  domain.CapsuleMetadata
+//	@Failure      400       {object}  errorResponse
+//	@Failure      401       {object}  errorResponse
+//	@Failure      403       {object}  errorResponse
+//	@Failure      404       {object}  errorResponse
+//	@Failure      500       {object}  errorResponse
+//	@Router       /api/v1/admin/capsules/{capsuleID} [get]
+func (h *handler) getCapsuleMetadata(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	capsuleID, err := parseObjectIDFromParam(params, pathCapsuleID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	capsule, err := h.svc.GetCapsuleMetadata(r.Context(), capsuleID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, capsule)
+	return
+}
+
+// ResendNotifications | Resends The Emails Of An Opened Capsule
+//
+//	@Summary      ResendNotifications
+//	@Security     ApiKeyAuth
+//	@Description  Queues the emails sent to the owner and the recipients when the capsule last opened again. Requires the support role
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        capsuleID path      string true "capsuleID"
+//	@Success      204
+//	@Failure      400       {object}  errorResponse
+//	@Failure      401       {object}  errorResponse
+//	@Failure      403       {object}  errorResponse
+//	@Failure      404       {object}  errorResponse
+//	@Failure      409       {object}  errorResponse
+//	@Failure      500       {object}  errorResponse
+//	@Router       /api/v1/admin/capsules/{capsuleID}/resend [post]
+func (h *handler) resendNotifications(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	capsuleID, err := parseObjectIDFromParam(params, pathCapsuleID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.svc.ResendNotifications(h.withClient(r), capsuleID); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// GetWorkerStatus | Retrieves The Worker Status
+//
+//	@Summary      GetWorkerStatus
+//	@Security     ApiKeyAuth
+//	@Description  Retrieves when the worker of this instance last ran and how much work is waiting for it. Requires the support role
+//	@Tags         Admin
+//	@Produce      json
+//	@Success      200    {object}  domain.WorkerStatus
+//	@Failure      401    {object}  errorResponse
+//	@Failure      403    {object}  errorResponse
+//	@Failure      500    {object}  errorResponse
+//	@Router       /api/v1/admin/worker [get]
+func (h *handler) getWorkerStatus(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	status, err := h.svc.GetWorkerStatus(r.Context())
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, status)
+	return
+}
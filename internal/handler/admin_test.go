@@ -0,0 +1,449 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/service"
+	mock_service "time-capsule/internal/service/mocks"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAdminHandler_previewEmail(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockMailService)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		url                  string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockMailService) {
+				s.EXPECT().PreviewEmail(mail.TemplateOpened, "ru").Return(&mail.Message{
+					Subject: "subject",
+					HTML:    "html",
+					Text:    "text",
+				}, nil).Times(1)
+			},
+			url:                  adminURL + "/emails/opened/preview?lang=ru",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"subject":"subject","html":"html","text":"text"}`,
+		},
+		{
+			name: "Not-Found",
+			mockBehavior: func(s *mock_service.MockMailService) {
+				s.EXPECT().PreviewEmail("unknown", "").Return(nil, service.ErrNotFound).Times(1)
+			},
+			url:                  adminURL + "/emails/unknown/preview",
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"message":"not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				mailSvc = mock_service.NewMockMailService(c)
+				svc     = &service.Service{
+					MailService: mailSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(mailSvc)
+
+			router.GET(previewEmailURL, hndlr.previewEmail)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAdminHandler_getOutbox(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockMailService)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		url                  string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockMailService) {
+				s.EXPECT().GetOutbox(gomock.Any(), domain.OutboxStatusFailed).Return([]*domain.OutboxEntry{{
+					Key:           "opened:1:0",
+					Recipient:     "foo@example.com",
+					Template:      mail.TemplateOpened,
+					Subject:       "Time Capsule Opened!",
+					HTML:          "<p>sealed</p>",
+					Status:        domain.OutboxStatusFailed,
+					Attempts:      8,
+					LastError:     "connection refused",
+					NextAttemptAt: time.Unix(0, 0).UTC(),
+					CreatedAt:     time.Unix(0, 0).UTC(),
+				}}, nil).Times(1)
+			},
+			url:                "/api/v1/admin/outbox?status=failed",
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `[{"id":"000000000000000000000000","key":"opened:1:0","userID":"000000000000000000000000",` +
+				`"capsuleID":"000000000000000000000000","recipient":"foo@example.com","template":"opened",` +
+				`"subject":"Time Capsule Opened!","status":"failed","attempts":8,"lastError":"connection refused",` +
+				`"nextAttemptAt":"1970-01-01T00:00:00Z","createdAt":"1970-01-01T00:00:00Z"}]`,
+		},
+		{
+			name: "Invalid-Status",
+			mockBehavior: func(s *mock_service.MockMailService) {
+				s.EXPECT().GetOutbox(gomock.Any(), "lost").Return(nil, service.ErrInvalidOutboxStatus).Times(1)
+			},
+			url:                  "/api/v1/admin/outbox?status=lost",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"status must be one of \"pending\", \"sent\" or \"failed\""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				mailSvc = mock_service.NewMockMailService(c)
+				svc     = &service.Service{
+					MailService: mailSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(mailSvc)
+
+			router.GET(getOutboxURL, hndlr.getOutbox)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAdminHandler_replayOutboxEntry(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockMailService, id primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		entryID              string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockMailService, id primitive.ObjectID) {
+				s.EXPECT().ReplayOutboxEntry(gomock.Any(), id).Return(nil).Times(1)
+			},
+			entryID:              primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name:                 "Invalid-ID",
+			mockBehavior:         func(s *mock_service.MockMailService, id primitive.ObjectID) {},
+			entryID:              "123",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid id"}`,
+		},
+		{
+			name: "Not-Found",
+			mockBehavior: func(s *mock_service.MockMailService, id primitive.ObjectID) {
+				s.EXPECT().ReplayOutboxEntry(gomock.Any(), id).Return(service.ErrNotFound).Times(1)
+			},
+			entryID:              primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"message":"not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				mailSvc = mock_service.NewMockMailService(c)
+				svc     = &service.Service{
+					MailService: mailSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(mailSvc, primitive.NilObjectID)
+
+			router.POST(replayOutboxEntryURL, hndlr.replayOutboxEntry)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, getOutboxURL+"/"+test.entryID+"/replay", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAdminHandler_searchUsers(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAdminService)
+
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		query                string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAdminService) {
+				s.EXPECT().SearchUsers(gomock.Any(), "foo").Return([]*domain.User{
+					{ID: id, Email: "foo@example.com", Role: domain.RoleSupport},
+				}, nil).Times(1)
+			},
+			query:              "foo",
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `[{"id":"` + id.Hex() +
+				`","username":"","email":"foo@example.com","registeredAt":"0001-01-01T00:00:00Z","role":"support"}]`,
+		},
+		{
+			name: "Empty-Query",
+			mockBehavior: func(s *mock_service.MockAdminService) {
+				s.EXPECT().SearchUsers(gomock.Any(), "").Return(nil, service.ErrEmptySearchQuery).Times(1)
+			},
+			query:                "",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"` + service.ErrEmptySearchQuery.Error() + `"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				adminSvc = mock_service.NewMockAdminService(c)
+				svc      = &service.Service{
+					AdminService: adminSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(adminSvc)
+
+			router.GET(searchUsersURL, hndlr.searchUsers)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, searchUsersURL+"?q="+test.query, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAdminHandler_disableUser(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAdminService, id primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		userID               string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAdminService, id primitive.ObjectID) {
+				s.EXPECT().DisableUser(gomock.Any(), primitive.NilObjectID, id).Return(nil).Times(1)
+			},
+			userID:               primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name:                 "Invalid-ID",
+			mockBehavior:         func(s *mock_service.MockAdminService, id primitive.ObjectID) {},
+			userID:               "123",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid id"}`,
+		},
+		{
+			name: "Own-Account",
+			mockBehavior: func(s *mock_service.MockAdminService, id primitive.ObjectID) {
+				s.EXPECT().DisableUser(gomock.Any(), primitive.NilObjectID, id).Return(service.ErrOwnAccount).Times(1)
+			},
+			userID:               primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"message":"` + service.ErrOwnAccount.Error() + `"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				adminSvc = mock_service.NewMockAdminService(c)
+				svc      = &service.Service{
+					AdminService: adminSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(adminSvc, primitive.NilObjectID)
+
+			router.POST(disableUserURL, hndlr.disableUser)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, searchUsersURL+"/"+test.userID+"/disable", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAdminHandler_resendNotifications(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAdminService, id primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAdminService, id primitive.ObjectID) {
+				s.EXPECT().ResendNotifications(gomock.Any(), id).Return(nil).Times(1)
+			},
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name: "Nothing-To-Resend",
+			mockBehavior: func(s *mock_service.MockAdminService, id primitive.ObjectID) {
+				s.EXPECT().ResendNotifications(gomock.Any(), id).Return(service.ErrNothingToResend).Times(1)
+			},
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"message":"` + service.ErrNothingToResend.Error() + `"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				adminSvc = mock_service.NewMockAdminService(c)
+				svc      = &service.Service{
+					AdminService: adminSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(adminSvc, primitive.NilObjectID)
+
+			router.POST(resendNotificationsURL, hndlr.resendNotifications)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, adminURL+"/capsules/"+primitive.NilObjectID.Hex()+"/resend", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"time-capsule/internal/domain"
+
+	"github.com/julienschmidt/httprouter"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetMyAuditEvents | Lists The Audit Log Of The Account
+//
+//	@Summary      GetMyAuditEvents
+//	@Security     ApiKeyAuth
+//	@Description  Lists the audit events about the user, newest first, without the IP and user agent of staff. Pass the id of the last event as before to get the next page
+//	@Tags         Users
+//	@Produce      json
+//	@Param        action query     string false "action"
+//	@Param        before query     string false "id of the last event of the previous page"
+//	@Param        limit  query     int    false "number of events, 50 by default and at most 200"
+//	@Success      200    {array}   domain.AuditEvent
+//	@Failure      400    {object}  errorResponse
+//	@Failure      401    {object}  errorResponse
+//	@Failure      500    {object}  errorResponse
+//	@Router       /api/v1/me/audit [get]
+func (h *handler) getMyAuditEvents(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.svc.GetUserAuditEvents(r.Context(), userID, filter)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, events)
+	return
+}
+
+// GetAuditEvents | Searches The Audit Log
+//
+//	@Summary      GetAuditEvents
+//	@Security     ApiKeyAuth
+//	@Description  Lists the audit events of every user, newest first. Requires the admin role
+//	@Tags         Admin
+//	@Produce      json
+//	@Param        userID    query     string false "the user the actions are about"
+//	@Param        actorID   query     string false "the user who took the actions"
+//	@Param        action    query     string false "action"
+//	@Param        requestID query     string false "request ID"
+//	@Param        before    query     string false "id of the last event of the previous page"
+//	@Param        limit     query     int    false "number of events, 50 by default and at most 200"
+//	@Success      200       {array}   domain.AuditEvent
+//	@Failure      400       {object}  errorResponse
+//	@Failure      401       {object}  errorResponse
+//	@Failure      403       {object}  errorResponse
+//	@Failure      500       {object}  errorResponse
+//	@Router       /api/v1/admin/audit [get]
+func (h *handler) getAuditEvents(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	query := r.URL.Query()
+
+	filter, err := parseAuditFilter(query)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if filter.UserID, err = parseOptionalObjectID(query.Get("userID")); err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if filter.ActorID, err = parseOptionalObjectID(query.Get("actorID")); err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	filter.RequestID = query.Get("requestID")
+
+	events, err := h.svc.GetAuditEvents(r.Context(), filter)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, events)
+	return
+}
+
+// parseAuditFilter parses the filters users and staff have in common.
+func parseAuditFilter(query url.Values) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{
+		Action: query.Get("action"),
+	}
+
+	var err error
+	if filter.Before, err = parseOptionalObjectID(query.Get("before")); err != nil {
+		return filter, err
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.ParseInt(limit, 10, 64); err != nil || filter.Limit < 1 {
+			return filter, errors.New("invalid limit")
+		}
+	}
+
+	return filter, nil
+}
+
+func parseOptionalObjectID(id string) (primitive.ObjectID, error) {
+	if id == "" {
+		return primitive.NilObjectID, nil
+	}
+
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, errors.New("invalid id")
+	}
+
+	return oid, nil
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/service"
+	mock_service "time-capsule/internal/service/mocks"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuditHandler_getMyAuditEvents(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuditService, userID primitive.ObjectID)
+
+	before := primitive.NewObjectID()
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		query                string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAuditService, userID primitive.ObjectID) {
+				s.EXPECT().GetUserAuditEvents(gomock.Any(), userID, domain.AuditFilter{
+					Action: domain.AuditSignIn,
+					Before: before,
+					Limit:  10,
+				}).Return([]*domain.AuditEvent{}, nil).Times(1)
+			},
+			query:                "?action=sign_in&before=" + before.Hex() + "&limit=10",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `[]`,
+		},
+		{
+			name:                 "Invalid-Limit",
+			mockBehavior:         func(s *mock_service.MockAuditService, userID primitive.ObjectID) {},
+			query:                "?limit=0",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid limit"}`,
+		},
+		{
+			name:                 "Invalid-Before",
+			mockBehavior:         func(s *mock_service.MockAuditService, userID primitive.ObjectID) {},
+			query:                "?before=123",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid id"}`,
+		},
+		{
+			name: "Service-Failure",
+			mockBehavior: func(s *mock_service.MockAuditService, userID primitive.ObjectID) {
+				s.EXPECT().GetUserAuditEvents(gomock.Any(), userID, domain.AuditFilter{}).
+					Return(nil, errors.New("some error")).Times(1)
+			},
+			query:                "",
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				userID   = primitive.NewObjectID()
+				ctx      = context.WithValue(context.Background(), userCtx, userID.Hex())
+				auditSvc = mock_service.NewMockAuditService(c)
+				svc      = &service.Service{
+					AuditService: auditSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(auditSvc, userID)
+
+			router.GET(myAuditURL, hndlr.getMyAuditEvents)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, myAuditURL+test.query, nil)
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAuditHandler_getAuditEvents(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuditService)
+
+	var (
+		userID  = primitive.NewObjectID()
+		actorID = primitive.NewObjectID()
+	)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		query                string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAuditService) {
+				s.EXPECT().GetAuditEvents(gomock.Any(), domain.AuditFilter{
+					UserID:    userID,
+					ActorID:   actorID,
+					Action:    domain.AuditCapsuleDeleted,
+					RequestID: "abc123",
+				}).Return([]*domain.AuditEvent{}, nil).Times(1)
+			},
+			query:                "?userID=" + userID.Hex() + "&actorID=" + actorID.Hex() + "&action=capsule_deleted&requestID=abc123",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `[]`,
+		},
+		{
+			name:                 "Invalid-UserID",
+			mockBehavior:         func(s *mock_service.MockAuditService) {},
+			query:                "?userID=123",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid id"}`,
+		},
+		{
+			name:                 "Invalid-ActorID",
+			mockBehavior:         func(s *mock_service.MockAuditService) {},
+			query:                "?actorID=123",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid id"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				auditSvc = mock_service.NewMockAuditService(c)
+				svc      = &service.Service{
+					AuditService: auditSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(auditSvc)
+
+			router.GET(getAuditEventsURL, hndlr.getAuditEvents)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, getAuditEventsURL+test.query, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"encoding/json"
+	"html/template"
+	"log/slog"
 	"net/http"
 
 	"time-capsule/internal/domain"
@@ -16,14 +18,16 @@ type tokenResponse struct {
 // SignIn | Login
 //
 //	@Summary      SignIn
-//	@Description  Log in
+//	@Description  Log in. With two-factor authentication enabled, it returns an MFA token to complete the sign-in with instead
 //	@Tags         Auth
 //	@Accept       json
 //	@Produce      json
 //	@Param        input body      domain.LogInUserDTO true "Input"
-//	@Success      200   {object}  tokenResponse
+//	@Success      200   {object}  domain.SignInResult
 //	@Failure      400   {object}  errorResponse
 //	@Failure      401   {object}  errorResponse
+//	@Failure      423   {object}  errorResponse
+//	@Failure      429   {object}  errorResponse
 //	@Failure      500   {object}  errorResponse
 //	@Router       /api/v1/sign-in [post]
 func (h *handler) signIn(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -33,7 +37,39 @@ func (h *handler) signIn(w http.ResponseWriter, r *http.Request, _ httprouter.Pa
 		return
 	}
 
-	token, err := h.svc.GenerateToken(r.Context(), input.Email, input.Password)
+	result, err := h.svc.GenerateToken(h.withClient(r), input.Email, input.Password)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, result)
+	return
+}
+
+// VerifyMFA | Completes A Two-Factor Sign-In
+//
+//	@Summary      VerifyMFA
+//	@Description  Completes the sign-in with the MFA token and a code of the authenticator app or a recovery code
+//	@Tags         Auth
+//	@Accept       json
+//	@Produce      json
+//	@Param        input body      domain.MFASignInDTO true "Input"
+//	@Success      200   {object}  tokenResponse
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      423   {object}  errorResponse
+//	@Failure      429   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/sign-in/mfa [post]
+func (h *handler) verifyMFA(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var input domain.MFASignInDTO
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	token, err := h.svc.VerifyMFA(h.withClient(r), input)
 	if err != nil {
 		newErrorResponse(w, err)
 		return
@@ -43,6 +79,150 @@ func (h *handler) signIn(w http.ResponseWriter, r *http.Request, _ httprouter.Pa
 	return
 }
 
+// GetJWKS | Lists The Token Verification Keys
+//
+//	@Summary      GetJWKS
+//	@Description  Lists the public keys the access tokens are signed with, as a JSON Web Key Set
+//	@Tags         Auth
+//	@Produce      json
+//	@Success      200   {object}  jwks.Set
+//	@Failure      429   {object}  errorResponse
+//	@Router       /.well-known/jwks.json [get]
+func (h *handler) getJWKS(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	newJSONResponse(w, h.svc.JWKS())
+	return
+}
+
+// confirmPage asks to confirm the action of an emailed link, so that mail scanners and link previews opening
+// the link don't use up its token. It posts the token back to the same URL.
+var confirmPage = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+</head>
+<body>
+<form id="confirm" method="post">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">{{.Button}}</button>
+</form>
+<p id="result"></p>
+<script>
+document.getElementById("confirm").addEventListener("submit", async (event) => {
+	event.preventDefault();
+	const res = await fetch(location.pathname, {method: "POST", body: new URLSearchParams(new FormData(event.target))});
+	const result = document.getElementById("result");
+	result.textContent = res.ok ? {{.Done}} : (await res.json()).message;
+	event.target.hidden = res.ok;
+});
+</script>
+</body>
+</html>
+`))
+
+type confirmPageData struct {
+	Title  string
+	Button string
+	Done   string
+	Token  string
+}
+
+// serveConfirmPage serves confirmPage for the token of the emailed link.
+func serveConfirmPage(w http.ResponseWriter, r *http.Request, data confirmPageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	data.Token = r.URL.Query().Get("token")
+
+	if err := confirmPage.Execute(w, data); err != nil {
+		slog.ErrorContext(r.Context(), "serveConfirmPage", "error", err)
+	}
+}
+
+// UnlockAccountPage | Confirms Unlocking A Locked Account
+//
+//	@Summary      UnlockAccountPage
+//	@Description  Serves the page the unlock link of the email opens, which asks to confirm unlocking the account
+//	@Tags         Auth
+//	@Produce      html
+//	@Param        token query     string true "unlock token"
+//	@Success      200
+//	@Router       /api/v1/unlock [get]
+func (h *handler) unlockAccountPage(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	serveConfirmPage(w, r, confirmPageData{
+		Title:  "Unlock your account",
+		Button: "Unlock my account",
+		Done:   "Your account is unlocked, you can sign in again.",
+	})
+}
+
+// UnlockAccount | Unlocks A Locked Account
+//
+//	@Summary      UnlockAccount
+//	@Description  Unlocks an account locked after too many failed sign-in attempts, with the token from the email
+//	@Tags         Auth
+//	@Accept       x-www-form-urlencoded
+//	@Produce      json
+//	@Param        token formData  string true "unlock token"
+//	@Success      204
+//	@Failure      400   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/unlock [post]
+func (h *handler) unlockAccount(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if err := h.svc.UnlockAccount(h.withClient(r), r.PostFormValue("token")); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// VerifyEmailPage | Confirms A New Email
+//
+//	@Summary      VerifyEmailPage
+//	@Description  Serves the page the verification link of the email opens, which asks to confirm the new email
+//	@Tags         Auth
+//	@Produce      html
+//	@Param        token query     string true "verification token"
+//	@Success      200
+//	@Router       /api/v1/verify-email [get]
+func (h *handler) verifyEmailPage(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	serveConfirmPage(w, r, confirmPageData{
+		Title:  "Confirm your new email",
+		Button: "Use this email",
+		Done:   "Your email is changed, use it to sign in from now on.",
+	})
+}
+
+// VerifyEmail | Verifies A New Email
+//
+//	@Summary      VerifyEmail
+//	@Description  Replaces the email of the account with the new one, with the token from the email sent to it
+//	@Tags         Auth
+//	@Accept       x-www-form-urlencoded
+//	@Produce      json
+//	@Param        token formData  string true "verification token"
+//	@Success      204
+//	@Failure      400   {object}  errorResponse
+//	@Failure      409   {object}  errorResponse
+//	@Failure      429   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/verify-email [post]
+func (h *handler) verifyEmail(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if err := h.svc.VerifyEmail(h.withClient(r), r.PostFormValue("token")); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
 // SignUp | Creates New Account
 //
 //	@Summary      SignUp
@@ -6,10 +6,13 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"time-capsule/config"
 	"time-capsule/internal/domain"
+	"time-capsule/internal/jwks"
 	"time-capsule/internal/service"
 	mock_service "time-capsule/internal/service/mocks"
 
@@ -33,7 +36,7 @@ func TestAuthHandler_signIn(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, email, password string) {
-				s.EXPECT().GenerateToken(ctx, email, password).Return("some-token", nil).Times(1)
+				s.EXPECT().GenerateToken(ctx, email, password).Return(&domain.SignInResult{Token: "some-token"}, nil).Times(1)
 			},
 			inputBody: `{"email": "foo@example.com", "password": "Qwerty123"}`,
 			inputData: domain.LogInUserDTO{
@@ -43,6 +46,22 @@ func TestAuthHandler_signIn(t *testing.T) {
 			expectedStatusCode:   http.StatusOK,
 			expectedResponseBody: `{"token":"some-token"}`,
 		},
+		{
+			name: "MFA-Required",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, email, password string) {
+				s.EXPECT().GenerateToken(ctx, email, password).Return(&domain.SignInResult{
+					MFARequired: true,
+					MFAToken:    "some-mfa-token",
+				}, nil).Times(1)
+			},
+			inputBody: `{"email": "foo@example.com", "password": "Qwerty123"}`,
+			inputData: domain.LogInUserDTO{
+				Email:    "foo@example.com",
+				Password: "Qwerty123",
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"mfaRequired":true,"mfaToken":"some-mfa-token"}`,
+		},
 		{
 			name:                 "Invalid JSON",
 			mockBehavior:         func(s *mock_service.MockUserService, ctx context.Context, email, password string) {},
@@ -53,7 +72,7 @@ func TestAuthHandler_signIn(t *testing.T) {
 		{
 			name: "Service-Failure",
 			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, email, password string) {
-				s.EXPECT().GenerateToken(ctx, email, password).Return("", errors.New("some error")).Times(1)
+				s.EXPECT().GenerateToken(ctx, email, password).Return(nil, errors.New("some error")).Times(1)
 			},
 			inputBody: `{"email": "foo@example.com", "password": "Qwerty123"}`,
 			inputData: domain.LogInUserDTO{
@@ -63,6 +82,32 @@ func TestAuthHandler_signIn(t *testing.T) {
 			expectedStatusCode:   http.StatusInternalServerError,
 			expectedResponseBody: `{"message":"some error"}`,
 		},
+		{
+			name: "Account-Locked",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, email, password string) {
+				s.EXPECT().GenerateToken(ctx, email, password).Return(nil, service.ErrAccountLocked).Times(1)
+			},
+			inputBody: `{"email": "foo@example.com", "password": "Qwerty123"}`,
+			inputData: domain.LogInUserDTO{
+				Email:    "foo@example.com",
+				Password: "Qwerty123",
+			},
+			expectedStatusCode:   http.StatusLocked,
+			expectedResponseBody: `{"message":"account is temporarily locked after too many failed sign-in attempts, check your email to unlock it"}`,
+		},
+		{
+			name: "Throttled",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, email, password string) {
+				s.EXPECT().GenerateToken(ctx, email, password).Return(nil, service.ErrSignInThrottled).Times(1)
+			},
+			inputBody: `{"email": "foo@example.com", "password": "Qwerty123"}`,
+			inputData: domain.LogInUserDTO{
+				Email:    "foo@example.com",
+				Password: "Qwerty123",
+			},
+			expectedStatusCode:   http.StatusTooManyRequests,
+			expectedResponseBody: `{"message":"too many failed sign-in attempts, try again later"}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -71,7 +116,10 @@ func TestAuthHandler_signIn(t *testing.T) {
 			defer c.Finish()
 
 			var (
-				ctx = context.Background()
+				ctx = service.WithClient(context.Background(), service.Client{
+					IP:        "192.0.2.1",
+					UserAgent: "test-agent",
+				})
 
 				userSvc = mock_service.NewMockUserService(c)
 				svc     = &service.Service{
@@ -83,6 +131,7 @@ func TestAuthHandler_signIn(t *testing.T) {
 					router:  router,
 					svc:     svc,
 					storage: nil,
+					cfg:     &config.Config{},
 				}
 			)
 
@@ -93,6 +142,319 @@ func TestAuthHandler_signIn(t *testing.T) {
 			w := httptest.NewRecorder()
 			req := httptest.NewRequest(http.MethodPost, signInURL, bytes.NewBufferString(test.inputBody))
 			req.Header.Add("Content-Type", "application/json")
+			req.Header.Set("User-Agent", "test-agent")
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAuthHandler_verifyMFA(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService, ctx context.Context, input domain.MFASignInDTO)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		inputBody            string
+		inputData            domain.MFASignInDTO
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, input domain.MFASignInDTO) {
+				s.EXPECT().VerifyMFA(ctx, input).Return("some-token", nil).Times(1)
+			},
+			inputBody: `{"mfaToken": "some-mfa-token", "code": "123456"}`,
+			inputData: domain.MFASignInDTO{
+				MFAToken: "some-mfa-token",
+				Code:     "123456",
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"token":"some-token"}`,
+		},
+		{
+			name:                 "Invalid JSON",
+			mockBehavior:         func(s *mock_service.MockUserService, ctx context.Context, input domain.MFASignInDTO) {},
+			inputBody:            `{`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid json"}`,
+		},
+		{
+			name: "Invalid-Code",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, input domain.MFASignInDTO) {
+				s.EXPECT().VerifyMFA(ctx, input).Return("", service.ErrInvalidTwoFactorCode).Times(1)
+			},
+			inputBody: `{"mfaToken": "some-mfa-token", "code": "000000"}`,
+			inputData: domain.MFASignInDTO{
+				MFAToken: "some-mfa-token",
+				Code:     "000000",
+			},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"invalid two-factor authentication code"}`,
+		},
+		{
+			name: "Invalid-Token",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, input domain.MFASignInDTO) {
+				s.EXPECT().VerifyMFA(ctx, input).Return("", service.ErrInvalidToken).Times(1)
+			},
+			inputBody: `{"mfaToken": "foo", "code": "123456"}`,
+			inputData: domain.MFASignInDTO{
+				MFAToken: "foo",
+				Code:     "123456",
+			},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"` + service.ErrInvalidToken.Error() + `"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = service.WithClient(context.Background(), service.Client{IP: "192.0.2.1"})
+
+				userSvc = mock_service.NewMockUserService(c)
+				svc     = &service.Service{
+					UserService: userSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(userSvc, ctx, test.inputData)
+
+			router.POST(mfaURL, hndlr.verifyMFA)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, mfaURL, bytes.NewBufferString(test.inputBody))
+			req.Header.Add("Content-Type", "application/json")
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAuthHandler_getJWKS(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	var (
+		userSvc = mock_service.NewMockUserService(c)
+		svc     = &service.Service{
+			UserService: userSvc,
+		}
+		router = httprouter.New()
+
+		hndlr = handler{
+			router: router,
+			svc:    svc,
+		}
+	)
+
+	userSvc.EXPECT().JWKS().Return(jwks.Set{Keys: []jwks.JWK{{
+		KeyType:   "OKP",
+		Use:       "sig",
+		Algorithm: jwks.AlgorithmEdDSA,
+		KeyID:     "some-kid",
+		Curve:     "Ed25519",
+		X:         "some-x",
+	}}}).Times(1)
+
+	router.GET(jwksURL, hndlr.getJWKS)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, jwksURL, nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+	assert.Equal(t, `{"keys":[{"kty":"OKP","use":"sig","alg":"EdDSA","kid":"some-kid","crv":"Ed25519","x":"some-x"}]}`,
+		w.Body.String())
+}
+
+func TestAuthHandler_unlockAccountPage(t *testing.T) {
+	var (
+		router = httprouter.New()
+		hndlr  = handler{router: router}
+	)
+
+	router.GET(unlockURL, hndlr.unlockAccountPage)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, unlockURL+`?token="><script>`, nil)
+
+	router.ServeHTTP(w, req)
+
+	// Opening the link only serves the form confirming the unlock, with the token escaped.
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `<form id="confirm" method="post">`)
+	assert.Contains(t, w.Body.String(), `value="&#34;&gt;&lt;script&gt;"`)
+}
+
+func TestAuthHandler_unlockAccount(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService, ctx context.Context)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context) {
+				s.EXPECT().UnlockAccount(ctx, "some-token").Return(nil).Times(1)
+			},
+			requestBody:          "token=some-token",
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name: "Invalid-Token",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context) {
+				s.EXPECT().UnlockAccount(ctx, "").Return(service.ErrInvalidUnlockToken).Times(1)
+			},
+			requestBody:          "",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid or expired unlock token"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = service.WithClient(context.Background(), service.Client{IP: "192.0.2.1"})
+
+				userSvc = mock_service.NewMockUserService(c)
+				svc     = &service.Service{
+					UserService: userSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(userSvc, ctx)
+
+			router.POST(unlockURL, hndlr.unlockAccount)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, unlockURL, strings.NewReader(test.requestBody))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAuthHandler_verifyEmailPage(t *testing.T) {
+	var (
+		router = httprouter.New()
+		hndlr  = handler{router: router}
+	)
+
+	router.GET(verifyEmailURL, hndlr.verifyEmailPage)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, verifyEmailURL+"?token=some-token", nil)
+
+	router.ServeHTTP(w, req)
+
+	// Opening the link only serves the form confirming the new email.
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), `<form id="confirm" method="post">`)
+	assert.Contains(t, w.Body.String(), `value="some-token"`)
+}
+
+func TestAuthHandler_verifyEmail(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService, ctx context.Context)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context) {
+				s.EXPECT().VerifyEmail(ctx, "some-token").Return(nil).Times(1)
+			},
+			requestBody:          "token=some-token",
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name: "Invalid-Token",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context) {
+				s.EXPECT().VerifyEmail(ctx, "").Return(service.ErrInvalidVerificationToken).Times(1)
+			},
+			requestBody:          "",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid or expired email verification token"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = service.WithClient(context.Background(), service.Client{IP: "192.0.2.1"})
+
+				userSvc = mock_service.NewMockUserService(c)
+				svc     = &service.Service{
+					UserService: userSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(userSvc, ctx)
+
+			router.POST(verifyEmailURL, hndlr.verifyEmail)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, verifyEmailURL, strings.NewReader(test.requestBody))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 			router.ServeHTTP(w, req)
 
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"time-capsule/internal/domain"
 
 	"github.com/julienschmidt/httprouter"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // DeleteCapsule | Removes Capsule
@@ -36,7 +38,7 @@ func (h *handler) deleteCapsule(w http.ResponseWriter, r *http.Request, params h
 		return
 	}
 
-	if err = h.svc.DeleteCapsule(r.Context(), userID, capsuleID); err != nil {
+	if err = h.svc.DeleteCapsule(h.withClient(r), userID, capsuleID); err != nil {
 		newErrorResponse(w, err)
 		return
 	}
@@ -80,7 +82,7 @@ func (h *handler) updateCapsule(w http.ResponseWriter, r *http.Request, params h
 		return
 	}
 
-	if err = h.svc.UpdateCapsule(r.Context(), userID, capsuleID, input); err != nil {
+	if err = h.svc.UpdateCapsule(h.withClient(r), userID, capsuleID, input); err != nil {
 		newErrorResponse(w, err)
 		return
 	}
@@ -130,10 +132,13 @@ func (h *handler) getCapsuleByID(w http.ResponseWriter, r *http.Request, params
 //
 //	@Summary      GetCapsules
 //	@Security     ApiKeyAuth
-//	@Description  Retrieves all capsules
+//	@Description  Retrieves all capsules, optionally filtered by tag or collection
 //	@Tags         Capsules
 //	@Produce      json
+//	@Param        tag          query     string false "tag"
+//	@Param        collection   query     string false "collectionID"
 //	@Success      200   {array}   domain.Capsule
+//	@Failure      400   {object}  errorResponse
 //	@Failure      401   {object}  errorResponse
 //	@Failure      500   {object}  errorResponse
 //	@Router       /api/v1/capsules [get]
@@ -144,7 +149,19 @@ func (h *handler) getCapsules(w http.ResponseWriter, r *http.Request, _ httprout
 		return
 	}
 
-	capsules, err := h.svc.GetAllCapsules(r.Context(), userID)
+	filter := domain.CapsuleFilter{
+		Tag: r.URL.Query().Get("tag"),
+	}
+
+	if collection := r.URL.Query().Get("collection"); collection != "" {
+		filter.CollectionID, err = primitive.ObjectIDFromHex(collection)
+		if err != nil {
+			newErrorResponse(w, errors.New("invalid id"), http.StatusBadRequest)
+			return
+		}
+	}
+
+	capsules, err := h.svc.GetAllCapsules(r.Context(), userID, filter)
 	if err != nil {
 		newErrorResponse(w, err)
 		return
@@ -154,6 +171,36 @@ func (h *handler) getCapsules(w http.ResponseWriter, r *http.Request, _ httprout
 	return
 }
 
+// SearchCapsules | Searches Opened Capsules
+//
+//	@Summary      SearchCapsules
+//	@Security     ApiKeyAuth
+//	@Description  Full-text search over the messages of opened capsules
+//	@Tags         Capsules
+//	@Produce      json
+//	@Param        q     query     string true "search query"
+//	@Success      200   {array}   domain.CapsuleSearchResult
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/capsules/search [get]
+func (h *handler) searchCapsules(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	results, err := h.svc.SearchCapsules(r.Context(), userID, r.URL.Query().Get("q"))
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, results)
+	return
+}
+
 // CreateCapsule | Creates New Capsule
 //
 //	@Summary      CreateCapsule
@@ -181,7 +228,7 @@ func (h *handler) createCapsule(w http.ResponseWriter, r *http.Request, _ httpro
 		return
 	}
 
-	capsule, err := h.svc.CreateCapsule(r.Context(), userID, input)
+	capsule, err := h.svc.CreateCapsule(h.withClient(r), userID, input)
 	if err != nil {
 		newErrorResponse(w, err)
 		return
@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"time-capsule/config"
 	"time-capsule/internal/domain"
 	"time-capsule/internal/service"
 	mock_service "time-capsule/internal/service/mocks"
@@ -36,7 +38,7 @@ func TestCapsuleHandler_deleteCapsule(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID) {
-				s.EXPECT().DeleteCapsule(ctx, userID, capsuleID).Return(nil).Times(1)
+				s.EXPECT().DeleteCapsule(gomock.Any(), userID, capsuleID).Return(nil).Times(1)
 			},
 			ctxUserID:            primitive.NilObjectID.Hex(),
 			capsuleID:            primitive.NilObjectID,
@@ -65,7 +67,7 @@ func TestCapsuleHandler_deleteCapsule(t *testing.T) {
 		{
 			name: "Service-Failure",
 			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID) {
-				s.EXPECT().DeleteCapsule(ctx, userID, capsuleID).Return(errors.New("some error")).Times(1)
+				s.EXPECT().DeleteCapsule(gomock.Any(), userID, capsuleID).Return(errors.New("some error")).Times(1)
 			},
 			ctxUserID:            primitive.NilObjectID.Hex(),
 			capsuleID:            primitive.NilObjectID,
@@ -94,6 +96,7 @@ func TestCapsuleHandler_deleteCapsule(t *testing.T) {
 						router:  router,
 						svc:     svc,
 						storage: nil,
+						cfg:     &config.Config{},
 					}
 				)
 
@@ -133,7 +136,7 @@ func TestCapsuleHandler_updateCapsule(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				s.EXPECT().UpdateCapsule(ctx, userID, capsuleID, gomock.Any()).Return(nil).Times(1)
+				s.EXPECT().UpdateCapsule(gomock.Any(), userID, capsuleID, gomock.Any()).Return(nil).Times(1)
 			},
 			ctxUserID:    primitive.NilObjectID.Hex(),
 			capsuleID:    primitive.NilObjectID,
@@ -183,10 +186,27 @@ func TestCapsuleHandler_updateCapsule(t *testing.T) {
 			expectedStatusCode:   http.StatusBadRequest,
 			expectedResponseBody: `{"message":"invalid json"}`,
 		},
+		{
+			name: "Update-Too-Late",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID, update domain.UpdateCapsuleDTO) {
+				s.EXPECT().UpdateCapsule(gomock.Any(), userID, capsuleID, gomock.Any()).
+					Return(fmt.Errorf("%w after 30m", service.ErrUpdateTooLate)).Times(1)
+			},
+			ctxUserID:    primitive.NilObjectID.Hex(),
+			capsuleID:    primitive.NilObjectID,
+			capsuleIDHex: primitive.NilObjectID.Hex(),
+			inputBody:    `{"message": "brand new message", "openAt": "1970-01-01T00:00:00Z"}`,
+			inputData: domain.UpdateCapsuleDTO{
+				Message: "brand new message",
+				OpenAt:  time.Unix(0, 0),
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"updating the capsule is no longer allowed after 30m"}`,
+		},
 		{
 			name: "Service-Failure",
 			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				s.EXPECT().UpdateCapsule(ctx, userID, capsuleID, gomock.Any()).Return(errors.New("some error")).Times(1)
+				s.EXPECT().UpdateCapsule(gomock.Any(), userID, capsuleID, gomock.Any()).Return(errors.New("some error")).Times(1)
 			},
 			ctxUserID:    primitive.NilObjectID.Hex(),
 			capsuleID:    primitive.NilObjectID,
@@ -219,6 +239,7 @@ func TestCapsuleHandler_updateCapsule(t *testing.T) {
 					router:  router,
 					svc:     svc,
 					storage: nil,
+					cfg:     &config.Config{},
 				}
 			)
 
@@ -269,7 +290,26 @@ func TestCapsuleHandler_getCapsuleByID(t *testing.T) {
 			capsuleID:            primitive.NilObjectID,
 			capsuleIDHex:         primitive.NilObjectID.Hex(),
 			expectedStatusCode:   http.StatusOK,
-			expectedResponseBody: `{"id":"000000000000000000000000","userID":"000000000000000000000000","message":"some message","images":[],"openAt":"1970-01-01T00:00:01Z","createdAt":"1970-01-01T00:00:00Z"}`,
+			expectedResponseBody: `{"id":"000000000000000000000000","userID":"000000000000000000000000","message":"some message","images":[],"openAt":"1970-01-01T00:00:01Z","createdAt":"1970-01-01T00:00:00Z","reminders":null}`,
+		},
+		{
+			name: "Reminders-Off",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID, capsuleID primitive.ObjectID) {
+				s.EXPECT().GetCapsuleByID(ctx, userID, capsuleID).Return(&domain.Capsule{
+					ID:        primitive.NilObjectID,
+					UserID:    primitive.NilObjectID,
+					Message:   "some message",
+					Images:    []string{},
+					OpenAt:    time.Unix(1, 0),
+					CreatedAt: time.Unix(0, 0),
+					Reminders: []int{},
+				}, nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			capsuleID:            primitive.NilObjectID,
+			capsuleIDHex:         primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"id":"000000000000000000000000","userID":"000000000000000000000000","message":"some message","images":[],"openAt":"1970-01-01T00:00:01Z","createdAt":"1970-01-01T00:00:00Z","reminders":[]}`,
 		},
 		{
 			name: "Invalid-Context",
@@ -322,6 +362,7 @@ func TestCapsuleHandler_getCapsuleByID(t *testing.T) {
 					router:  router,
 					svc:     svc,
 					storage: nil,
+					cfg:     &config.Config{},
 				}
 			)
 
@@ -345,17 +386,20 @@ func TestCapsuleHandler_getCapsuleByID(t *testing.T) {
 func TestCapsuleHandler_getCapsules(t *testing.T) {
 	type mockBehavior func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID)
 
+	collectionID := primitive.NewObjectID()
+
 	tests := []struct {
 		name                 string
 		mockBehavior         mockBehavior
 		ctxUserID            string
+		query                string
 		expectedStatusCode   int
 		expectedResponseBody string
 	}{
 		{
 			name: "OK",
 			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID) {
-				s.EXPECT().GetAllCapsules(ctx, userID).
+				s.EXPECT().GetAllCapsules(ctx, userID, domain.CapsuleFilter{}).
 					Return([]*domain.Capsule{
 						{
 							ID:        primitive.NilObjectID,
@@ -380,8 +424,8 @@ func TestCapsuleHandler_getCapsules(t *testing.T) {
 			ctxUserID:          primitive.NilObjectID.Hex(),
 			expectedStatusCode: http.StatusOK,
 			expectedResponseBody: strings.Replace(strings.Replace(`[
-					{"id":"000000000000000000000000","userID":"000000000000000000000000","message":"some message 1","images":[],"openAt":"1970-01-01T00:00:01Z","createdAt":"1970-01-01T00:00:00Z"},
-					{"id":"000000000000000000000000","userID":"000000000000000000000000","message":"some message 2","images":[],"openAt":"1970-01-01T00:00:02Z","createdAt":"1970-01-01T00:00:03Z"}
+					{"id":"000000000000000000000000","userID":"000000000000000000000000","message":"some message 1","images":[],"openAt":"1970-01-01T00:00:01Z","createdAt":"1970-01-01T00:00:00Z","reminders":null},
+					{"id":"000000000000000000000000","userID":"000000000000000000000000","message":"some message 2","images":[],"openAt":"1970-01-01T00:00:02Z","createdAt":"1970-01-01T00:00:03Z","reminders":null}
 			]`, "\n", "", -1), "\t", "", -1),
 		},
 		{
@@ -391,10 +435,31 @@ func TestCapsuleHandler_getCapsules(t *testing.T) {
 			expectedStatusCode:   http.StatusInternalServerError,
 			expectedResponseBody: `{"message":"internal server error"}`,
 		},
+		{
+			name: "OK-Filtered",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().GetAllCapsules(ctx, userID, domain.CapsuleFilter{
+					Tag:          "kids",
+					CollectionID: collectionID,
+				}).Return([]*domain.Capsule{}, nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			query:                "?tag=kids&collection=" + collectionID.Hex(),
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `[]`,
+		},
+		{
+			name:                 "Invalid-CollectionID",
+			mockBehavior:         func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID) {},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			query:                "?collection=123",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid id"}`,
+		},
 		{
 			name: "Service-Failure",
 			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID) {
-				s.EXPECT().GetAllCapsules(ctx, userID).
+				s.EXPECT().GetAllCapsules(ctx, userID, domain.CapsuleFilter{}).
 					Return(nil, errors.New("some error")).Times(1)
 			},
 			ctxUserID:            primitive.NilObjectID.Hex(),
@@ -421,6 +486,7 @@ func TestCapsuleHandler_getCapsules(t *testing.T) {
 					router:  router,
 					svc:     svc,
 					storage: nil,
+					cfg:     &config.Config{},
 				}
 			)
 
@@ -430,7 +496,7 @@ func TestCapsuleHandler_getCapsules(t *testing.T) {
 
 			w := httptest.NewRecorder()
 
-			req := httptest.NewRequest(http.MethodGet, getCapsulesURL, nil)
+			req := httptest.NewRequest(http.MethodGet, getCapsulesURL+test.query, nil)
 			req = req.WithContext(ctx)
 
 			router.ServeHTTP(w, req)
@@ -456,7 +522,7 @@ func TestCapsuleHandler_createCapsule(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
-				s.EXPECT().CreateCapsule(ctx, userID, gomock.Any()).Return(
+				s.EXPECT().CreateCapsule(gomock.Any(), userID, gomock.Any()).Return(
 					&domain.Capsule{
 						ID:        primitive.NilObjectID,
 						UserID:    primitive.NilObjectID,
@@ -474,7 +540,7 @@ func TestCapsuleHandler_createCapsule(t *testing.T) {
 				OpenAt:  time.Unix(0, 0),
 			},
 			expectedStatusCode:   http.StatusCreated,
-			expectedResponseBody: `{"id":"000000000000000000000000","userID":"000000000000000000000000","message":"some message","images":[],"openAt":"1970-01-01T00:00:00Z","createdAt":"1970-01-01T00:00:00Z"}`,
+			expectedResponseBody: `{"id":"000000000000000000000000","userID":"000000000000000000000000","message":"some message","images":[],"openAt":"1970-01-01T00:00:00Z","createdAt":"1970-01-01T00:00:00Z","reminders":null}`,
 		},
 		{
 			name: "Invalid-Context",
@@ -497,7 +563,7 @@ func TestCapsuleHandler_createCapsule(t *testing.T) {
 		{
 			name: "Service-Failure",
 			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
-				s.EXPECT().CreateCapsule(ctx, userID, gomock.Any()).Return(nil, errors.New("some error")).Times(1)
+				s.EXPECT().CreateCapsule(gomock.Any(), userID, gomock.Any()).Return(nil, errors.New("some error")).Times(1)
 			},
 			ctxUserID: primitive.NilObjectID.Hex(),
 			inputBody: `{"message":"some message", "openAt": "1970-01-01T00:00:00Z"}`,
@@ -528,6 +594,7 @@ func TestCapsuleHandler_createCapsule(t *testing.T) {
 					router:  router,
 					svc:     svc,
 					storage: nil,
+					cfg:     &config.Config{},
 				}
 			)
 
@@ -548,3 +615,113 @@ func TestCapsuleHandler_createCapsule(t *testing.T) {
 		})
 	}
 }
+
+func TestCapsuleHandler_searchCapsules(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID, query string)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		query                string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID, query string) {
+				s.EXPECT().SearchCapsules(ctx, userID, query).
+					Return([]*domain.CapsuleSearchResult{
+						{
+							Capsule: &domain.Capsule{
+								ID:        primitive.NilObjectID,
+								UserID:    primitive.NilObjectID,
+								Message:   "graduation day",
+								Images:    []string{},
+								OpenAt:    time.Unix(1, 0),
+								CreatedAt: time.Unix(0, 0),
+							},
+							Score:   1.5,
+							Snippet: "<mark>graduation</mark> day",
+						},
+					}, nil).Times(1)
+			},
+			ctxUserID:          primitive.NilObjectID.Hex(),
+			query:              "graduation",
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: strings.Replace(strings.Replace(`[
+					{"capsule":{"id":"000000000000000000000000","userID":"000000000000000000000000","message":"graduation day","images":[],"openAt":"1970-01-01T00:00:01Z","createdAt":"1970-01-01T00:00:00Z","reminders":null},
+					"score":1.5,"snippet":"\u003cmark\u003egraduation\u003c/mark\u003e day"}
+			]`, "\n", "", -1), "\t", "", -1),
+		},
+		{
+			name: "Invalid-Context",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID, query string) {
+			},
+			ctxUserID:            "12321312",
+			query:                "graduation",
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+		{
+			name: "Empty-Query",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID, query string) {
+				s.EXPECT().SearchCapsules(ctx, userID, query).
+					Return(nil, service.ErrEmptySearchQuery).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			query:                "",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"search query cannot be empty"}`,
+		},
+		{
+			name: "Service-Failure",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID, query string) {
+				s.EXPECT().SearchCapsules(ctx, userID, query).
+					Return(nil, errors.New("some error")).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			query:                "graduation",
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				capSvc = mock_service.NewMockCapsuleService(c)
+				svc    = &service.Service{
+					CapsuleService: capSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(capSvc, ctx, primitive.NilObjectID, test.query)
+
+			router.GET(getCapsuleURL, staticOrParam(pathCapsuleID, searchSegment, hndlr.searchCapsules, hndlr.getCapsuleByID))
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, searchCapsulesURL+"?q="+test.query, nil)
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"time-capsule/internal/domain"
+
+	"github.com/julienschmidt/httprouter"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RemoveCollectionCapsule | Removes Capsule From The Collection
+//
+//	@Summary      RemoveCollectionCapsule
+//	@Security     ApiKeyAuth
+//	@Description  Removes a capsule from the collection
+//	@Tags         Collections
+//	@Produce      json
+//	@Param        collectionID path      string true "collectionID"
+//	@Param        capsuleID    path      string true "capsuleID"
+//	@Success      204
+//	@Failure      400          {object}  errorResponse
+//	@Failure      401          {object}  errorResponse
+//	@Failure      403          {object}  errorResponse
+//	@Failure      404          {object}  errorResponse
+//	@Failure      500          {object}  errorResponse
+//	@Router       /api/v1/collections/{collectionID}/capsules/{capsuleID} [delete]
+func (h *handler) removeCollectionCapsule(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	h.changeCollectionCapsule(w, r, params, h.svc.RemoveCapsule)
+}
+
+// AddCollectionCapsule | Adds Capsule To The Collection
+//
+//	@Summary      AddCollectionCapsule
+//	@Security     ApiKeyAuth
+//	@Description  Adds a capsule to the collection
+//	@Tags         Collections
+//	@Produce      json
+//	@Param        collectionID path      string true "collectionID"
+//	@Param        capsuleID    path      string true "capsuleID"
+//	@Success      204
+//	@Failure      400          {object}  errorResponse
+//	@Failure      401          {object}  errorResponse
+//	@Failure      403          {object}  errorResponse
+//	@Failure      404          {object}  errorResponse
+//	@Failure      500          {object}  errorResponse
+//	@Router       /api/v1/collections/{collectionID}/capsules/{capsuleID} [put]
+func (h *handler) addCollectionCapsule(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	h.changeCollectionCapsule(w, r, params, h.svc.AddCapsule)
+}
+
+type collectionCapsuleFunc func(ctx context.Context, userID, id, capsuleID primitive.ObjectID) error
+
+func (h *handler) changeCollectionCapsule(w http.ResponseWriter, r *http.Request, params httprouter.Params, change collectionCapsuleFunc) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	collectionID, err := parseObjectIDFromParam(params, pathCollectionID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	capsuleID, err := parseObjectIDFromParam(params, pathCapsuleID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = change(r.Context(), userID, collectionID, capsuleID); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteCollection | Removes Collection
+//
+//	@Summary      DeleteCollection
+//	@Security     ApiKeyAuth
+//	@Description  Removes a collection. Its capsules are kept
+//	@Tags         Collections
+//	@Produce      json
+//	@Param        collectionID path      string true "collectionID"
+//	@Success      204
+//	@Failure      400          {object}  errorResponse
+//	@Failure      401          {object}  errorResponse
+//	@Failure      403          {object}  errorResponse
+//	@Failure      404          {object}  errorResponse
+//	@Failure      500          {object}  errorResponse
+//	@Router       /api/v1/collections/{collectionID} [delete]
+func (h *handler) deleteCollection(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	collectionID, err := parseObjectIDFromParam(params, pathCollectionID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.svc.DeleteCollection(r.Context(), userID, collectionID); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// UpdateCollection | Renames Collection
+//
+//	@Summary      UpdateCollection
+//	@Security     ApiKeyAuth
+//	@Description  Renames a collection
+//	@Tags         Collections
+//	@Accept       json
+//	@Produce      json
+//	@Param        collectionID path      string true "collectionID"
+//	@Param        input        body      domain.UpdateCollectionDTO true "input"
+//	@Success      204
+//	@Failure      400          {object}  errorResponse
+//	@Failure      401          {object}  errorResponse
+//	@Failure      403          {object}  errorResponse
+//	@Failure      404          {object}  errorResponse
+//	@Failure      409          {object}  errorResponse
+//	@Failure      500          {object}  errorResponse
+//	@Router       /api/v1/collections/{collectionID} [patch]
+func (h *handler) updateCollection(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	collectionID, err := parseObjectIDFromParam(params, pathCollectionID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.UpdateCollectionDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	if err = h.svc.UpdateCollection(r.Context(), userID, collectionID, input); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// GetCollection | Retrieves Collection By ID
+//
+//	@Summary      GetCollection
+//	@Security     ApiKeyAuth
+//	@Description  Retrieves a collection by ID
+//	@Tags         Collections
+//	@Produce      json
+//	@Param        collectionID path      string true "collectionID"
+//	@Success      200          {object}  domain.Collection
+//	@Failure      400          {object}  errorResponse
+//	@Failure      401          {object}  errorResponse
+//	@Failure      403          {object}  errorResponse
+//	@Failure      404          {object}  errorResponse
+//	@Failure      500          {object}  errorResponse
+//	@Router       /api/v1/collections/{collectionID} [get]
+func (h *handler) getCollectionByID(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	collectionID, err := parseObjectIDFromParam(params, pathCollectionID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.svc.GetCollectionByID(r.Context(), userID, collectionID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, collection)
+	return
+}
+
+// GetCollections | Retrieves All Collections
+//
+//	@Summary      GetCollections
+//	@Security     ApiKeyAuth
+//	@Description  Retrieves all collections
+//	@Tags         Collections
+//	@Produce      json
+//	@Success      200   {array}   domain.Collection
+//	@Failure      401   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/collections [get]
+func (h *handler) getCollections(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	collections, err := h.svc.GetAllCollections(r.Context(), userID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, collections)
+	return
+}
+
+// CreateCollection | Creates New Collection
+//
+//	@Summary      CreateCollection
+//	@Security     ApiKeyAuth
+//	@Description  Creates a new collection
+//	@Tags         Collections
+//	@Accept       json
+//	@Produce      json
+//	@Param        input body      domain.CreateCollectionDTO true "input"
+//	@Success      201   {object}  domain.Collection
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      409   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/collections [post]
+func (h *handler) createCollection(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	var input domain.CreateCollectionDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	collection, err := h.svc.CreateCollection(r.Context(), userID, input)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, collection, http.StatusCreated)
+	return
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/service"
+	mock_service "time-capsule/internal/service/mocks"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCollectionHandler_createCollection(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCollectionService, ctx context.Context, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		inputBody            string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockCollectionService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().CreateCollection(ctx, userID, domain.CreateCollectionDTO{Name: "Kids"}).Return(
+					&domain.Collection{
+						ID:        primitive.NilObjectID,
+						UserID:    primitive.NilObjectID,
+						Name:      "Kids",
+						CreatedAt: time.Unix(0, 0).UTC(),
+					}, nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			inputBody:            `{"name":"Kids"}`,
+			expectedStatusCode:   http.StatusCreated,
+			expectedResponseBody: `{"id":"000000000000000000000000","userID":"000000000000000000000000","name":"Kids","createdAt":"1970-01-01T00:00:00Z"}`,
+		},
+		{
+			name:                 "Invalid-Context",
+			mockBehavior:         func(s *mock_service.MockCollectionService, ctx context.Context, userID primitive.ObjectID) {},
+			ctxUserID:            "123123",
+			inputBody:            `{"name":"Kids"}`,
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+		{
+			name:                 "Invalid-JSON",
+			mockBehavior:         func(s *mock_service.MockCollectionService, ctx context.Context, userID primitive.ObjectID) {},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			inputBody:            `{"name":1}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid json"}`,
+		},
+		{
+			name: "Duplicate",
+			mockBehavior: func(s *mock_service.MockCollectionService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().CreateCollection(ctx, userID, domain.CreateCollectionDTO{Name: "Kids"}).
+					Return(nil, service.ErrCollectionDuplicate).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			inputBody:            `{"name":"Kids"}`,
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"message":"collection with this name already exists"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				colSvc = mock_service.NewMockCollectionService(c)
+				svc    = &service.Service{
+					CollectionService: colSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(colSvc, ctx, primitive.NilObjectID)
+
+			router.POST(createCollectionURL, hndlr.createCollection)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, createCollectionURL, bytes.NewBufferString(test.inputBody))
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestCollectionHandler_addCollectionCapsule(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCollectionService, ctx context.Context, userID, id, capsuleID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		collectionIDHex      string
+		capsuleIDHex         string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockCollectionService, ctx context.Context, userID, id, capsuleID primitive.ObjectID) {
+				s.EXPECT().AddCapsule(ctx, userID, id, capsuleID).Return(nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			collectionIDHex:      primitive.NilObjectID.Hex(),
+			capsuleIDHex:         primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name: "Invalid-CollectionID",
+			mockBehavior: func(s *mock_service.MockCollectionService, ctx context.Context, userID, id, capsuleID primitive.ObjectID) {
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			collectionIDHex:      "123",
+			capsuleIDHex:         primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid id"}`,
+		},
+		{
+			name: "Invalid-CapsuleID",
+			mockBehavior: func(s *mock_service.MockCollectionService, ctx context.Context, userID, id, capsuleID primitive.ObjectID) {
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			collectionIDHex:      primitive.NilObjectID.Hex(),
+			capsuleIDHex:         "123",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid id"}`,
+		},
+		{
+			name: "Service-Failure",
+			mockBehavior: func(s *mock_service.MockCollectionService, ctx context.Context, userID, id, capsuleID primitive.ObjectID) {
+				s.EXPECT().AddCapsule(ctx, userID, id, capsuleID).Return(errors.New("some error")).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			collectionIDHex:      primitive.NilObjectID.Hex(),
+			capsuleIDHex:         primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				colSvc = mock_service.NewMockCollectionService(c)
+				svc    = &service.Service{
+					CollectionService: colSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(colSvc, ctx, primitive.NilObjectID, primitive.NilObjectID, primitive.NilObjectID)
+
+			router.PUT(addCollectionCapsule, hndlr.addCollectionCapsule)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPut,
+				getCollectionsURL+"/"+test.collectionIDHex+"/capsules/"+test.capsuleIDHex, nil)
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"time-capsule/internal/export"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const maxImportSize = 64 << 20 // 64 megabytes
+
+// RequestExport | Exports The Account Data
+//
+//	@Summary      RequestExport
+//	@Security     ApiKeyAuth
+//	@Description  Starts building a ZIP archive of the profile, the capsules and their images. A notification with the download link is sent once it's ready
+//	@Tags         Me
+//	@Produce      json
+//	@Success      202   {object}  domain.Export
+//	@Failure      401   {object}  errorResponse
+//	@Failure      403   {object}  errorResponse
+//	@Failure      409   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me/export [post]
+func (h *handler) requestExport(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	export, err := h.svc.RequestExport(h.withClient(r), userID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, export, http.StatusAccepted)
+	return
+}
+
+// GetExports | Lists The Data Exports
+//
+//	@Summary      GetExports
+//	@Security     ApiKeyAuth
+//	@Description  Lists the data exports, the latest first
+//	@Tags         Me
+//	@Produce      json
+//	@Success      200   {array}   domain.Export
+//	@Failure      401   {object}  errorResponse
+//	@Failure      403   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me/exports [get]
+func (h *handler) getExports(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	exports, err := h.svc.GetExports(r.Context(), userID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, exports)
+	return
+}
+
+// DownloadExport | Downloads A Data Export
+//
+//	@Summary      DownloadExport
+//	@Description  Downloads the archive of a data export, with the token of the link from the notification
+//	@Tags         Me
+//	@Produce      application/zip
+//	@Param        token query     string true "download token"
+//	@Success      200
+//	@Failure      400   {object}  errorResponse
+//	@Failure      429   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/exports/download [get]
+func (h *handler) downloadExport(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	file, err := h.svc.DownloadExport(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="time-capsule-export.zip"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(file.Bytes)))
+	w.WriteHeader(http.StatusOK)
+
+	w.Write(file.Bytes)
+	return
+}
+
+// ImportCapsules | Imports Capsules
+//
+//	@Summary      ImportCapsules
+//	@Security     ApiKeyAuth
+//	@Description  Imports the collections and capsules of a data export archive, or of its JSON manifest without images.
+//	@Description  Capsules keep their timestamps and whether they were opened. Capsules that can't be imported are reported in the errors, the others are imported anyway
+//	@Tags         Me
+//	@Accept       application/zip,json
+//	@Produce      json
+//	@Param        archive body      string true "export archive or manifest"
+//	@Success      200     {object}  domain.ImportReport
+//	@Failure      400     {object}  errorResponse
+//	@Failure      401     {object}  errorResponse
+//	@Failure      403     {object}  errorResponse
+//	@Failure      413     {object}  errorResponse
+//	@Failure      500     {object}  errorResponse
+//	@Router       /api/v1/me/import [post]
+func (h *handler) importCapsules(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			newErrorResponse(w, errors.New("the archive is too large"), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "importCapsules", "error", err)
+		newErrorResponse(w, errors.New("failed to read the archive"), http.StatusBadRequest)
+		return
+	}
+
+	archive, err := export.Read(b)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.svc.ImportCapsules(h.withClient(r), userID, archive)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, report)
+	return
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/export"
+	"time-capsule/internal/service"
+	mock_service "time-capsule/internal/service/mocks"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestExportHandler_requestExport(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockExportService, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockExportService, userID primitive.ObjectID) {
+				s.EXPECT().RequestExport(gomock.Any(), userID).Return(&domain.Export{
+					ID:     primitive.NilObjectID,
+					Status: domain.ExportStatusPending,
+				}, nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusAccepted,
+			expectedResponseBody: `{"id":"000000000000000000000000","status":"pending","createdAt":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name: "In-Progress",
+			mockBehavior: func(s *mock_service.MockExportService, userID primitive.ObjectID) {
+				s.EXPECT().RequestExport(gomock.Any(), userID).Return(nil, service.ErrExportInProgress).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"message":"an export is already in progress"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				exportSvc = mock_service.NewMockExportService(c)
+				svc       = &service.Service{
+					ExportService: exportSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(exportSvc, primitive.NilObjectID)
+
+			router.POST(exportURL, hndlr.requestExport)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, exportURL, nil)
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestExportHandler_downloadExport(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockExportService, token string)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		token                string
+		expectedStatusCode   int
+		expectedContentType  string
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockExportService, token string) {
+				s.EXPECT().DownloadExport(gomock.Any(), token).Return(&domain.File{Bytes: []byte("PK")}, nil).Times(1)
+			},
+			token:                "some-token",
+			expectedStatusCode:   http.StatusOK,
+			expectedContentType:  "application/zip",
+			expectedResponseBody: "PK",
+		},
+		{
+			name: "Invalid-Token",
+			mockBehavior: func(s *mock_service.MockExportService, token string) {
+				s.EXPECT().DownloadExport(gomock.Any(), token).Return(nil, service.ErrInvalidDownloadToken).Times(1)
+			},
+			token:                "foo",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedContentType:  "application/json",
+			expectedResponseBody: `{"message":"invalid or expired download link"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				exportSvc = mock_service.NewMockExportService(c)
+				svc       = &service.Service{
+					ExportService: exportSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(exportSvc, test.token)
+
+			router.GET(downloadExportURL, hndlr.downloadExport)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, downloadExportURL+"?token="+test.token, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestExportHandler_importCapsules(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockImportService, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		body                 string
+		ctxUserID            string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockImportService, userID primitive.ObjectID) {
+				s.EXPECT().ImportCapsules(gomock.Any(), userID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ primitive.ObjectID, archive *export.Archive) (*domain.ImportReport, error) {
+						assert.Len(t, archive.Manifest.Capsules, 1)
+						return &domain.ImportReport{Capsules: 1, Errors: []domain.ImportError{}}, nil
+					}).Times(1)
+			},
+			body:                 `{"capsules":[{"message":"Hello, world!","openAt":"2030-01-01T00:00:00Z"}]}`,
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"capsules":1,"collections":0,"images":0,"errors":[]}`,
+		},
+		{
+			name:                 "Invalid-Archive",
+			mockBehavior:         func(s *mock_service.MockImportService, userID primitive.ObjectID) {},
+			body:                 "hello",
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"not an export archive or manifest"}`,
+		},
+		{
+			name: "Service-Failure",
+			mockBehavior: func(s *mock_service.MockImportService, userID primitive.ObjectID) {
+				s.EXPECT().ImportCapsules(gomock.Any(), userID, gomock.Any()).Return(nil, service.ErrDBFailure).Times(1)
+			},
+			body:                 `[]`,
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"something went wrong... try again later :("}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				importSvc = mock_service.NewMockImportService(c)
+				svc       = &service.Service{
+					ImportService: importSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(importSvc, primitive.NilObjectID)
+
+			router.POST(importURL, hndlr.importCapsules)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, importURL, strings.NewReader(test.body))
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
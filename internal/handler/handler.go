@@ -4,6 +4,11 @@ import (
 	"errors"
 	"net/http"
 
+	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/health"
+	"time-capsule/internal/metrics"
+	"time-capsule/internal/ratelimit"
 	"time-capsule/internal/service"
 	"time-capsule/internal/storage"
 
@@ -14,8 +19,44 @@ import (
 const (
 	apiPrefix = "/api/v1"
 
+	jwksURL    = "/.well-known/jwks.json"
+	metricsURL = "/metrics"
+	healthzURL = "/healthz"
+	readyzURL  = "/readyz"
+
 	signUpURL = apiPrefix + "/sign-up"
 	signInURL = apiPrefix + "/sign-in"
+	mfaURL    = signInURL + "/mfa"
+	unlockURL = apiPrefix + "/unlock"
+
+	verifyEmailURL = apiPrefix + "/verify-email"
+
+	pathProvider = "provider"
+
+	oidcURL         = apiPrefix + "/oidc/"
+	oidcLoginURL    = oidcURL + ":" + pathProvider + "/login"
+	oidcCallbackURL = oidcURL + ":" + pathProvider + "/callback"
+
+	meURL        = apiPrefix + "/me"
+	checkInURL   = meURL + "/check-in"
+	remindersURL = meURL + "/reminders"
+	restoreURL   = meURL + "/restore"
+	exportURL    = meURL + "/export"
+	exportsURL   = meURL + "/exports"
+	importURL    = meURL + "/import"
+	myAuditURL   = meURL + "/audit"
+
+	downloadExportURL = apiPrefix + "/exports/download"
+
+	enrollTOTPURL  = meURL + "/2fa/totp"
+	confirmTOTPURL = enrollTOTPURL + "/confirm"
+	disableTOTPURL = enrollTOTPURL + "/disable"
+
+	pathTokenID = "tokenID"
+
+	createAccessTokenURL = meURL + "/tokens"
+	getAccessTokensURL
+	revokeAccessTokenURL = getAccessTokensURL + "/:" + pathTokenID
 
 	pathCapsuleID = "capsuleID"
 
@@ -30,6 +71,60 @@ const (
 	addCapsuleImage = getCapsuleURL + "/images"
 	getCapsuleImage = addCapsuleImage + "/:" + pathImageID
 	removeCapsuleImage
+
+	searchSegment     = "search"
+	searchCapsulesURL = getCapsulesURL + "/" + searchSegment
+
+	pathTag = "tag"
+
+	addCapsuleTags   = getCapsuleURL + "/tags"
+	removeCapsuleTag = addCapsuleTags + "/:" + pathTag
+
+	getTagsURL   = apiPrefix + "/tags"
+	renameTagURL = getTagsURL + "/:" + pathTag
+	mergeTagsURL = getTagsURL + "/merge"
+
+	pathCollectionID = "collectionID"
+
+	createCollectionURL = apiPrefix + "/collections"
+	getCollectionsURL
+	getCollectionURL = getCollectionsURL + "/:" + pathCollectionID
+	updateCollectionURL
+	deleteCollectionURL
+
+	addCollectionCapsule = getCollectionURL + "/capsules/:" + pathCapsuleID
+	removeCollectionCapsule
+
+	getNotificationsURL      = apiPrefix + "/notifications"
+	markNotificationsReadURL = getNotificationsURL + "/read"
+
+	eventsURL = apiPrefix + "/events"
+
+	adminURL = apiPrefix + "/admin"
+
+	pathTemplate = "template"
+
+	previewEmailURL = adminURL + "/emails/:" + pathTemplate + "/preview"
+
+	pathOutboxEntryID = "entryID"
+
+	getOutboxURL         = adminURL + "/outbox"
+	replayOutboxEntryURL = getOutboxURL + "/:" + pathOutboxEntryID + "/replay"
+
+	pathUserID = "userID"
+
+	searchUsersURL     = adminURL + "/users"
+	getUserURL         = searchUsersURL + "/:" + pathUserID
+	getUserCapsulesURL = getUserURL + "/capsules"
+	disableUserURL     = getUserURL + "/disable"
+	enableUserURL      = getUserURL + "/enable"
+	updateRoleURL      = getUserURL + "/role"
+
+	getCapsuleMetadataURL  = adminURL + "/capsules/:" + pathCapsuleID
+	resendNotificationsURL = getCapsuleMetadataURL + "/resend"
+
+	getWorkerStatusURL = adminURL + "/worker"
+	getAuditEventsURL  = adminURL + "/audit"
 )
 
 type Handler interface {
@@ -40,15 +135,22 @@ type handler struct {
 	router  *httprouter.Router
 	svc     *service.Service
 	storage storage.Storage
+	cfg     *config.Config
+	limiter *ratelimit.Limiter
+	health  *health.Checker
 }
 
-func NewHandler(svc *service.Service, storage storage.Storage) Handler {
+func NewHandler(cfg *config.Config, svc *service.Service, storage storage.Storage, limiter *ratelimit.Limiter,
+	checker *health.Checker) Handler {
 	router := httprouter.New()
 
 	h := &handler{
 		router:  router,
 		svc:     svc,
 		storage: storage,
+		cfg:     cfg,
+		limiter: limiter,
+		health:  checker,
 	}
 
 	h.initRoutes()
@@ -57,24 +159,119 @@ func NewHandler(svc *service.Service, storage storage.Storage) Handler {
 }
 
 func (h *handler) Router() http.Handler {
-	return h.router
+	return RequestID(AccessLog(h.router))
 }
 
 func (h *handler) initRoutes() {
 	h.router.ServeFiles("/swagger/*filepath", http.Dir("docs"))
+	h.router.Handler(http.MethodGet, metricsURL, metrics.Handler())
+	h.router.GET(healthzURL, h.healthz)
+	h.router.GET(readyzURL, h.readyz)
+
+	h.handle(http.MethodGet, jwksURL, h.RateLimiter(apiRateLimit, h.getJWKS))
+
+	h.handle(http.MethodPost, signUpURL, h.RateLimiter(signUpRateLimit, h.signUp))
+	h.handle(http.MethodPost, signInURL, h.RateLimiter(signInRateLimit, h.signIn))
+	h.handle(http.MethodPost, mfaURL, h.RateLimiter(signInRateLimit, h.verifyMFA))
+	h.handle(http.MethodGet, unlockURL, h.unlockAccountPage)
+	h.handle(http.MethodPost, unlockURL, h.RateLimiter(signInRateLimit, h.unlockAccount))
+	h.handle(http.MethodGet, oidcLoginURL, h.RateLimiter(signInRateLimit, h.oidcLogin))
+	h.handle(http.MethodGet, oidcCallbackURL, h.RateLimiter(signInRateLimit, h.oidcCallback))
+	h.handle(http.MethodGet, verifyEmailURL, h.verifyEmailPage)
+	h.handle(http.MethodPost, verifyEmailURL, h.RateLimiter(signInRateLimit, h.verifyEmail))
+
+	h.handle(http.MethodGet, meURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.getMe))))
+	h.handle(http.MethodPatch, meURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.updateMe))))
+	h.handle(http.MethodDelete, meURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.deleteAccount))))
+	h.handle(http.MethodPost, restoreURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.restoreAccount))))
+
+	h.handle(http.MethodPost, exportURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.requestExport))))
+	h.handle(http.MethodGet, exportsURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.getExports))))
+	h.handle(http.MethodPost, importURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.importCapsules))))
+	h.handle(http.MethodGet, downloadExportURL, h.RateLimiter(apiRateLimit, h.downloadExport))
+
+	h.handle(http.MethodGet, myAuditURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.getMyAuditEvents))))
+
+	h.handle(http.MethodPost, checkInURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.checkIn))))
+	h.handle(http.MethodPut, remindersURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.updateReminders))))
+
+	h.handle(http.MethodPost, enrollTOTPURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.enrollTOTP))))
+	h.handle(http.MethodPost, confirmTOTPURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.confirmTOTP))))
+	h.handle(http.MethodPost, disableTOTPURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.disableTOTP))))
+
+	h.handle(http.MethodPost, createAccessTokenURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.createAccessToken))))
+	h.handle(http.MethodGet, getAccessTokensURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.getAccessTokens))))
+	h.handle(http.MethodDelete, revokeAccessTokenURL, h.Authenticated(h.RequireSession(h.RateLimiter(apiRateLimit, h.revokeAccessToken))))
+
+	h.handle(http.MethodPost, createCapsuleURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.createCapsule))))
+	h.handle(http.MethodGet, getCapsulesURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesRead, h.RateLimiter(apiRateLimit, h.getCapsules))))
+	h.handle(http.MethodGet, getCapsuleURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesRead, h.RateLimiter(apiRateLimit,
+		staticOrParam(pathCapsuleID, searchSegment, h.searchCapsules, h.getCapsuleByID),
+	))))
+	h.handle(http.MethodPatch, updateCapsule, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.updateCapsule))))
+	h.handle(http.MethodDelete, deleteCapsule, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.deleteCapsule))))
+
+	h.handle(http.MethodPost, addCapsuleImage, h.Authenticated(h.RequireScope(domain.ScopeImagesWrite, h.RateLimiter(uploadRateLimit, h.addCapsuleImage))))
+	h.handle(http.MethodGet, getCapsuleImage, h.Authenticated(h.RequireScope(domain.ScopeCapsulesRead, h.RateLimiter(apiRateLimit, h.getCapsuleImage))))
+	h.handle(http.MethodDelete, removeCapsuleImage, h.Authenticated(h.RequireScope(domain.ScopeImagesWrite, h.RateLimiter(apiRateLimit, h.removeCapsuleImage))))
+
+	h.handle(http.MethodPost, addCapsuleTags, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.addCapsuleTags))))
+	h.handle(http.MethodDelete, removeCapsuleTag, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.removeCapsuleTag))))
+
+	h.handle(http.MethodGet, getTagsURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesRead, h.RateLimiter(apiRateLimit, h.getTags))))
+	h.handle(http.MethodPatch, renameTagURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.renameTag))))
+	h.handle(http.MethodPost, mergeTagsURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.mergeTags))))
 
-	h.router.POST(signUpURL, h.RateLimiter(h.signUp))
-	h.router.POST(signInURL, h.RateLimiter(h.signIn))
+	h.handle(http.MethodPost, createCollectionURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.createCollection))))
+	h.handle(http.MethodGet, getCollectionsURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesRead, h.RateLimiter(apiRateLimit, h.getCollections))))
+	h.handle(http.MethodGet, getCollectionURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesRead, h.RateLimiter(apiRateLimit, h.getCollectionByID))))
+	h.handle(http.MethodPatch, updateCollectionURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.updateCollection))))
+	h.handle(http.MethodDelete, deleteCollectionURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.deleteCollection))))
 
-	h.router.POST(createCapsuleURL, h.RateLimiter(h.JWTAuthentication(h.createCapsule)))
-	h.router.GET(getCapsulesURL, h.RateLimiter(h.JWTAuthentication(h.getCapsules)))
-	h.router.GET(getCapsuleURL, h.RateLimiter(h.JWTAuthentication(h.getCapsuleByID)))
-	h.router.PATCH(updateCapsule, h.RateLimiter(h.JWTAuthentication(h.updateCapsule)))
-	h.router.DELETE(deleteCapsule, h.RateLimiter(h.JWTAuthentication(h.deleteCapsule)))
+	h.handle(http.MethodPut, addCollectionCapsule, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.addCollectionCapsule))))
+	h.handle(http.MethodDelete, removeCollectionCapsule, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.removeCollectionCapsule))))
 
-	h.router.POST(addCapsuleImage, h.RateLimiter(h.JWTAuthentication(h.addCapsuleImage)))
-	h.router.GET(getCapsuleImage, h.RateLimiter(h.JWTAuthentication(h.getCapsuleImage)))
-	h.router.DELETE(removeCapsuleImage, h.RateLimiter(h.JWTAuthentication(h.removeCapsuleImage)))
+	h.handle(http.MethodGet, getNotificationsURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesRead, h.RateLimiter(apiRateLimit, h.getNotifications))))
+	h.handle(http.MethodPost, markNotificationsReadURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesWrite, h.RateLimiter(apiRateLimit, h.markNotificationsRead))))
+
+	h.handle(http.MethodGet, eventsURL, h.Authenticated(h.RequireScope(domain.ScopeCapsulesRead, h.RateLimiter(eventsRateLimit, h.streamEvents))))
+
+	h.handle(http.MethodGet, previewEmailURL, h.StaffAuthentication(domain.RoleSupport, h.previewEmail))
+	h.handle(http.MethodGet, getOutboxURL, h.StaffAuthentication(domain.RoleSupport, h.getOutbox))
+	h.handle(http.MethodPost, replayOutboxEntryURL, h.StaffAuthentication(domain.RoleSupport, h.replayOutboxEntry))
+
+	h.handle(http.MethodGet, searchUsersURL, h.StaffAuthentication(domain.RoleSupport, h.searchUsers))
+	h.handle(http.MethodGet, getUserURL, h.StaffAuthentication(domain.RoleSupport, h.getUser))
+	h.handle(http.MethodGet, getUserCapsulesURL, h.StaffAuthentication(domain.RoleSupport, h.getUserCapsules))
+	h.handle(http.MethodPost, disableUserURL, h.StaffAuthentication(domain.RoleAdmin, h.disableUser))
+	h.handle(http.MethodPost, enableUserURL, h.StaffAuthentication(domain.RoleAdmin, h.enableUser))
+	h.handle(http.MethodPut, updateRoleURL, h.StaffAuthentication(domain.RoleAdmin, h.updateRole))
+
+	h.handle(http.MethodGet, getCapsuleMetadataURL, h.StaffAuthentication(domain.RoleSupport, h.getCapsuleMetadata))
+	h.handle(http.MethodPost, resendNotificationsURL, h.StaffAuthentication(domain.RoleSupport, h.resendNotifications))
+
+	h.handle(http.MethodGet, getWorkerStatusURL, h.StaffAuthentication(domain.RoleSupport, h.getWorkerStatus))
+
+	h.handle(http.MethodGet, getAuditEventsURL, h.StaffAuthentication(domain.RoleAdmin, h.getAuditEvents))
+}
+
+// handle registers the handle of the route, tracing its requests and recording them in the metrics.
+func (h *handler) handle(method, path string, handle httprouter.Handle) {
+	h.router.Handle(method, path, Tracing(path, Metrics(path, handle)))
+}
+
+// staticOrParam serves the static handle when the named parameter equals segment and
+// the param handle otherwise. httprouter can't register a static segment
+// (e.g. /capsules/search) next to a named parameter (e.g. /capsules/:capsuleID).
+func staticOrParam(name, segment string, static, param httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		if params.ByName(name) == segment {
+			static(w, r, params)
+			return
+		}
+
+		param(w, r, params)
+	}
 }
 
 func parseObjectIDFromParam(params httprouter.Params, name string) (primitive.ObjectID, error) {
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"time-capsule/internal/health"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// Healthz | Checks That The Service Is Alive
+//
+//	@Summary      Healthz
+//	@Description  Liveness probe, succeeds as long as the service serves requests. Dependencies aren't checked
+//	@Tags         Health
+//	@Produce      json
+//	@Success      200   {object}  health.Report
+//	@Router       /healthz [get]
+func (h *handler) healthz(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	newJSONResponse(w, health.Report{Status: health.StatusUp})
+	return
+}
+
+// Readyz | Checks That The Service Is Ready
+//
+//	@Summary      Readyz
+//	@Description  Readiness probe, checks MongoDB, the MinIO bucket, the worker's heartbeat and, optionally, the SMTP server. Fails while the service shuts down
+//	@Tags         Health
+//	@Produce      json
+//	@Success      200   {object}  health.Report
+//	@Failure      503   {object}  health.Report
+//	@Router       /readyz [get]
+func (h *handler) readyz(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	report := h.health.Ready(r.Context())
+
+	if report.Status != health.StatusReady {
+		newJSONResponse(w, report, http.StatusServiceUnavailable)
+		return
+	}
+
+	newJSONResponse(w, report)
+	return
+}
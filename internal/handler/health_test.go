@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"time-capsule/internal/health"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler_healthz(t *testing.T) {
+	var (
+		router = httprouter.New()
+		hndlr  = handler{router: router}
+	)
+
+	router.GET(healthzURL, hndlr.healthz)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, healthzURL, nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"status":"up"}`, w.Body.String())
+}
+
+func TestHealthHandler_readyz(t *testing.T) {
+	tests := []struct {
+		name                 string
+		setup                func(c *health.Checker)
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Ready",
+			setup: func(c *health.Checker) {
+				c.Add("mongodb", func(context.Context) error { return nil })
+				c.AddOptional("smtp", func(context.Context) error { return errors.New("some error") })
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"status":"ready","checks":{"mongodb":{"status":"up"},"smtp":{"status":"down","optional":true}}}`,
+		},
+		{
+			name: "Not-Ready",
+			setup: func(c *health.Checker) {
+				c.Add("mongodb", func(context.Context) error { return errors.New("some error") })
+			},
+			expectedStatusCode:   http.StatusServiceUnavailable,
+			expectedResponseBody: `{"status":"not_ready","checks":{"mongodb":{"status":"down"}}}`,
+		},
+		{
+			name: "Shutting-Down",
+			setup: func(c *health.Checker) {
+				c.Add("mongodb", func(context.Context) error { return nil })
+				c.Shutdown()
+			},
+			expectedStatusCode:   http.StatusServiceUnavailable,
+			expectedResponseBody: `{"status":"shutting_down"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				checker = health.NewChecker()
+				router  = httprouter.New()
+				hndlr   = handler{router: router, health: checker}
+			)
+
+			test.setup(checker)
+
+			router.GET(readyzURL, hndlr.readyz)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, readyzURL, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -2,7 +2,7 @@ package handler
 
 import (
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"time-capsule/internal/domain"
@@ -11,8 +11,6 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-const maxUploadSize = 5 << 20 // 5 megabytes
-
 var fileTypes = map[string]interface{}{
 	"image/jpeg": nil,
 	"image/png":  nil,
@@ -52,14 +50,14 @@ func (h *handler) removeCapsuleImage(w http.ResponseWriter, r *http.Request, par
 		return
 	}
 
-	if err = h.svc.RemoveImage(r.Context(), userID, capsuleID, imageID.Hex()); err != nil {
-		log.Println(err)
+	if err = h.svc.RemoveImage(h.withClient(r), userID, capsuleID, imageID.Hex()); err != nil {
+		slog.ErrorContext(r.Context(), "removeCapsuleImage", "error", err)
 		newErrorResponse(w, err)
 		return
 	}
 
 	if err = h.storage.Delete(r.Context(), imageID.Hex()); err != nil {
-		log.Println(err)
+		slog.ErrorContext(r.Context(), "removeCapsuleImage", "error", err)
 		newErrorResponse(w, errors.New("internal server error"), http.StatusInternalServerError)
 		return
 	}
@@ -109,7 +107,7 @@ func (h *handler) getCapsuleImage(w http.ResponseWriter, r *http.Request, params
 
 	file, err := h.storage.Get(r.Context(), imageID.Hex())
 	if err != nil {
-		log.Println(err)
+		slog.ErrorContext(r.Context(), "getCapsuleImage", "error", err)
 		newErrorResponse(w, errors.New("not found"), http.StatusNotFound)
 		return
 	}
@@ -150,11 +148,11 @@ func (h *handler) addCapsuleImage(w http.ResponseWriter, r *http.Request, params
 		return
 	}
 
-	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MaxUploadSize)
 
 	file, header, err := r.FormFile("image")
 	if err != nil {
-		log.Println(err)
+		slog.DebugContext(r.Context(), "addCapsuleImage", "error", err)
 		newErrorResponse(w, errors.New("unable to parse the form"), http.StatusBadRequest)
 		return
 	}
@@ -162,7 +160,7 @@ func (h *handler) addCapsuleImage(w http.ResponseWriter, r *http.Request, params
 
 	fileBytes := make([]byte, header.Size)
 	if _, err = file.Read(fileBytes); err != nil {
-		log.Println("addCapsuleImage", err)
+		slog.ErrorContext(r.Context(), "addCapsuleImage", "error", err)
 		newErrorResponse(w, errors.New("failed to read the uploaded file"), http.StatusBadRequest)
 		return
 	}
@@ -180,13 +178,13 @@ func (h *handler) addCapsuleImage(w http.ResponseWriter, r *http.Request, params
 	}
 
 	if err = h.storage.Upload(r.Context(), input); err != nil {
-		log.Println(err)
+		slog.ErrorContext(r.Context(), "addCapsuleImage", "error", err)
 		newErrorResponse(w, errors.New("internal server error"))
 		return
 	}
 
-	if err = h.svc.AddImage(r.Context(), userID, capsuleID, input.Name); err != nil {
-		log.Println(err)
+	if err = h.svc.AddImage(h.withClient(r), userID, capsuleID, input.Name); err != nil {
+		slog.ErrorContext(r.Context(), "addCapsuleImage", "error", err)
 		newErrorResponse(w, errors.New("internal server error"))
 		return
 	}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"testing"
 
+	"time-capsule/config"
 	"time-capsule/internal/domain"
 	"time-capsule/internal/service"
 	mock_service "time-capsule/internal/service/mocks"
@@ -45,7 +46,7 @@ func TestImageHandler_removeCapsuleImage(t *testing.T) {
 		{
 			name: "OK",
 			serviceMockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID, imageID string) {
-				s.EXPECT().RemoveImage(ctx, userID, capsuleID, imageID).Return(nil).Times(1)
+				s.EXPECT().RemoveImage(gomock.Any(), userID, capsuleID, imageID).Return(nil).Times(1)
 			},
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, fileName string) {
 				s.EXPECT().Delete(ctx, fileName).Return(nil).Times(1)
@@ -100,7 +101,7 @@ func TestImageHandler_removeCapsuleImage(t *testing.T) {
 		{
 			name: "Service-Failure",
 			serviceMockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID, imageID string) {
-				s.EXPECT().RemoveImage(ctx, userID, capsuleID, imageID).Return(errors.New("some error")).Times(1)
+				s.EXPECT().RemoveImage(gomock.Any(), userID, capsuleID, imageID).Return(errors.New("some error")).Times(1)
 			},
 			storageMockBehavior:  func(s *mock_storage.MockStorage, ctx context.Context, fileName string) {},
 			ctxUserID:            primitive.NilObjectID.Hex(),
@@ -114,7 +115,7 @@ func TestImageHandler_removeCapsuleImage(t *testing.T) {
 		{
 			name: "Storage-Failure",
 			serviceMockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID, imageID string) {
-				s.EXPECT().RemoveImage(ctx, userID, capsuleID, imageID).Return(nil).Times(1)
+				s.EXPECT().RemoveImage(gomock.Any(), userID, capsuleID, imageID).Return(nil).Times(1)
 			},
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, fileName string) {
 				s.EXPECT().Delete(ctx, fileName).Return(errors.New("some error")).Times(1)
@@ -149,6 +150,7 @@ func TestImageHandler_removeCapsuleImage(t *testing.T) {
 					router:  router,
 					svc:     svc,
 					storage: strge,
+					cfg:     &config.Config{},
 				}
 			)
 
@@ -304,6 +306,7 @@ func TestImageHandler_getCapsuleImage(t *testing.T) {
 					router:  router,
 					svc:     svc,
 					storage: strge,
+					cfg:     &config.Config{},
 				}
 			)
 
@@ -353,7 +356,7 @@ func TestImageHandler_addCapsuleImage(t *testing.T) {
 		{
 			name: "OK-PNG",
 			serviceMockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID, image string) {
-				s.EXPECT().AddImage(ctx, userID, capsuleID, image).Return(nil).Times(1)
+				s.EXPECT().AddImage(gomock.Any(), userID, capsuleID, image).Return(nil).Times(1)
 			},
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, file domain.File) {
 				s.EXPECT().Upload(ctx, file).Return(nil).Times(1)
@@ -368,7 +371,7 @@ func TestImageHandler_addCapsuleImage(t *testing.T) {
 		{
 			name: "OK-JPEG",
 			serviceMockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID, image string) {
-				s.EXPECT().AddImage(ctx, userID, capsuleID, image).Return(nil).Times(1)
+				s.EXPECT().AddImage(gomock.Any(), userID, capsuleID, image).Return(nil).Times(1)
 			},
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, file domain.File) {
 				s.EXPECT().Upload(ctx, file).Return(nil).Times(1)
@@ -457,7 +460,7 @@ func TestImageHandler_addCapsuleImage(t *testing.T) {
 		{
 			name: "Service-Failure",
 			serviceMockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID, image string) {
-				s.EXPECT().AddImage(ctx, userID, capsuleID, image).Return(errors.New("some error")).Times(1)
+				s.EXPECT().AddImage(gomock.Any(), userID, capsuleID, image).Return(errors.New("some error")).Times(1)
 			},
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, file domain.File) {
 				s.EXPECT().Upload(ctx, file).Return(nil).Times(1)
@@ -507,6 +510,7 @@ func TestImageHandler_addCapsuleImage(t *testing.T) {
 					router:  router,
 					svc:     svc,
 					storage: strge,
+					cfg:     &config.Config{MaxUploadSize: 5 << 20},
 				}
 			)
 
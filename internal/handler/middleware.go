@@ -3,28 +3,80 @@ package handler
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"time-capsule/internal/logging"
+	"time-capsule/internal/metrics"
+	"time-capsule/internal/ratelimit"
+	"time-capsule/internal/service"
+	"time-capsule/internal/tracing"
+
 	"github.com/julienschmidt/httprouter"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/time/rate"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	userCtx = "userID"
+	userCtx   = "userID"
+	scopesCtx = "scopes"
+	roleCtx   = "role"
+
+	requestIDHeader = "X-Request-ID"
 
-	requestRateTimeout = 1 * time.Second
-	requestRateLimit   = 20
+	maxRequestIDLength = 64
 )
 
-var limiter = rate.NewLimiter(rate.Every(requestRateTimeout), requestRateLimit)
+// Rate limit policies. Unauthenticated routes are limited per client IP, authenticated ones per user.
+// Authenticated routes are also limited per client IP before authentication, by clientRateLimit, so that requests
+// with invalid tokens are limited as well. It allows more requests than apiRateLimit, clients can share an IP.
+var (
+	clientRateLimit = ratelimit.Policy{Name: "client", Limit: 600, Window: time.Minute}
+	signUpRateLimit = ratelimit.Policy{Name: "sign-up", Limit: 10, Window: time.Hour}
+	signInRateLimit = ratelimit.Policy{Name: "sign-in", Limit: 10, Window: time.Minute}
+	apiRateLimit    = ratelimit.Policy{Name: "api", Limit: 120, Window: time.Minute}
+	uploadRateLimit = ratelimit.Policy{Name: "upload", Limit: 30, Window: time.Minute}
+	eventsRateLimit = ratelimit.Policy{Name: "events", Limit: 10, Window: time.Minute}
+	adminRateLimit  = ratelimit.Policy{Name: "admin", Limit: 60, Window: time.Minute}
+)
 
-func (h *handler) RateLimiter(next httprouter.Handle) httprouter.Handle {
+// RateLimiter limits the requests under the policy, per user for authenticated requests and per client IP otherwise,
+// so it goes after JWTAuthentication to limit users. Requests are let through if the limiter's store fails.
+func (h *handler) RateLimiter(policy ratelimit.Policy, next httprouter.Handle) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-		if !limiter.Allow() {
+		key := ratelimit.IPKey(h.clientIP(r))
+		if userID, ok := r.Context().Value(userCtx).(string); ok && userID != "" {
+			key = ratelimit.UserKey(userID)
+		}
+
+		res, err := h.limiter.Allow(r.Context(), policy, key)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "RateLimiter", "error", err)
+			next(w, r, params)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(res.Reset.Unix(), 10))
+
+		if !res.Allowed {
+			metrics.RateLimitRejections.WithLabelValues(policy.Name).Inc()
+
+			retryAfter := math.Ceil(res.RetryAfter(time.Now()).Seconds())
+			w.Header().Set("Retry-After", strconv.Itoa(max(int(retryAfter), 1)))
+
 			newErrorResponse(w, errors.New("rate limit exceeded"), http.StatusTooManyRequests)
 			return
 		}
@@ -33,6 +85,40 @@ func (h *handler) RateLimiter(next httprouter.Handle) httprouter.Handle {
 	}
 }
 
+// clientIP returns the IP of the client. The X-Forwarded-For and X-Real-IP headers are only trusted
+// when the service is configured to run behind a proxy, otherwise clients could pick their own key.
+// Only the rightmost X-Forwarded-For entry is taken, the one appended by the proxy: the entries before it
+// are whatever the client sent.
+func (h *handler) clientIP(r *http.Request) string {
+	if h.cfg.RateLimitTrustProxy {
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			last := forwarded[len(forwarded)-1]
+			if i := strings.LastIndex(last, ","); i >= 0 {
+				last = last[i+1:]
+			}
+
+			return strings.TrimSpace(last)
+		}
+
+		if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// Authenticated limits the requests per client IP, then authenticates them with JWTAuthentication.
+// Every authenticated route goes through it, so that a client can't have unlimited tokens checked.
+func (h *handler) Authenticated(next httprouter.Handle) httprouter.Handle {
+	return h.RateLimiter(clientRateLimit, h.JWTAuthentication(next))
+}
+
 func (h *handler) JWTAuthentication(next httprouter.Handle) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 		header := r.Header.Get("Authorization")
@@ -53,6 +139,27 @@ func (h *handler) JWTAuthentication(next httprouter.Handle) httprouter.Handle {
 			return
 		}
 
+		if strings.HasPrefix(headerParts[1], service.AccessTokenPrefix) {
+			token, err := h.svc.AuthenticateAccessToken(r.Context(), headerParts[1])
+			if err != nil {
+				newErrorResponse(w, err)
+				return
+			}
+
+			ctx, err := h.withUser(r, token.UserID)
+			if err != nil {
+				newErrorResponse(w, err)
+				return
+			}
+
+			if err = h.svc.RecordActivity(r.Context(), token.UserID); err != nil {
+				slog.ErrorContext(r.Context(), "RecordActivity", "error", err)
+			}
+
+			next(w, r.WithContext(context.WithValue(ctx, scopesCtx, token.Scopes)), params)
+			return
+		}
+
 		claims, err := h.svc.ParseToken(headerParts[1])
 		if err != nil {
 			newErrorResponse(w, err)
@@ -65,25 +172,230 @@ func (h *handler) JWTAuthentication(next httprouter.Handle) httprouter.Handle {
 			return
 		}
 
-		next(w, r.WithContext(context.WithValue(r.Context(), userCtx, userID)), params)
+		oid, err := primitive.ObjectIDFromHex(userID)
+		if err != nil {
+			newErrorResponse(w, errors.New("invalid token"), http.StatusUnauthorized)
+			return
+		}
+
+		ctx, err := h.withUser(r, oid)
+		if err != nil {
+			newErrorResponse(w, err)
+			return
+		}
+
+		if err = h.svc.RecordActivity(r.Context(), oid); err != nil {
+			slog.ErrorContext(r.Context(), "RecordActivity", "error", err)
+		}
+
+		next(w, r.WithContext(ctx), params)
+	}
+}
+
+// withUser returns the context of the request carrying the user the token was issued to, and their role.
+// Disabled and deleted accounts are rejected.
+func (h *handler) withUser(r *http.Request, userID primitive.ObjectID) (context.Context, error) {
+	user, err := h.svc.AuthenticateUser(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.WithValue(r.Context(), userCtx, userID.Hex())
+
+	return context.WithValue(ctx, roleCtx, user.Role), nil
+}
+
+// RequireScope lets through requests authenticated with a personal access token only if it has the scope.
+// Signed-in sessions aren't limited by scopes. It goes after JWTAuthentication.
+func (h *handler) RequireScope(scope string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		if scopes, ok := r.Context().Value(scopesCtx).([]string); ok && !slices.Contains(scopes, scope) {
+			newErrorResponse(w, fmt.Errorf("token is missing the %s scope", scope), http.StatusForbidden)
+			return
+		}
+
+		next(w, r, params)
+	}
+}
+
+// RequireSession rejects requests authenticated with a personal access token, for the routes managing
+// the account itself. It goes after JWTAuthentication.
+func (h *handler) RequireSession(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		if _, ok := r.Context().Value(scopesCtx).([]string); ok {
+			newErrorResponse(w, errors.New("personal access tokens can't be used here"), http.StatusForbidden)
+			return
+		}
+
+		next(w, r, params)
+	}
+}
+
+// RequireRole lets through requests of users with the role, admins have every role. It goes after JWTAuthentication.
+func (h *handler) RequireRole(role string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		userRole, _ := r.Context().Value(roleCtx).(string)
+
+		if !service.HasRole(userRole, role) {
+			newErrorResponse(w, errors.New("forbidden"), http.StatusForbidden)
+			return
+		}
+
+		next(w, r, params)
+	}
+}
+
+// StaffAuthentication lets through signed-in staff with the role. Staff can't use personal access tokens.
+func (h *handler) StaffAuthentication(role string, next httprouter.Handle) httprouter.Handle {
+	return h.Authenticated(h.RequireSession(h.RequireRole(role, h.RateLimiter(adminRateLimit, next))))
+}
+
+// RequestID tags every request with an ID, echoed in the X-Request-ID response header. It's carried in the context
+// through the service calls, so that it's in the logs and audit events of the request.
+// The ID of the incoming header is kept, e.g. when it's set by a proxy, if it's short and printable.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = primitive.NewObjectID().Hex()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// AccessLog logs every request once it's served, probes only at debug. The query isn't logged, it can hold tokens.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			start = time.Now()
+			rec   = &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		)
+
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		switch {
+		case r.URL.Path == healthzURL || r.URL.Path == readyzURL:
+			// Probes come every few seconds, and readiness reports its failing dependencies itself.
+			level = slog.LevelDebug
+		case rec.status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		}
+
+		slog.Log(r.Context(), level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration", time.Since(start),
+		)
+	})
+}
+
+// Metrics records the requests of the route, the path pattern the handle is registered with,
+// so requests to /capsules/:capsuleID are counted together whatever the ID.
+func Metrics(route string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		var (
+			start = time.Now()
+			rec   = &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		)
+
+		next(rec, r, params)
+
+		metrics.ObserveRequest(r.Method, route, rec.status, time.Since(start))
+	}
+}
+
+// Tracing starts the span of the request to the route, continuing the trace of the W3C traceparent header if any.
+func Tracing(route string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		ctx, span := tracing.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethod(r.Method),
+				semconv.HTTPRoute(route),
+			),
+		)
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next(rec, r.WithContext(ctx), params)
+
+		span.SetAttributes(semconv.HTTPStatusCode(rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	}
+}
+
+// statusRecorder records the status of the response. Unwrap lets http.ResponseController reach the
+// underlying writer, e.g. to flush event streams.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
 	}
+
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// withClient returns the context of the request carrying its client, which audit events are attributed to.
+func (h *handler) withClient(r *http.Request) context.Context {
+	return service.WithClient(r.Context(), service.Client{
+		IP:        h.clientIP(r),
+		UserAgent: r.UserAgent(),
+		ActorID:   getActorID(r),
+	})
+}
+
+// getActorID returns the ID of the signed-in user making the request, or nil before signing in.
+func getActorID(r *http.Request) primitive.ObjectID {
+	id, _ := r.Context().Value(userCtx).(string)
+	oid, _ := primitive.ObjectIDFromHex(id)
+
+	return oid
 }
 
 func getUserID(r *http.Request) (primitive.ObjectID, error) {
 	id, ok := r.Context().Value(userCtx).(string)
 	if !ok {
-		log.Println("getUserID", "failed to convert", r.Context().Value(userCtx))
+		slog.ErrorContext(r.Context(), "getUserID", "error", "no user ID in context")
 		return primitive.NilObjectID, errors.New("internal server error")
 	}
 
 	if id == "" {
-		log.Println("getUserID", "empty userId in context")
+		slog.ErrorContext(r.Context(), "getUserID", "error", "empty user ID in context")
 		return primitive.NilObjectID, errors.New("internal server error")
 	}
 
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Println("getUserID", err)
+		slog.ErrorContext(r.Context(), "getUserID", "error", err)
 		return primitive.NilObjectID, errors.New("internal server error")
 	}
 
@@ -1,42 +1,124 @@
 package handler
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/logging"
+	"time-capsule/internal/metrics"
+	"time-capsule/internal/ratelimit"
 	"time-capsule/internal/service"
 	mock_service "time-capsule/internal/service/mocks"
+	"time-capsule/internal/tracing"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/mock/gomock"
 )
 
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Hit(context.Context, string, time.Time) (int, error) {
+	return 0, errors.New("some error")
+}
+
+func (failingRateLimitStore) DeletePrefix(context.Context, string) error {
+	return errors.New("some error")
+}
+
 func TestMiddlewareHandler_RateLimiter(t *testing.T) {
+	policy := ratelimit.Policy{Name: "test", Limit: 3, Window: time.Hour}
+
+	type request struct {
+		remoteAddr    string
+		forwardedFor  string
+		userID        string
+		expectedCode  int
+		expectedLimit string
+		expectedLeft  string
+	}
+
 	tests := []struct {
-		name                 string
-		requests             int
-		expectedStatusCode   int
-		expectedResponseBody string
+		name       string
+		store      ratelimit.Store
+		trustProxy bool
+		requests   []request
 	}{
 		{
-			name:                 "OK",
-			requests:             requestRateLimit - 1,
-			expectedStatusCode:   http.StatusOK,
-			expectedResponseBody: "ok",
+			name:  "Per-IP",
+			store: ratelimit.NewMemoryStore(),
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "2"},
+				{remoteAddr: "10.0.0.1:1001", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "1"},
+				{remoteAddr: "10.0.0.1:1002", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "0"},
+				{remoteAddr: "10.0.0.1:1003", expectedCode: http.StatusTooManyRequests, expectedLimit: "3", expectedLeft: "0"},
+				{remoteAddr: "10.0.0.2:1000", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "2"},
+			},
 		},
 		{
-			name:                 "Rate Limit",
-			requests:             requestRateLimit + 1,
-			expectedStatusCode:   http.StatusTooManyRequests,
-			expectedResponseBody: `{"message":"rate limit exceeded"}`,
+			name:  "Per-User",
+			store: ratelimit.NewMemoryStore(),
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", userID: "alice", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "2"},
+				{remoteAddr: "10.0.0.2:1000", userID: "alice", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "1"},
+				{remoteAddr: "10.0.0.3:1000", userID: "alice", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "0"},
+				{remoteAddr: "10.0.0.4:1000", userID: "alice", expectedCode: http.StatusTooManyRequests, expectedLimit: "3", expectedLeft: "0"},
+				{remoteAddr: "10.0.0.4:1000", userID: "bob", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "2"},
+			},
+		},
+		{
+			name:  "Untrusted-Forwarded-For",
+			store: ratelimit.NewMemoryStore(),
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", forwardedFor: "1.1.1.1", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "2"},
+				{remoteAddr: "10.0.0.1:1000", forwardedFor: "2.2.2.2", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "1"},
+			},
+		},
+		{
+			name:       "Trusted-Forwarded-For",
+			store:      ratelimit.NewMemoryStore(),
+			trustProxy: true,
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", forwardedFor: "1.1.1.1", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "2"},
+				{remoteAddr: "10.0.0.1:1000", forwardedFor: "2.2.2.2", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "2"},
+			},
+		},
+		{
+			name:       "Spoofed-Forwarded-For",
+			store:      ratelimit.NewMemoryStore(),
+			trustProxy: true,
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", forwardedFor: "3.3.3.3, 1.1.1.1", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "2"},
+				{remoteAddr: "10.0.0.1:1000", forwardedFor: "4.4.4.4,1.1.1.1", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "1"},
+				{remoteAddr: "10.0.0.1:1000", forwardedFor: "1.1.1.1", expectedCode: http.StatusOK, expectedLimit: "3", expectedLeft: "0"},
+			},
+		},
+		{
+			name:  "Store-Failure",
+			store: failingRateLimitStore{},
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1000", expectedCode: http.StatusOK},
+			},
 		},
 	}
 
@@ -49,31 +131,57 @@ func TestMiddlewareHandler_RateLimiter(t *testing.T) {
 					router:  router,
 					svc:     nil,
 					storage: nil,
+					cfg:     &config.Config{RateLimitTrustProxy: test.trustProxy},
+					limiter: ratelimit.NewLimiter(test.store),
 				}
 			)
 
-			router.GET("/", hndlr.RateLimiter(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+			router.GET("/", hndlr.RateLimiter(policy, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 				w.WriteHeader(http.StatusOK)
 				fmt.Fprint(w, "ok")
 			}))
 
-			var w *httptest.ResponseRecorder
-			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, r := range test.requests {
+				w := httptest.NewRecorder()
 
-			for i := 0; i < test.requests; i++ {
-				w = httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = r.remoteAddr
+				if r.forwardedFor != "" {
+					req.Header.Set("X-Forwarded-For", r.forwardedFor)
+				}
+				if r.userID != "" {
+					req = req.WithContext(context.WithValue(req.Context(), userCtx, r.userID))
+				}
 
 				router.ServeHTTP(w, req)
-			}
 
-			assert.Equal(t, test.expectedStatusCode, w.Code)
-			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+				assert.Equal(t, r.expectedCode, w.Code)
+				assert.Equal(t, r.expectedLimit, w.Header().Get("X-RateLimit-Limit"))
+				assert.Equal(t, r.expectedLeft, w.Header().Get("X-RateLimit-Remaining"))
+
+				if r.expectedCode != http.StatusTooManyRequests {
+					assert.Equal(t, "ok", w.Body.String())
+					assert.Empty(t, w.Header().Get("Retry-After"))
+					continue
+				}
+
+				assert.Equal(t, `{"message":"rate limit exceeded"}`, w.Body.String())
+
+				reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+				assert.NoError(t, err)
+				assert.Greater(t, reset, time.Now().Unix())
+
+				retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+				assert.NoError(t, err)
+				assert.Greater(t, retryAfter, 0)
+				assert.LessOrEqual(t, retryAfter, int(policy.Window.Seconds()))
+			}
 		})
 	}
 }
 
 func TestMiddlewareHandler_JWTAuthentication(t *testing.T) {
-	type mockBehavior func(s *mock_service.MockUserService, token string)
+	type mockBehavior func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string)
 
 	tests := []struct {
 		name                 string
@@ -86,11 +194,14 @@ func TestMiddlewareHandler_JWTAuthentication(t *testing.T) {
 	}{
 		{
 			name: "OK",
-			mockBehavior: func(s *mock_service.MockUserService, token string) {
+			mockBehavior: func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {
 				s.EXPECT().ParseToken(token).Return(jwt.MapClaims{
 					"userID": primitive.NilObjectID.Hex(),
 					"exp":    time.Now().Add(1 * time.Hour).Unix(),
 				}, nil).Times(1)
+
+				s.EXPECT().AuthenticateUser(gomock.Any(), primitive.NilObjectID).Return(&domain.User{}, nil).Times(1)
+				s.EXPECT().RecordActivity(gomock.Any(), primitive.NilObjectID).Return(nil).Times(1)
 			},
 			headerName:           "Authorization",
 			headerValue:          "Bearer token",
@@ -100,7 +211,7 @@ func TestMiddlewareHandler_JWTAuthentication(t *testing.T) {
 		},
 		{
 			name:                 "Empty-Auth-Header",
-			mockBehavior:         func(s *mock_service.MockUserService, token string) {},
+			mockBehavior:         func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {},
 			headerName:           "",
 			headerValue:          "",
 			expectedStatusCode:   http.StatusUnauthorized,
@@ -108,7 +219,7 @@ func TestMiddlewareHandler_JWTAuthentication(t *testing.T) {
 		},
 		{
 			name:                 "Invalid-Auth-Header",
-			mockBehavior:         func(s *mock_service.MockUserService, token string) {},
+			mockBehavior:         func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {},
 			headerName:           "Authorization",
 			headerValue:          "Bumblebee token",
 			expectedStatusCode:   http.StatusUnauthorized,
@@ -116,7 +227,7 @@ func TestMiddlewareHandler_JWTAuthentication(t *testing.T) {
 		},
 		{
 			name:                 "Empty-Token",
-			mockBehavior:         func(s *mock_service.MockUserService, token string) {},
+			mockBehavior:         func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {},
 			headerName:           "Authorization",
 			headerValue:          "Bearer ",
 			expectedStatusCode:   http.StatusUnauthorized,
@@ -124,7 +235,7 @@ func TestMiddlewareHandler_JWTAuthentication(t *testing.T) {
 		},
 		{
 			name: "Service-Failure",
-			mockBehavior: func(s *mock_service.MockUserService, token string) {
+			mockBehavior: func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {
 				s.EXPECT().ParseToken(token).Return(nil, errors.New("some error")).Times(1)
 			},
 			headerName:           "Authorization",
@@ -133,9 +244,99 @@ func TestMiddlewareHandler_JWTAuthentication(t *testing.T) {
 			expectedStatusCode:   http.StatusInternalServerError,
 			expectedResponseBody: `{"message":"some error"}`,
 		},
+		{
+			name: "Activity-Failure-Ignored",
+			mockBehavior: func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {
+				s.EXPECT().ParseToken(token).Return(jwt.MapClaims{
+					"userID": primitive.NilObjectID.Hex(),
+					"exp":    time.Now().Add(1 * time.Hour).Unix(),
+				}, nil).Times(1)
+
+				s.EXPECT().AuthenticateUser(gomock.Any(), primitive.NilObjectID).Return(&domain.User{}, nil).Times(1)
+				s.EXPECT().RecordActivity(gomock.Any(), primitive.NilObjectID).Return(errors.New("some error")).Times(1)
+			},
+			headerName:           "Authorization",
+			headerValue:          "Bearer token",
+			token:                "token",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: primitive.NilObjectID.Hex(),
+		},
+		{
+			name: "OK-Access-Token",
+			mockBehavior: func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {
+				a.EXPECT().AuthenticateAccessToken(gomock.Any(), token).Return(&domain.AccessToken{
+					UserID: primitive.NilObjectID,
+					Scopes: []string{domain.ScopeCapsulesRead},
+				}, nil).Times(1)
+				s.EXPECT().AuthenticateUser(gomock.Any(), primitive.NilObjectID).Return(&domain.User{}, nil).Times(1)
+				// Requests of scripts count as activity of the user too.
+				s.EXPECT().RecordActivity(gomock.Any(), primitive.NilObjectID).Return(nil).Times(1)
+			},
+			headerName:           "Authorization",
+			headerValue:          "Bearer tc_pat_token",
+			token:                "tc_pat_token",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: primitive.NilObjectID.Hex(),
+		},
+		{
+			name: "Expired-Access-Token",
+			mockBehavior: func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {
+				a.EXPECT().AuthenticateAccessToken(gomock.Any(), token).Return(nil, service.ErrTokenExpired).Times(1)
+			},
+			headerName:           "Authorization",
+			headerValue:          "Bearer tc_pat_token",
+			token:                "tc_pat_token",
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"` + service.ErrTokenExpired.Error() + `"}`,
+		},
+		{
+			name: "Disabled-Account",
+			mockBehavior: func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {
+				s.EXPECT().ParseToken(token).Return(jwt.MapClaims{
+					"userID": primitive.NilObjectID.Hex(),
+					"exp":    time.Now().Add(1 * time.Hour).Unix(),
+				}, nil).Times(1)
+
+				s.EXPECT().AuthenticateUser(gomock.Any(), primitive.NilObjectID).Return(nil, service.ErrAccountDisabled).Times(1)
+			},
+			headerName:           "Authorization",
+			headerValue:          "Bearer token",
+			token:                "token",
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"message":"the account is disabled"}`,
+		},
+		{
+			name: "Disabled-Account-Access-Token",
+			mockBehavior: func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {
+				a.EXPECT().AuthenticateAccessToken(gomock.Any(), token).Return(&domain.AccessToken{
+					UserID: primitive.NilObjectID,
+					Scopes: []string{domain.ScopeCapsulesRead},
+				}, nil).Times(1)
+				s.EXPECT().AuthenticateUser(gomock.Any(), primitive.NilObjectID).Return(nil, service.ErrAccountDisabled).Times(1)
+			},
+			headerName:           "Authorization",
+			headerValue:          "Bearer tc_pat_token",
+			token:                "tc_pat_token",
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"message":"the account is disabled"}`,
+		},
+		{
+			name: "Invalid-UserID-In-Claims",
+			mockBehavior: func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {
+				s.EXPECT().ParseToken(token).Return(jwt.MapClaims{
+					"userID": "foo",
+					"exp":    time.Now().Add(1 * time.Hour).Unix(),
+				}, nil).Times(1)
+			},
+			headerName:           "Authorization",
+			headerValue:          "Bearer token",
+			token:                "token",
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"invalid token"}`,
+		},
 		{
 			name: "No-UserID-In-Claims",
-			mockBehavior: func(s *mock_service.MockUserService, token string) {
+			mockBehavior: func(s *mock_service.MockUserService, a *mock_service.MockAccessTokenService, token string) {
 				s.EXPECT().ParseToken(token).Return(jwt.MapClaims{
 					"exp": time.Now().Add(1 * time.Hour).Unix(),
 				}, nil).Times(1)
@@ -154,9 +355,11 @@ func TestMiddlewareHandler_JWTAuthentication(t *testing.T) {
 			defer c.Finish()
 
 			var (
-				userSvc = mock_service.NewMockUserService(c)
-				svc     = &service.Service{
-					UserService: userSvc,
+				userSvc  = mock_service.NewMockUserService(c)
+				tokenSvc = mock_service.NewMockAccessTokenService(c)
+				svc      = &service.Service{
+					UserService:        userSvc,
+					AccessTokenService: tokenSvc,
 				}
 				router = httprouter.New()
 
@@ -167,7 +370,7 @@ func TestMiddlewareHandler_JWTAuthentication(t *testing.T) {
 				}
 			)
 
-			test.mockBehavior(userSvc, test.token)
+			test.mockBehavior(userSvc, tokenSvc, test.token)
 
 			router.GET("/", hndlr.JWTAuthentication(func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 				oid, _ := getUserID(r)
@@ -186,6 +389,143 @@ func TestMiddlewareHandler_JWTAuthentication(t *testing.T) {
 	}
 }
 
+func TestMiddlewareHandler_Authenticated(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	var (
+		userSvc = mock_service.NewMockUserService(c)
+		svc     = &service.Service{UserService: userSvc}
+		router  = httprouter.New()
+
+		hndlr = handler{
+			router:  router,
+			svc:     svc,
+			cfg:     &config.Config{},
+			limiter: ratelimit.NewLimiter(ratelimit.NewMemoryStore()),
+		}
+	)
+
+	// Invalid tokens are only checked until the client is limited.
+	userSvc.EXPECT().ParseToken("garbage").Return(nil, service.ErrInvalidToken).Times(clientRateLimit.Limit)
+
+	router.GET("/", hndlr.Authenticated(func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 0; i <= clientRateLimit.Limit; i++ {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer garbage")
+
+		router.ServeHTTP(w, req)
+
+		if i < clientRateLimit.Limit {
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			continue
+		}
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	}
+}
+
+func TestMiddlewareHandler_RequireScope(t *testing.T) {
+	tests := []struct {
+		name               string
+		scopes             []string
+		expectedStatusCode int
+	}{
+		{
+			name:               "Session",
+			scopes:             nil,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Token-With-Scope",
+			scopes:             []string{domain.ScopeCapsulesRead, domain.ScopeCapsulesWrite},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Token-Without-Scope",
+			scopes:             []string{domain.ScopeCapsulesRead},
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				router = httprouter.New()
+				hndlr  = handler{router: router}
+			)
+
+			router.GET("/", hndlr.RequireScope(domain.ScopeCapsulesWrite, func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			ctx := context.Background()
+			if test.scopes != nil {
+				ctx = context.WithValue(ctx, scopesCtx, test.scopes)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestMiddlewareHandler_RequireSession(t *testing.T) {
+	tests := []struct {
+		name                 string
+		scopes               []string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "Session",
+			scopes:               nil,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: "",
+		},
+		{
+			name:                 "Token",
+			scopes:               domain.Scopes,
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"message":"personal access tokens can't be used here"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				router = httprouter.New()
+				hndlr  = handler{router: router}
+			)
+
+			router.GET("/", hndlr.RequireSession(func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			ctx := context.Background()
+			if test.scopes != nil {
+				ctx = context.WithValue(ctx, scopesCtx, test.scopes)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
 func TestMiddlewareHandler_getUserID(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -246,3 +586,277 @@ func TestMiddlewareHandler_getUserID(t *testing.T) {
 		})
 	}
 }
+
+func TestMiddlewareHandler_RequireRole(t *testing.T) {
+	tests := []struct {
+		name                 string
+		role                 string
+		required             string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "OK",
+			role:                 domain.RoleSupport,
+			required:             domain.RoleSupport,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: "ok",
+		},
+		{
+			name:                 "Admin-Has-Every-Role",
+			role:                 domain.RoleAdmin,
+			required:             domain.RoleSupport,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: "ok",
+		},
+		{
+			name:                 "Missing-Role",
+			role:                 domain.RoleSupport,
+			required:             domain.RoleAdmin,
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"message":"forbidden"}`,
+		},
+		{
+			name:                 "No-Role",
+			role:                 "",
+			required:             domain.RoleSupport,
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"message":"forbidden"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				router = httprouter.New()
+				hndlr  = handler{router: router}
+			)
+
+			router.GET("/", hndlr.RequireRole(test.required, func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+				fmt.Fprint(w, "ok")
+			}))
+
+			ctx := context.WithValue(context.Background(), roleCtx, test.role)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestMiddlewareHandler_StaffAuthentication(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		headers              map[string]string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Staff-Session",
+			mockBehavior: func(s *mock_service.MockUserService) {
+				s.EXPECT().ParseToken("token").Return(jwt.MapClaims{"userID": primitive.NilObjectID.Hex()}, nil).Times(1)
+				s.EXPECT().AuthenticateUser(gomock.Any(), primitive.NilObjectID).Return(&domain.User{Role: domain.RoleAdmin}, nil).Times(1)
+				s.EXPECT().RecordActivity(gomock.Any(), primitive.NilObjectID).Return(nil).Times(1)
+			},
+			headers:              map[string]string{"Authorization": "Bearer token"},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: primitive.NilObjectID.Hex(),
+		},
+		{
+			name: "User-Session",
+			mockBehavior: func(s *mock_service.MockUserService) {
+				s.EXPECT().ParseToken("token").Return(jwt.MapClaims{"userID": primitive.NilObjectID.Hex()}, nil).Times(1)
+				s.EXPECT().AuthenticateUser(gomock.Any(), primitive.NilObjectID).Return(&domain.User{}, nil).Times(1)
+				s.EXPECT().RecordActivity(gomock.Any(), primitive.NilObjectID).Return(nil).Times(1)
+			},
+			headers:              map[string]string{"Authorization": "Bearer token"},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"message":"forbidden"}`,
+		},
+		{
+			name:                 "No-Session",
+			mockBehavior:         func(s *mock_service.MockUserService) {},
+			headers:              map[string]string{"X-Admin-Key": "secret"},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"empty auth header"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				userSvc = mock_service.NewMockUserService(c)
+				router  = httprouter.New()
+
+				hndlr = &handler{
+					router:  router,
+					svc:     &service.Service{UserService: userSvc},
+					cfg:     &config.Config{},
+					limiter: ratelimit.NewLimiter(ratelimit.NewMemoryStore()),
+				}
+			)
+
+			test.mockBehavior(userSvc)
+
+			router.GET("/", hndlr.StaffAuthentication(domain.RoleSupport, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+				fmt.Fprint(w, getActorID(r).Hex())
+			}))
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestMiddlewareHandler_RequestID(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		expectKept bool
+	}{
+		{
+			name:       "Kept",
+			header:     "abc-123",
+			expectKept: true,
+		},
+		{
+			name:       "Generated",
+			header:     "",
+			expectKept: false,
+		},
+		{
+			name:       "Too-Long",
+			header:     strings.Repeat("a", maxRequestIDLength+1),
+			expectKept: false,
+		},
+		{
+			name:       "Not-Printable",
+			header:     "abc 123",
+			expectKept: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got string
+
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = logging.RequestID(r.Context())
+			}))
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(requestIDHeader, test.header)
+
+			handler.ServeHTTP(w, req)
+
+			assert.NotEmpty(t, got)
+			assert.Equal(t, got, w.Header().Get(requestIDHeader))
+			assert.Equal(t, test.expectKept, got == test.header)
+		})
+	}
+}
+
+func TestMiddlewareHandler_AccessLog(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := logging.New(&buf, "info")
+	assert.NoError(t, err)
+
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(logger)
+
+	handler := RequestID(AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+
+		// Event streams flush through the recorder.
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/exports/download?token=secret", nil)
+	req.Header.Set(requestIDHeader, "abc123")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, "abc123", record["requestID"])
+	assert.Equal(t, "/api/v1/exports/download", record["path"])
+	assert.Equal(t, float64(http.StatusTeapot), record["status"])
+}
+
+func TestMiddlewareHandler_Metrics(t *testing.T) {
+	router := httprouter.New()
+	router.GET("/test/:id", Metrics("/test/:id", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	for _, id := range []string{"1", "2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test/"+id, nil))
+	}
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, metricsURL, nil))
+
+	assert.Contains(t, w.Body.String(), `time_capsule_http_requests_total{method="GET",route="/test/:id",status="418"} 2`)
+	assert.Contains(t, w.Body.String(), `time_capsule_http_request_duration_seconds_count{method="GET",route="/test/:id"} 2`)
+}
+
+func TestMiddlewareHandler_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	otel.SetTracerProvider(tracing.NewProvider(sdktrace.WithSyncer(exporter)))
+
+	defer otel.SetTextMapPropagator(otel.GetTextMapPropagator())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	router := httprouter.New()
+	router.GET("/test/:id", Tracing("/test/:id", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		_, span := tracing.Start(r.Context(), "child")
+		span.End()
+
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		child, server := spans[0], spans[1]
+
+		assert.Equal(t, "GET /test/:id", server.Name)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+		assert.Equal(t, codes.Error, server.Status.Code)
+		assert.Contains(t, server.Attributes, attribute.Int("http.status_code", http.StatusInternalServerError))
+
+		assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+	}
+}
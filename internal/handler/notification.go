@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/events"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment, so that proxies keep it open.
+const keepAliveInterval = 25 * time.Second
+
+// GetNotifications | Lists The Notifications
+//
+//	@Summary      GetNotifications
+//	@Security     ApiKeyAuth
+//	@Description  Lists the latest notifications of the user, along with the number of unread ones
+//	@Tags         Notifications
+//	@Produce      json
+//	@Param        unread query     bool false "only the unread notifications"
+//	@Success      200    {object}  domain.NotificationList
+//	@Failure      401    {object}  errorResponse
+//	@Failure      500    {object}  errorResponse
+//	@Router       /api/v1/notifications [get]
+func (h *handler) getNotifications(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	notifications, err := h.svc.GetNotifications(r.Context(), userID, r.URL.Query().Get("unread") == "true")
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, notifications)
+	return
+}
+
+// MarkNotificationsRead | Marks Notifications As Read
+//
+//	@Summary      MarkNotificationsRead
+//	@Security     ApiKeyAuth
+//	@Description  Marks the given notifications as read, all of them when no ids are given
+//	@Tags         Notifications
+//	@Accept       json
+//	@Produce      json
+//	@Param        input body      domain.MarkNotificationsReadDTO true "input"
+//	@Success      204
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/notifications/read [post]
+func (h *handler) markNotificationsRead(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	var input domain.MarkNotificationsReadDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	if err = h.svc.MarkNotificationsRead(r.Context(), userID, input); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// StreamEvents | Streams Events
+//
+//	@Summary      StreamEvents
+//	@Security     ApiKeyAuth
+//	@Description  Streams the user's events as Server-Sent Events: "notification" when a capsule opens or a reminder
+//	@Description  is due, and "notifications_read" when notifications are marked as read. Events that happen while
+//	@Description  disconnected aren't replayed, fetch the notifications after reconnecting.
+//	@Tags         Notifications
+//	@Produce      text/event-stream
+//	@Success      200
+//	@Failure      401 {object}  errorResponse
+//	@Failure      500 {object}  errorResponse
+//	@Router       /api/v1/events [get]
+func (h *handler) streamEvents(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	// The stream outlives the server's write timeout.
+	rc := http.NewResponseController(w)
+	if err = rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.ErrorContext(r.Context(), "streamEvents", "error", err)
+		newErrorResponse(w, errors.New("internal server error"), http.StatusInternalServerError)
+		return
+	}
+
+	stream, unsubscribe := h.svc.Subscribe(userID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err = rc.Flush(); err != nil {
+		slog.ErrorContext(r.Context(), "streamEvents", "error", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-stream:
+			if !ok {
+				return
+			}
+
+			if err = writeEvent(w, event); err != nil {
+				slog.ErrorContext(r.Context(), "streamEvents", "error", err)
+				return
+			}
+		case <-keepAlive.C:
+			if _, err = fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err = rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes the event in the Server-Sent Events format.
+func writeEvent(w http.ResponseWriter, event events.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+
+	return err
+}
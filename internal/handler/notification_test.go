@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/events"
+	"time-capsule/internal/service"
+	mock_service "time-capsule/internal/service/mocks"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationHandler_getNotifications(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockNotificationService, ctx context.Context, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		url                  string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockNotificationService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().GetNotifications(ctx, userID, true).Return(&domain.NotificationList{
+					Notifications: []*domain.Notification{{
+						Key:       "reminder:1:0:7",
+						Type:      domain.NotificationCapsuleReminder,
+						Days:      7,
+						CreatedAt: time.Unix(0, 0).UTC(),
+					}},
+					Unread: 1,
+				}, nil).Times(1)
+			},
+			ctxUserID:          primitive.NilObjectID.Hex(),
+			url:                getNotificationsURL + "?unread=true",
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"notifications":[{"id":"000000000000000000000000","userID":"000000000000000000000000",` +
+				`"type":"capsule_reminder","capsuleID":"000000000000000000000000","days":7,` +
+				`"createdAt":"1970-01-01T00:00:00Z"}],"unread":1}`,
+		},
+		{
+			name: "Internal-Error",
+			mockBehavior: func(s *mock_service.MockNotificationService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().GetNotifications(ctx, userID, false).Return(nil, service.ErrDBFailure).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			url:                  getNotificationsURL,
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"something went wrong... try again later :("}`,
+		},
+		{
+			name:                 "Invalid-User-ID",
+			mockBehavior:         func(s *mock_service.MockNotificationService, ctx context.Context, userID primitive.ObjectID) {},
+			ctxUserID:            "",
+			url:                  getNotificationsURL,
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				notificationSvc = mock_service.NewMockNotificationService(c)
+				svc             = &service.Service{
+					NotificationService: notificationSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(notificationSvc, ctx, primitive.NilObjectID)
+
+			router.GET(getNotificationsURL, hndlr.getNotifications)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestNotificationHandler_markNotificationsRead(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockNotificationService, ctx context.Context, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockNotificationService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().MarkNotificationsRead(ctx, userID, domain.MarkNotificationsReadDTO{
+					IDs: []primitive.ObjectID{primitive.NilObjectID},
+				}).Return(nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{"ids":["000000000000000000000000"]}`,
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name: "OK-All",
+			mockBehavior: func(s *mock_service.MockNotificationService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().MarkNotificationsRead(ctx, userID, domain.MarkNotificationsReadDTO{}).Return(nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{}`,
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name:                 "Invalid-JSON",
+			mockBehavior:         func(s *mock_service.MockNotificationService, ctx context.Context, userID primitive.ObjectID) {},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{"ids":"all"}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid json"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				notificationSvc = mock_service.NewMockNotificationService(c)
+				svc             = &service.Service{
+					NotificationService: notificationSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(notificationSvc, ctx, primitive.NilObjectID)
+
+			router.POST(markNotificationsReadURL, hndlr.markNotificationsRead)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, markNotificationsReadURL, strings.NewReader(test.requestBody))
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestNotificationHandler_streamEvents(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockNotificationService, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		expectedStatusCode   int
+		expectedContentType  string
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockNotificationService, userID primitive.ObjectID) {
+				stream := make(chan events.Event, 2)
+				stream <- events.Event{
+					Type: events.TypeNotification,
+					Data: &domain.Notification{
+						Type:      domain.NotificationCapsuleOpened,
+						CreatedAt: time.Unix(0, 0).UTC(),
+					},
+				}
+				stream <- events.Event{
+					Type: events.TypeNotificationsRead,
+					Data: domain.MarkNotificationsReadDTO{IDs: []primitive.ObjectID{}},
+				}
+				close(stream)
+
+				s.EXPECT().Subscribe(userID).Return(stream, func() {}).Times(1)
+			},
+			ctxUserID:           primitive.NilObjectID.Hex(),
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/event-stream",
+			expectedResponseBody: "event: notification\n" +
+				`data: {"id":"000000000000000000000000","userID":"000000000000000000000000","type":"capsule_opened",` +
+				`"capsuleID":"000000000000000000000000","createdAt":"1970-01-01T00:00:00Z"}` + "\n\n" +
+				"event: notifications_read\n" +
+				`data: {"ids":[]}` + "\n\n",
+		},
+		{
+			name:                 "Invalid-User-ID",
+			mockBehavior:         func(s *mock_service.MockNotificationService, userID primitive.ObjectID) {},
+			ctxUserID:            "",
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedContentType:  "application/json",
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				notificationSvc = mock_service.NewMockNotificationService(c)
+				svc             = &service.Service{
+					NotificationService: notificationSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(notificationSvc, primitive.NilObjectID)
+
+			router.GET(eventsURL, hndlr.streamEvents)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, eventsURL, nil)
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestNotificationHandler_streamEvents_Disconnect(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	var (
+		ctx, cancel = context.WithCancel(context.WithValue(context.Background(), userCtx, primitive.NilObjectID.Hex()))
+
+		notificationSvc = mock_service.NewMockNotificationService(c)
+		svc             = &service.Service{
+			NotificationService: notificationSvc,
+		}
+		router = httprouter.New()
+
+		hndlr = handler{
+			router:  router,
+			svc:     svc,
+			storage: nil,
+		}
+
+		unsubscribed bool
+	)
+
+	notificationSvc.EXPECT().Subscribe(primitive.NilObjectID).
+		Return(make(chan events.Event), func() { unsubscribed = true }).Times(1)
+
+	router.GET(eventsURL, hndlr.streamEvents)
+
+	w := httptest.NewRecorder()
+
+	req := httptest.NewRequest(http.MethodGet, eventsURL, nil)
+	req = req.WithContext(ctx)
+
+	cancel()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.True(t, unsubscribed)
+}
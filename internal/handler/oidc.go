@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"time-capsule/internal/domain"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// oidcStateCookie keeps the state token between the redirect to the provider and the callback.
+const oidcStateCookie = "oidc_state"
+
+// OIDCLogin | Signs In With An Identity Provider
+//
+//	@Summary      OIDCLogin
+//	@Description  Redirects to the identity provider to sign in with
+//	@Tags         Auth
+//	@Param        provider path      string true "Provider name"
+//	@Success      302
+//	@Failure      404      {object}  errorResponse
+//	@Failure      429      {object}  errorResponse
+//	@Failure      500      {object}  errorResponse
+//	@Failure      502      {object}  errorResponse
+//	@Router       /api/v1/oidc/{provider}/login [get]
+func (h *handler) oidcLogin(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	authorization, err := h.svc.StartOIDCSignIn(r.Context(), ps.ByName(pathProvider))
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	h.setOIDCStateCookie(w, authorization.StateToken, authorization.ExpiresAt)
+
+	http.Redirect(w, r, authorization.URL, http.StatusFound)
+	return
+}
+
+// OIDCCallback | Completes A Sign-In With An Identity Provider
+//
+//	@Summary      OIDCCallback
+//	@Description  The identity provider redirects back here. With two-factor authentication enabled, it returns an MFA token to complete the sign-in with instead
+//	@Tags         Auth
+//	@Produce      json
+//	@Param        provider path      string true  "Provider name"
+//	@Param        code     query     string false "Authorization code"
+//	@Param        state    query     string false "State"
+//	@Param        error    query     string false "Error"
+//	@Success      200      {object}  domain.SignInResult
+//	@Failure      400      {object}  errorResponse
+//	@Failure      401      {object}  errorResponse
+//	@Failure      403      {object}  errorResponse
+//	@Failure      404      {object}  errorResponse
+//	@Failure      423      {object}  errorResponse
+//	@Failure      429      {object}  errorResponse
+//	@Failure      500      {object}  errorResponse
+//	@Failure      502      {object}  errorResponse
+//	@Router       /api/v1/oidc/{provider}/callback [get]
+func (h *handler) oidcCallback(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var (
+		query = r.URL.Query()
+		input = domain.OIDCCallback{
+			Code:  query.Get("code"),
+			State: query.Get("state"),
+			Error: query.Get("error"),
+		}
+	)
+
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		input.StateToken = cookie.Value
+	}
+
+	// The state is single use, whatever the outcome.
+	h.setOIDCStateCookie(w, "", time.Unix(0, 0))
+
+	result, err := h.svc.CompleteOIDCSignIn(h.withClient(r), ps.ByName(pathProvider), input)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, result)
+	return
+}
+
+func (h *handler) setOIDCStateCookie(w http.ResponseWriter, value string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     oidcURL,
+		Expires:  expires,
+		Secure:   strings.HasPrefix(h.cfg.PublicURL, "https://"),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
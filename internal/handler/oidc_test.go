@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/service"
+	mock_service "time-capsule/internal/service/mocks"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOIDCHandler_oidcLogin(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService)
+
+	expiresAt := time.Now().UTC().Add(10 * time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		provider             string
+		expectedStatusCode   int
+		expectedLocation     string
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUserService) {
+				s.EXPECT().StartOIDCSignIn(gomock.Any(), "google").Return(&domain.OIDCAuthorization{
+					URL:        "https://accounts.example.com/authorize?state=some-state",
+					StateToken: "some-state-token",
+					ExpiresAt:  expiresAt,
+				}, nil).Times(1)
+			},
+			provider:           "google",
+			expectedStatusCode: http.StatusFound,
+			expectedLocation:   "https://accounts.example.com/authorize?state=some-state",
+		},
+		{
+			name: "Unknown-Provider",
+			mockBehavior: func(s *mock_service.MockUserService) {
+				s.EXPECT().StartOIDCSignIn(gomock.Any(), "other").Return(nil, service.ErrUnknownProvider).Times(1)
+			},
+			provider:             "other",
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"message":"unknown identity provider"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				userSvc = mock_service.NewMockUserService(c)
+				svc     = &service.Service{
+					UserService: userSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{PublicURL: "https://time-capsule.example.com"},
+				}
+			)
+
+			test.mockBehavior(userSvc)
+
+			router.GET(oidcLoginURL, hndlr.oidcLogin)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/oidc/"+test.provider+"/login", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+
+			if test.expectedLocation == "" {
+				assert.Equal(t, test.expectedResponseBody, w.Body.String())
+				assert.Empty(t, w.Result().Cookies())
+				return
+			}
+
+			assert.Equal(t, test.expectedLocation, w.Header().Get("Location"))
+
+			cookies := w.Result().Cookies()
+			require.Len(t, cookies, 1)
+			assert.Equal(t, oidcStateCookie, cookies[0].Name)
+			assert.Equal(t, "some-state-token", cookies[0].Value)
+			assert.Equal(t, oidcURL, cookies[0].Path)
+			assert.True(t, cookies[0].HttpOnly)
+			assert.True(t, cookies[0].Secure)
+			assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+			assert.Equal(t, expiresAt, cookies[0].Expires.UTC())
+		})
+	}
+}
+
+func TestOIDCHandler_oidcCallback(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService, ctx context.Context, input domain.OIDCCallback)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		query                string
+		stateToken           string
+		inputData            domain.OIDCCallback
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, input domain.OIDCCallback) {
+				s.EXPECT().CompleteOIDCSignIn(ctx, "google", input).
+					Return(&domain.SignInResult{Token: "some-token"}, nil).Times(1)
+			},
+			query:      "?code=some-code&state=some-state",
+			stateToken: "some-state-token",
+			inputData: domain.OIDCCallback{
+				Code:       "some-code",
+				State:      "some-state",
+				StateToken: "some-state-token",
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"token":"some-token"}`,
+		},
+		{
+			name: "Missing-Cookie",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, input domain.OIDCCallback) {
+				s.EXPECT().CompleteOIDCSignIn(ctx, "google", input).
+					Return(nil, service.ErrInvalidOIDCState).Times(1)
+			},
+			query: "?code=some-code&state=some-state",
+			inputData: domain.OIDCCallback{
+				Code:  "some-code",
+				State: "some-state",
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"` + service.ErrInvalidOIDCState.Error() + `"}`,
+		},
+		{
+			name: "Denied",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, input domain.OIDCCallback) {
+				s.EXPECT().CompleteOIDCSignIn(ctx, "google", input).
+					Return(nil, service.ErrOIDCDenied).Times(1)
+			},
+			query:      "?error=access_denied&state=some-state",
+			stateToken: "some-state-token",
+			inputData: domain.OIDCCallback{
+				State:      "some-state",
+				StateToken: "some-state-token",
+				Error:      "access_denied",
+			},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"` + service.ErrOIDCDenied.Error() + `"}`,
+		},
+		{
+			name: "Provider-Failure",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, input domain.OIDCCallback) {
+				s.EXPECT().CompleteOIDCSignIn(ctx, "google", input).
+					Return(nil, service.ErrOIDCFailure).Times(1)
+			},
+			query:      "?code=some-code&state=some-state",
+			stateToken: "some-state-token",
+			inputData: domain.OIDCCallback{
+				Code:       "some-code",
+				State:      "some-state",
+				StateToken: "some-state-token",
+			},
+			expectedStatusCode:   http.StatusBadGateway,
+			expectedResponseBody: `{"message":"` + service.ErrOIDCFailure.Error() + `"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = service.WithClient(context.Background(), service.Client{IP: "192.0.2.1"})
+
+				userSvc = mock_service.NewMockUserService(c)
+				svc     = &service.Service{
+					UserService: userSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(userSvc, ctx, test.inputData)
+
+			router.GET(oidcCallbackURL, hndlr.oidcCallback)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/oidc/google/callback"+test.query, nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			if test.stateToken != "" {
+				req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: test.stateToken})
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+
+			// The state cookie is cleared whatever the outcome.
+			cookies := w.Result().Cookies()
+			require.Len(t, cookies, 1)
+			assert.Equal(t, oidcStateCookie, cookies[0].Name)
+			assert.Empty(t, cookies[0].Value)
+			assert.True(t, cookies[0].Expires.Before(time.Now()))
+		})
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -16,26 +16,68 @@ var statusCodes = map[error]int{
 	service.ErrPasswordHashFailure: http.StatusInternalServerError,
 	service.ErrStorageFailure:      http.StatusInternalServerError,
 	service.ErrTokenCreationFailed: http.StatusInternalServerError,
-
-	service.ErrUsernameDuplicate: http.StatusConflict, // 409
-	service.ErrEmailDuplicate:    http.StatusConflict,
-
-	service.ErrNotFound: http.StatusNotFound, // 404
-
-	service.ErrForbidden: http.StatusForbidden, // 403
-
-	service.ErrInvalidToken:       http.StatusUnauthorized, // 401
-	service.ErrInvalidCredentials: http.StatusUnauthorized,
-	service.ErrTokenExpired:       http.StatusUnauthorized,
-
-	service.ErrInvalidTime:      http.StatusBadRequest, // 400
-	service.ErrInvalidEmail:     http.StatusBadRequest,
-	service.ErrInvalidUsername:  http.StatusBadRequest,
-	service.ErrInvalidPassword:  http.StatusBadRequest,
-	service.ErrShortMessage:     http.StatusBadRequest,
-	service.ErrOpenTimeTooEarly: http.StatusBadRequest,
-	service.ErrUpdateTooLate:    http.StatusBadRequest,
-	service.ErrEmptyUpdate:      http.StatusBadRequest,
+	service.ErrRenderFailure:       http.StatusInternalServerError,
+
+	service.ErrOIDCFailure: http.StatusBadGateway, // 502
+
+	service.ErrUsernameDuplicate:   http.StatusConflict, // 409
+	service.ErrEmailDuplicate:      http.StatusConflict,
+	service.ErrCollectionDuplicate: http.StatusConflict,
+	service.ErrTwoFactorEnabled:    http.StatusConflict,
+	service.ErrExportInProgress:    http.StatusConflict,
+	service.ErrNothingToResend:     http.StatusConflict,
+
+	service.ErrSignInThrottled: http.StatusTooManyRequests, // 429
+
+	service.ErrAccountLocked: http.StatusLocked, // 423
+
+	service.ErrNotFound:        http.StatusNotFound, // 404
+	service.ErrUnknownProvider: http.StatusNotFound,
+
+	service.ErrForbidden:        http.StatusForbidden, // 403
+	service.ErrEmailNotVerified: http.StatusForbidden,
+	service.ErrAccountDisabled:  http.StatusForbidden,
+	service.ErrOwnAccount:       http.StatusForbidden,
+
+	service.ErrInvalidToken:         http.StatusUnauthorized, // 401
+	service.ErrInvalidCredentials:   http.StatusUnauthorized,
+	service.ErrTokenExpired:         http.StatusUnauthorized,
+	service.ErrInvalidTwoFactorCode: http.StatusUnauthorized,
+	service.ErrOIDCDenied:           http.StatusUnauthorized,
+
+	service.ErrInvalidTime:              http.StatusBadRequest, // 400
+	service.ErrInvalidRole:              http.StatusBadRequest,
+	service.ErrInvalidEmail:             http.StatusBadRequest,
+	service.ErrInvalidUsername:          http.StatusBadRequest,
+	service.ErrInvalidLanguage:          http.StatusBadRequest,
+	service.ErrInvalidPassword:          http.StatusBadRequest,
+	service.ErrShortMessage:             http.StatusBadRequest,
+	service.ErrOpenTimeTooEarly:         http.StatusBadRequest,
+	service.ErrUpdateTooLate:            http.StatusBadRequest,
+	service.ErrEmptyUpdate:              http.StatusBadRequest,
+	service.ErrEmptySearchQuery:         http.StatusBadRequest,
+	service.ErrInvalidTag:               http.StatusBadRequest,
+	service.ErrTooManyTags:              http.StatusBadRequest,
+	service.ErrInvalidCollectionName:    http.StatusBadRequest,
+	service.ErrInvalidRecurrence:        http.StatusBadRequest,
+	service.ErrInvalidMode:              http.StatusBadRequest,
+	service.ErrInvalidInactivityPeriod:  http.StatusBadRequest,
+	service.ErrInvalidRecipients:        http.StatusBadRequest,
+	service.ErrInvalidReminders:         http.StatusBadRequest,
+	service.ErrInvalidOutboxStatus:      http.StatusBadRequest,
+	service.ErrInvalidUnlockToken:       http.StatusBadRequest,
+	service.ErrTwoFactorNotEnabled:      http.StatusBadRequest,
+	service.ErrTOTPNotEnrolled:          http.StatusBadRequest,
+	service.ErrInvalidTokenName:         http.StatusBadRequest,
+	service.ErrInvalidScopes:            http.StatusBadRequest,
+	service.ErrInvalidTokenExpiry:       http.StatusBadRequest,
+	service.ErrTooManyAccessTokens:      http.StatusBadRequest,
+	service.ErrInvalidOIDCState:         http.StatusBadRequest,
+	service.ErrInvalidDisplayName:       http.StatusBadRequest,
+	service.ErrInvalidTimezone:          http.StatusBadRequest,
+	service.ErrInvalidVerificationToken: http.StatusBadRequest,
+	service.ErrDeletionNotScheduled:     http.StatusBadRequest,
+	service.ErrInvalidDownloadToken:     http.StatusBadRequest,
 }
 
 type errorResponse struct {
@@ -52,7 +94,11 @@ func newErrorResponse(w http.ResponseWriter, err error, code ...int) {
 	)
 
 	if len(code) < 1 {
-		statusCode, ok = statusCodes[err]
+		// Errors wrapping a known one, e.g. the broken capsule rules stating their limits, share its status code.
+		for e := err; e != nil && !ok; e = errors.Unwrap(e) {
+			statusCode, ok = statusCodes[e]
+		}
+
 		if !ok {
 			statusCode = http.StatusInternalServerError
 		}
@@ -94,7 +140,7 @@ func handleRequestError(w http.ResponseWriter, err error) {
 		newErrorResponse(w, errors.New("invalid json"), http.StatusBadRequest)
 		return
 	default:
-		log.Println(err)
+		slog.Error("handleRequestError", "error", err)
 		newErrorResponse(w, errors.New("internal server error"), http.StatusInternalServerError)
 		return
 	}
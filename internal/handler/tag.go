@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"time-capsule/internal/domain"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// MergeTags | Merges Tags
+//
+//	@Summary      MergeTags
+//	@Security     ApiKeyAuth
+//	@Description  Replaces the given tags with a single tag on every capsule
+//	@Tags         Tags
+//	@Accept       json
+//	@Produce      json
+//	@Param        input body      domain.MergeTagsDTO true "input"
+//	@Success      204
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      404   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/tags/merge [post]
+func (h *handler) mergeTags(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	var input domain.MergeTagsDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	if err = h.svc.MergeTags(r.Context(), userID, input.Tags, input.Into); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// RenameTag | Renames Tag
+//
+//	@Summary      RenameTag
+//	@Security     ApiKeyAuth
+//	@Description  Renames a tag on every capsule. Renaming to an existing tag merges them
+//	@Tags         Tags
+//	@Accept       json
+//	@Produce      json
+//	@Param        tag   path      string true "tag"
+//	@Param        input body      domain.RenameTagDTO true "input"
+//	@Success      204
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      404   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/tags/{tag} [patch]
+func (h *handler) renameTag(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	var input domain.RenameTagDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	if err = h.svc.RenameTag(r.Context(), userID, params.ByName(pathTag), input.Name); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// GetTags | Retrieves All Tags
+//
+//	@Summary      GetTags
+//	@Security     ApiKeyAuth
+//	@Description  Retrieves all tags with the number of capsules using them
+//	@Tags         Tags
+//	@Produce      json
+//	@Success      200   {array}   domain.TagCount
+//	@Failure      401   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/tags [get]
+func (h *handler) getTags(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	tags, err := h.svc.GetTags(r.Context(), userID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, tags)
+	return
+}
+
+// RemoveCapsuleTag | Removes Tag From The Capsule
+//
+//	@Summary      RemoveCapsuleTag
+//	@Security     ApiKeyAuth
+//	@Description  Removes a tag from the capsule
+//	@Tags         Tags
+//	@Produce      json
+//	@Param        capsuleID    path      string true "capsuleID"
+//	@Param        tag          path      string true "tag"
+//	@Success      204
+//	@Failure      400          {object}  errorResponse
+//	@Failure      401          {object}  errorResponse
+//	@Failure      403          {object}  errorResponse
+//	@Failure      404          {object}  errorResponse
+//	@Failure      500          {object}  errorResponse
+//	@Router       /api/v1/capsules/{capsuleID}/tags/{tag} [delete]
+func (h *handler) removeCapsuleTag(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	capsuleID, err := parseObjectIDFromParam(params, pathCapsuleID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.svc.RemoveTag(r.Context(), userID, capsuleID, params.ByName(pathTag)); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// AddCapsuleTags | Adds Tags To The Capsule
+//
+//	@Summary      AddCapsuleTags
+//	@Security     ApiKeyAuth
+//	@Description  Adds tags to the capsule
+//	@Tags         Tags
+//	@Accept       json
+//	@Produce      json
+//	@Param        capsuleID    path      string true "capsuleID"
+//	@Param        input        body      domain.AddTagsDTO true "input"
+//	@Success      204
+//	@Failure      400          {object}  errorResponse
+//	@Failure      401          {object}  errorResponse
+//	@Failure      403          {object}  errorResponse
+//	@Failure      404          {object}  errorResponse
+//	@Failure      500          {object}  errorResponse
+//	@Router       /api/v1/capsules/{capsuleID}/tags [post]
+func (h *handler) addCapsuleTags(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	capsuleID, err := parseObjectIDFromParam(params, pathCapsuleID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var input domain.AddTagsDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	if err = h.svc.AddTags(r.Context(), userID, capsuleID, input.Tags); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
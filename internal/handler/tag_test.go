@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"time-capsule/internal/service"
+	mock_service "time-capsule/internal/service/mocks"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTagHandler_addCapsuleTags(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		capsuleIDHex         string
+		inputBody            string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID) {
+				s.EXPECT().AddTags(ctx, userID, capsuleID, []string{"kids", "birthday"}).Return(nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			capsuleIDHex:         primitive.NilObjectID.Hex(),
+			inputBody:            `{"tags":["kids","birthday"]}`,
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name:                 "Invalid-Context",
+			mockBehavior:         func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID) {},
+			ctxUserID:            "123123",
+			capsuleIDHex:         primitive.NilObjectID.Hex(),
+			inputBody:            `{"tags":["kids"]}`,
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+		{
+			name:                 "Invalid-CapsuleID",
+			mockBehavior:         func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID) {},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			capsuleIDHex:         "123123",
+			inputBody:            `{"tags":["kids"]}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid id"}`,
+		},
+		{
+			name:                 "Invalid-JSON",
+			mockBehavior:         func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID) {},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			capsuleIDHex:         primitive.NilObjectID.Hex(),
+			inputBody:            `{"tags":"kids"}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid json"}`,
+		},
+		{
+			name: "Invalid-Tag",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID) {
+				s.EXPECT().AddTags(ctx, userID, capsuleID, []string{"<b>"}).Return(service.ErrInvalidTag).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			capsuleIDHex:         primitive.NilObjectID.Hex(),
+			inputBody:            `{"tags":["<b>"]}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"` + service.ErrInvalidTag.Error() + `"}`,
+		},
+		{
+			name: "Service-Failure",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID, capsuleID primitive.ObjectID) {
+				s.EXPECT().AddTags(ctx, userID, capsuleID, []string{"kids"}).Return(errors.New("some error")).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			capsuleIDHex:         primitive.NilObjectID.Hex(),
+			inputBody:            `{"tags":["kids"]}`,
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				capSvc = mock_service.NewMockCapsuleService(c)
+				svc    = &service.Service{
+					CapsuleService: capSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(capSvc, ctx, primitive.NilObjectID, primitive.NilObjectID)
+
+			router.POST(addCapsuleTags, hndlr.addCapsuleTags)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, getCapsulesURL+"/"+test.capsuleIDHex+"/tags",
+				bytes.NewBufferString(test.inputBody))
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestTagHandler_renameTag(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		tag                  string
+		inputBody            string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().RenameTag(ctx, userID, "children", "kids").Return(nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			tag:                  "children",
+			inputBody:            `{"name":"kids"}`,
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name:                 "Invalid-JSON",
+			mockBehavior:         func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID) {},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			tag:                  "children",
+			inputBody:            `{"name":`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid json"}`,
+		},
+		{
+			name: "Tag-Not-Found",
+			mockBehavior: func(s *mock_service.MockCapsuleService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().RenameTag(ctx, userID, "children", "kids").Return(service.ErrNotFound).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			tag:                  "children",
+			inputBody:            `{"name":"kids"}`,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"message":"not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				capSvc = mock_service.NewMockCapsuleService(c)
+				svc    = &service.Service{
+					CapsuleService: capSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(capSvc, ctx, primitive.NilObjectID)
+
+			router.PATCH(renameTagURL, hndlr.renameTag)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPatch, getTagsURL+"/"+test.tag, bytes.NewBufferString(test.inputBody))
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"time-capsule/internal/domain"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// CreateAccessToken | Creates A Personal Access Token
+//
+//	@Summary      CreateAccessToken
+//	@Security     ApiKeyAuth
+//	@Description  Creates a personal access token for scripts and integrations, limited to its scopes (capsules:read, capsules:write, images:write). The token is only returned once
+//	@Tags         Me
+//	@Accept       json
+//	@Produce      json
+//	@Param        input body      domain.CreateAccessTokenDTO true "input"
+//	@Success      201   {object}  domain.AccessToken
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      403   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me/tokens [post]
+func (h *handler) createAccessToken(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	var input domain.CreateAccessTokenDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	token, err := h.svc.CreateAccessToken(h.withClient(r), userID, input)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, token, http.StatusCreated)
+	return
+}
+
+// GetAccessTokens | Lists The Personal Access Tokens
+//
+//	@Summary      GetAccessTokens
+//	@Security     ApiKeyAuth
+//	@Description  Lists the personal access tokens of the user, newest first
+//	@Tags         Me
+//	@Produce      json
+//	@Success      200   {array}   domain.AccessToken
+//	@Failure      401   {object}  errorResponse
+//	@Failure      403   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me/tokens [get]
+func (h *handler) getAccessTokens(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	tokens, err := h.svc.GetAccessTokens(r.Context(), userID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, tokens)
+	return
+}
+
+// RevokeAccessToken | Revokes A Personal Access Token
+//
+//	@Summary      RevokeAccessToken
+//	@Security     ApiKeyAuth
+//	@Description  Revokes a personal access token
+//	@Tags         Me
+//	@Produce      json
+//	@Param        tokenID path      string true "tokenID"
+//	@Success      204
+//	@Failure      400     {object}  errorResponse
+//	@Failure      401     {object}  errorResponse
+//	@Failure      403     {object}  errorResponse
+//	@Failure      404     {object}  errorResponse
+//	@Failure      500     {object}  errorResponse
+//	@Router       /api/v1/me/tokens/{tokenID} [delete]
+func (h *handler) revokeAccessToken(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	tokenID, err := parseObjectIDFromParam(params, pathTokenID)
+	if err != nil {
+		newErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.svc.RevokeAccessToken(h.withClient(r), userID, tokenID); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
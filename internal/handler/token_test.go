@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/service"
+	mock_service "time-capsule/internal/service/mocks"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTokenHandler_createAccessToken(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccessTokenService, userID primitive.ObjectID, input domain.CreateAccessTokenDTO)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		inputBody            string
+		inputData            domain.CreateAccessTokenDTO
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAccessTokenService, userID primitive.ObjectID, input domain.CreateAccessTokenDTO) {
+				s.EXPECT().CreateAccessToken(gomock.Any(), userID, input).Return(&domain.AccessToken{
+					ID:     primitive.NilObjectID,
+					Name:   "backup",
+					Token:  "tc_pat_0123456789",
+					Prefix: "tc_pat_012345",
+					Scopes: []string{domain.ScopeCapsulesRead},
+				}, nil).Times(1)
+			},
+			inputBody: `{"name": "backup", "scopes": ["capsules:read"]}`,
+			inputData: domain.CreateAccessTokenDTO{
+				Name:   "backup",
+				Scopes: []string{domain.ScopeCapsulesRead},
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponseBody: `{"id":"000000000000000000000000","name":"backup","token":"tc_pat_0123456789",` +
+				`"prefix":"tc_pat_012345","scopes":["capsules:read"],"createdAt":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name: "Invalid JSON",
+			mockBehavior: func(s *mock_service.MockAccessTokenService, userID primitive.ObjectID, input domain.CreateAccessTokenDTO) {
+			},
+			inputBody:            `{`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid json"}`,
+		},
+		{
+			name: "Invalid-Scopes",
+			mockBehavior: func(s *mock_service.MockAccessTokenService, userID primitive.ObjectID, input domain.CreateAccessTokenDTO) {
+				s.EXPECT().CreateAccessToken(gomock.Any(), userID, input).Return(nil, service.ErrInvalidScopes).Times(1)
+			},
+			inputBody: `{"name": "backup", "scopes": ["admin"]}`,
+			inputData: domain.CreateAccessTokenDTO{
+				Name:   "backup",
+				Scopes: []string{"admin"},
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"scopes must be some of capsules:read, capsules:write, images:write"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, primitive.NilObjectID.Hex())
+
+				tokenSvc = mock_service.NewMockAccessTokenService(c)
+				svc      = &service.Service{
+					AccessTokenService: tokenSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(tokenSvc, primitive.NilObjectID, test.inputData)
+
+			router.POST(createAccessTokenURL, hndlr.createAccessToken)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, createAccessTokenURL, strings.NewReader(test.inputBody))
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestTokenHandler_revokeAccessToken(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccessTokenService, userID, tokenID primitive.ObjectID)
+
+	tokenID := primitive.NewObjectID()
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		tokenID              string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAccessTokenService, userID, tokenID primitive.ObjectID) {
+				s.EXPECT().RevokeAccessToken(gomock.Any(), userID, tokenID).Return(nil).Times(1)
+			},
+			tokenID:              tokenID.Hex(),
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name:                 "Invalid-ID",
+			mockBehavior:         func(s *mock_service.MockAccessTokenService, userID, tokenID primitive.ObjectID) {},
+			tokenID:              "123",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid id"}`,
+		},
+		{
+			name: "Not-Found",
+			mockBehavior: func(s *mock_service.MockAccessTokenService, userID, tokenID primitive.ObjectID) {
+				s.EXPECT().RevokeAccessToken(gomock.Any(), userID, tokenID).Return(service.ErrNotFound).Times(1)
+			},
+			tokenID:              tokenID.Hex(),
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"message":"not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, primitive.NilObjectID.Hex())
+
+				tokenSvc = mock_service.NewMockAccessTokenService(c)
+				svc      = &service.Service{
+					AccessTokenService: tokenSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(tokenSvc, primitive.NilObjectID, tokenID)
+
+			router.DELETE(revokeAccessTokenURL, hndlr.revokeAccessToken)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodDelete, getAccessTokensURL+"/"+test.tokenID, nil)
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
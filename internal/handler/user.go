@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"time-capsule/internal/domain"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// CheckIn | Resets The Inactivity Timer
+//
+//	@Summary      CheckIn
+//	@Security     ApiKeyAuth
+//	@Description  Resets the inactivity timer of every pending inactivity capsule
+//	@Tags         Me
+//	@Produce      json
+//	@Success      204
+//	@Failure      401   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me/check-in [post]
+func (h *handler) checkIn(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	if err = h.svc.CheckIn(r.Context(), userID); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// UpdateReminders | Sets The Default Reminders
+//
+//	@Summary      UpdateReminders
+//	@Security     ApiKeyAuth
+//	@Description  Sets the days before opening at which capsules without their own reminders remind the user
+//	@Tags         Me
+//	@Accept       json
+//	@Produce      json
+//	@Param        input body      domain.UpdateRemindersDTO true "input"
+//	@Success      204
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me/reminders [put]
+func (h *handler) updateReminders(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	var input domain.UpdateRemindersDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	if err = h.svc.UpdateReminders(r.Context(), userID, input); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// EnrollTOTP | Starts The Two-Factor Authentication Enrollment
+//
+//	@Summary      EnrollTOTP
+//	@Security     ApiKeyAuth
+//	@Description  Generates the secret for an authenticator app. Two-factor authentication is enabled once a code is confirmed
+//	@Tags         Me
+//	@Produce      json
+//	@Success      200   {object}  domain.TOTPEnrollment
+//	@Failure      401   {object}  errorResponse
+//	@Failure      409   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me/2fa/totp [post]
+func (h *handler) enrollTOTP(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	enrollment, err := h.svc.EnrollTOTP(r.Context(), userID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, enrollment)
+	return
+}
+
+// ConfirmTOTP | Enables Two-Factor Authentication
+//
+//	@Summary      ConfirmTOTP
+//	@Security     ApiKeyAuth
+//	@Description  Enables two-factor authentication with a code of the authenticator app, and returns the recovery codes
+//	@Tags         Me
+//	@Accept       json
+//	@Produce      json
+//	@Param        input body      domain.TwoFactorCodeDTO true "input"
+//	@Success      200   {object}  domain.RecoveryCodes
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      409   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me/2fa/totp/confirm [post]
+func (h *handler) confirmTOTP(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	var input domain.TwoFactorCodeDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	codes, err := h.svc.ConfirmTOTP(r.Context(), userID, input)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, codes)
+	return
+}
+
+// DisableTOTP | Disables Two-Factor Authentication
+//
+//	@Summary      DisableTOTP
+//	@Security     ApiKeyAuth
+//	@Description  Disables two-factor authentication, given a code of the authenticator app or a recovery code
+//	@Tags         Me
+//	@Accept       json
+//	@Produce      json
+//	@Param        input body      domain.TwoFactorCodeDTO true "input"
+//	@Success      204
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      423   {object}  errorResponse
+//	@Failure      429   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me/2fa/totp/disable [post]
+func (h *handler) disableTOTP(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	var input domain.TwoFactorCodeDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	if err = h.svc.DisableTOTP(h.withClient(r), userID, input); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// GetMe | Gets The Profile
+//
+//	@Summary      GetMe
+//	@Security     ApiKeyAuth
+//	@Description  Gets the profile of the signed-in user
+//	@Tags         Me
+//	@Produce      json
+//	@Success      200   {object}  domain.User
+//	@Failure      401   {object}  errorResponse
+//	@Failure      403   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me [get]
+func (h *handler) getMe(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	user, err := h.svc.GetProfile(r.Context(), userID)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, user)
+	return
+}
+
+// UpdateMe | Updates The Profile
+//
+//	@Summary      UpdateMe
+//	@Security     ApiKeyAuth
+//	@Description  Updates the profile. A new email is pending until it's verified with the link sent to it
+//	@Tags         Me
+//	@Accept       json
+//	@Produce      json
+//	@Param        input body      domain.UpdateProfileDTO true "input"
+//	@Success      200   {object}  domain.User
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      403   {object}  errorResponse
+//	@Failure      409   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me [patch]
+func (h *handler) updateMe(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	var input domain.UpdateProfileDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	user, err := h.svc.UpdateProfile(r.Context(), userID, input)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, user)
+	return
+}
+
+// DeleteAccount | Deletes The Account
+//
+//	@Summary      DeleteAccount
+//	@Security     ApiKeyAuth
+//	@Description  Schedules the account, its capsules and their images to be purged after a grace period of 30 days. Until then, it can be restored
+//	@Tags         Me
+//	@Accept       json
+//	@Produce      json
+//	@Param        input body      domain.DeleteAccountDTO true "input"
+//	@Success      202   {object}  domain.AccountDeletion
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      403   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me [delete]
+func (h *handler) deleteAccount(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	var input domain.DeleteAccountDTO
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		handleRequestError(w, err)
+		return
+	}
+
+	deletion, err := h.svc.DeleteAccount(h.withClient(r), userID, input)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	newJSONResponse(w, deletion, http.StatusAccepted)
+	return
+}
+
+// RestoreAccount | Cancels The Account Deletion
+//
+//	@Summary      RestoreAccount
+//	@Security     ApiKeyAuth
+//	@Description  Cancels the scheduled deletion of the account
+//	@Tags         Me
+//	@Produce      json
+//	@Success      204
+//	@Failure      400   {object}  errorResponse
+//	@Failure      401   {object}  errorResponse
+//	@Failure      403   {object}  errorResponse
+//	@Failure      500   {object}  errorResponse
+//	@Router       /api/v1/me/restore [post]
+func (h *handler) restoreAccount(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	userID, err := getUserID(r)
+	if err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	if err = h.svc.RestoreAccount(h.withClient(r), userID); err != nil {
+		newErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
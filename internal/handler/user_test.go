@@ -0,0 +1,536 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/service"
+	mock_service "time-capsule/internal/service/mocks"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUserHandler_checkIn(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().CheckIn(ctx, userID).Return(nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name:                 "Invalid-Context",
+			mockBehavior:         func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {},
+			ctxUserID:            "123",
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+		{
+			name: "Service-Failure",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().CheckIn(ctx, userID).Return(errors.New("some error")).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				userSvc = mock_service.NewMockUserService(c)
+				svc     = &service.Service{
+					UserService: userSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(userSvc, ctx, primitive.NilObjectID)
+
+			router.POST(checkInURL, hndlr.checkIn)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, checkInURL, nil)
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_updateReminders(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().UpdateReminders(ctx, userID, domain.UpdateRemindersDTO{Days: []int{7, 1}}).Return(nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{"days":[7,1]}`,
+			expectedStatusCode:   http.StatusNoContent,
+			expectedResponseBody: "",
+		},
+		{
+			name:                 "Invalid-JSON",
+			mockBehavior:         func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{"days":"7"}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid json"}`,
+		},
+		{
+			name: "Invalid-Reminders",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().UpdateReminders(ctx, userID, domain.UpdateRemindersDTO{Days: []int{90}}).
+					Return(service.ErrInvalidReminders).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{"days":[90]}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"reminders must be at most 5 distinct days between 1 and 30"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				userSvc = mock_service.NewMockUserService(c)
+				svc     = &service.Service{
+					UserService: userSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(userSvc, ctx, primitive.NilObjectID)
+
+			router.PUT(remindersURL, hndlr.updateReminders)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPut, remindersURL, strings.NewReader(test.requestBody))
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_enrollTOTP(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().EnrollTOTP(ctx, userID).Return(&domain.TOTPEnrollment{
+					Secret: "JBSWY3DPEHPK3PXP",
+					URI:    "otpauth://totp/foo",
+				}, nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"secret":"JBSWY3DPEHPK3PXP","uri":"otpauth://totp/foo"}`,
+		},
+		{
+			name:                 "Invalid-Context",
+			mockBehavior:         func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {},
+			ctxUserID:            "123",
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"message":"internal server error"}`,
+		},
+		{
+			name: "Already-Enabled",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().EnrollTOTP(ctx, userID).Return(nil, service.ErrTwoFactorEnabled).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"message":"two-factor authentication is already enabled"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				userSvc = mock_service.NewMockUserService(c)
+				svc     = &service.Service{
+					UserService: userSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(userSvc, ctx, primitive.NilObjectID)
+
+			router.POST(enrollTOTPURL, hndlr.enrollTOTP)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, enrollTOTPURL, nil)
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_confirmTOTP(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		inputBody            string
+		inputData            domain.TwoFactorCodeDTO
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO) {
+				s.EXPECT().ConfirmTOTP(ctx, userID, input).Return(&domain.RecoveryCodes{
+					Codes: []string{"1a2b3-c4d5e"},
+				}, nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			inputBody:            `{"code": "123456"}`,
+			inputData:            domain.TwoFactorCodeDTO{Code: "123456"},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"recoveryCodes":["1a2b3-c4d5e"]}`,
+		},
+		{
+			name: "Invalid JSON",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO) {
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			inputBody:            `{`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid json"}`,
+		},
+		{
+			name: "Not-Enrolled",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO) {
+				s.EXPECT().ConfirmTOTP(ctx, userID, input).Return(nil, service.ErrTOTPNotEnrolled).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			inputBody:            `{"code": "123456"}`,
+			inputData:            domain.TwoFactorCodeDTO{Code: "123456"},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"start the two-factor authentication enrollment first"}`,
+		},
+		{
+			name: "Invalid-Code",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO) {
+				s.EXPECT().ConfirmTOTP(ctx, userID, input).Return(nil, service.ErrInvalidTwoFactorCode).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			inputBody:            `{"code": "000000"}`,
+			inputData:            domain.TwoFactorCodeDTO{Code: "000000"},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"invalid two-factor authentication code"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				userSvc = mock_service.NewMockUserService(c)
+				svc     = &service.Service{
+					UserService: userSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(userSvc, ctx, primitive.NilObjectID, test.inputData)
+
+			router.POST(confirmTOTPURL, hndlr.confirmTOTP)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPost, confirmTOTPURL, strings.NewReader(test.inputBody))
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_updateMe(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().UpdateProfile(ctx, userID, domain.UpdateProfileDTO{
+					Email:    "bar@example.com",
+					Timezone: "Europe/Berlin",
+				}).Return(&domain.User{
+					ID:           userID,
+					Username:     "foo",
+					Email:        "foo@example.com",
+					RegisteredAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+					Timezone:     "Europe/Berlin",
+					PendingEmail: "bar@example.com",
+				}, nil).Times(1)
+			},
+			ctxUserID:          primitive.NilObjectID.Hex(),
+			requestBody:        `{"email":"bar@example.com","timezone":"Europe/Berlin"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"id":"000000000000000000000000","username":"foo","email":"foo@example.com",` +
+				`"registeredAt":"2030-01-01T00:00:00Z","timezone":"Europe/Berlin","pendingEmail":"bar@example.com"}`,
+		},
+		{
+			name:                 "Invalid-JSON",
+			mockBehavior:         func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{"email":1}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid json"}`,
+		},
+		{
+			name: "Invalid-Timezone",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().UpdateProfile(ctx, userID, domain.UpdateProfileDTO{Timezone: "Mars/Olympus"}).
+					Return(nil, service.ErrInvalidTimezone).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{"timezone":"Mars/Olympus"}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"timezone must be an IANA time zone, e.g. \"Europe/Berlin\""}`,
+		},
+		{
+			name: "Username-Duplicate",
+			mockBehavior: func(s *mock_service.MockUserService, ctx context.Context, userID primitive.ObjectID) {
+				s.EXPECT().UpdateProfile(ctx, userID, domain.UpdateProfileDTO{Username: "bar"}).
+					Return(nil, service.ErrUsernameDuplicate).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{"username":"bar"}`,
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"message":"username already in use"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				userSvc = mock_service.NewMockUserService(c)
+				svc     = &service.Service{
+					UserService: userSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+				}
+			)
+
+			test.mockBehavior(userSvc, ctx, primitive.NilObjectID)
+
+			router.PATCH(meURL, hndlr.updateMe)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodPatch, meURL, strings.NewReader(test.requestBody))
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_deleteAccount(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAccountService, userID primitive.ObjectID)
+
+	scheduledAt := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		ctxUserID            string
+		requestBody          string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAccountService, userID primitive.ObjectID) {
+				s.EXPECT().DeleteAccount(gomock.Any(), userID, domain.DeleteAccountDTO{Password: "Qwerty123"}).
+					Return(&domain.AccountDeletion{ScheduledAt: scheduledAt}, nil).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{"password":"Qwerty123"}`,
+			expectedStatusCode:   http.StatusAccepted,
+			expectedResponseBody: `{"scheduledAt":"2030-01-31T00:00:00Z"}`,
+		},
+		{
+			name: "Wrong-Password",
+			mockBehavior: func(s *mock_service.MockAccountService, userID primitive.ObjectID) {
+				s.EXPECT().DeleteAccount(gomock.Any(), userID, domain.DeleteAccountDTO{Password: "foo"}).
+					Return(nil, service.ErrInvalidCredentials).Times(1)
+			},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{"password":"foo"}`,
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"message":"invalid credentials"}`,
+		},
+		{
+			name:                 "Invalid-JSON",
+			mockBehavior:         func(s *mock_service.MockAccountService, userID primitive.ObjectID) {},
+			ctxUserID:            primitive.NilObjectID.Hex(),
+			requestBody:          `{`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"message":"invalid json"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				ctx = context.WithValue(context.Background(), userCtx, test.ctxUserID)
+
+				accountSvc = mock_service.NewMockAccountService(c)
+				svc        = &service.Service{
+					AccountService: accountSvc,
+				}
+				router = httprouter.New()
+
+				hndlr = handler{
+					router:  router,
+					svc:     svc,
+					storage: nil,
+					cfg:     &config.Config{},
+				}
+			)
+
+			test.mockBehavior(accountSvc, primitive.NilObjectID)
+
+			router.DELETE(meURL, hndlr.deleteAccount)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodDelete, meURL, strings.NewReader(test.requestBody))
+			req = req.WithContext(ctx)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package health reports whether the service is ready to serve requests, checking the dependencies it needs.
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of the service and of its dependencies.
+const (
+	StatusReady        = "ready"
+	StatusNotReady     = "not_ready"
+	StatusShuttingDown = "shutting_down"
+
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+const checkTimeout = 3 * time.Second
+
+// Check returns an error when the dependency isn't available.
+type Check func(ctx context.Context) error
+
+type check struct {
+	name     string
+	check    Check
+	optional bool
+}
+
+// Report is the readiness of the service and the status of each of its dependencies.
+type Report struct {
+	Status string                      `json:"status"`
+	Checks map[string]DependencyStatus `json:"checks,omitempty"`
+}
+
+type DependencyStatus struct {
+	Status string `json:"status"`
+	// Optional dependencies being down doesn't make the service not ready.
+	Optional bool `json:"optional,omitempty"`
+}
+
+// Checker checks the dependencies of the service.
+type Checker struct {
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// Add adds a dependency the service isn't ready without.
+func (c *Checker) Add(name string, check Check) {
+	c.add(name, check, false)
+}
+
+// AddOptional adds a dependency that's reported, but that the service is ready without.
+func (c *Checker) AddOptional(name string, check Check) {
+	c.add(name, check, true)
+}
+
+func (c *Checker) add(name string, fn Check, optional bool) {
+	c.checks = append(c.checks, check{name: name, check: fn, optional: optional})
+}
+
+// Shutdown makes the service not ready for good, so that load balancers stop sending it requests while it shuts down.
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready checks the dependencies concurrently. The service is ready when none of the required ones is down.
+func (c *Checker) Ready(ctx context.Context) *Report {
+	if c.shuttingDown.Load() {
+		return &Report{Status: StatusShuttingDown}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		report = &Report{
+			Status: StatusReady,
+			Checks: make(map[string]DependencyStatus, len(c.checks)),
+		}
+	)
+
+	for _, chk := range c.checks {
+		wg.Add(1)
+
+		go func(chk check) {
+			defer wg.Done()
+
+			status := DependencyStatus{Status: StatusUp, Optional: chk.optional}
+
+			if err := chk.check(ctx); err != nil {
+				slog.WarnContext(ctx, "a dependency isn't available", "dependency", chk.name, "error", err)
+				status.Status = StatusDown
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[chk.name] = status
+			if status.Status == StatusDown && !chk.optional {
+				report.Status = StatusNotReady
+			}
+		}(chk)
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+// Dial returns a check connecting to the TCP address, e.g. of a server the service only connects to from time to time.
+func Dial(addr string) Check {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+}
+
+// Heartbeat returns a check failing when the last beat is older than maxAge, e.g. when a background loop is stuck.
+func Heartbeat(last func() time.Time, maxAge time.Duration) Check {
+	return func(context.Context) error {
+		if age := time.Since(last()); age > maxAge {
+			return fmt.Errorf("last beat was %s ago", age.Round(time.Second))
+		}
+
+		return nil
+	}
+}
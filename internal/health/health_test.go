@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("some error") }
+
+func TestChecker_Ready(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          func(c *Checker)
+		expectedReport *Report
+	}{
+		{
+			name: "Ready",
+			setup: func(c *Checker) {
+				c.Add("mongodb", up)
+				c.AddOptional("smtp", up)
+			},
+			expectedReport: &Report{
+				Status: StatusReady,
+				Checks: map[string]DependencyStatus{
+					"mongodb": {Status: StatusUp},
+					"smtp":    {Status: StatusUp, Optional: true},
+				},
+			},
+		},
+		{
+			name: "Optional-Down",
+			setup: func(c *Checker) {
+				c.Add("mongodb", up)
+				c.AddOptional("smtp", down)
+			},
+			expectedReport: &Report{
+				Status: StatusReady,
+				Checks: map[string]DependencyStatus{
+					"mongodb": {Status: StatusUp},
+					"smtp":    {Status: StatusDown, Optional: true},
+				},
+			},
+		},
+		{
+			name: "Required-Down",
+			setup: func(c *Checker) {
+				c.Add("mongodb", down)
+				c.Add("minio", up)
+			},
+			expectedReport: &Report{
+				Status: StatusNotReady,
+				Checks: map[string]DependencyStatus{
+					"mongodb": {Status: StatusDown},
+					"minio":   {Status: StatusUp},
+				},
+			},
+		},
+		{
+			name: "Shutting-Down",
+			setup: func(c *Checker) {
+				c.Add("mongodb", up)
+				c.Shutdown()
+			},
+			expectedReport: &Report{
+				Status: StatusShuttingDown,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checker := NewChecker()
+			test.setup(checker)
+
+			assert.Equal(t, test.expectedReport, checker.Ready(context.Background()))
+		})
+	}
+}
+
+func TestDial(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := l.Addr().String()
+
+	assert.NoError(t, Dial(addr)(context.Background()))
+
+	require.NoError(t, l.Close())
+
+	assert.Error(t, Dial(addr)(context.Background()))
+}
+
+func TestHeartbeat(t *testing.T) {
+	now := time.Now()
+
+	assert.NoError(t, Heartbeat(func() time.Time { return now }, time.Minute)(context.Background()))
+	assert.Error(t, Heartbeat(func() time.Time { return now.Add(-time.Hour) }, time.Minute)(context.Background()))
+}
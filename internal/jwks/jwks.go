@@ -0,0 +1,377 @@
+// Package jwks signs and verifies JWTs with asymmetric keys, and publishes the public keys
+// as a JSON Web Key Set so that other services can verify the tokens too.
+//
+// Keys are identified by their RFC 7638 thumbprint, set as the "kid" header of the tokens.
+// To rotate keys, sign with the new key and keep verifying with the previous one until
+// the tokens it signed expire.
+package jwks
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	minRSAKeyBits = 2048
+)
+
+var (
+	ErrUnsupportedKey    = errors.New("unsupported key, use an RSA key of at least 2048 bits or an Ed25519 key")
+	ErrUnknownKey        = errors.New("unknown key id")
+	ErrAlgorithmMismatch = errors.New("signing method doesn't match the key")
+)
+
+// Key is a key tokens are verified with, and signed with if its private key is known.
+type Key struct {
+	ID        string
+	Algorithm string
+
+	public  crypto.PublicKey
+	private crypto.Signer
+}
+
+// KeySet signs tokens with its signing key and verifies them with any of its keys.
+type KeySet struct {
+	signing *Key
+	keys    []*Key
+}
+
+// NewKeySet returns the key set signing with signer and also verifying with the other keys.
+func NewKeySet(signer crypto.Signer, verification ...crypto.PublicKey) (*KeySet, error) {
+	signing, err := newKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	signing.private = signer
+
+	set := &KeySet{
+		signing: signing,
+		keys:    []*Key{signing},
+	}
+
+	for _, public := range verification {
+		key, err := newKey(public)
+		if err != nil {
+			return nil, err
+		}
+
+		if set.key(key.ID) == nil {
+			set.keys = append(set.keys, key)
+		}
+	}
+
+	return set, nil
+}
+
+// Generate returns a key set with a new Ed25519 key. Its tokens can't be verified
+// by other replicas, nor after a restart.
+func Generate() (*KeySet, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeySet(private)
+}
+
+// Load reads the PEM encoded private key to sign with, and the keys to also verify with.
+// Verification keys may be either public or private keys.
+func Load(signingKeyFile string, verificationKeyFiles []string) (*KeySet, error) {
+	_, signer, err := readKeyFile(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if signer == nil {
+		return nil, fmt.Errorf("%s: signing key must be a private key", signingKeyFile)
+	}
+
+	var verification []crypto.PublicKey
+	for _, file := range verificationKeyFiles {
+		public, _, err := readKeyFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		verification = append(verification, public)
+	}
+
+	return NewKeySet(signer, verification...)
+}
+
+// Sign returns the signed token with the claims.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(signingMethod(s.signing.Algorithm), claims)
+	token.Header["kid"] = s.signing.ID
+
+	return token.SignedString(s.signing.private)
+}
+
+// Parse verifies the token and parses its claims. Only tokens signed with one of the keys of the set,
+// with the algorithm of that key, are valid.
+func (s *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}))
+
+	return parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		key := s.key(kid)
+		if key == nil {
+			return nil, ErrUnknownKey
+		}
+
+		if token.Method.Alg() != key.Algorithm {
+			return nil, ErrAlgorithmMismatch
+		}
+
+		return key.public, nil
+	})
+}
+
+// SigningKeyID returns the ID of the key tokens are signed with.
+func (s *KeySet) SigningKeyID() string {
+	return s.signing.ID
+}
+
+func (s *KeySet) key(id string) *Key {
+	for _, key := range s.keys {
+		if key.ID == id {
+			return key
+		}
+	}
+
+	return nil
+}
+
+// Set is a JSON Web Key Set, as defined by RFC 7517.
+type Set struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is a public JSON Web Key.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	// Curve and X are set for Ed25519 keys.
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	// Y is also set for EC keys.
+	Y string `json:"y,omitempty"`
+	// N and E are set for RSA keys.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// PublicKey decodes the key. Besides the key types of the set, it decodes P-256 EC keys,
+// which identity providers may sign with.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, ErrUnsupportedKey
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, ErrUnsupportedKey
+		}
+
+		return key, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, ErrUnsupportedKey
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+// JWKS returns the public keys of the set, the signing key first.
+func (s *KeySet) JWKS() Set {
+	set := Set{Keys: make([]JWK, 0, len(s.keys))}
+
+	for _, key := range s.keys {
+		jwk := publicJWK(key.public)
+		jwk.Use = "sig"
+		jwk.Algorithm = key.Algorithm
+		jwk.KeyID = key.ID
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func newKey(public crypto.PublicKey) (*Key, error) {
+	var algorithm string
+
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSAKeyBits {
+			return nil, ErrUnsupportedKey
+		}
+
+		algorithm = AlgorithmRS256
+	case ed25519.PublicKey:
+		algorithm = AlgorithmEdDSA
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	return &Key{
+		ID:        thumbprint(public),
+		Algorithm: algorithm,
+		public:    public,
+	}, nil
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	if algorithm == AlgorithmRS256 {
+		return jwt.SigningMethodRS256
+	}
+
+	return jwt.SigningMethodEdDSA
+}
+
+func publicJWK(public crypto.PublicKey) JWK {
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType: "RSA",
+			N:       base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       base64.RawURLEncoding.EncodeToString(public),
+		}
+	}
+
+	return JWK{}
+}
+
+// thumbprint returns the RFC 7638 thumbprint of the key: the hash of its required members,
+// in lexicographic order.
+func thumbprint(public crypto.PublicKey) string {
+	var (
+		jwk       = publicJWK(public)
+		canonical string
+	)
+
+	if jwk.KeyType == "RSA" {
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	} else {
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, jwk.Curve, jwk.X)
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// readKeyFile reads a PEM encoded key: a PKIX public key, or a PKCS #8 or PKCS #1 private key.
+// The private key is nil for public keys.
+func readKeyFile(file string) (crypto.PublicKey, crypto.Signer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("%s: no PEM data found", file)
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		return public, nil, nil
+	case "PRIVATE KEY":
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s: %w", file, ErrUnsupportedKey)
+		}
+
+		return signer.Public(), signer, nil
+	case "RSA PRIVATE KEY":
+		private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		return private.Public(), private, nil
+	}
+
+	return nil, nil, fmt.Errorf("%s: unsupported PEM block %q", file, block.Type)
+}
@@ -0,0 +1,280 @@
+package jwks
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"userID": "some-user",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestKeySet_SignParse(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name              string
+		signer            crypto.Signer
+		expectedAlgorithm string
+	}{
+		{
+			name:              "RS256",
+			signer:            rsaKey,
+			expectedAlgorithm: AlgorithmRS256,
+		},
+		{
+			name:              "EdDSA",
+			signer:            edKey,
+			expectedAlgorithm: AlgorithmEdDSA,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			set, err := NewKeySet(test.signer)
+			require.NoError(t, err)
+
+			signed, err := set.Sign(claims())
+			require.NoError(t, err)
+
+			token, err := set.Parse(signed, jwt.MapClaims{})
+			require.NoError(t, err)
+
+			assert.True(t, token.Valid)
+			assert.Equal(t, test.expectedAlgorithm, token.Method.Alg())
+			assert.Equal(t, set.SigningKeyID(), token.Header["kid"])
+			assert.Equal(t, "some-user", token.Claims.(jwt.MapClaims)["userID"])
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	oldSet, err := NewKeySet(oldKey)
+	require.NoError(t, err)
+
+	signed, err := oldSet.Sign(claims())
+	require.NoError(t, err)
+
+	rotated, err := NewKeySet(newKey, oldKey.Public())
+	require.NoError(t, err)
+
+	_, err = rotated.Parse(signed, jwt.MapClaims{})
+	assert.NoError(t, err)
+
+	retired, err := NewKeySet(newKey)
+	require.NoError(t, err)
+
+	_, err = retired.Parse(signed, jwt.MapClaims{})
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestKeySet_Parse_Rejected(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	set, err := NewKeySet(edKey)
+	require.NoError(t, err)
+
+	public, err := x509.MarshalPKIXPublicKey(edKey.Public())
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{
+			name: "HMAC-With-Public-Key",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+				token.Header["kid"] = set.SigningKeyID()
+
+				signed, _ := token.SignedString(public)
+				return signed
+			},
+		},
+		{
+			name: "None",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, claims())
+				token.Header["kid"] = set.SigningKeyID()
+
+				signed, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				return signed
+			},
+		},
+		{
+			name: "Algorithm-Mismatch",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims())
+				token.Header["kid"] = set.SigningKeyID()
+
+				signed, _ := token.SignedString(rsaKey)
+				return signed
+			},
+		},
+		{
+			name: "No-Key-ID",
+			token: func() string {
+				signed, _ := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims()).SignedString(edKey)
+				return signed
+			},
+		},
+		{
+			name: "Expired",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+					"exp": time.Now().Add(-time.Minute).Unix(),
+				})
+				token.Header["kid"] = set.SigningKeyID()
+
+				signed, _ := token.SignedString(edKey)
+				return signed
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := set.Parse(test.token(), jwt.MapClaims{})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	// The Ed25519 key of RFC 8037, appendix A.
+	x, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	require.NoError(t, err)
+
+	_, signer, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	set, err := NewKeySet(signer, ed25519.PublicKey(x))
+	require.NoError(t, err)
+
+	jwks := set.JWKS()
+	require.Len(t, jwks.Keys, 2)
+
+	assert.Equal(t, set.SigningKeyID(), jwks.Keys[0].KeyID)
+	assert.Equal(t, JWK{
+		KeyType:   "OKP",
+		Use:       "sig",
+		Algorithm: AlgorithmEdDSA,
+		KeyID:     "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+		Curve:     "Ed25519",
+		X:         "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+	}, jwks.Keys[1])
+}
+
+func TestJWK_PublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	set, err := NewKeySet(rsaKey, edKey.Public())
+	require.NoError(t, err)
+
+	keys := set.JWKS().Keys
+
+	public, err := keys[0].PublicKey()
+	require.NoError(t, err)
+	assert.True(t, rsaKey.PublicKey.Equal(public))
+
+	public, err = keys[1].PublicKey()
+	require.NoError(t, err)
+	assert.True(t, edKey.Public().(ed25519.PublicKey).Equal(public))
+
+	_, err = JWK{KeyType: "oct"}.PublicKey()
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
+
+func TestNewKeySet_UnsupportedKey(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	_, err = NewKeySet(small)
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	edPrivate, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+
+	edPublic, err := x509.MarshalPKIXPublicKey(edKey.Public())
+	require.NoError(t, err)
+
+	write := func(name, blockType string, bytes []byte) string {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: bytes}), 0o600))
+		return file
+	}
+
+	var (
+		rsaFile       = write("rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+		edFile        = write("ed25519.pem", "PRIVATE KEY", edPrivate)
+		edPublicFile  = write("ed25519.pub", "PUBLIC KEY", edPublic)
+		garbageFile   = filepath.Join(dir, "garbage.pem")
+		missingFile   = filepath.Join(dir, "missing.pem")
+		edSet, _      = NewKeySet(edKey)
+		signedByEd, _ = edSet.Sign(claims())
+	)
+
+	require.NoError(t, os.WriteFile(garbageFile, []byte("garbage"), 0o600))
+
+	set, err := Load(rsaFile, []string{edPublicFile})
+	require.NoError(t, err)
+
+	_, err = set.Parse(signedByEd, jwt.MapClaims{})
+	assert.NoError(t, err)
+
+	_, err = Load(edFile, nil)
+	assert.NoError(t, err)
+
+	_, err = Load(edPublicFile, nil)
+	assert.Error(t, err)
+
+	_, err = Load(garbageFile, nil)
+	assert.Error(t, err)
+
+	_, err = Load(rsaFile, []string{missingFile})
+	assert.Error(t, err)
+}
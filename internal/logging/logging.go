@@ -0,0 +1,108 @@
+// Package logging sets up the structured JSON logger of the service. Records logged with a context
+// carry the ID of the request they belong to, and attributes that may hold secrets or the content
+// of capsules and emails are redacted.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const (
+	requestIDKey = "requestID"
+
+	redacted = "[REDACTED]"
+)
+
+// redactedKeys are the attribute keys whose values are never logged, compared case-insensitively.
+var redactedKeys = map[string]struct{}{
+	"password":      {},
+	"token":         {},
+	"accesstoken":   {},
+	"refreshtoken":  {},
+	"secret":        {},
+	"clientsecret":  {},
+	"apikey":        {},
+	"authorization": {},
+	"cookie":        {},
+	"code":          {},
+	"message":       {},
+	"body":          {},
+	"html":          {},
+	"text":          {},
+}
+
+type requestIDCtxKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestID returns the ID of the request ctx belongs to, or an empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+// ParseLevel parses a level name: debug, info, warn or error. An empty name is info.
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if name == "" {
+		return level, nil
+	}
+
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return level, fmt.Errorf("unknown log level %q", name)
+	}
+
+	return level, nil
+}
+
+// New returns a logger writing JSON records of the level and above to w.
+func New(w io.Writer, level string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	return slog.New(&contextHandler{
+		Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{
+			Level:       lvl,
+			ReplaceAttr: redact,
+		}),
+	}), nil
+}
+
+// redact replaces the values of the attributes in redactedKeys.
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if _, ok := redactedKeys[strings.ToLower(a.Key)]; ok && a.Value.Kind() != slog.KindGroup {
+		return slog.String(a.Key, redacted)
+	}
+
+	return a
+}
+
+// contextHandler adds the ID of the request to the records logged with its context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String(requestIDKey, id))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
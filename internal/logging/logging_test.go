@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := New(&buf, "info")
+	require.NoError(t, err)
+
+	ctx := WithRequestID(context.Background(), "abc123")
+
+	logger.DebugContext(ctx, "skipped")
+	logger.InfoContext(ctx, "signed in",
+		"userID", "42",
+		"password", "hunter2",
+		slog.Group("email", "to", "foo@example.com", "HTML", "<p>Hello</p>"),
+		"error", errors.New("some error"),
+	)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "signed in", record["msg"])
+	assert.Equal(t, "abc123", record["requestID"])
+	assert.Equal(t, "42", record["userID"])
+	assert.Equal(t, redacted, record["password"])
+	assert.Equal(t, map[string]any{"to": "foo@example.com", "HTML": redacted}, record["email"])
+	assert.Equal(t, "some error", record["error"])
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		level         string
+		expectedLevel slog.Level
+		expectError   bool
+	}{
+		{name: "Default", level: "", expectedLevel: slog.LevelInfo},
+		{name: "Debug", level: "debug", expectedLevel: slog.LevelDebug},
+		{name: "Upper-Case", level: "WARN", expectedLevel: slog.LevelWarn},
+		{name: "Unknown", level: "verbose", expectError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			level, err := ParseLevel(test.level)
+			assert.Equal(t, test.expectError, err != nil)
+
+			if !test.expectError {
+				assert.Equal(t, test.expectedLevel, level)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const base64LineLength = 76
+
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Attachment is an inline file, referenced from the HTML part as "cid:" + ContentID.
+type Attachment struct {
+	ContentID   string
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Compose builds an RFC 5322 message with the From, To, Subject, Date and Message-ID headers.
+// The body is multipart/alternative with a plain-text and an HTML part, wrapped into
+// multipart/related when there are inline attachments.
+func Compose(from *netmail.Address, to []string, msg *Message, attachments ...Attachment) ([]byte, error) {
+	recipients := make([]string, 0, len(to))
+	for _, addr := range to {
+		parsed, err := netmail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, addr)
+		}
+
+		recipients = append(recipients, parsed.String())
+	}
+
+	messageID, err := newMessageID(from.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	body, contentType, err := composeBody(msg, attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	for _, header := range [][2]string{
+		{"From", from.String()},
+		{"To", strings.Join(recipients, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", contentType},
+	} {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+	}
+
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	return buf.Bytes(), nil
+}
+
+func composeBody(msg *Message, attachments []Attachment) ([]byte, string, error) {
+	var (
+		alternative bytes.Buffer
+		aw          = multipart.NewWriter(&alternative)
+	)
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		if err := writeQuotedPrintable(aw, part.contentType, part.content); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := aw.Close(); err != nil {
+		return nil, "", err
+	}
+
+	alternativeType := mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": aw.Boundary()})
+
+	if len(attachments) == 0 {
+		return alternative.Bytes(), alternativeType, nil
+	}
+
+	var (
+		related bytes.Buffer
+		rw      = multipart.NewWriter(&related)
+	)
+
+	pw, err := rw.CreatePart(textproto.MIMEHeader{"Content-Type": {alternativeType}})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err = pw.Write(alternative.Bytes()); err != nil {
+		return nil, "", err
+	}
+
+	for _, attachment := range attachments {
+		if err = writeAttachment(rw, attachment); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err = rw.Close(); err != nil {
+		return nil, "", err
+	}
+
+	relatedType := mime.FormatMediaType("multipart/related", map[string]string{
+		"boundary": rw.Boundary(),
+		"type":     "multipart/alternative",
+	})
+
+	return related.Bytes(), relatedType, nil
+}
+
+func writeQuotedPrintable(w *multipart.Writer, contentType, content string) error {
+	pw, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"charset": "utf-8"})},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	qw := quotedprintable.NewWriter(pw)
+	if _, err = io.WriteString(qw, content); err != nil {
+		return err
+	}
+
+	return qw.Close()
+}
+
+func writeAttachment(w *multipart.Writer, attachment Attachment) error {
+	if strings.ContainsAny(attachment.ContentID, "<>\r\n") {
+		return fmt.Errorf("invalid content id %q", attachment.ContentID)
+	}
+
+	pw, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(attachment.ContentType, map[string]string{"name": attachment.Filename})},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-ID":                {"<" + attachment.ContentID + ">"},
+		"Content-Disposition":       {mime.FormatMediaType("inline", map[string]string{"filename": attachment.Filename})},
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+
+	for len(encoded) > 0 {
+		n := min(len(encoded), base64LineLength)
+
+		if _, err = io.WriteString(pw, encoded[:n]+"\r\n"); err != nil {
+			return err
+		}
+
+		encoded = encoded[n:]
+	}
+
+	return nil
+}
+
+// newMessageID returns a unique Message-ID in the domain of the sender's address.
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 && i < len(from)-1 {
+		domain = from[i+1:]
+	}
+
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">", nil
+}
@@ -0,0 +1,134 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompose(t *testing.T) {
+	var (
+		from = &netmail.Address{Name: "Time Capsule", Address: "noreply@example.com"}
+		msg  = &Message{
+			Subject: "Ваша капсула — opened",
+			HTML:    "<p>" + strings.Repeat("long line ", 20) + "</p>",
+			Text:    "Привет!\nBye.",
+		}
+		attachment = Attachment{
+			ContentID:   "image-0@time-capsule",
+			Filename:    "image-0.jpg",
+			ContentType: "image/jpeg",
+			Data:        bytes.Repeat([]byte{0xff, 0xd8, 0x00}, 100),
+		}
+	)
+
+	t.Run("Headers", func(t *testing.T) {
+		data, err := Compose(from, []string{"foo@example.com"}, msg)
+		require.NoError(t, err)
+
+		parsed, err := netmail.ReadMessage(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+		require.NoError(t, err)
+
+		assert.Equal(t, `"Time Capsule" <noreply@example.com>`, parsed.Header.Get("From"))
+		assert.Equal(t, "<foo@example.com>", parsed.Header.Get("To"))
+		assert.Equal(t, msg.Subject, subject)
+		assert.Equal(t, "1.0", parsed.Header.Get("MIME-Version"))
+		assert.Regexp(t, regexp.MustCompile(`^<[0-9a-f]{32}@example\.com>$`), parsed.Header.Get("Message-ID"))
+
+		_, err = parsed.Header.Date()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Alternative", func(t *testing.T) {
+		data, err := Compose(from, []string{"foo@example.com"}, msg)
+		require.NoError(t, err)
+
+		parts := readParts(t, data, "multipart/alternative")
+		require.Len(t, parts, 2)
+
+		assert.Equal(t, "text/plain; charset=utf-8", parts[0].header.Get("Content-Type"))
+		assert.Equal(t, strings.ReplaceAll(msg.Text, "\n", "\r\n"), parts[0].body)
+		assert.Equal(t, "text/html; charset=utf-8", parts[1].header.Get("Content-Type"))
+		assert.Equal(t, msg.HTML, parts[1].body)
+	})
+
+	t.Run("Inline-Attachment", func(t *testing.T) {
+		data, err := Compose(from, []string{"foo@example.com"}, msg, attachment)
+		require.NoError(t, err)
+
+		parts := readParts(t, data, "multipart/related")
+		require.Len(t, parts, 2)
+
+		assert.True(t, strings.HasPrefix(parts[0].header.Get("Content-Type"), "multipart/alternative"))
+		assert.Equal(t, "<image-0@time-capsule>", parts[1].header.Get("Content-ID"))
+		assert.Equal(t, `inline; filename=image-0.jpg`, parts[1].header.Get("Content-Disposition"))
+
+		for _, line := range strings.Split(strings.TrimSpace(parts[1].body), "\r\n") {
+			assert.LessOrEqual(t, len(line), base64LineLength)
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(parts[1].body, "\r\n", ""))
+		require.NoError(t, err)
+		assert.Equal(t, attachment.Data, decoded)
+	})
+
+	t.Run("Invalid-Recipient", func(t *testing.T) {
+		_, err := Compose(from, []string{"foo@example.com\r\nBcc: bar@example.com"}, msg)
+		assert.ErrorIs(t, err, ErrInvalidAddress)
+	})
+}
+
+type testPart struct {
+	header netmail.Header
+	body   string
+}
+
+// readParts parses the message body as a multipart of the given type, decoding quoted-printable parts.
+func readParts(t *testing.T, data []byte, mediaType string) []testPart {
+	t.Helper()
+
+	parsed, err := netmail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	typ, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, mediaType, typ)
+
+	var (
+		res []testPart
+		mr  = multipart.NewReader(parsed.Body, params["boundary"])
+	)
+
+	for {
+		part, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		var r io.Reader = part
+		if part.Header.Get("Content-Transfer-Encoding") == "quoted-printable" {
+			r = quotedprintable.NewReader(part)
+		}
+
+		body, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		res = append(res, testPart{header: netmail.Header(part.Header), body: string(body)})
+	}
+
+	return res
+}
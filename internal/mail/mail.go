@@ -0,0 +1,276 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+const (
+	TemplateOpened          = "opened"
+	TemplateRecipient       = "recipient"
+	TemplateReminder        = "reminder"
+	TemplateCheckInReminder = "check_in_reminder"
+	TemplateAccountLocked   = "account_locked"
+	TemplateVerifyEmail     = "verify_email"
+
+	DefaultLanguage = "en"
+
+	templatesRoot = "templates"
+	layoutFile    = "layout.html"
+)
+
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+//go:embed templates
+var embedded embed.FS
+
+// templates lists every email the service sends.
+var templates = []string{TemplateOpened, TemplateRecipient, TemplateReminder, TemplateCheckInReminder, TemplateAccountLocked,
+	TemplateVerifyEmail}
+
+// Message is a rendered email with an HTML and a plain-text alternative.
+type Message struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+type OpenedData struct {
+	Username string
+	// Images are the content IDs of the inline attachments to show in the email.
+	Images []string
+}
+
+type RecipientData struct {
+	Sender  string
+	Message string
+	Images  []string
+}
+
+type ReminderData struct {
+	Username string
+	Days     int
+	OpenAt   time.Time
+}
+
+type CheckInReminderData struct {
+	Username string
+	Days     int
+}
+
+type AccountLockedData struct {
+	Username string
+	// Until is when the account unlocks by itself.
+	Until     time.Time
+	UnlockURL string
+}
+
+type VerifyEmailData struct {
+	Username string
+	// Email is the new address to verify.
+	Email     string
+	VerifyURL string
+	ExpiresAt time.Time
+}
+
+// Renderer renders the email templates.
+type Renderer interface {
+	// Render renders the named template in the given language, falling back to DefaultLanguage
+	// when the template isn't translated. Both "ru" and "ru-RU" select the "ru" templates.
+	Render(name, lang string, data any) (*Message, error)
+	Templates() []string
+	Languages() []string
+}
+
+var htmlFuncs = htmltemplate.FuncMap{
+	// cid references an inline attachment. html/template doesn't allow the "cid:" scheme in URLs otherwise.
+	"cid": func(contentID string) htmltemplate.URL {
+		return htmltemplate.URL("cid:" + url.PathEscape(contentID))
+	},
+}
+
+type templateSet struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+type templateRenderer struct {
+	sets map[string]map[string]*templateSet // language -> template -> set
+}
+
+// NewRenderer parses the templates embedded in the binary. When dir is not empty, files in it
+// override the embedded ones with the same path (e.g. dir/en/opened.html) and may add new languages.
+func NewRenderer(dir string) (Renderer, error) {
+	fsys, err := fs.Sub(embedded, templatesRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir != "" {
+		fsys = overlay{top: os.DirFS(dir), base: fsys}
+	}
+
+	languages, err := languages(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &templateRenderer{
+		sets: make(map[string]map[string]*templateSet, len(languages)),
+	}
+
+	for _, lang := range languages {
+		r.sets[lang] = make(map[string]*templateSet, len(templates))
+
+		for _, name := range templates {
+			set, err := parse(fsys, lang, name)
+			if errors.Is(err, fs.ErrNotExist) && lang != DefaultLanguage {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s/%s: %w", lang, name, err)
+			}
+
+			r.sets[lang][name] = set
+		}
+	}
+
+	return r, nil
+}
+
+func (r *templateRenderer) Render(name, lang string, data any) (*Message, error) {
+	set, ok := r.sets[normalizeLanguage(lang)][name]
+	if !ok {
+		if set, ok = r.sets[DefaultLanguage][name]; !ok {
+			return nil, ErrUnknownTemplate
+		}
+	}
+
+	var (
+		subject strings.Builder
+		text    strings.Builder
+		html    bytes.Buffer
+	)
+
+	if err := set.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	if err := set.text.ExecuteTemplate(&text, "body", data); err != nil {
+		return nil, err
+	}
+
+	if err := set.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
+
+func (r *templateRenderer) Templates() []string {
+	return append([]string(nil), templates...)
+}
+
+func (r *templateRenderer) Languages() []string {
+	res := make([]string, 0, len(r.sets))
+	for lang := range r.sets {
+		res = append(res, lang)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// SampleData returns made-up data for previewing the named template.
+func SampleData(name string) (any, bool) {
+	switch name {
+	case TemplateOpened:
+		return OpenedData{Username: "johndoe"}, true
+	case TemplateRecipient:
+		return RecipientData{Sender: "johndoe", Message: "Happy birthday! <3\nSee you soon."}, true
+	case TemplateReminder:
+		return ReminderData{Username: "johndoe", Days: 7, OpenAt: time.Now().UTC().AddDate(0, 0, 7)}, true
+	case TemplateCheckInReminder:
+		return CheckInReminderData{Username: "johndoe", Days: 3}, true
+	case TemplateAccountLocked:
+		return AccountLockedData{
+			Username:  "johndoe",
+			Until:     time.Now().UTC().Add(30 * time.Minute),
+			UnlockURL: "https://time-capsule.example.com/api/v1/unlock?token=sample",
+		}, true
+	case TemplateVerifyEmail:
+		return VerifyEmailData{
+			Username:  "johndoe",
+			Email:     "john@example.com",
+			VerifyURL: "https://time-capsule.example.com/api/v1/verify-email?token=sample",
+			ExpiresAt: time.Now().UTC().Add(24 * time.Hour),
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// parse parses the HTML template, wrapped in the common layout, and the plain-text template
+// defining the subject and the body.
+func parse(fsys fs.FS, lang, name string) (*templateSet, error) {
+	var (
+		htmlFile = path.Join(lang, name+".html")
+		textFile = path.Join(lang, name+".txt")
+	)
+
+	for _, file := range []string{htmlFile, textFile} {
+		if _, err := fs.Stat(fsys, file); err != nil {
+			return nil, err
+		}
+	}
+
+	html, err := htmltemplate.New(layoutFile).Funcs(htmlFuncs).ParseFS(fsys, layoutFile, htmlFile)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := texttemplate.ParseFS(fsys, textFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &templateSet{html: html, text: text}, nil
+}
+
+// languages lists the language directories.
+func languages(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			res = append(res, entry.Name())
+		}
+	}
+
+	return res, nil
+}
+
+func normalizeLanguage(lang string) string {
+	lang, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(lang)), "-")
+	return lang
+}
@@ -0,0 +1,143 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		template        string
+		lang            string
+		data            any
+		expectedSubject string
+		expectedHTML    []string
+		expectedText    []string
+		expectedError   error
+	}{
+		{
+			name:            "OK",
+			template:        TemplateOpened,
+			lang:            "en",
+			data:            OpenedData{Username: "johndoe"},
+			expectedSubject: "Time Capsule Opened!",
+			expectedHTML:    []string{"<html>", "Dear johndoe,"},
+			expectedText:    []string{"Dear johndoe,"},
+		},
+		{
+			name:            "Escaping",
+			template:        TemplateRecipient,
+			lang:            "en",
+			data:            RecipientData{Sender: "<b>john</b>", Message: "1 < 2 & \"quotes\""},
+			expectedSubject: "A Time Capsule Was Left For You",
+			expectedHTML:    []string{"&lt;b&gt;john&lt;/b&gt;", "1 &lt; 2 &amp; &#34;quotes&#34;"},
+			expectedText:    []string{"<b>john</b>", "1 < 2 & \"quotes\""},
+		},
+		{
+			name:            "Localized",
+			template:        TemplateReminder,
+			lang:            "ru-RU",
+			data:            ReminderData{Username: "johndoe", Days: 7, OpenAt: time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)},
+			expectedSubject: "Ваша капсула времени откроется через 7 дн.",
+			expectedHTML:    []string{"02.01.2030"},
+			expectedText:    []string{"Здравствуйте, johndoe!"},
+		},
+		{
+			name:            "Unknown-Language",
+			template:        TemplateCheckInReminder,
+			lang:            "xx",
+			data:            CheckInReminderData{Username: "johndoe", Days: 3},
+			expectedSubject: "Time Capsule Check-In Reminder",
+			expectedHTML:    []string{"in 3 day(s)"},
+			expectedText:    []string{"in 3 day(s)"},
+		},
+		{
+			name:          "Unknown-Template",
+			template:      "unknown",
+			lang:          "en",
+			expectedError: ErrUnknownTemplate,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msg, err := renderer.Render(test.template, test.lang, test.data)
+			assert.Equal(t, test.expectedError, err)
+
+			if test.expectedError != nil {
+				return
+			}
+
+			assert.Equal(t, test.expectedSubject, msg.Subject)
+
+			for _, s := range test.expectedHTML {
+				assert.Contains(t, msg.HTML, s)
+			}
+
+			for _, s := range test.expectedText {
+				assert.Contains(t, msg.Text, s)
+			}
+		})
+	}
+}
+
+func TestRenderer_Override(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "en"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "de"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en", "opened.txt"),
+		[]byte(`{{define "subject"}}Custom subject{{end}}{{define "body"}}Hi {{.Username}}{{end}}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de", "opened.txt"),
+		[]byte(`{{define "subject"}}Zeitkapsel geöffnet!{{end}}{{define "body"}}Hallo {{.Username}}{{end}}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de", "opened.html"),
+		[]byte(`{{define "title"}}Geöffnet{{end}}{{define "content"}}<p>Hallo {{.Username}}</p>{{end}}`), 0o644))
+
+	renderer, err := NewRenderer(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"de", "en", "ru"}, renderer.Languages())
+
+	msg, err := renderer.Render(TemplateOpened, "en", OpenedData{Username: "johndoe"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Custom subject", msg.Subject)
+	assert.Equal(t, "Hi johndoe\n", msg.Text)
+	assert.Contains(t, msg.HTML, "We're thrilled to share")
+
+	msg, err = renderer.Render(TemplateOpened, "de", OpenedData{Username: "johndoe"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Zeitkapsel geöffnet!", msg.Subject)
+	assert.Contains(t, msg.HTML, "<p>Hallo johndoe</p>")
+
+	msg, err = renderer.Render(TemplateReminder, "de", ReminderData{Days: 1})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(msg.Subject, "Your Time Capsule Opens"))
+}
+
+func TestSampleData(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	for _, name := range renderer.Templates() {
+		data, ok := SampleData(name)
+		assert.True(t, ok, name)
+
+		for _, lang := range renderer.Languages() {
+			_, err = renderer.Render(name, lang, data)
+			assert.NoError(t, err, name, lang)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package mail
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+)
+
+// overlay serves files from top, falling back to base for the files top doesn't have.
+// Directory listings are merged.
+type overlay struct {
+	top  fs.FS
+	base fs.FS
+}
+
+func (o overlay) Open(name string) (fs.File, error) {
+	f, err := o.top.Open(name)
+	if err == nil {
+		return f, nil
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return o.base.Open(name)
+}
+
+func (o overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	top, topErr := fs.ReadDir(o.top, name)
+	base, baseErr := fs.ReadDir(o.base, name)
+
+	if topErr != nil && baseErr != nil {
+		return nil, baseErr
+	}
+
+	var (
+		res  = append([]fs.DirEntry(nil), top...)
+		seen = make(map[string]struct{}, len(top))
+	)
+
+	for _, entry := range top {
+		seen[entry.Name()] = struct{}{}
+	}
+
+	for _, entry := range base {
+		if _, ok := seen[entry.Name()]; !ok {
+			res = append(res, entry)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name() < res[j].Name()
+	})
+
+	return res, nil
+}
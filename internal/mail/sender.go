@@ -0,0 +1,201 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"time"
+
+	"time-capsule/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TLSModeStartTLS upgrades a plain connection with STARTTLS, and fails if the server doesn't support it.
+	TLSModeStartTLS = "starttls"
+	// TLSModeImplicit connects over TLS right away, usually on port 465.
+	TLSModeImplicit = "tls"
+	// TLSModeNone never encrypts the connection. Meant for local development servers only.
+	TLSModeNone = "none"
+
+	defaultFromName = "Time Capsule"
+
+	sendTimeout = 30 * time.Second
+)
+
+var (
+	ErrInvalidTLSMode      = fmt.Errorf("smtp tls mode must be one of %q, %q or %q", TLSModeStartTLS, TLSModeImplicit, TLSModeNone)
+	ErrStartTLSUnsupported = errors.New("smtp server doesn't support STARTTLS")
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+
+	// From defaults to Username, FromName to "Time Capsule".
+	From     string
+	FromName string
+
+	// TLSMode defaults to TLSModeStartTLS.
+	TLSMode string
+}
+
+// Sender sends emails.
+type Sender interface {
+	Send(ctx context.Context, to []string, msg *Message, attachments ...Attachment) error
+}
+
+type smtpSender struct {
+	cfg       SMTPConfig
+	from      *netmail.Address
+	tlsConfig *tls.Config
+}
+
+func NewSender(cfg SMTPConfig) (Sender, error) {
+	switch cfg.TLSMode {
+	case "":
+		cfg.TLSMode = TLSModeStartTLS
+	case TLSModeStartTLS, TLSModeImplicit, TLSModeNone:
+	default:
+		return nil, ErrInvalidTLSMode
+	}
+
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+
+	if cfg.FromName == "" {
+		cfg.FromName = defaultFromName
+	}
+
+	from, err := netmail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, cfg.From)
+	}
+	from.Name = cfg.FromName
+
+	return &smtpSender{
+		cfg:  cfg,
+		from: from,
+		tlsConfig: &tls.Config{
+			ServerName: cfg.Host,
+			MinVersion: tls.VersionTLS12,
+		},
+	}, nil
+}
+
+func (s *smtpSender) Send(ctx context.Context, to []string, msg *Message, attachments ...Attachment) (err error) {
+	ctx, span := tracing.Start(ctx, "smtp.Send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("smtp.host", s.cfg.Host),
+			attribute.Int("smtp.recipients", len(to)),
+			attribute.Int("smtp.attachments", len(attachments)),
+		),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	data, err := Compose(s.from, to, msg, attachments...)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if s.cfg.TLSMode == TLSModeStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return ErrStartTLSUnsupported
+		}
+
+		if err = c.StartTLS(s.tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if s.cfg.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err = c.Mail(s.from.Address); err != nil {
+		return err
+	}
+
+	for _, addr := range to {
+		if err = c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// dial connects to the server, over TLS in the implicit mode. The connection is bound
+// to the context's deadline, or to sendTimeout if it has none.
+func (s *smtpSender) dial(ctx context.Context) (*smtp.Client, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(sendTimeout)
+	}
+
+	var (
+		dialer = &net.Dialer{Deadline: deadline}
+		addr   = net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	)
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if s.cfg.TLSMode == TLSModeImplicit {
+		tlsConn := tls.Client(conn, s.tlsConfig)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		conn = tlsConn
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,127 @@
+package mail
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"time-capsule/internal/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSender_Send(t *testing.T) {
+	serverTLS, pool := testCertificate(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	otel.SetTracerProvider(tracing.NewProvider(sdktrace.WithSyncer(exporter)))
+
+	tests := []struct {
+		name          string
+		tlsMode       string
+		serverTLS     bool
+		implicitTLS   bool
+		username      string
+		expectedTLS   bool
+		expectedAuth  string
+		expectedError error
+	}{
+		{
+			name:         "OK-StartTLS",
+			tlsMode:      TLSModeStartTLS,
+			serverTLS:    true,
+			username:     "user@example.com",
+			expectedTLS:  true,
+			expectedAuth: "\x00user@example.com\x00password",
+		},
+		{
+			name:         "OK-Implicit-TLS",
+			tlsMode:      TLSModeImplicit,
+			serverTLS:    true,
+			implicitTLS:  true,
+			username:     "user@example.com",
+			expectedTLS:  true,
+			expectedAuth: "\x00user@example.com\x00password",
+		},
+		{
+			name:        "OK-No-TLS",
+			tlsMode:     TLSModeNone,
+			expectedTLS: false,
+		},
+		{
+			name:          "StartTLS-Unsupported",
+			tlsMode:       TLSModeStartTLS,
+			username:      "user@example.com",
+			expectedError: ErrStartTLSUnsupported,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var server *testSMTPServer
+			if test.serverTLS {
+				server = newTestSMTPServer(t, serverTLS, test.implicitTLS)
+			} else {
+				server = newTestSMTPServer(t, nil, false)
+			}
+
+			host, port := server.Addr()
+
+			sender, err := NewSender(SMTPConfig{
+				Host:     host,
+				Port:     port,
+				Username: test.username,
+				Password: "password",
+				From:     "noreply@example.com",
+				TLSMode:  test.tlsMode,
+			})
+			require.NoError(t, err)
+
+			sender.(*smtpSender).tlsConfig.RootCAs = pool
+
+			exporter.Reset()
+
+			err = sender.Send(context.Background(), []string{"foo@example.com", "bar@example.com"}, &Message{
+				Subject: "Hello",
+				HTML:    "<p>Hello</p>",
+				Text:    "Hello",
+			})
+			assert.Equal(t, test.expectedError, err)
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "smtp.Send", spans[0].Name)
+
+			if test.expectedError != nil {
+				assert.Equal(t, codes.Error, spans[0].Status.Code)
+				assert.Empty(t, server.Messages())
+				return
+			}
+
+			messages := server.Messages()
+			require.Len(t, messages, 1)
+
+			msg := messages[0]
+			assert.Equal(t, "noreply@example.com", msg.From)
+			assert.Equal(t, []string{"foo@example.com", "bar@example.com"}, msg.To)
+			assert.Equal(t, test.expectedTLS, msg.TLS)
+			assert.Equal(t, test.expectedAuth, msg.Auth)
+			assert.True(t, strings.HasPrefix(msg.Data, `From: "Time Capsule" <noreply@example.com>`))
+		})
+	}
+}
+
+func TestNewSender(t *testing.T) {
+	_, err := NewSender(SMTPConfig{Host: "localhost", From: "noreply@example.com", TLSMode: "ssl"})
+	assert.Equal(t, ErrInvalidTLSMode, err)
+
+	_, err = NewSender(SMTPConfig{Host: "localhost"})
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
@@ -0,0 +1,214 @@
+package mail
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSMTPServer is an in-process SMTP stand-in that accepts every message it is sent.
+type testSMTPServer struct {
+	ln          net.Listener
+	tlsConfig   *tls.Config
+	implicitTLS bool
+
+	mu       sync.Mutex
+	messages []testSMTPMessage
+}
+
+type testSMTPMessage struct {
+	From     string
+	To       []string
+	Data     string
+	Auth     string
+	TLS      bool
+	Identity string
+}
+
+// newTestSMTPServer starts a server. With a tlsConfig it either advertises STARTTLS,
+// or only accepts TLS connections when implicitTLS is set.
+func newTestSMTPServer(t *testing.T, tlsConfig *tls.Config, implicitTLS bool) *testSMTPServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if implicitTLS {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+
+	s := &testSMTPServer{
+		ln:          ln,
+		tlsConfig:   tlsConfig,
+		implicitTLS: implicitTLS,
+	}
+
+	go s.serve()
+
+	t.Cleanup(func() {
+		ln.Close()
+	})
+
+	return s
+}
+
+func (s *testSMTPServer) Addr() (string, string) {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	return host, port
+}
+
+func (s *testSMTPServer) Messages() []testSMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]testSMTPMessage(nil), s.messages...)
+}
+
+func (s *testSMTPServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *testSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var (
+		tp  = textproto.NewConn(conn)
+		msg = testSMTPMessage{TLS: s.implicitTLS}
+	)
+
+	tp.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			msg.Identity = arg
+
+			ext := []string{"localhost"}
+			if s.tlsConfig != nil && !msg.TLS {
+				ext = append(ext, "STARTTLS")
+			}
+			ext = append(ext, "AUTH PLAIN")
+
+			for i, e := range ext {
+				sep := "-"
+				if i == len(ext)-1 {
+					sep = " "
+				}
+
+				tp.PrintfLine("250%s%s", sep, e)
+			}
+		case "STARTTLS":
+			tp.PrintfLine("220 ready to start TLS")
+
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err = tlsConn.Handshake(); err != nil {
+				return
+			}
+
+			conn = tlsConn
+			tp = textproto.NewConn(conn)
+			msg.TLS = true
+		case "AUTH":
+			_, initial, _ := strings.Cut(arg, " ")
+
+			auth, _ := base64.StdEncoding.DecodeString(initial)
+			msg.Auth = string(auth)
+
+			tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			msg.From = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			tp.PrintfLine("250 ok")
+		case "RCPT":
+			msg.To = append(msg.To, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+
+			tp.PrintfLine("250 queued")
+		case "RSET", "NOOP":
+			tp.PrintfLine("250 ok")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+// testCertificate creates a self-signed certificate for 127.0.0.1, returning the server
+// configuration and a pool that trusts it.
+func testCertificate(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}, pool
+}
@@ -0,0 +1,82 @@
+package mail
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+)
+
+const thumbnailQuality = 80
+
+// Thumbnail decodes a JPEG or PNG image and re-encodes it as a JPEG that fits into a maxSize square,
+// keeping the aspect ratio. Smaller images are only re-encoded.
+func Thumbnail(data []byte, maxSize int) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		bounds = src.Bounds()
+		width  = bounds.Dx()
+		height = bounds.Dy()
+	)
+
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			width, height = maxSize, max(1, height*maxSize/width)
+		} else {
+			width, height = max(1, width*maxSize/height), maxSize
+		}
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	var buf bytes.Buffer
+	if err = jpeg.Encode(&buf, downscale(rgba, width, height), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// downscale resizes the image by averaging the source pixels covered by each destination pixel.
+func downscale(src *image.RGBA, width, height int) *image.RGBA {
+	var (
+		srcW = src.Bounds().Dx()
+		srcH = src.Bounds().Dy()
+		dst  = image.NewRGBA(image.Rect(0, 0, width, height))
+	)
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcH/height, max((y+1)*srcH/height, y*srcH/height+1)
+
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcW/width, max((x+1)*srcW/width, x*srcW/width+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(row[sx*4+c])
+					}
+				}
+			}
+
+			var (
+				n = (y1 - y0) * (x1 - x0)
+				i = y*dst.Stride + x*4
+			)
+
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name           string
+		width, height  int
+		maxSize        int
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{
+			name:           "Landscape",
+			width:          1000,
+			height:         500,
+			maxSize:        200,
+			expectedWidth:  200,
+			expectedHeight: 100,
+		},
+		{
+			name:           "Portrait",
+			width:          300,
+			height:         900,
+			maxSize:        300,
+			expectedWidth:  100,
+			expectedHeight: 300,
+		},
+		{
+			name:           "Small",
+			width:          50,
+			height:         40,
+			maxSize:        200,
+			expectedWidth:  50,
+			expectedHeight: 40,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, test.width, test.height))
+			for y := 0; y < test.height; y++ {
+				for x := 0; x < test.width; x++ {
+					img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+				}
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, png.Encode(&buf, img))
+
+			data, err := Thumbnail(buf.Bytes(), test.maxSize)
+			require.NoError(t, err)
+
+			thumbnail, err := jpeg.Decode(bytes.NewReader(data))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedWidth, thumbnail.Bounds().Dx())
+			assert.Equal(t, test.expectedHeight, thumbnail.Bounds().Dy())
+
+			r, g, b, _ := thumbnail.At(test.expectedWidth/2, test.expectedHeight/2).RGBA()
+			assert.InDelta(t, 200, r>>8, 8)
+			assert.InDelta(t, 100, g>>8, 8)
+			assert.InDelta(t, 50, b>>8, 8)
+		})
+	}
+}
+
+func TestThumbnail_InvalidImage(t *testing.T) {
+	_, err := Thumbnail([]byte("not an image"), 100)
+	assert.Error(t, err)
+}
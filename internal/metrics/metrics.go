@@ -0,0 +1,157 @@
+// Package metrics holds the Prometheus metrics of the service, exposed at /metrics.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+const namespace = "time_capsule"
+
+// Notification channels and results.
+const (
+	ChannelEmail = "email"
+	ChannelInApp = "in_app"
+
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Registry is the registry the metrics are registered with, along with the Go runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts the served requests by method, route and status.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests served, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes how long requests take to be served by method and route.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// RateLimitRejections counts the requests rejected by the rate limiter by policy.
+	RateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Number of requests rejected by the rate limiter, by policy.",
+	}, []string{"policy"})
+
+	// WorkerCycleDuration observes how long the worker's cycles take.
+	WorkerCycleDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "worker_cycle_duration_seconds",
+		Help:      "Time taken by the worker's cycles.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	})
+
+	// DueCapsules is the number of due capsules found by the worker's last cycle.
+	DueCapsules = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "due_capsules",
+		Help:      "Number of due capsules waiting to be opened, as of the worker's last cycle.",
+	})
+
+	// Notifications counts the notifications delivered by channel and result.
+	Notifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_total",
+		Help:      "Number of notifications delivered, by channel and result.",
+	}, []string{"channel", "result"})
+
+	// StorageOperationDuration observes how long object storage operations take by operation.
+	StorageOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "Time taken by object storage operations, by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	// StorageOperationErrors counts the failed object storage operations by operation.
+	StorageOperationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_errors_total",
+		Help:      "Number of failed object storage operations, by operation.",
+	}, []string{"operation"})
+
+	// MongoCommandDuration observes how long MongoDB commands take by command and result.
+	MongoCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_command_duration_seconds",
+		Help:      "Time taken by MongoDB commands, by command and result.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"command", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		RateLimitRejections,
+		WorkerCycleDuration,
+		DueCapsules,
+		Notifications,
+		StorageOperationDuration,
+		StorageOperationErrors,
+		MongoCommandDuration,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a served request of the route, the path pattern it was registered with.
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	HTTPRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	HTTPRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveNotification records a notification delivered through the channel.
+func ObserveNotification(channel string, err error) {
+	Notifications.WithLabelValues(channel, result(err)).Inc()
+}
+
+// ObserveStorage records an object storage operation that started at start.
+func ObserveStorage(operation string, start time.Time, err error) {
+	StorageOperationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		StorageOperationErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// MongoMonitor returns a command monitor timing the commands of a MongoDB client.
+func MongoMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			MongoCommandDuration.WithLabelValues(e.CommandName, ResultSuccess).Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			MongoCommandDuration.WithLabelValues(e.CommandName, ResultFailure).Observe(e.Duration.Seconds())
+		},
+	}
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+
+	return ResultSuccess
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	return w.Body.String()
+}
+
+func TestHandler(t *testing.T) {
+	ObserveNotification(ChannelEmail, nil)
+	ObserveNotification(ChannelEmail, errors.New("some error"))
+	ObserveNotification(ChannelEmail, errors.New("some error"))
+
+	ObserveStorage("get", time.Now(), nil)
+	ObserveStorage("get", time.Now(), errors.New("some error"))
+
+	body := scrape(t)
+
+	assert.Contains(t, body, `time_capsule_notifications_total{channel="email",result="success"} 1`)
+	assert.Contains(t, body, `time_capsule_notifications_total{channel="email",result="failure"} 2`)
+	assert.Contains(t, body, `time_capsule_storage_operation_duration_seconds_count{operation="get"} 2`)
+	assert.Contains(t, body, `time_capsule_storage_operation_errors_total{operation="get"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestMongoMonitor(t *testing.T) {
+	monitor := MongoMonitor()
+
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", Duration: time.Millisecond},
+	})
+	monitor.Failed(context.Background(), &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", Duration: time.Millisecond},
+	})
+
+	body := scrape(t)
+
+	assert.Contains(t, body, `time_capsule_mongo_command_duration_seconds_count{command="find",result="success"} 1`)
+	assert.Contains(t, body, `time_capsule_mongo_command_duration_seconds_count{command="insert",result="failure"} 1`)
+}
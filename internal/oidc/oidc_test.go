@@ -0,0 +1,103 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"time-capsule/internal/oidc"
+	"time-capsule/internal/oidc/oidctest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURI = "https://time-capsule.example.com/api/v1/oidc/mock/callback"
+
+func TestProvider_Flow(t *testing.T) {
+	server := oidctest.NewServer()
+	defer server.Close()
+
+	user := oidctest.User{
+		Subject:       "some-subject",
+		Email:         "Foo@Example.com",
+		EmailVerified: true,
+		Name:          "John Doe",
+	}
+
+	tests := []struct {
+		name          string
+		nonce         string
+		verifier      func(verifier string) string
+		expectedError error
+	}{
+		{
+			name:          "OK",
+			verifier:      func(verifier string) string { return verifier },
+			expectedError: nil,
+		},
+		{
+			name:          "Wrong-Verifier",
+			verifier:      func(verifier string) string { return verifier + "x" },
+			expectedError: oidc.ErrExchange,
+		},
+		{
+			name:          "Wrong-Nonce",
+			nonce:         "other-nonce",
+			verifier:      func(verifier string) string { return verifier },
+			expectedError: oidc.ErrInvalidToken,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				ctx      = context.Background()
+				provider = oidc.NewProvider(server.Config("mock"), http.DefaultClient)
+			)
+
+			verifier, err := oidc.RandomString()
+			require.NoError(t, err)
+
+			authURL, err := provider.AuthCodeURL(ctx, redirectURI, "some-state", "some-nonce", verifier)
+			require.NoError(t, err)
+
+			redirect, err := server.Authorize(authURL, user)
+			require.NoError(t, err)
+			assert.Equal(t, "some-state", redirect.Query().Get("state"))
+
+			nonce := "some-nonce"
+			if test.nonce != "" {
+				nonce = test.nonce
+			}
+
+			claims, err := provider.Exchange(ctx, redirectURI, redirect.Query().Get("code"), test.verifier(verifier), nonce)
+			assert.ErrorIs(t, err, test.expectedError)
+
+			if test.expectedError == nil {
+				assert.Equal(t, &oidc.Claims{
+					Subject:       "some-subject",
+					Email:         "Foo@Example.com",
+					EmailVerified: true,
+					Name:          "John Doe",
+				}, claims)
+			}
+		})
+	}
+}
+
+func TestProvider_Discovery(t *testing.T) {
+	server := oidctest.NewServer()
+	defer server.Close()
+
+	cfg := server.Config("mock")
+	cfg.Issuer = server.URL + "/other"
+
+	_, err := oidc.NewProvider(cfg, http.DefaultClient).AuthCodeURL(context.Background(), redirectURI, "state", "nonce", "verifier")
+	assert.ErrorIs(t, err, oidc.ErrDiscovery)
+}
+
+func TestChallenge(t *testing.T) {
+	// BASE64URL(SHA256(verifier)), without padding.
+	assert.Equal(t, "iyZXJdphBNaz1hxBWumOhlv8YOF8q_tHqRnqw8Sh124", oidc.Challenge("dBjftJeZ4CVP-mB92K9uhqVvwkA4EiXqR_XiEj4VODw"))
+}
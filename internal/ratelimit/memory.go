@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the memory store evicts the counters of past windows.
+const sweepInterval = time.Minute
+
+type counter struct {
+	hits      int
+	expiresAt time.Time
+}
+
+// MemoryStore keeps the counters in memory, so they're local to the instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]*counter
+	nextSweep time.Time
+}
+
+func NewMemoryStore() Store {
+	return &MemoryStore{
+		counters: make(map[string]*counter),
+	}
+}
+
+func (s *MemoryStore) Hit(_ context.Context, key string, expiresAt time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if !now.Before(s.nextSweep) {
+		s.sweep(now)
+	}
+
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.expiresAt) {
+		c = &counter{expiresAt: expiresAt}
+		s.counters[key] = c
+	}
+
+	c.hits++
+
+	return c.hits, nil
+}
+
+func (s *MemoryStore) DeletePrefix(_ context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.counters {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.counters, key)
+		}
+	}
+
+	return nil
+}
+
+// sweep evicts the expired counters, i.e. the ones of keys that were idle since their window ended.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, c := range s.counters {
+		if !now.Before(c.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+
+	s.nextSweep = now.Add(sweepInterval)
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	rateLimitCollection = "rateLimits"
+	// indexTimeout bounds the creation of the TTL index.
+	indexTimeout = 30 * time.Second
+)
+
+// MongoStore keeps the counters in MongoDB, so that limits are shared by every instance.
+// Expired counters are evicted by a TTL index.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoStore(db *mongo.Database) Store {
+	ctx, cancel := context.WithTimeout(context.Background(), indexTimeout)
+	defer cancel()
+
+	// Without the TTL index counters are never evicted, so a failure is logged as an error.
+	if _, err := db.Collection(rateLimitCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"expiresAt": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}); err != nil {
+		slog.Error("failed to create the indexes of a collection", "collection", rateLimitCollection, "error", err)
+	}
+
+	return &MongoStore{
+		collection: db.Collection(rateLimitCollection),
+	}
+}
+
+func (s *MongoStore) Hit(ctx context.Context, key string, expiresAt time.Time) (int, error) {
+	var (
+		res struct {
+			Hits int `bson:"hits"`
+		}
+		err error
+	)
+
+	// Concurrent upserts of a new key may race, the loser retries and increments the winner's counter.
+	for attempt := 0; attempt < 2; attempt++ {
+		err = s.collection.FindOneAndUpdate(ctx,
+			bson.M{"_id": key},
+			bson.M{
+				"$inc":         bson.M{"hits": 1},
+				"$setOnInsert": bson.M{"expiresAt": expiresAt},
+			},
+			options.FindOneAndUpdate().
+				SetUpsert(true).
+				SetReturnDocument(options.After),
+		).Decode(&res)
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Hits, nil
+}
+
+func (s *MongoStore) DeletePrefix(ctx context.Context, prefix string) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{
+		"_id": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)},
+	})
+
+	return err
+}
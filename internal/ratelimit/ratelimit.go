@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	StoreMemory  = "memory"
+	StoreMongoDB = "mongodb"
+)
+
+var ErrUnknownStore = fmt.Errorf("rate limit store must be %q or %q", StoreMemory, StoreMongoDB)
+
+// Policy allows Limit requests per Window to every key. Name separates the counters of policies
+// that apply to the same key, e.g. different routes requested by the same user.
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Result is the state of a key's counter after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is when the current window ends and the counter starts over.
+	Reset time.Time
+}
+
+// RetryAfter returns how long to wait until requests are allowed again.
+func (r Result) RetryAfter(now time.Time) time.Duration {
+	return max(r.Reset.Sub(now), 0)
+}
+
+// Store counts the hits of keys. Counters only live until the end of their window,
+// once it's over they can be evicted.
+type Store interface {
+	// Hit increments the counter of key, which is created to last until expiresAt, and returns its new value.
+	Hit(ctx context.Context, key string, expiresAt time.Time) (int, error)
+	// DeletePrefix deletes the counters of the keys starting with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// UserKey is the key requests of the user are limited under.
+func UserKey(userID string) string {
+	return "user:" + userID
+}
+
+// IPKey is the key requests of the client IP are limited under.
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Limiter limits requests in fixed windows, counted in a Store that may be shared between instances.
+type Limiter struct {
+	store Store
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{
+		store: store,
+	}
+}
+
+// Allow records a request of key under the policy and reports whether it's within the limit.
+func (l *Limiter) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	if policy.Limit <= 0 || policy.Window <= 0 {
+		return Result{}, errors.New("rate limit policy must have a positive limit and window")
+	}
+
+	var (
+		now   = time.Now()
+		start = now.Truncate(policy.Window)
+		reset = start.Add(policy.Window)
+	)
+
+	// Counters are identified by key first, so that Forget can find every counter of a key.
+	count, err := l.store.Hit(ctx, fmt.Sprintf("%s:%s:%d", key, policy.Name, start.Unix()), reset)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:   count <= policy.Limit,
+		Limit:     policy.Limit,
+		Remaining: max(policy.Limit-count, 0),
+		Reset:     reset,
+	}, nil
+}
+
+// Forget deletes the counters of key under every policy, e.g. when the user it belongs to is deleted.
+func (l *Limiter) Forget(ctx context.Context, key string) error {
+	return l.store.DeletePrefix(ctx, key+":")
+}
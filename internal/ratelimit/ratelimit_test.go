@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingStore struct{}
+
+func (failingStore) Hit(context.Context, string, time.Time) (int, error) {
+	return 0, errors.New("some error")
+}
+
+func (failingStore) DeletePrefix(context.Context, string) error {
+	return errors.New("some error")
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 30, 0, time.UTC)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return now })
+	defer patches.Reset()
+
+	var (
+		limiter = NewLimiter(NewMemoryStore())
+		policy  = Policy{Name: "test", Limit: 2, Window: time.Minute}
+		reset   = time.Date(2024, time.January, 1, 12, 1, 0, 0, time.UTC)
+		ctx     = context.Background()
+	)
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		key      string
+		expected Result
+	}{
+		{
+			name:     "First",
+			policy:   policy,
+			key:      "alice",
+			expected: Result{Allowed: true, Limit: 2, Remaining: 1, Reset: reset},
+		},
+		{
+			name:     "Last",
+			policy:   policy,
+			key:      "alice",
+			expected: Result{Allowed: true, Limit: 2, Remaining: 0, Reset: reset},
+		},
+		{
+			name:     "Exceeded",
+			policy:   policy,
+			key:      "alice",
+			expected: Result{Allowed: false, Limit: 2, Remaining: 0, Reset: reset},
+		},
+		{
+			name:     "Other-Key",
+			policy:   policy,
+			key:      "bob",
+			expected: Result{Allowed: true, Limit: 2, Remaining: 1, Reset: reset},
+		},
+		{
+			name:     "Other-Policy",
+			policy:   Policy{Name: "other", Limit: 5, Window: time.Hour},
+			key:      "alice",
+			expected: Result{Allowed: true, Limit: 5, Remaining: 4, Reset: time.Date(2024, time.January, 1, 13, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := limiter.Allow(ctx, test.policy, test.key)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+
+	t.Run("Next-Window", func(t *testing.T) {
+		now = reset
+
+		res, err := limiter.Allow(ctx, policy, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: reset.Add(time.Minute)}, res)
+	})
+}
+
+func TestLimiter_Allow_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewLimiter(NewMemoryStore()).Allow(ctx, Policy{Name: "test"}, "alice")
+	assert.Error(t, err)
+
+	_, err = NewLimiter(failingStore{}).Allow(ctx, Policy{Name: "test", Limit: 1, Window: time.Second}, "alice")
+	assert.EqualError(t, err, "some error")
+}
+
+func TestLimiter_Forget(t *testing.T) {
+	var (
+		limiter = NewLimiter(NewMemoryStore())
+		policy  = Policy{Name: "test", Limit: 1, Window: time.Hour}
+		other   = Policy{Name: "other", Limit: 1, Window: time.Hour}
+		ctx     = context.Background()
+	)
+
+	for _, key := range []string{"user:alice", "user:alice2"} {
+		for _, p := range []Policy{policy, other} {
+			_, err := limiter.Allow(ctx, p, key)
+			require.NoError(t, err)
+		}
+	}
+
+	require.NoError(t, limiter.Forget(ctx, "user:alice"))
+
+	for _, p := range []Policy{policy, other} {
+		res, err := limiter.Allow(ctx, p, "user:alice")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+
+		res, err = limiter.Allow(ctx, p, "user:alice2")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+	}
+}
+
+func TestResult_RetryAfter(t *testing.T) {
+	now := time.Unix(100, 0)
+
+	assert.Equal(t, 20*time.Second, Result{Reset: time.Unix(120, 0)}.RetryAfter(now))
+	assert.Equal(t, time.Duration(0), Result{Reset: time.Unix(90, 0)}.RetryAfter(now))
+}
+
+func TestMemoryStore_Eviction(t *testing.T) {
+	now := time.Unix(0, 0)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return now })
+	defer patches.Reset()
+
+	var (
+		store = NewMemoryStore().(*MemoryStore)
+		ctx   = context.Background()
+	)
+
+	_, _ = store.Hit(ctx, "idle", now.Add(time.Second))
+	_, _ = store.Hit(ctx, "active", now.Add(time.Hour))
+	assert.Len(t, store.counters, 2)
+
+	// Expired counters are kept until the next sweep.
+	now = now.Add(2 * time.Second)
+	_, _ = store.Hit(ctx, "active", now.Add(time.Hour))
+	assert.Len(t, store.counters, 2)
+
+	now = now.Add(sweepInterval)
+	hits, _ := store.Hit(ctx, "active", now.Add(time.Hour))
+	assert.Equal(t, 3, hits)
+	assert.Len(t, store.counters, 1)
+	assert.Contains(t, store.counters, "active")
+}
@@ -0,0 +1,183 @@
+// Package recurrence computes occurrences of repeating capsule open schedules.
+// It understands a subset of RFC 5545 RRULEs: FREQ (YEARLY, MONTHLY, WEEKLY), INTERVAL, COUNT and UNTIL.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"time-capsule/internal/domain"
+)
+
+const (
+	Yearly  = "yearly"
+	Monthly = "monthly"
+	Weekly  = "weekly"
+
+	maxInterval = 100
+	maxCount    = 100
+)
+
+var (
+	ErrUnsupportedFrequency = errors.New("frequency must be one of yearly, monthly or weekly")
+	ErrInvalidInterval      = fmt.Errorf("interval must be between 1 and %d", maxInterval)
+	ErrInvalidCount         = fmt.Errorf("count must be between 0 and %d", maxCount)
+	ErrInvalidUntil         = errors.New("until must be after the first occurrence")
+)
+
+var untilLayouts = []string{"20060102T150405Z", "20060102"}
+
+// Parse converts an RRULE string (with or without the "RRULE:" prefix) into a Recurrence.
+func Parse(rrule string) (*domain.Recurrence, error) {
+	rec := &domain.Recurrence{}
+
+	rrule = strings.TrimPrefix(strings.TrimSpace(rrule), "RRULE:")
+
+	for _, part := range strings.Split(rrule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed rrule part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rec.Frequency = strings.ToLower(value)
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, ErrInvalidInterval
+			}
+			rec.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, ErrInvalidCount
+			}
+			rec.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			rec.Until = &until
+		default:
+			return nil, fmt.Errorf("unsupported rrule part %q", key)
+		}
+	}
+
+	return rec, nil
+}
+
+// Normalize parses the RRULE of rec if it has one, fills in defaults and validates the result
+// against the first occurrence at start.
+func Normalize(rec *domain.Recurrence, start time.Time) (*domain.Recurrence, error) {
+	if rec.RRule != "" {
+		parsed, err := Parse(rec.RRule)
+		if err != nil {
+			return nil, err
+		}
+
+		rec = parsed
+	}
+
+	res := *rec
+	res.Frequency = strings.ToLower(res.Frequency)
+	res.RRule = ""
+
+	if res.Interval == 0 {
+		res.Interval = 1
+	}
+
+	if res.Until != nil {
+		until := res.Until.UTC()
+		res.Until = &until
+	}
+
+	switch res.Frequency {
+	case Yearly, Monthly, Weekly:
+	default:
+		return nil, ErrUnsupportedFrequency
+	}
+
+	if res.Interval < 1 || res.Interval > maxInterval {
+		return nil, ErrInvalidInterval
+	}
+
+	if res.Count < 0 || res.Count > maxCount {
+		return nil, ErrInvalidCount
+	}
+
+	if res.Until != nil && !res.Until.After(start) {
+		return nil, ErrInvalidUntil
+	}
+
+	return &res, nil
+}
+
+// Occurrence returns the n-th (zero-based) occurrence of the schedule starting at start.
+// The second return value is false once the schedule is exhausted by COUNT or UNTIL.
+func Occurrence(rec domain.Recurrence, start time.Time, n int) (time.Time, bool) {
+	if rec.Count > 0 && n >= rec.Count {
+		return time.Time{}, false
+	}
+
+	interval := max(rec.Interval, 1)
+
+	var at time.Time
+
+	switch rec.Frequency {
+	case Yearly:
+		at = addMonths(start, 12*interval*n)
+	case Monthly:
+		at = addMonths(start, interval*n)
+	case Weekly:
+		at = start.AddDate(0, 0, 7*interval*n)
+	default:
+		return time.Time{}, false
+	}
+
+	if rec.Until != nil && at.After(*rec.Until) {
+		return time.Time{}, false
+	}
+
+	return at, true
+}
+
+// NextAfter returns the index and time of the first occurrence strictly after t,
+// starting the search at occurrence n.
+func NextAfter(rec domain.Recurrence, start time.Time, n int, t time.Time) (int, time.Time, bool) {
+	for ; ; n++ {
+		at, ok := Occurrence(rec, start, n)
+		if !ok {
+			return n, time.Time{}, false
+		}
+
+		if at.After(t) {
+			return n, at, true
+		}
+	}
+}
+
+// addMonths adds months to t, clamping the day to the end of the target month
+// so that e.g. a Feb 29 birthday falls on Feb 28 in common years.
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+
+	return first.AddDate(0, 0, min(day, last)-1)
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range untilLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("malformed rrule until %q", value)
+}
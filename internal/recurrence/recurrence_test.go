@@ -0,0 +1,212 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurrence_Parse(t *testing.T) {
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		rrule         string
+		expected      *domain.Recurrence
+		expectedError bool
+	}{
+		{
+			name:  "Yearly-Count",
+			rrule: "FREQ=YEARLY;COUNT=10",
+			expected: &domain.Recurrence{
+				Frequency: Yearly,
+				Count:     10,
+			},
+		},
+		{
+			name:  "Prefix-Interval-Until",
+			rrule: "RRULE:FREQ=MONTHLY;INTERVAL=3;UNTIL=20300101",
+			expected: &domain.Recurrence{
+				Frequency: Monthly,
+				Interval:  3,
+				Until:     &until,
+			},
+		},
+		{
+			name:          "Unsupported-Part",
+			rrule:         "FREQ=YEARLY;BYMONTH=1",
+			expectedError: true,
+		},
+		{
+			name:          "Malformed",
+			rrule:         "FREQ",
+			expectedError: true,
+		},
+		{
+			name:          "Malformed-Until",
+			rrule:         "FREQ=YEARLY;UNTIL=tomorrow",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec, err := Parse(test.rrule)
+
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, rec)
+		})
+	}
+}
+
+func TestRecurrence_Normalize(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	past := start.Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		input         *domain.Recurrence
+		expected      *domain.Recurrence
+		expectedError error
+	}{
+		{
+			name:     "Defaults",
+			input:    &domain.Recurrence{Frequency: "Yearly"},
+			expected: &domain.Recurrence{Frequency: Yearly, Interval: 1},
+		},
+		{
+			name:     "RRule",
+			input:    &domain.Recurrence{RRule: "FREQ=WEEKLY;INTERVAL=2;COUNT=5"},
+			expected: &domain.Recurrence{Frequency: Weekly, Interval: 2, Count: 5},
+		},
+		{
+			name:          "Unsupported-Frequency",
+			input:         &domain.Recurrence{Frequency: "daily"},
+			expectedError: ErrUnsupportedFrequency,
+		},
+		{
+			name:          "Invalid-Interval",
+			input:         &domain.Recurrence{Frequency: Yearly, Interval: maxInterval + 1},
+			expectedError: ErrInvalidInterval,
+		},
+		{
+			name:          "Invalid-Count",
+			input:         &domain.Recurrence{Frequency: Yearly, Count: -1},
+			expectedError: ErrInvalidCount,
+		},
+		{
+			name:          "Until-Before-Start",
+			input:         &domain.Recurrence{Frequency: Yearly, Until: &past},
+			expectedError: ErrInvalidUntil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec, err := Normalize(test.input, start)
+
+			assert.Equal(t, test.expectedError, err)
+			assert.Equal(t, test.expected, rec)
+		})
+	}
+}
+
+func TestRecurrence_Occurrence(t *testing.T) {
+	leapDay := time.Date(2024, 2, 29, 9, 30, 0, 0, time.UTC)
+	until := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		recurrence domain.Recurrence
+		start      time.Time
+		n          int
+		expected   time.Time
+		expectedOk bool
+	}{
+		{
+			name:       "First",
+			recurrence: domain.Recurrence{Frequency: Yearly, Interval: 1},
+			start:      leapDay,
+			n:          0,
+			expected:   leapDay,
+			expectedOk: true,
+		},
+		{
+			name:       "Yearly-Clamps-Leap-Day",
+			recurrence: domain.Recurrence{Frequency: Yearly, Interval: 1},
+			start:      leapDay,
+			n:          1,
+			expected:   time.Date(2025, 2, 28, 9, 30, 0, 0, time.UTC),
+			expectedOk: true,
+		},
+		{
+			name:       "Every-N-Years",
+			recurrence: domain.Recurrence{Frequency: Yearly, Interval: 4},
+			start:      leapDay,
+			n:          1,
+			expected:   time.Date(2028, 2, 29, 9, 30, 0, 0, time.UTC),
+			expectedOk: true,
+		},
+		{
+			name:       "Monthly-Clamps-Month-End",
+			recurrence: domain.Recurrence{Frequency: Monthly, Interval: 1},
+			start:      time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			n:          1,
+			expected:   time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			expectedOk: true,
+		},
+		{
+			name:       "Weekly",
+			recurrence: domain.Recurrence{Frequency: Weekly, Interval: 2},
+			start:      leapDay,
+			n:          2,
+			expected:   time.Date(2024, 3, 28, 9, 30, 0, 0, time.UTC),
+			expectedOk: true,
+		},
+		{
+			name:       "Count-Exhausted",
+			recurrence: domain.Recurrence{Frequency: Yearly, Interval: 1, Count: 10},
+			start:      leapDay,
+			n:          10,
+			expectedOk: false,
+		},
+		{
+			name:       "Until-Exhausted",
+			recurrence: domain.Recurrence{Frequency: Yearly, Interval: 1, Until: &until},
+			start:      leapDay,
+			n:          3,
+			expectedOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			at, ok := Occurrence(test.recurrence, test.start, test.n)
+
+			assert.Equal(t, test.expectedOk, ok)
+			assert.Equal(t, test.expected, at)
+		})
+	}
+}
+
+func TestRecurrence_NextAfter(t *testing.T) {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	rec := domain.Recurrence{Frequency: Yearly, Interval: 1, Count: 10}
+
+	n, at, ok := NextAfter(rec, start, 1, time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), at)
+
+	n, _, ok = NextAfter(rec, start, 1, time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+	assert.Equal(t, 10, n)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"time-capsule/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const auditCollection = "auditEvents"
+
+type MongoAuditRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAuditRepository(db *mongo.Database) AuditRepository {
+	createIndexes(db.Collection(auditCollection), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "userID", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "actorID", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys: bson.M{"requestID": 1},
+		},
+	})
+
+	return &MongoAuditRepository{
+		collection: db.Collection(auditCollection),
+	}
+}
+
+func (r *MongoAuditRepository) InsertAuditEvent(ctx context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+	res, err := r.collection.InsertOne(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	event.ID = res.InsertedID.(primitive.ObjectID)
+
+	return event, nil
+}
+
+func (r *MongoAuditRepository) GetAuditEvents(ctx context.Context, filter bson.M, limit int64) ([]*domain.AuditEvent, error) {
+	cur, err := r.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.M{"_id": -1}).
+		SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*domain.AuditEvent
+	if err := cur.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
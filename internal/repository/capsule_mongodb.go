@@ -8,6 +8,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const capsulesCollection = "capsules"
@@ -17,6 +18,12 @@ type MongoCapsuleRepository struct {
 }
 
 func NewMongoCapsuleRepository(db *mongo.Database) CapsuleRepository {
+	createIndexes(db.Collection(capsulesCollection), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "message", Value: "text"}},
+		},
+	})
+
 	return &MongoCapsuleRepository{
 		collection: db.Collection(capsulesCollection),
 	}
@@ -57,14 +64,90 @@ func (r *MongoCapsuleRepository) GetCapsules(ctx context.Context, filter bson.M)
 	return capsules, nil
 }
 
+func (r *MongoCapsuleRepository) CountCapsules(ctx context.Context, filter bson.M) (int64, error) {
+	return r.collection.CountDocuments(ctx, filter)
+}
+
 func (r *MongoCapsuleRepository) UpdateCapsule(ctx context.Context, id primitive.ObjectID, update bson.M) error {
 	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
 
 	return err
 }
 
+// UpdateCapsules applies update, an update document or an aggregation pipeline, to the capsules matching filter.
+func (r *MongoCapsuleRepository) UpdateCapsules(ctx context.Context, filter bson.M, update any) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.MatchedCount, nil
+}
+
 func (r *MongoCapsuleRepository) DeleteCapsule(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 
 	return err
 }
+
+type scoredCapsule struct {
+	domain.Capsule `bson:",inline"`
+	Score          float64 `bson:"score"`
+}
+
+func (r *MongoCapsuleRepository) SearchCapsules(ctx context.Context, filter bson.M, query string, limit int64) ([]*domain.CapsuleSearchResult, error) {
+	textFilter := bson.M{"$text": bson.M{"$search": query}}
+	for k, v := range filter {
+		textFilter[k] = v
+	}
+
+	score := bson.M{"score": bson.M{"$meta": "textScore"}}
+
+	opts := options.Find().
+		SetProjection(score).
+		SetSort(score).
+		SetLimit(limit)
+
+	cur, err := r.collection.Find(ctx, textFilter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var scored []*scoredCapsule
+	if err := cur.All(ctx, &scored); err != nil {
+		return nil, err
+	}
+
+	results := make([]*domain.CapsuleSearchResult, 0, len(scored))
+	for _, sc := range scored {
+		capsule := sc.Capsule
+
+		results = append(results, &domain.CapsuleSearchResult{
+			Capsule: &capsule,
+			Score:   sc.Score,
+		})
+	}
+
+	return results, nil
+}
+
+func (r *MongoCapsuleRepository) CountTags(ctx context.Context, filter bson.M) ([]*domain.TagCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	cur, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []*domain.TagCount
+	if err := cur.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"time-capsule/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const collectionsCollection = "collections"
+
+type MongoCollectionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoCollectionRepository(db *mongo.Database) CollectionRepository {
+	createIndexes(db.Collection(collectionsCollection), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+
+	return &MongoCollectionRepository{
+		collection: db.Collection(collectionsCollection),
+	}
+}
+
+func (r *MongoCollectionRepository) InsertCollection(ctx context.Context, collection *domain.Collection) (*domain.Collection, error) {
+	res, err := r.collection.InsertOne(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	collection.ID = res.InsertedID.(primitive.ObjectID)
+
+	return collection, nil
+}
+
+func (r *MongoCollectionRepository) GetCollection(ctx context.Context, filter bson.M) (*domain.Collection, error) {
+	var collection domain.Collection
+
+	if err := r.collection.FindOne(ctx, filter).Decode(&collection); err != nil {
+		return nil, err
+	}
+
+	return &collection, nil
+}
+
+func (r *MongoCollectionRepository) GetCollections(ctx context.Context, filter bson.M) ([]*domain.Collection, error) {
+	cur, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var collections []*domain.Collection
+	if err := cur.All(ctx, &collections); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+func (r *MongoCollectionRepository) UpdateCollection(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+
+	return err
+}
+
+func (r *MongoCollectionRepository) DeleteCollection(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+
+	return err
+}
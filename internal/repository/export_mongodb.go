@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"time-capsule/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const exportsCollection = "exports"
+
+type MongoExportRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoExportRepository(db *mongo.Database) ExportRepository {
+	createIndexes(db.Collection(exportsCollection), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "userID", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys:    bson.M{"downloadTokenHash": 1},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+
+	return &MongoExportRepository{
+		collection: db.Collection(exportsCollection),
+	}
+}
+
+func (r *MongoExportRepository) InsertExport(ctx context.Context, export *domain.Export) (*domain.Export, error) {
+	res, err := r.collection.InsertOne(ctx, export)
+	if err != nil {
+		return nil, err
+	}
+
+	export.ID = res.InsertedID.(primitive.ObjectID)
+
+	return export, nil
+}
+
+func (r *MongoExportRepository) GetExport(ctx context.Context, filter bson.M) (*domain.Export, error) {
+	var export domain.Export
+
+	if err := r.collection.FindOne(ctx, filter).Decode(&export); err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *MongoExportRepository) GetExports(ctx context.Context, filter bson.M) ([]*domain.Export, error) {
+	cur, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var exports []*domain.Export
+	if err := cur.All(ctx, &exports); err != nil {
+		return nil, err
+	}
+
+	return exports, nil
+}
+
+func (r *MongoExportRepository) CountExports(ctx context.Context, filter bson.M) (int64, error) {
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+// ClaimExport picks the pending export that is due the longest and postpones it by lease,
+// so that concurrent workers don't build it twice. It returns mongo.ErrNoDocuments when nothing is due.
+func (r *MongoExportRepository) ClaimExport(ctx context.Context, now time.Time, lease time.Duration) (*domain.Export, error) {
+	var export domain.Export
+
+	if err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"status":        domain.ExportStatusPending,
+			"nextAttemptAt": bson.M{"$lte": now},
+		},
+		bson.M{
+			"$set": bson.M{
+				"nextAttemptAt": now.Add(lease),
+			},
+			"$inc": bson.M{
+				"attempts": 1,
+			},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.M{"nextAttemptAt": 1}).
+			SetReturnDocument(options.After),
+	).Decode(&export); err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *MongoExportRepository) UpdateExport(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+
+	return err
+}
+
+func (r *MongoExportRepository) DeleteExports(ctx context.Context, filter bson.M) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.DeletedCount, nil
+}
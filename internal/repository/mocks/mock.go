@@ -7,6 +7,7 @@ package mock_repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 	domain "time-capsule/internal/domain"
 
 	bson "go.mongodb.org/mongo-driver/bson"
@@ -37,6 +38,20 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// DeleteUser mocks base method.
+func (m *MockUserRepository) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserRepositoryMockRecorder) DeleteUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepository)(nil).DeleteUser), ctx, id)
+}
+
 // GetUser mocks base method.
 func (m *MockUserRepository) GetUser(ctx context.Context, filter bson.M) (*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -52,6 +67,21 @@ func (mr *MockUserRepositoryMockRecorder) GetUser(ctx, filter interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserRepository)(nil).GetUser), ctx, filter)
 }
 
+// GetUsers mocks base method.
+func (m *MockUserRepository) GetUsers(ctx context.Context, filter bson.M) ([]*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx, filter)
+	ret0, _ := ret[0].([]*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockUserRepositoryMockRecorder) GetUsers(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserRepository)(nil).GetUsers), ctx, filter)
+}
+
 // InsertUser mocks base method.
 func (m *MockUserRepository) InsertUser(ctx context.Context, user *domain.User) (*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -67,6 +97,50 @@ func (mr *MockUserRepositoryMockRecorder) InsertUser(ctx, user interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUser", reflect.TypeOf((*MockUserRepository)(nil).InsertUser), ctx, user)
 }
 
+// SearchUsers mocks base method.
+func (m *MockUserRepository) SearchUsers(ctx context.Context, filter bson.M, limit int64) ([]*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, filter, limit)
+	ret0, _ := ret[0].([]*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockUserRepositoryMockRecorder) SearchUsers(ctx, filter, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockUserRepository)(nil).SearchUsers), ctx, filter, limit)
+}
+
+// UpdateUser mocks base method.
+func (m *MockUserRepository) UpdateUser(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, id, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockUserRepositoryMockRecorder) UpdateUser(ctx, id, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserRepository)(nil).UpdateUser), ctx, id, update)
+}
+
+// UpdateUsers mocks base method.
+func (m *MockUserRepository) UpdateUsers(ctx context.Context, filter, update bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUsers", ctx, filter, update)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUsers indicates an expected call of UpdateUsers.
+func (mr *MockUserRepositoryMockRecorder) UpdateUsers(ctx, filter, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUsers", reflect.TypeOf((*MockUserRepository)(nil).UpdateUsers), ctx, filter, update)
+}
+
 // MockCapsuleRepository is a mock of CapsuleRepository interface.
 type MockCapsuleRepository struct {
 	ctrl     *gomock.Controller
@@ -90,6 +164,36 @@ func (m *MockCapsuleRepository) EXPECT() *MockCapsuleRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountCapsules mocks base method.
+func (m *MockCapsuleRepository) CountCapsules(ctx context.Context, filter bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCapsules", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCapsules indicates an expected call of CountCapsules.
+func (mr *MockCapsuleRepositoryMockRecorder) CountCapsules(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCapsules", reflect.TypeOf((*MockCapsuleRepository)(nil).CountCapsules), ctx, filter)
+}
+
+// CountTags mocks base method.
+func (m *MockCapsuleRepository) CountTags(ctx context.Context, filter bson.M) ([]*domain.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTags", ctx, filter)
+	ret0, _ := ret[0].([]*domain.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTags indicates an expected call of CountTags.
+func (mr *MockCapsuleRepositoryMockRecorder) CountTags(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTags", reflect.TypeOf((*MockCapsuleRepository)(nil).CountTags), ctx, filter)
+}
+
 // DeleteCapsule mocks base method.
 func (m *MockCapsuleRepository) DeleteCapsule(ctx context.Context, id primitive.ObjectID) error {
 	m.ctrl.T.Helper()
@@ -149,6 +253,21 @@ func (mr *MockCapsuleRepositoryMockRecorder) InsertCapsule(ctx, capsule interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCapsule", reflect.TypeOf((*MockCapsuleRepository)(nil).InsertCapsule), ctx, capsule)
 }
 
+// SearchCapsules mocks base method.
+func (m *MockCapsuleRepository) SearchCapsules(ctx context.Context, filter bson.M, query string, limit int64) ([]*domain.CapsuleSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchCapsules", ctx, filter, query, limit)
+	ret0, _ := ret[0].([]*domain.CapsuleSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchCapsules indicates an expected call of SearchCapsules.
+func (mr *MockCapsuleRepositoryMockRecorder) SearchCapsules(ctx, filter, query, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchCapsules", reflect.TypeOf((*MockCapsuleRepository)(nil).SearchCapsules), ctx, filter, query, limit)
+}
+
 // UpdateCapsule mocks base method.
 func (m *MockCapsuleRepository) UpdateCapsule(ctx context.Context, id primitive.ObjectID, update bson.M) error {
 	m.ctrl.T.Helper()
@@ -162,3 +281,699 @@ func (mr *MockCapsuleRepositoryMockRecorder) UpdateCapsule(ctx, id, update inter
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCapsule", reflect.TypeOf((*MockCapsuleRepository)(nil).UpdateCapsule), ctx, id, update)
 }
+
+// UpdateCapsules mocks base method.
+func (m *MockCapsuleRepository) UpdateCapsules(ctx context.Context, filter bson.M, update any) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCapsules", ctx, filter, update)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCapsules indicates an expected call of UpdateCapsules.
+func (mr *MockCapsuleRepositoryMockRecorder) UpdateCapsules(ctx, filter, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCapsules", reflect.TypeOf((*MockCapsuleRepository)(nil).UpdateCapsules), ctx, filter, update)
+}
+
+// MockCollectionRepository is a mock of CollectionRepository interface.
+type MockCollectionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollectionRepositoryMockRecorder
+}
+
+// MockCollectionRepositoryMockRecorder is the mock recorder for MockCollectionRepository.
+type MockCollectionRepositoryMockRecorder struct {
+	mock *MockCollectionRepository
+}
+
+// NewMockCollectionRepository creates a new mock instance.
+func NewMockCollectionRepository(ctrl *gomock.Controller) *MockCollectionRepository {
+	mock := &MockCollectionRepository{ctrl: ctrl}
+	mock.recorder = &MockCollectionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollectionRepository) EXPECT() *MockCollectionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteCollection mocks base method.
+func (m *MockCollectionRepository) DeleteCollection(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockCollectionRepositoryMockRecorder) DeleteCollection(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockCollectionRepository)(nil).DeleteCollection), ctx, id)
+}
+
+// GetCollection mocks base method.
+func (m *MockCollectionRepository) GetCollection(ctx context.Context, filter bson.M) (*domain.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollection", ctx, filter)
+	ret0, _ := ret[0].(*domain.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollection indicates an expected call of GetCollection.
+func (mr *MockCollectionRepositoryMockRecorder) GetCollection(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollection", reflect.TypeOf((*MockCollectionRepository)(nil).GetCollection), ctx, filter)
+}
+
+// GetCollections mocks base method.
+func (m *MockCollectionRepository) GetCollections(ctx context.Context, filter bson.M) ([]*domain.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollections", ctx, filter)
+	ret0, _ := ret[0].([]*domain.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollections indicates an expected call of GetCollections.
+func (mr *MockCollectionRepositoryMockRecorder) GetCollections(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollections", reflect.TypeOf((*MockCollectionRepository)(nil).GetCollections), ctx, filter)
+}
+
+// InsertCollection mocks base method.
+func (m *MockCollectionRepository) InsertCollection(ctx context.Context, collection *domain.Collection) (*domain.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertCollection", ctx, collection)
+	ret0, _ := ret[0].(*domain.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertCollection indicates an expected call of InsertCollection.
+func (mr *MockCollectionRepositoryMockRecorder) InsertCollection(ctx, collection interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCollection", reflect.TypeOf((*MockCollectionRepository)(nil).InsertCollection), ctx, collection)
+}
+
+// UpdateCollection mocks base method.
+func (m *MockCollectionRepository) UpdateCollection(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCollection", ctx, id, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCollection indicates an expected call of UpdateCollection.
+func (mr *MockCollectionRepositoryMockRecorder) UpdateCollection(ctx, id, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCollection", reflect.TypeOf((*MockCollectionRepository)(nil).UpdateCollection), ctx, id, update)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimOutboxEntry mocks base method.
+func (m *MockOutboxRepository) ClaimOutboxEntry(ctx context.Context, now time.Time, lease time.Duration) (*domain.OutboxEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxEntry", ctx, now, lease)
+	ret0, _ := ret[0].(*domain.OutboxEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxEntry indicates an expected call of ClaimOutboxEntry.
+func (mr *MockOutboxRepositoryMockRecorder) ClaimOutboxEntry(ctx, now, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEntry", reflect.TypeOf((*MockOutboxRepository)(nil).ClaimOutboxEntry), ctx, now, lease)
+}
+
+// CountOutboxEntries mocks base method.
+func (m *MockOutboxRepository) CountOutboxEntries(ctx context.Context, filter bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOutboxEntries", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOutboxEntries indicates an expected call of CountOutboxEntries.
+func (mr *MockOutboxRepositoryMockRecorder) CountOutboxEntries(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOutboxEntries", reflect.TypeOf((*MockOutboxRepository)(nil).CountOutboxEntries), ctx, filter)
+}
+
+// DeleteOutboxEntries mocks base method.
+func (m *MockOutboxRepository) DeleteOutboxEntries(ctx context.Context, filter bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutboxEntries", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOutboxEntries indicates an expected call of DeleteOutboxEntries.
+func (mr *MockOutboxRepositoryMockRecorder) DeleteOutboxEntries(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutboxEntries", reflect.TypeOf((*MockOutboxRepository)(nil).DeleteOutboxEntries), ctx, filter)
+}
+
+// GetOutboxEntries mocks base method.
+func (m *MockOutboxRepository) GetOutboxEntries(ctx context.Context, filter bson.M, limit int64) ([]*domain.OutboxEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxEntries", ctx, filter, limit)
+	ret0, _ := ret[0].([]*domain.OutboxEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxEntries indicates an expected call of GetOutboxEntries.
+func (mr *MockOutboxRepositoryMockRecorder) GetOutboxEntries(ctx, filter, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxEntries", reflect.TypeOf((*MockOutboxRepository)(nil).GetOutboxEntries), ctx, filter, limit)
+}
+
+// InsertOutboxEntry mocks base method.
+func (m *MockOutboxRepository) InsertOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) (*domain.OutboxEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOutboxEntry", ctx, entry)
+	ret0, _ := ret[0].(*domain.OutboxEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertOutboxEntry indicates an expected call of InsertOutboxEntry.
+func (mr *MockOutboxRepositoryMockRecorder) InsertOutboxEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOutboxEntry", reflect.TypeOf((*MockOutboxRepository)(nil).InsertOutboxEntry), ctx, entry)
+}
+
+// UpdateOutboxEntries mocks base method.
+func (m *MockOutboxRepository) UpdateOutboxEntries(ctx context.Context, filter, update bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOutboxEntries", ctx, filter, update)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOutboxEntries indicates an expected call of UpdateOutboxEntries.
+func (mr *MockOutboxRepositoryMockRecorder) UpdateOutboxEntries(ctx, filter, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOutboxEntries", reflect.TypeOf((*MockOutboxRepository)(nil).UpdateOutboxEntries), ctx, filter, update)
+}
+
+// UpdateOutboxEntry mocks base method.
+func (m *MockOutboxRepository) UpdateOutboxEntry(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOutboxEntry", ctx, id, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOutboxEntry indicates an expected call of UpdateOutboxEntry.
+func (mr *MockOutboxRepositoryMockRecorder) UpdateOutboxEntry(ctx, id, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOutboxEntry", reflect.TypeOf((*MockOutboxRepository)(nil).UpdateOutboxEntry), ctx, id, update)
+}
+
+// MockNotificationRepository is a mock of NotificationRepository interface.
+type MockNotificationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationRepositoryMockRecorder
+}
+
+// MockNotificationRepositoryMockRecorder is the mock recorder for MockNotificationRepository.
+type MockNotificationRepositoryMockRecorder struct {
+	mock *MockNotificationRepository
+}
+
+// NewMockNotificationRepository creates a new mock instance.
+func NewMockNotificationRepository(ctrl *gomock.Controller) *MockNotificationRepository {
+	mock := &MockNotificationRepository{ctrl: ctrl}
+	mock.recorder = &MockNotificationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationRepository) EXPECT() *MockNotificationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountNotifications mocks base method.
+func (m *MockNotificationRepository) CountNotifications(ctx context.Context, filter bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountNotifications", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountNotifications indicates an expected call of CountNotifications.
+func (mr *MockNotificationRepositoryMockRecorder) CountNotifications(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountNotifications", reflect.TypeOf((*MockNotificationRepository)(nil).CountNotifications), ctx, filter)
+}
+
+// DeleteNotifications mocks base method.
+func (m *MockNotificationRepository) DeleteNotifications(ctx context.Context, filter bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNotifications", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteNotifications indicates an expected call of DeleteNotifications.
+func (mr *MockNotificationRepositoryMockRecorder) DeleteNotifications(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNotifications", reflect.TypeOf((*MockNotificationRepository)(nil).DeleteNotifications), ctx, filter)
+}
+
+// GetNotifications mocks base method.
+func (m *MockNotificationRepository) GetNotifications(ctx context.Context, filter bson.M, limit int64) ([]*domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, filter, limit)
+	ret0, _ := ret[0].([]*domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockNotificationRepositoryMockRecorder) GetNotifications(ctx, filter, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockNotificationRepository)(nil).GetNotifications), ctx, filter, limit)
+}
+
+// InsertNotification mocks base method.
+func (m *MockNotificationRepository) InsertNotification(ctx context.Context, notification *domain.Notification) (*domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertNotification", ctx, notification)
+	ret0, _ := ret[0].(*domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertNotification indicates an expected call of InsertNotification.
+func (mr *MockNotificationRepositoryMockRecorder) InsertNotification(ctx, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNotification", reflect.TypeOf((*MockNotificationRepository)(nil).InsertNotification), ctx, notification)
+}
+
+// UpdateNotifications mocks base method.
+func (m *MockNotificationRepository) UpdateNotifications(ctx context.Context, filter, update bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotifications", ctx, filter, update)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNotifications indicates an expected call of UpdateNotifications.
+func (mr *MockNotificationRepositoryMockRecorder) UpdateNotifications(ctx, filter, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotifications", reflect.TypeOf((*MockNotificationRepository)(nil).UpdateNotifications), ctx, filter, update)
+}
+
+// MockSignInFailureRepository is a mock of SignInFailureRepository interface.
+type MockSignInFailureRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignInFailureRepositoryMockRecorder
+}
+
+// MockSignInFailureRepositoryMockRecorder is the mock recorder for MockSignInFailureRepository.
+type MockSignInFailureRepositoryMockRecorder struct {
+	mock *MockSignInFailureRepository
+}
+
+// NewMockSignInFailureRepository creates a new mock instance.
+func NewMockSignInFailureRepository(ctrl *gomock.Controller) *MockSignInFailureRepository {
+	mock := &MockSignInFailureRepository{ctrl: ctrl}
+	mock.recorder = &MockSignInFailureRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSignInFailureRepository) EXPECT() *MockSignInFailureRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteSignInFailures mocks base method.
+func (m *MockSignInFailureRepository) DeleteSignInFailures(ctx context.Context, filter bson.M) (*domain.SignInFailures, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSignInFailures", ctx, filter)
+	ret0, _ := ret[0].(*domain.SignInFailures)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSignInFailures indicates an expected call of DeleteSignInFailures.
+func (mr *MockSignInFailureRepositoryMockRecorder) DeleteSignInFailures(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSignInFailures", reflect.TypeOf((*MockSignInFailureRepository)(nil).DeleteSignInFailures), ctx, filter)
+}
+
+// GetSignInFailures mocks base method.
+func (m *MockSignInFailureRepository) GetSignInFailures(ctx context.Context, filter bson.M) ([]*domain.SignInFailures, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSignInFailures", ctx, filter)
+	ret0, _ := ret[0].([]*domain.SignInFailures)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSignInFailures indicates an expected call of GetSignInFailures.
+func (mr *MockSignInFailureRepositoryMockRecorder) GetSignInFailures(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSignInFailures", reflect.TypeOf((*MockSignInFailureRepository)(nil).GetSignInFailures), ctx, filter)
+}
+
+// RecordSignInFailure mocks base method.
+func (m *MockSignInFailureRepository) RecordSignInFailure(ctx context.Context, key string, now, expiresAt time.Time) (*domain.SignInFailures, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSignInFailure", ctx, key, now, expiresAt)
+	ret0, _ := ret[0].(*domain.SignInFailures)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordSignInFailure indicates an expected call of RecordSignInFailure.
+func (mr *MockSignInFailureRepositoryMockRecorder) RecordSignInFailure(ctx, key, now, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSignInFailure", reflect.TypeOf((*MockSignInFailureRepository)(nil).RecordSignInFailure), ctx, key, now, expiresAt)
+}
+
+// UpdateSignInFailures mocks base method.
+func (m *MockSignInFailureRepository) UpdateSignInFailures(ctx context.Context, filter, update bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSignInFailures", ctx, filter, update)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSignInFailures indicates an expected call of UpdateSignInFailures.
+func (mr *MockSignInFailureRepositoryMockRecorder) UpdateSignInFailures(ctx, filter, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSignInFailures", reflect.TypeOf((*MockSignInFailureRepository)(nil).UpdateSignInFailures), ctx, filter, update)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockAuditRepository) GetAuditEvents(ctx context.Context, filter bson.M, limit int64) ([]*domain.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, filter, limit)
+	ret0, _ := ret[0].([]*domain.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockAuditRepositoryMockRecorder) GetAuditEvents(ctx, filter, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockAuditRepository)(nil).GetAuditEvents), ctx, filter, limit)
+}
+
+// InsertAuditEvent mocks base method.
+func (m *MockAuditRepository) InsertAuditEvent(ctx context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAuditEvent", ctx, event)
+	ret0, _ := ret[0].(*domain.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertAuditEvent indicates an expected call of InsertAuditEvent.
+func (mr *MockAuditRepositoryMockRecorder) InsertAuditEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAuditEvent", reflect.TypeOf((*MockAuditRepository)(nil).InsertAuditEvent), ctx, event)
+}
+
+// MockAccessTokenRepository is a mock of AccessTokenRepository interface.
+type MockAccessTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessTokenRepositoryMockRecorder
+}
+
+// MockAccessTokenRepositoryMockRecorder is the mock recorder for MockAccessTokenRepository.
+type MockAccessTokenRepositoryMockRecorder struct {
+	mock *MockAccessTokenRepository
+}
+
+// NewMockAccessTokenRepository creates a new mock instance.
+func NewMockAccessTokenRepository(ctrl *gomock.Controller) *MockAccessTokenRepository {
+	mock := &MockAccessTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockAccessTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccessTokenRepository) EXPECT() *MockAccessTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteAccessToken mocks base method.
+func (m *MockAccessTokenRepository) DeleteAccessToken(ctx context.Context, filter bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccessToken", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccessToken indicates an expected call of DeleteAccessToken.
+func (mr *MockAccessTokenRepositoryMockRecorder) DeleteAccessToken(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccessToken", reflect.TypeOf((*MockAccessTokenRepository)(nil).DeleteAccessToken), ctx, filter)
+}
+
+// GetAccessToken mocks base method.
+func (m *MockAccessTokenRepository) GetAccessToken(ctx context.Context, filter bson.M) (*domain.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessToken", ctx, filter)
+	ret0, _ := ret[0].(*domain.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessToken indicates an expected call of GetAccessToken.
+func (mr *MockAccessTokenRepositoryMockRecorder) GetAccessToken(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessToken", reflect.TypeOf((*MockAccessTokenRepository)(nil).GetAccessToken), ctx, filter)
+}
+
+// GetAccessTokens mocks base method.
+func (m *MockAccessTokenRepository) GetAccessTokens(ctx context.Context, filter bson.M) ([]*domain.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokens", ctx, filter)
+	ret0, _ := ret[0].([]*domain.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokens indicates an expected call of GetAccessTokens.
+func (mr *MockAccessTokenRepositoryMockRecorder) GetAccessTokens(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokens", reflect.TypeOf((*MockAccessTokenRepository)(nil).GetAccessTokens), ctx, filter)
+}
+
+// InsertAccessToken mocks base method.
+func (m *MockAccessTokenRepository) InsertAccessToken(ctx context.Context, token *domain.AccessToken) (*domain.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAccessToken", ctx, token)
+	ret0, _ := ret[0].(*domain.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertAccessToken indicates an expected call of InsertAccessToken.
+func (mr *MockAccessTokenRepositoryMockRecorder) InsertAccessToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAccessToken", reflect.TypeOf((*MockAccessTokenRepository)(nil).InsertAccessToken), ctx, token)
+}
+
+// UpdateAccessToken mocks base method.
+func (m *MockAccessTokenRepository) UpdateAccessToken(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccessToken", ctx, id, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAccessToken indicates an expected call of UpdateAccessToken.
+func (mr *MockAccessTokenRepositoryMockRecorder) UpdateAccessToken(ctx, id, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccessToken", reflect.TypeOf((*MockAccessTokenRepository)(nil).UpdateAccessToken), ctx, id, update)
+}
+
+// MockExportRepository is a mock of ExportRepository interface.
+type MockExportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportRepositoryMockRecorder
+}
+
+// MockExportRepositoryMockRecorder is the mock recorder for MockExportRepository.
+type MockExportRepositoryMockRecorder struct {
+	mock *MockExportRepository
+}
+
+// NewMockExportRepository creates a new mock instance.
+func NewMockExportRepository(ctrl *gomock.Controller) *MockExportRepository {
+	mock := &MockExportRepository{ctrl: ctrl}
+	mock.recorder = &MockExportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportRepository) EXPECT() *MockExportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimExport mocks base method.
+func (m *MockExportRepository) ClaimExport(ctx context.Context, now time.Time, lease time.Duration) (*domain.Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimExport", ctx, now, lease)
+	ret0, _ := ret[0].(*domain.Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimExport indicates an expected call of ClaimExport.
+func (mr *MockExportRepositoryMockRecorder) ClaimExport(ctx, now, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimExport", reflect.TypeOf((*MockExportRepository)(nil).ClaimExport), ctx, now, lease)
+}
+
+// CountExports mocks base method.
+func (m *MockExportRepository) CountExports(ctx context.Context, filter bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountExports", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountExports indicates an expected call of CountExports.
+func (mr *MockExportRepositoryMockRecorder) CountExports(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountExports", reflect.TypeOf((*MockExportRepository)(nil).CountExports), ctx, filter)
+}
+
+// DeleteExports mocks base method.
+func (m *MockExportRepository) DeleteExports(ctx context.Context, filter bson.M) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExports", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExports indicates an expected call of DeleteExports.
+func (mr *MockExportRepositoryMockRecorder) DeleteExports(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExports", reflect.TypeOf((*MockExportRepository)(nil).DeleteExports), ctx, filter)
+}
+
+// GetExport mocks base method.
+func (m *MockExportRepository) GetExport(ctx context.Context, filter bson.M) (*domain.Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExport", ctx, filter)
+	ret0, _ := ret[0].(*domain.Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExport indicates an expected call of GetExport.
+func (mr *MockExportRepositoryMockRecorder) GetExport(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExport", reflect.TypeOf((*MockExportRepository)(nil).GetExport), ctx, filter)
+}
+
+// GetExports mocks base method.
+func (m *MockExportRepository) GetExports(ctx context.Context, filter bson.M) ([]*domain.Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExports", ctx, filter)
+	ret0, _ := ret[0].([]*domain.Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExports indicates an expected call of GetExports.
+func (mr *MockExportRepositoryMockRecorder) GetExports(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExports", reflect.TypeOf((*MockExportRepository)(nil).GetExports), ctx, filter)
+}
+
+// InsertExport mocks base method.
+func (m *MockExportRepository) InsertExport(ctx context.Context, export *domain.Export) (*domain.Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertExport", ctx, export)
+	ret0, _ := ret[0].(*domain.Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertExport indicates an expected call of InsertExport.
+func (mr *MockExportRepositoryMockRecorder) InsertExport(ctx, export interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertExport", reflect.TypeOf((*MockExportRepository)(nil).InsertExport), ctx, export)
+}
+
+// UpdateExport mocks base method.
+func (m *MockExportRepository) UpdateExport(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateExport", ctx, id, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateExport indicates an expected call of UpdateExport.
+func (mr *MockExportRepositoryMockRecorder) UpdateExport(ctx, id, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateExport", reflect.TypeOf((*MockExportRepository)(nil).UpdateExport), ctx, id, update)
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"time-capsule/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const notificationCollection = "notifications"
+
+type MongoNotificationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoNotificationRepository(db *mongo.Database) NotificationRepository {
+	createIndexes(db.Collection(notificationCollection), []mongo.IndexModel{
+		{
+			Keys:    bson.M{"key": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "userID", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	})
+
+	return &MongoNotificationRepository{
+		collection: db.Collection(notificationCollection),
+	}
+}
+
+func (r *MongoNotificationRepository) InsertNotification(ctx context.Context, notification *domain.Notification) (*domain.Notification, error) {
+	res, err := r.collection.InsertOne(ctx, notification)
+	if err != nil {
+		return nil, err
+	}
+
+	notification.ID = res.InsertedID.(primitive.ObjectID)
+
+	return notification, nil
+}
+
+func (r *MongoNotificationRepository) GetNotifications(ctx context.Context, filter bson.M, limit int64) ([]*domain.Notification, error) {
+	cur, err := r.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.M{"createdAt": -1}).
+		SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifications []*domain.Notification
+	if err := cur.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *MongoNotificationRepository) CountNotifications(ctx context.Context, filter bson.M) (int64, error) {
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+func (r *MongoNotificationRepository) UpdateNotifications(ctx context.Context, filter bson.M, update bson.M) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.MatchedCount, nil
+}
+
+func (r *MongoNotificationRepository) DeleteNotifications(ctx context.Context, filter bson.M) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.DeletedCount, nil
+}
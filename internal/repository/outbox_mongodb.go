@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"time-capsule/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	outboxCollection = "outbox"
+	// sentRetention is how long sent emails are kept, e.g. to be resent, before they're deleted with their content.
+	sentRetention = 30 * 24 * time.Hour
+)
+
+type MongoOutboxRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoOutboxRepository(db *mongo.Database) OutboxRepository {
+	createIndexes(db.Collection(outboxCollection), []mongo.IndexModel{
+		{
+			Keys:    bson.M{"key": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+		},
+		{
+			Keys:    bson.M{"sentAt": 1},
+			Options: options.Index().SetExpireAfterSeconds(int32(sentRetention.Seconds())),
+		},
+	})
+
+	return &MongoOutboxRepository{
+		collection: db.Collection(outboxCollection),
+	}
+}
+
+func (r *MongoOutboxRepository) InsertOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) (*domain.OutboxEntry, error) {
+	res, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.ID = res.InsertedID.(primitive.ObjectID)
+
+	return entry, nil
+}
+
+// ClaimOutboxEntry picks the pending entry that is due the longest and postpones it by lease,
+// so that concurrent workers don't deliver it twice. It returns mongo.ErrNoDocuments when nothing is due.
+func (r *MongoOutboxRepository) ClaimOutboxEntry(ctx context.Context, now time.Time, lease time.Duration) (*domain.OutboxEntry, error) {
+	var entry domain.OutboxEntry
+
+	if err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"status":        domain.OutboxStatusPending,
+			"nextAttemptAt": bson.M{"$lte": now},
+		},
+		bson.M{
+			"$set": bson.M{
+				"nextAttemptAt": now.Add(lease),
+			},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.M{"nextAttemptAt": 1}).
+			SetReturnDocument(options.After),
+	).Decode(&entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *MongoOutboxRepository) GetOutboxEntries(ctx context.Context, filter bson.M, limit int64) ([]*domain.OutboxEntry, error) {
+	cur, err := r.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.M{"createdAt": -1}).
+		SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*domain.OutboxEntry
+	if err := cur.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *MongoOutboxRepository) CountOutboxEntries(ctx context.Context, filter bson.M) (int64, error) {
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+func (r *MongoOutboxRepository) UpdateOutboxEntry(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+
+	return err
+}
+
+func (r *MongoOutboxRepository) UpdateOutboxEntries(ctx context.Context, filter bson.M, update bson.M) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.MatchedCount, nil
+}
+
+func (r *MongoOutboxRepository) DeleteOutboxEntries(ctx context.Context, filter bson.M) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.DeletedCount, nil
+}
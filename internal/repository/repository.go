@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"log/slog"
+	"time"
 
 	"time-capsule/internal/domain"
 
@@ -13,24 +15,117 @@ import (
 type Repository struct {
 	UserRepository
 	CapsuleRepository
+	CollectionRepository
+	OutboxRepository
+	NotificationRepository
+	SignInFailureRepository
+	AuditRepository
+	AccessTokenRepository
+	ExportRepository
 }
 
 func NewRepository(db *mongo.Database) *Repository {
 	return &Repository{
-		UserRepository:    NewMongoUserRepository(db),
-		CapsuleRepository: NewMongoCapsuleRepository(db),
+		UserRepository:          NewMongoUserRepository(db),
+		CapsuleRepository:       NewMongoCapsuleRepository(db),
+		CollectionRepository:    NewMongoCollectionRepository(db),
+		OutboxRepository:        NewMongoOutboxRepository(db),
+		NotificationRepository:  NewMongoNotificationRepository(db),
+		SignInFailureRepository: NewMongoSignInFailureRepository(db),
+		AuditRepository:         NewMongoAuditRepository(db),
+		AccessTokenRepository:   NewMongoAccessTokenRepository(db),
+		ExportRepository:        NewMongoExportRepository(db),
+	}
+}
+
+// indexTimeout bounds the creation of the indexes of a collection.
+const indexTimeout = 30 * time.Second
+
+// createIndexes creates the indexes of the collection. Queries rely on them, text searches need the text index
+// and unique ones detect duplicates, so a failure is logged as an error.
+func createIndexes(collection *mongo.Collection, models []mongo.IndexModel) {
+	ctx, cancel := context.WithTimeout(context.Background(), indexTimeout)
+	defer cancel()
+
+	if _, err := collection.Indexes().CreateMany(ctx, models); err != nil {
+		slog.Error("failed to create the indexes of a collection", "collection", collection.Name(), "error", err)
 	}
 }
 
 type UserRepository interface {
 	InsertUser(ctx context.Context, user *domain.User) (*domain.User, error)
 	GetUser(ctx context.Context, filter bson.M) (*domain.User, error)
+	GetUsers(ctx context.Context, filter bson.M) ([]*domain.User, error)
+	SearchUsers(ctx context.Context, filter bson.M, limit int64) ([]*domain.User, error)
+	UpdateUser(ctx context.Context, id primitive.ObjectID, update bson.M) error
+	UpdateUsers(ctx context.Context, filter bson.M, update bson.M) (int64, error)
+	DeleteUser(ctx context.Context, id primitive.ObjectID) error
 }
 
 type CapsuleRepository interface {
 	InsertCapsule(ctx context.Context, capsule *domain.Capsule) (*domain.Capsule, error)
 	GetCapsule(ctx context.Context, filter bson.M) (*domain.Capsule, error)
 	GetCapsules(ctx context.Context, filter bson.M) ([]*domain.Capsule, error)
+	CountCapsules(ctx context.Context, filter bson.M) (int64, error)
 	UpdateCapsule(ctx context.Context, id primitive.ObjectID, update bson.M) error
+	UpdateCapsules(ctx context.Context, filter bson.M, update any) (int64, error)
 	DeleteCapsule(ctx context.Context, id primitive.ObjectID) error
+	SearchCapsules(ctx context.Context, filter bson.M, query string, limit int64) ([]*domain.CapsuleSearchResult, error)
+	CountTags(ctx context.Context, filter bson.M) ([]*domain.TagCount, error)
+}
+
+type CollectionRepository interface {
+	InsertCollection(ctx context.Context, collection *domain.Collection) (*domain.Collection, error)
+	GetCollection(ctx context.Context, filter bson.M) (*domain.Collection, error)
+	GetCollections(ctx context.Context, filter bson.M) ([]*domain.Collection, error)
+	UpdateCollection(ctx context.Context, id primitive.ObjectID, update bson.M) error
+	DeleteCollection(ctx context.Context, id primitive.ObjectID) error
+}
+
+type OutboxRepository interface {
+	InsertOutboxEntry(ctx context.Context, entry *domain.OutboxEntry) (*domain.OutboxEntry, error)
+	ClaimOutboxEntry(ctx context.Context, now time.Time, lease time.Duration) (*domain.OutboxEntry, error)
+	GetOutboxEntries(ctx context.Context, filter bson.M, limit int64) ([]*domain.OutboxEntry, error)
+	CountOutboxEntries(ctx context.Context, filter bson.M) (int64, error)
+	UpdateOutboxEntry(ctx context.Context, id primitive.ObjectID, update bson.M) error
+	UpdateOutboxEntries(ctx context.Context, filter bson.M, update bson.M) (int64, error)
+	DeleteOutboxEntries(ctx context.Context, filter bson.M) (int64, error)
+}
+
+type NotificationRepository interface {
+	InsertNotification(ctx context.Context, notification *domain.Notification) (*domain.Notification, error)
+	GetNotifications(ctx context.Context, filter bson.M, limit int64) ([]*domain.Notification, error)
+	CountNotifications(ctx context.Context, filter bson.M) (int64, error)
+	UpdateNotifications(ctx context.Context, filter bson.M, update bson.M) (int64, error)
+	DeleteNotifications(ctx context.Context, filter bson.M) (int64, error)
+}
+
+type SignInFailureRepository interface {
+	GetSignInFailures(ctx context.Context, filter bson.M) ([]*domain.SignInFailures, error)
+	RecordSignInFailure(ctx context.Context, key string, now, expiresAt time.Time) (*domain.SignInFailures, error)
+	UpdateSignInFailures(ctx context.Context, filter bson.M, update bson.M) (int64, error)
+	DeleteSignInFailures(ctx context.Context, filter bson.M) (*domain.SignInFailures, error)
+}
+
+type AuditRepository interface {
+	InsertAuditEvent(ctx context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error)
+	GetAuditEvents(ctx context.Context, filter bson.M, limit int64) ([]*domain.AuditEvent, error)
+}
+
+type AccessTokenRepository interface {
+	InsertAccessToken(ctx context.Context, token *domain.AccessToken) (*domain.AccessToken, error)
+	GetAccessToken(ctx context.Context, filter bson.M) (*domain.AccessToken, error)
+	GetAccessTokens(ctx context.Context, filter bson.M) ([]*domain.AccessToken, error)
+	UpdateAccessToken(ctx context.Context, id primitive.ObjectID, update bson.M) error
+	DeleteAccessToken(ctx context.Context, filter bson.M) (int64, error)
+}
+
+type ExportRepository interface {
+	InsertExport(ctx context.Context, export *domain.Export) (*domain.Export, error)
+	GetExport(ctx context.Context, filter bson.M) (*domain.Export, error)
+	GetExports(ctx context.Context, filter bson.M) ([]*domain.Export, error)
+	CountExports(ctx context.Context, filter bson.M) (int64, error)
+	ClaimExport(ctx context.Context, now time.Time, lease time.Duration) (*domain.Export, error)
+	UpdateExport(ctx context.Context, id primitive.ObjectID, update bson.M) error
+	DeleteExports(ctx context.Context, filter bson.M) (int64, error)
 }
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"time-capsule/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const signInFailuresCollection = "signInFailures"
+
+type MongoSignInFailureRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoSignInFailureRepository(db *mongo.Database) SignInFailureRepository {
+	createIndexes(db.Collection(signInFailuresCollection), []mongo.IndexModel{
+		{
+			Keys:    bson.M{"expiresAt": 1},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys:    bson.M{"unlockTokenHash": 1},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+
+	return &MongoSignInFailureRepository{
+		collection: db.Collection(signInFailuresCollection),
+	}
+}
+
+func (r *MongoSignInFailureRepository) GetSignInFailures(ctx context.Context, filter bson.M) ([]*domain.SignInFailures, error) {
+	cur, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []*domain.SignInFailures
+	if err := cur.All(ctx, &failures); err != nil {
+		return nil, err
+	}
+
+	return failures, nil
+}
+
+// RecordSignInFailure increments the failures of key and extends its counter until expiresAt.
+func (r *MongoSignInFailureRepository) RecordSignInFailure(ctx context.Context, key string, now, expiresAt time.Time) (*domain.SignInFailures, error) {
+	var failures domain.SignInFailures
+
+	if err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": key},
+		bson.M{
+			"$inc": bson.M{"failures": 1},
+			"$set": bson.M{
+				"lastFailureAt": now,
+				"expiresAt":     expiresAt,
+			},
+		},
+		options.FindOneAndUpdate().
+			SetUpsert(true).
+			SetReturnDocument(options.After),
+	).Decode(&failures); err != nil {
+		return nil, err
+	}
+
+	return &failures, nil
+}
+
+func (r *MongoSignInFailureRepository) UpdateSignInFailures(ctx context.Context, filter bson.M, update bson.M) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.MatchedCount, nil
+}
+
+// DeleteSignInFailures deletes the counter matching filter and returns it.
+// It returns mongo.ErrNoDocuments when nothing matches.
+func (r *MongoSignInFailureRepository) DeleteSignInFailures(ctx context.Context, filter bson.M) (*domain.SignInFailures, error) {
+	var failures domain.SignInFailures
+
+	if err := r.collection.FindOneAndDelete(ctx, filter).Decode(&failures); err != nil {
+		return nil, err
+	}
+
+	return &failures, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"time-capsule/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const accessTokensCollection = "accessTokens"
+
+type MongoAccessTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAccessTokenRepository(db *mongo.Database) AccessTokenRepository {
+	createIndexes(db.Collection(accessTokensCollection), []mongo.IndexModel{
+		{
+			Keys:    bson.M{"hash": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "userID", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	})
+
+	return &MongoAccessTokenRepository{
+		collection: db.Collection(accessTokensCollection),
+	}
+}
+
+func (r *MongoAccessTokenRepository) InsertAccessToken(ctx context.Context, token *domain.AccessToken) (*domain.AccessToken, error) {
+	res, err := r.collection.InsertOne(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	token.ID = res.InsertedID.(primitive.ObjectID)
+
+	return token, nil
+}
+
+func (r *MongoAccessTokenRepository) GetAccessToken(ctx context.Context, filter bson.M) (*domain.AccessToken, error) {
+	var token domain.AccessToken
+
+	if err := r.collection.FindOne(ctx, filter).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *MongoAccessTokenRepository) GetAccessTokens(ctx context.Context, filter bson.M) ([]*domain.AccessToken, error) {
+	cur, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []*domain.AccessToken
+	if err := cur.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (r *MongoAccessTokenRepository) UpdateAccessToken(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+
+	return err
+}
+
+func (r *MongoAccessTokenRepository) DeleteAccessToken(ctx context.Context, filter bson.M) (int64, error) {
+	res, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.DeletedCount, nil
+}
@@ -18,19 +18,29 @@ type MongoUserRepository struct {
 }
 
 func NewMongoUserRepository(db *mongo.Database) UserRepository {
-	db.Collection(usersCollection).Indexes().CreateMany(
-		context.Background(),
-		[]mongo.IndexModel{
-			{
-				Keys:    bson.M{"username": 1},
-				Options: options.Index().SetUnique(true),
-			},
-			{
-				Keys:    bson.M{"email": 1},
-				Options: options.Index().SetUnique(true),
-			},
+	createIndexes(db.Collection(usersCollection), []mongo.IndexModel{
+		{
+			Keys:    bson.M{"username": 1},
+			Options: options.Index().SetUnique(true),
 		},
-	)
+		{
+			Keys:    bson.M{"email": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"identities": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.M{"emailVerificationHash": 1},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.M{"deletionScheduledAt": 1},
+			Options: options.Index().SetSparse(true),
+		},
+	})
 
 	return &MongoUserRepository{
 		collection: db.Collection(usersCollection),
@@ -57,3 +67,56 @@ func (r *MongoUserRepository) GetUser(ctx context.Context, filter bson.M) (*doma
 
 	return &user, nil
 }
+
+func (r *MongoUserRepository) GetUsers(ctx context.Context, filter bson.M) ([]*domain.User, error) {
+	cur, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*domain.User
+	if err := cur.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// SearchUsers returns at most limit users matching the filter, the latest registered first.
+func (r *MongoUserRepository) SearchUsers(ctx context.Context, filter bson.M, limit int64) ([]*domain.User, error) {
+	cur, err := r.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.M{"_id": -1}).
+		SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*domain.User
+	if err := cur.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *MongoUserRepository) UpdateUser(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+
+	return err
+}
+
+func (r *MongoUserRepository) UpdateUsers(ctx context.Context, filter bson.M, update bson.M) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.MatchedCount, nil
+}
+
+func (r *MongoUserRepository) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+
+	return err
+}
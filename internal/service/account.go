@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/ratelimit"
+	"time-capsule/internal/repository"
+	"time-capsule/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// accountDeletionGracePeriod is how long deleted accounts are kept before they're purged, so that
+// they can be restored.
+const accountDeletionGracePeriod = 30 * 24 * time.Hour
+
+var ErrDeletionNotScheduled = errors.New("account deletion isn't scheduled")
+
+type accountService struct {
+	repository             repository.UserRepository
+	capsuleRepository      repository.CapsuleRepository
+	collectionRepository   repository.CollectionRepository
+	accessTokenRepository  repository.AccessTokenRepository
+	exportRepository       repository.ExportRepository
+	outboxRepository       repository.OutboxRepository
+	notificationRepository repository.NotificationRepository
+	signInRepository       repository.SignInFailureRepository
+	// capsules deletes the capsules along with their stored images.
+	capsules CapsuleService
+	// storage holds the archives of the exports.
+	storage storage.Storage
+	// limiter holds the rate limit counters of the user.
+	limiter *ratelimit.Limiter
+	audit   *auditLog
+}
+
+func NewAccountService(repository repository.UserRepository, capsuleRepository repository.CapsuleRepository,
+	collectionRepository repository.CollectionRepository, accessTokenRepository repository.AccessTokenRepository,
+	exportRepository repository.ExportRepository, outboxRepository repository.OutboxRepository,
+	notificationRepository repository.NotificationRepository, signInRepository repository.SignInFailureRepository,
+	auditRepository repository.AuditRepository, capsules CapsuleService, storage storage.Storage,
+	limiter *ratelimit.Limiter) AccountService {
+	return &accountService{
+		repository:             repository,
+		capsuleRepository:      capsuleRepository,
+		collectionRepository:   collectionRepository,
+		accessTokenRepository:  accessTokenRepository,
+		exportRepository:       exportRepository,
+		outboxRepository:       outboxRepository,
+		notificationRepository: notificationRepository,
+		signInRepository:       signInRepository,
+		capsules:               capsules,
+		storage:                storage,
+		limiter:                limiter,
+		audit:                  &auditLog{repository: auditRepository},
+	}
+}
+
+// DeleteAccount schedules the account to be purged after accountDeletionGracePeriod. Users with a password
+// confirm it. Deleting an account that is already scheduled keeps the original schedule.
+func (s *accountService) DeleteAccount(ctx context.Context, userID primitive.ObjectID, input domain.DeleteAccountDTO) (*domain.AccountDeletion, error) {
+	user, err := s.repository.GetUser(ctx, bson.M{"_id": userID})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+
+		slog.ErrorContext(ctx, "DeleteAccount", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if user.PasswordHash != "" && !comparePasswords(input.Password, user.PasswordHash) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.DeletionScheduledAt != nil {
+		return &domain.AccountDeletion{ScheduledAt: *user.DeletionScheduledAt}, nil
+	}
+
+	scheduledAt := time.Now().UTC().Add(accountDeletionGracePeriod)
+
+	if err = s.repository.UpdateUser(ctx, userID, bson.M{
+		"$set": bson.M{
+			"deletionScheduledAt": scheduledAt,
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "DeleteAccount", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditDeletionScheduled,
+		UserID: userID,
+	})
+
+	return &domain.AccountDeletion{ScheduledAt: scheduledAt}, nil
+}
+
+// RestoreAccount cancels the scheduled deletion of the account.
+func (s *accountService) RestoreAccount(ctx context.Context, userID primitive.ObjectID) error {
+	user, err := s.repository.GetUser(ctx, bson.M{"_id": userID})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrNotFound
+		}
+
+		slog.ErrorContext(ctx, "RestoreAccount", "error", err)
+		return ErrDBFailure
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return ErrDeletionNotScheduled
+	}
+
+	if err = s.repository.UpdateUser(ctx, userID, bson.M{
+		"$unset": bson.M{
+			"deletionScheduledAt": "",
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "RestoreAccount", "error", err)
+		return ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditDeletionCanceled,
+		UserID: userID,
+	})
+
+	return nil
+}
+
+// PurgeAccounts purges the accounts whose grace period is over. Accounts that fail to purge
+// stay scheduled and are picked up again on the next call.
+func (s *accountService) PurgeAccounts(ctx context.Context, now time.Time) error {
+	users, err := s.repository.GetUsers(ctx, bson.M{
+		"deletionScheduledAt": bson.M{"$lte": now},
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, user := range users {
+		if err = s.purgeAccount(ctx, user.ID); err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", user.ID.Hex(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// purgeAccount deletes the capsules of the user, with their images, then the rest of the user's data,
+// including the archives of their exports, the emails rendered for them, which hold capsule messages,
+// and the counters of their failed sign-ins and requests, and the user. Each step is safe to repeat after a failure.
+func (s *accountService) purgeAccount(ctx context.Context, userID primitive.ObjectID) error {
+	capsules, err := s.capsuleRepository.GetCapsules(ctx, bson.M{"userID": userID})
+	if err != nil {
+		return err
+	}
+
+	for _, capsule := range capsules {
+		if err = s.capsules.DeleteCapsule(ctx, userID, capsule.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	collections, err := s.collectionRepository.GetCollections(ctx, bson.M{"userID": userID})
+	if err != nil {
+		return err
+	}
+
+	for _, collection := range collections {
+		if err = s.collectionRepository.DeleteCollection(ctx, collection.ID); err != nil {
+			return err
+		}
+	}
+
+	tokens, err := s.accessTokenRepository.GetAccessTokens(ctx, bson.M{"userID": userID})
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if _, err = s.accessTokenRepository.DeleteAccessToken(ctx, bson.M{"_id": token.ID}); err != nil {
+			return err
+		}
+	}
+
+	exports, err := s.exportRepository.GetExports(ctx, bson.M{"userID": userID})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range exports {
+		if e.File == "" {
+			continue
+		}
+
+		if err = s.storage.Delete(ctx, e.File); err != nil {
+			return err
+		}
+	}
+
+	if _, err = s.exportRepository.DeleteExports(ctx, bson.M{"userID": userID}); err != nil {
+		return err
+	}
+
+	if _, err = s.outboxRepository.DeleteOutboxEntries(ctx, bson.M{"userID": userID}); err != nil {
+		return err
+	}
+
+	if _, err = s.notificationRepository.DeleteNotifications(ctx, bson.M{"userID": userID}); err != nil {
+		return err
+	}
+
+	if _, err = s.signInRepository.DeleteSignInFailures(ctx, bson.M{
+		"_id": accountFailuresKey(userID),
+	}); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	if err = s.limiter.Forget(ctx, ratelimit.UserKey(userID.Hex())); err != nil {
+		return err
+	}
+
+	if err = s.repository.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditAccountDeleted,
+		UserID: userID,
+	})
+
+	return nil
+}
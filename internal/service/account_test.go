@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/ratelimit"
+	mock_repository "time-capsule/internal/repository/mocks"
+	mock_storage "time-capsule/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAccountService_DeleteAccount(t *testing.T) {
+	type mocks struct {
+		users *mock_repository.MockUserRepository
+		audit *mock_repository.MockAuditRepository
+	}
+
+	var (
+		userID      = primitive.NewObjectID()
+		scheduledAt = time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	)
+
+	hash, err := hashPassword("Qwerty123", bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		mockBehavior  func(m mocks)
+		password      string
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID, PasswordHash: hash}, nil).Times(1)
+				m.users.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ primitive.ObjectID, update bson.M) error {
+						at := update["$set"].(bson.M)["deletionScheduledAt"].(time.Time)
+						assert.WithinDuration(t, time.Now().UTC().Add(accountDeletionGracePeriod), at, time.Minute)
+						return nil
+					}).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditDeletionScheduled, event.Action)
+						return event, nil
+					}).Times(1)
+			},
+			password:      "Qwerty123",
+			expectedError: nil,
+		},
+		{
+			name: "OK-Without-Password",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID}, nil).Times(1)
+				m.users.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).Return(nil).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Already-Scheduled",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID, PasswordHash: hash, DeletionScheduledAt: &scheduledAt}, nil).Times(1)
+			},
+			password:      "Qwerty123",
+			expectedError: nil,
+		},
+		{
+			name: "Wrong-Password",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID, PasswordHash: hash}, nil).Times(1)
+			},
+			password:      "Qwerty1234",
+			expectedError: ErrInvalidCredentials,
+		},
+		{
+			name: "Not-Found",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			expectedError: ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				m = mocks{
+					users: mock_repository.NewMockUserRepository(c),
+					audit: mock_repository.NewMockAuditRepository(c),
+				}
+				svc = NewAccountService(m.users, nil, nil, nil, nil, nil, nil, nil, m.audit, nil, nil, nil)
+			)
+
+			test.mockBehavior(m)
+
+			deletion, err := svc.DeleteAccount(context.Background(), userID, domain.DeleteAccountDTO{Password: test.password})
+			assert.Equal(t, test.expectedError, err)
+
+			if test.name == "Already-Scheduled" {
+				assert.Equal(t, scheduledAt, deletion.ScheduledAt)
+			}
+		})
+	}
+}
+
+func TestAccountService_PurgeAccounts(t *testing.T) {
+	type mocks struct {
+		users         *mock_repository.MockUserRepository
+		capsules      *mock_repository.MockCapsuleRepository
+		collections   *mock_repository.MockCollectionRepository
+		tokens        *mock_repository.MockAccessTokenRepository
+		exports       *mock_repository.MockExportRepository
+		outbox        *mock_repository.MockOutboxRepository
+		notifications *mock_repository.MockNotificationRepository
+		signIns       *mock_repository.MockSignInFailureRepository
+		audit         *mock_repository.MockAuditRepository
+		storage       *mock_storage.MockStorage
+	}
+
+	var (
+		now          = time.Now().UTC()
+		userID       = primitive.NewObjectID()
+		capsuleID    = primitive.NewObjectID()
+		collectionID = primitive.NewObjectID()
+		tokenID      = primitive.NewObjectID()
+		dueFilter    = bson.M{"deletionScheduledAt": bson.M{"$lte": now}}
+	)
+
+	tests := []struct {
+		name         string
+		mockBehavior func(m mocks)
+		expectError  bool
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUsers(gomock.Any(), dueFilter).Return([]*domain.User{{ID: userID}}, nil).Times(1)
+
+				m.capsules.EXPECT().GetCapsules(gomock.Any(), bson.M{"userID": userID}).
+					Return([]*domain.Capsule{{ID: capsuleID, UserID: userID}}, nil).Times(1)
+				m.capsules.EXPECT().GetCapsule(gomock.Any(), bson.M{"_id": capsuleID}).
+					Return(&domain.Capsule{ID: capsuleID, UserID: userID, Images: []string{"1.jpg", "2.jpg"}}, nil).Times(1)
+				m.outbox.EXPECT().DeleteOutboxEntries(gomock.Any(), bson.M{"capsuleID": capsuleID}).Return(int64(2), nil).Times(1)
+				m.storage.EXPECT().Delete(gomock.Any(), "1.jpg").Return(nil).Times(1)
+				m.storage.EXPECT().Delete(gomock.Any(), "2.jpg").Return(nil).Times(1)
+				m.capsules.EXPECT().DeleteCapsule(gomock.Any(), capsuleID).Return(nil).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditCapsuleDeleted, event.Action)
+						assert.Equal(t, capsuleID.Hex(), event.Details["capsuleID"])
+						return event, nil
+					}).Times(1)
+
+				m.collections.EXPECT().GetCollections(gomock.Any(), bson.M{"userID": userID}).
+					Return([]*domain.Collection{{ID: collectionID}}, nil).Times(1)
+				m.collections.EXPECT().DeleteCollection(gomock.Any(), collectionID).Return(nil).Times(1)
+
+				m.tokens.EXPECT().GetAccessTokens(gomock.Any(), bson.M{"userID": userID}).
+					Return([]*domain.AccessToken{{ID: tokenID}}, nil).Times(1)
+				m.tokens.EXPECT().DeleteAccessToken(gomock.Any(), bson.M{"_id": tokenID}).Return(int64(1), nil).Times(1)
+
+				m.exports.EXPECT().GetExports(gomock.Any(), bson.M{"userID": userID}).
+					Return([]*domain.Export{{File: "export.zip"}, {}}, nil).Times(1)
+				m.storage.EXPECT().Delete(gomock.Any(), "export.zip").Return(nil).Times(1)
+				m.exports.EXPECT().DeleteExports(gomock.Any(), bson.M{"userID": userID}).Return(int64(2), nil).Times(1)
+
+				m.outbox.EXPECT().DeleteOutboxEntries(gomock.Any(), bson.M{"userID": userID}).Return(int64(3), nil).Times(1)
+				m.notifications.EXPECT().DeleteNotifications(gomock.Any(), bson.M{"userID": userID}).Return(int64(1), nil).Times(1)
+				m.signIns.EXPECT().DeleteSignInFailures(gomock.Any(), bson.M{"_id": accountFailuresKey(userID)}).
+					Return(nil, mongo.ErrNoDocuments).Times(1)
+
+				m.users.EXPECT().DeleteUser(gomock.Any(), userID).Return(nil).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditAccountDeleted, event.Action)
+						assert.Equal(t, userID, event.UserID)
+						return event, nil
+					}).Times(1)
+			},
+			expectError: false,
+		},
+		{
+			name: "Nothing-Due",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUsers(gomock.Any(), dueFilter).Return(nil, nil).Times(1)
+			},
+			expectError: false,
+		},
+		{
+			name: "Storage-Failure",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUsers(gomock.Any(), dueFilter).Return([]*domain.User{{ID: userID}}, nil).Times(1)
+
+				m.capsules.EXPECT().GetCapsules(gomock.Any(), bson.M{"userID": userID}).
+					Return([]*domain.Capsule{{ID: capsuleID, UserID: userID}}, nil).Times(1)
+				m.capsules.EXPECT().GetCapsule(gomock.Any(), bson.M{"_id": capsuleID}).
+					Return(&domain.Capsule{ID: capsuleID, UserID: userID, Images: []string{"1.jpg"}}, nil).Times(1)
+				m.outbox.EXPECT().DeleteOutboxEntries(gomock.Any(), bson.M{"capsuleID": capsuleID}).Return(int64(0), nil).Times(1)
+				m.storage.EXPECT().Delete(gomock.Any(), "1.jpg").Return(errors.New("some error")).Times(1)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				m = mocks{
+					users:         mock_repository.NewMockUserRepository(c),
+					capsules:      mock_repository.NewMockCapsuleRepository(c),
+					collections:   mock_repository.NewMockCollectionRepository(c),
+					tokens:        mock_repository.NewMockAccessTokenRepository(c),
+					exports:       mock_repository.NewMockExportRepository(c),
+					outbox:        mock_repository.NewMockOutboxRepository(c),
+					notifications: mock_repository.NewMockNotificationRepository(c),
+					signIns:       mock_repository.NewMockSignInFailureRepository(c),
+					audit:         mock_repository.NewMockAuditRepository(c),
+					storage:       mock_storage.NewMockStorage(c),
+				}
+				limiter = ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+				policy  = ratelimit.Policy{Name: "test", Limit: 1, Window: time.Hour}
+				svc     = NewAccountService(m.users, m.capsules, m.collections, m.tokens, m.exports, m.outbox,
+					m.notifications, m.signIns, m.audit, NewCapsuleService(m.capsules, m.outbox, m.audit, m.storage, testCapsuleRules),
+					m.storage, limiter)
+			)
+
+			test.mockBehavior(m)
+
+			_, err := limiter.Allow(context.Background(), policy, ratelimit.UserKey(userID.Hex()))
+			assert.NoError(t, err)
+
+			err = svc.PurgeAccounts(context.Background(), now)
+			assert.Equal(t, test.expectError, err != nil)
+
+			// The rate limit counters of a purged user are forgotten.
+			result, err := limiter.Allow(context.Background(), policy, ratelimit.UserKey(userID.Hex()))
+			assert.NoError(t, err)
+			assert.Equal(t, test.name == "OK", result.Allowed)
+		})
+	}
+}
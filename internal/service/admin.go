@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxUserSearchResults = 50
+
+var (
+	ErrAccountDisabled = errors.New("the account is disabled")
+	ErrInvalidRole     = fmt.Errorf("role must be %q, %q or empty", domain.RoleSupport, domain.RoleAdmin)
+	ErrOwnAccount      = errors.New("staff can't disable their own account or change their own role")
+	ErrNothingToResend = errors.New("the capsule has no notifications to resend, it hasn't opened yet")
+)
+
+// HasRole reports whether a user with the role has the required one. Admins have every role.
+func HasRole(role, required string) bool {
+	return role == required || role == domain.RoleAdmin
+}
+
+type adminService struct {
+	userRepository    repository.UserRepository
+	capsuleRepository repository.CapsuleRepository
+	outboxRepository  repository.OutboxRepository
+	exportRepository  repository.ExportRepository
+	audit             *auditLog
+
+	// The worker runs in the same process, it reports its cycles here.
+	mu                  sync.Mutex
+	lastCycleStartedAt  time.Time
+	lastCycleFinishedAt time.Time
+}
+
+func NewAdminService(userRepository repository.UserRepository, capsuleRepository repository.CapsuleRepository,
+	outboxRepository repository.OutboxRepository, exportRepository repository.ExportRepository,
+	auditRepository repository.AuditRepository) AdminService {
+	return &adminService{
+		userRepository:    userRepository,
+		capsuleRepository: capsuleRepository,
+		outboxRepository:  outboxRepository,
+		exportRepository:  exportRepository,
+		audit:             &auditLog{repository: auditRepository},
+	}
+}
+
+// SearchUsers finds users by ID, or by a part of their email, username or display name.
+func (s *adminService) SearchUsers(ctx context.Context, query string) ([]*domain.User, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"email": pattern},
+			bson.M{"username": pattern},
+			bson.M{"displayName": pattern},
+		},
+	}
+
+	if id, err := primitive.ObjectIDFromHex(query); err == nil {
+		filter = bson.M{"_id": id}
+	}
+
+	users, err := s.userRepository.SearchUsers(ctx, filter, maxUserSearchResults)
+	if err != nil {
+		slog.ErrorContext(ctx, "SearchUsers", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return users, nil
+}
+
+func (s *adminService) GetUser(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	user, err := s.userRepository.GetUser(ctx, bson.M{"_id": id})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+
+		slog.ErrorContext(ctx, "GetUser", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return user, nil
+}
+
+// GetUserCapsules returns the metadata of the user's capsules, never their content.
+func (s *adminService) GetUserCapsules(ctx context.Context, userID primitive.ObjectID) ([]*domain.CapsuleMetadata, error) {
+	capsules, err := s.capsuleRepository.GetCapsules(ctx, bson.M{"userID": userID})
+	if err != nil {
+		slog.ErrorContext(ctx, "GetUserCapsules", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	res := make([]*domain.CapsuleMetadata, len(capsules))
+	for i, capsule := range capsules {
+		res[i] = capsuleMetadata(capsule)
+	}
+
+	return res, nil
+}
+
+// GetCapsuleMetadata returns the metadata of the capsule, never its content.
+func (s *adminService) GetCapsuleMetadata(ctx context.Context, id primitive.ObjectID) (*domain.CapsuleMetadata, error) {
+	capsule, err := s.getCapsule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return capsuleMetadata(capsule), nil
+}
+
+// ResendNotifications queues the emails sent when the capsule last opened again, to the owner and the recipients.
+// The emails were rendered when they were first queued, so they're sent as they were. The owner's email of the
+// last opening is the last one queued, and its key holds the occurrence the recipients' emails were queued for.
+// Sent emails expire after a while, along with the content they carry.
+func (s *adminService) ResendNotifications(ctx context.Context, id primitive.ObjectID) error {
+	capsule, err := s.getCapsule(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	opened, err := s.outboxRepository.GetOutboxEntries(ctx, bson.M{
+		"capsuleID": id,
+		"template":  mail.TemplateOpened,
+	}, 1)
+	if err != nil {
+		slog.ErrorContext(ctx, "ResendNotifications", "error", err)
+		return ErrDBFailure
+	}
+
+	if len(opened) == 0 {
+		return ErrNothingToResend
+	}
+
+	var (
+		occurrence = strings.TrimPrefix(opened[0].Key, domain.OutboxKey(mail.TemplateOpened, id.Hex())+":")
+		keys       = bson.A{opened[0].Key}
+	)
+	for _, recipient := range capsule.Recipients {
+		keys = append(keys, domain.OutboxKey(mail.TemplateRecipient, id.Hex(), occurrence, recipient))
+	}
+
+	matched, err := s.outboxRepository.UpdateOutboxEntries(ctx, bson.M{
+		"capsuleID": id,
+		"key":       bson.M{"$in": keys},
+		"status":    bson.M{"$ne": domain.OutboxStatusPending},
+	}, bson.M{
+		"$set": bson.M{
+			"status":        domain.OutboxStatusPending,
+			"attempts":      0,
+			"nextAttemptAt": time.Now().UTC(),
+		},
+		"$unset": bson.M{
+			"lastError": "",
+			"sentAt":    "",
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ResendNotifications", "error", err)
+		return ErrDBFailure
+	}
+
+	if matched == 0 {
+		return ErrNothingToResend
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditNotificationsResent,
+		UserID: capsule.UserID,
+		Details: map[string]string{
+			"capsuleID": id.Hex(),
+			"emails":    strconv.FormatInt(matched, 10),
+		},
+	})
+
+	return nil
+}
+
+// DisableUser disables the account: the user can't sign in, and their tokens are rejected until it's enabled again.
+// staffID is the staff member doing it, nil on the command line.
+func (s *adminService) DisableUser(ctx context.Context, staffID, userID primitive.ObjectID) error {
+	if staffID == userID {
+		return ErrOwnAccount
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.DisabledAt != nil {
+		return nil
+	}
+
+	if err = s.userRepository.UpdateUser(ctx, userID, bson.M{
+		"$set": bson.M{
+			"disabledAt": time.Now().UTC(),
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "DisableUser", "error", err)
+		return ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditAccountDisabled,
+		UserID: userID,
+	})
+
+	return nil
+}
+
+func (s *adminService) EnableUser(ctx context.Context, staffID, userID primitive.ObjectID) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.DisabledAt == nil {
+		return nil
+	}
+
+	if err = s.userRepository.UpdateUser(ctx, userID, bson.M{
+		"$unset": bson.M{
+			"disabledAt": "",
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "EnableUser", "error", err)
+		return ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditAccountEnabled,
+		UserID: userID,
+	})
+
+	return nil
+}
+
+// UpdateRole grants the user a role, or revokes it with an empty one.
+func (s *adminService) UpdateRole(ctx context.Context, staffID, userID primitive.ObjectID, input domain.UpdateRoleDTO) error {
+	switch input.Role {
+	case "", domain.RoleSupport, domain.RoleAdmin:
+	default:
+		return ErrInvalidRole
+	}
+
+	if staffID == userID {
+		return ErrOwnAccount
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == input.Role {
+		return nil
+	}
+
+	update := bson.M{"$set": bson.M{"role": input.Role}}
+	if input.Role == "" {
+		update = bson.M{"$unset": bson.M{"role": ""}}
+	}
+
+	if err = s.userRepository.UpdateUser(ctx, userID, update); err != nil {
+		slog.ErrorContext(ctx, "UpdateRole", "error", err)
+		return ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditRoleChanged,
+		UserID: userID,
+		Details: map[string]string{
+			"from": user.Role,
+			"to":   input.Role,
+		},
+	})
+
+	return nil
+}
+
+// RecordWorkerCycle records a cycle of the worker, reported in its status.
+func (s *adminService) RecordWorkerCycle(startedAt, finishedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastCycleStartedAt = startedAt
+	s.lastCycleFinishedAt = finishedAt
+}
+
+// GetWorkerStatus returns when the worker last ran and how much work is waiting for it.
+func (s *adminService) GetWorkerStatus(ctx context.Context) (*domain.WorkerStatus, error) {
+	var (
+		now    = time.Now().UTC()
+		status = &domain.WorkerStatus{}
+		err    error
+	)
+
+	s.mu.Lock()
+	if !s.lastCycleStartedAt.IsZero() {
+		startedAt, finishedAt := s.lastCycleStartedAt, s.lastCycleFinishedAt
+		status.LastCycleStartedAt = &startedAt
+		status.LastCycleFinishedAt = &finishedAt
+	}
+	s.mu.Unlock()
+
+	if status.DueCapsules, err = s.capsuleRepository.CountCapsules(ctx, bson.M{
+		"notified": false,
+		"$or": bson.A{
+			bson.M{
+				"nextOccurrenceAt": bson.M{"$exists": false},
+				"openAt":           bson.M{"$lte": now},
+			},
+			bson.M{
+				"nextOccurrenceAt": bson.M{"$lte": now},
+			},
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "GetWorkerStatus", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if status.PendingEmails, err = s.outboxRepository.CountOutboxEntries(ctx, bson.M{
+		"status": domain.OutboxStatusPending,
+	}); err != nil {
+		slog.ErrorContext(ctx, "GetWorkerStatus", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if status.FailedEmails, err = s.outboxRepository.CountOutboxEntries(ctx, bson.M{
+		"status": domain.OutboxStatusFailed,
+	}); err != nil {
+		slog.ErrorContext(ctx, "GetWorkerStatus", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if status.PendingExports, err = s.exportRepository.CountExports(ctx, bson.M{
+		"status": domain.ExportStatusPending,
+	}); err != nil {
+		slog.ErrorContext(ctx, "GetWorkerStatus", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return status, nil
+}
+
+func (s *adminService) getCapsule(ctx context.Context, id primitive.ObjectID) (*domain.Capsule, error) {
+	capsule, err := s.capsuleRepository.GetCapsule(ctx, bson.M{"_id": id})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+
+		slog.ErrorContext(ctx, "GetCapsule", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return capsule, nil
+}
+
+func capsuleMetadata(capsule *domain.Capsule) *domain.CapsuleMetadata {
+	return &domain.CapsuleMetadata{
+		ID:               capsule.ID,
+		UserID:           capsule.UserID,
+		OpenAt:           capsule.OpenAt,
+		CreatedAt:        capsule.CreatedAt,
+		Opened:           capsule.Notified,
+		Images:           len(capsule.Images),
+		Tags:             capsule.Tags,
+		Collections:      capsule.Collections,
+		Recurrence:       capsule.Recurrence,
+		Occurrences:      capsule.Occurrences,
+		NextOccurrenceAt: capsule.NextOccurrenceAt,
+		Recipients:       capsule.Recipients,
+		Reminders:        capsule.Reminders,
+		RemindersSent:    capsule.RemindersSent,
+		Mode:             capsule.Mode,
+		InactivityDays:   capsule.InactivityDays,
+	}
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/recurrence"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+type adminMocks struct {
+	users    *mock_repository.MockUserRepository
+	capsules *mock_repository.MockCapsuleRepository
+	outbox   *mock_repository.MockOutboxRepository
+	exports  *mock_repository.MockExportRepository
+	audit    *mock_repository.MockAuditRepository
+}
+
+func newAdminMocks(c *gomock.Controller) adminMocks {
+	return adminMocks{
+		users:    mock_repository.NewMockUserRepository(c),
+		capsules: mock_repository.NewMockCapsuleRepository(c),
+		outbox:   mock_repository.NewMockOutboxRepository(c),
+		exports:  mock_repository.NewMockExportRepository(c),
+		audit:    mock_repository.NewMockAuditRepository(c),
+	}
+}
+
+func (m adminMocks) service() AdminService {
+	return NewAdminService(m.users, m.capsules, m.outbox, m.exports, m.audit)
+}
+
+func TestHasRole(t *testing.T) {
+	assert.True(t, HasRole(domain.RoleSupport, domain.RoleSupport))
+	assert.True(t, HasRole(domain.RoleAdmin, domain.RoleSupport))
+	assert.False(t, HasRole(domain.RoleSupport, domain.RoleAdmin))
+	assert.False(t, HasRole("", domain.RoleSupport))
+}
+
+func TestAdminService_SearchUsers(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name          string
+		query         string
+		mockBehavior  func(m adminMocks)
+		expectedUsers []*domain.User
+		expectedError error
+	}{
+		{
+			name:  "By-ID",
+			query: id.Hex(),
+			mockBehavior: func(m adminMocks) {
+				m.users.EXPECT().SearchUsers(gomock.Any(), bson.M{"_id": id}, int64(maxUserSearchResults)).
+					Return([]*domain.User{{ID: id}}, nil).Times(1)
+			},
+			expectedUsers: []*domain.User{{ID: id}},
+		},
+		{
+			name:  "By-Text",
+			query: " foo.bar ",
+			mockBehavior: func(m adminMocks) {
+				pattern := primitive.Regex{Pattern: `foo\.bar`, Options: "i"}
+
+				m.users.EXPECT().SearchUsers(gomock.Any(), bson.M{
+					"$or": bson.A{
+						bson.M{"email": pattern},
+						bson.M{"username": pattern},
+						bson.M{"displayName": pattern},
+					},
+				}, int64(maxUserSearchResults)).Return([]*domain.User{}, nil).Times(1)
+			},
+			expectedUsers: []*domain.User{},
+		},
+		{
+			name:          "Empty-Query",
+			query:         " ",
+			mockBehavior:  func(m adminMocks) {},
+			expectedError: ErrEmptySearchQuery,
+		},
+		{
+			name:  "DB-Failure",
+			query: "foo",
+			mockBehavior: func(m adminMocks) {
+				m.users.EXPECT().SearchUsers(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			m := newAdminMocks(c)
+			test.mockBehavior(m)
+
+			users, err := m.service().SearchUsers(context.Background(), test.query)
+			assert.Equal(t, test.expectedError, err)
+			assert.Equal(t, test.expectedUsers, users)
+		})
+	}
+}
+
+func TestAdminService_DisableUser(t *testing.T) {
+	var (
+		staffID = primitive.NewObjectID()
+		userID  = primitive.NewObjectID()
+		now     = time.Now()
+	)
+
+	tests := []struct {
+		name          string
+		staffID       primitive.ObjectID
+		mockBehavior  func(m adminMocks)
+		expectedError error
+	}{
+		{
+			name:    "OK",
+			staffID: staffID,
+			mockBehavior: func(m adminMocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(&domain.User{ID: userID}, nil).Times(1)
+				m.users.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).Return(nil).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditAccountDisabled, event.Action)
+						assert.Equal(t, userID, event.UserID)
+						assert.Equal(t, staffID, event.ActorID)
+						return event, nil
+					}).Times(1)
+			},
+		},
+		{
+			name:    "Already-Disabled",
+			staffID: staffID,
+			mockBehavior: func(m adminMocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID, DisabledAt: &now}, nil).Times(1)
+			},
+		},
+		{
+			name:          "Own-Account",
+			staffID:       userID,
+			mockBehavior:  func(m adminMocks) {},
+			expectedError: ErrOwnAccount,
+		},
+		{
+			name:    "Not-Found",
+			staffID: staffID,
+			mockBehavior: func(m adminMocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			expectedError: ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			m := newAdminMocks(c)
+			test.mockBehavior(m)
+
+			ctx := WithClient(context.Background(), Client{ActorID: test.staffID})
+
+			err := m.service().DisableUser(ctx, test.staffID, userID)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestAdminService_UpdateRole(t *testing.T) {
+	userID := primitive.NewObjectID()
+
+	tests := []struct {
+		name          string
+		staffID       primitive.ObjectID
+		input         domain.UpdateRoleDTO
+		mockBehavior  func(m adminMocks)
+		expectedError error
+	}{
+		{
+			name:  "Grant",
+			input: domain.UpdateRoleDTO{Role: domain.RoleAdmin},
+			mockBehavior: func(m adminMocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(&domain.User{ID: userID}, nil).Times(1)
+				m.users.EXPECT().UpdateUser(gomock.Any(), userID, bson.M{
+					"$set": bson.M{"role": domain.RoleAdmin},
+				}).Return(nil).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditRoleChanged, event.Action)
+						assert.Equal(t, map[string]string{"from": "", "to": domain.RoleAdmin}, event.Details)
+						return event, nil
+					}).Times(1)
+			},
+		},
+		{
+			name:    "Revoke",
+			staffID: primitive.NewObjectID(),
+			input:   domain.UpdateRoleDTO{},
+			mockBehavior: func(m adminMocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID, Role: domain.RoleSupport}, nil).Times(1)
+				m.users.EXPECT().UpdateUser(gomock.Any(), userID, bson.M{
+					"$unset": bson.M{"role": ""},
+				}).Return(nil).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			},
+		},
+		{
+			name:          "Invalid-Role",
+			input:         domain.UpdateRoleDTO{Role: "owner"},
+			mockBehavior:  func(m adminMocks) {},
+			expectedError: ErrInvalidRole,
+		},
+		{
+			name:          "Own-Account",
+			staffID:       userID,
+			input:         domain.UpdateRoleDTO{},
+			mockBehavior:  func(m adminMocks) {},
+			expectedError: ErrOwnAccount,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			m := newAdminMocks(c)
+			test.mockBehavior(m)
+
+			err := m.service().UpdateRole(context.Background(), test.staffID, userID, test.input)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestAdminService_ResendNotifications(t *testing.T) {
+	var (
+		capsuleID = primitive.NewObjectID()
+		// The capsule recurs, and it opened for the third time, on its occurrence 2.
+		capsule = &domain.Capsule{
+			ID:          capsuleID,
+			Recipients:  []string{"alice@example.com", "bob@example.com"},
+			Recurrence:  &domain.Recurrence{Frequency: recurrence.Yearly, Interval: 1},
+			Occurrences: 3,
+		}
+		openedFilter = bson.M{"capsuleID": capsuleID, "template": mail.TemplateOpened}
+		openedKey    = domain.OutboxKey(mail.TemplateOpened, capsuleID.Hex(), 2)
+	)
+
+	tests := []struct {
+		name          string
+		mockBehavior  func(m adminMocks)
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(m adminMocks) {
+				m.capsules.EXPECT().GetCapsule(gomock.Any(), bson.M{"_id": capsuleID}).Return(capsule, nil).Times(1)
+				m.outbox.EXPECT().GetOutboxEntries(gomock.Any(), openedFilter, int64(1)).
+					Return([]*domain.OutboxEntry{{Key: openedKey}}, nil).Times(1)
+				m.outbox.EXPECT().UpdateOutboxEntries(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, filter, _ bson.M) (int64, error) {
+						// Only the emails of the last opening are queued again, not the ones of occurrences 0 and 1.
+						assert.Equal(t, bson.M{"$in": bson.A{
+							openedKey,
+							domain.OutboxKey(mail.TemplateRecipient, capsuleID.Hex(), 2, "alice@example.com"),
+							domain.OutboxKey(mail.TemplateRecipient, capsuleID.Hex(), 2, "bob@example.com"),
+						}}, filter["key"])
+						return 3, nil
+					}).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditNotificationsResent, event.Action)
+						assert.Equal(t, "3", event.Details["emails"])
+						return event, nil
+					}).Times(1)
+			},
+		},
+		{
+			name: "Not-Opened",
+			mockBehavior: func(m adminMocks) {
+				m.capsules.EXPECT().GetCapsule(gomock.Any(), bson.M{"_id": capsuleID}).Return(capsule, nil).Times(1)
+				m.outbox.EXPECT().GetOutboxEntries(gomock.Any(), openedFilter, int64(1)).Return(nil, nil).Times(1)
+			},
+			expectedError: ErrNothingToResend,
+		},
+		{
+			name: "Nothing-To-Resend",
+			mockBehavior: func(m adminMocks) {
+				m.capsules.EXPECT().GetCapsule(gomock.Any(), bson.M{"_id": capsuleID}).Return(capsule, nil).Times(1)
+				m.outbox.EXPECT().GetOutboxEntries(gomock.Any(), openedFilter, int64(1)).
+					Return([]*domain.OutboxEntry{{Key: openedKey}}, nil).Times(1)
+				m.outbox.EXPECT().UpdateOutboxEntries(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+			},
+			expectedError: ErrNothingToResend,
+		},
+		{
+			name: "Not-Found",
+			mockBehavior: func(m adminMocks) {
+				m.capsules.EXPECT().GetCapsule(gomock.Any(), bson.M{"_id": capsuleID}).
+					Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			expectedError: ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			m := newAdminMocks(c)
+			test.mockBehavior(m)
+
+			err := m.service().ResendNotifications(context.Background(), capsuleID)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestAdminService_GetWorkerStatus(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	var (
+		m          = newAdminMocks(c)
+		svc        = m.service()
+		startedAt  = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		finishedAt = startedAt.Add(time.Second)
+	)
+
+	m.capsules.EXPECT().CountCapsules(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+	m.outbox.EXPECT().CountOutboxEntries(gomock.Any(), bson.M{"status": domain.OutboxStatusPending}).Return(int64(2), nil).Times(1)
+	m.outbox.EXPECT().CountOutboxEntries(gomock.Any(), bson.M{"status": domain.OutboxStatusFailed}).Return(int64(3), nil).Times(1)
+	m.exports.EXPECT().CountExports(gomock.Any(), bson.M{"status": domain.ExportStatusPending}).Return(int64(4), nil).Times(1)
+
+	svc.RecordWorkerCycle(startedAt, finishedAt)
+
+	status, err := svc.GetWorkerStatus(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.WorkerStatus{
+		LastCycleStartedAt:  &startedAt,
+		LastCycleFinishedAt: &finishedAt,
+		DueCapsules:         1,
+		PendingEmails:       2,
+		FailedEmails:        3,
+		PendingExports:      4,
+	}, status)
+}
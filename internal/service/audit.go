@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/logging"
+	"time-capsule/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultAuditEvents = 50
+	maxAuditEvents     = 200
+)
+
+type clientCtxKey struct{}
+
+// Client describes where a request comes from.
+type Client struct {
+	IP        string
+	UserAgent string
+	// ActorID is the signed-in user making the request, if any.
+	ActorID primitive.ObjectID
+}
+
+// WithClient returns a copy of ctx carrying the client of the request, used to attribute audit events.
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientCtxKey{}, client)
+}
+
+func clientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientCtxKey{}).(Client)
+	return client
+}
+
+type auditLog struct {
+	repository repository.AuditRepository
+}
+
+// record appends the event to the audit log, attributed to the client of the request.
+// Failing to record it doesn't fail the action, the error is only logged.
+func (a *auditLog) record(ctx context.Context, event *domain.AuditEvent) {
+	client := clientFromContext(ctx)
+
+	event.ActorID = client.ActorID
+	event.RequestID = logging.RequestID(ctx)
+	event.IP = client.IP
+	event.UserAgent = client.UserAgent
+	event.CreatedAt = time.Now().UTC()
+
+	if _, err := a.repository.InsertAuditEvent(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "failed to record an audit event", "action", event.Action, "error", err)
+	}
+}
+
+type auditService struct {
+	repository repository.AuditRepository
+}
+
+func NewAuditService(repository repository.AuditRepository) AuditService {
+	return &auditService{
+		repository: repository,
+	}
+}
+
+// GetUserAuditEvents returns the audit events about the user, whoever took the actions.
+// The IP and user agent of actions taken by staff are left out, they're only shown to staff.
+func (s *auditService) GetUserAuditEvents(ctx context.Context, userID primitive.ObjectID, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	filter.UserID = userID
+	filter.ActorID = primitive.NilObjectID
+
+	events, err := s.GetAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		if !event.ActorID.IsZero() && event.ActorID != userID {
+			event.IP = ""
+			event.UserAgent = ""
+		}
+	}
+
+	return events, nil
+}
+
+// GetAuditEvents returns the audit events matching the filter, newest first.
+func (s *auditService) GetAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	query := bson.M{}
+
+	if !filter.UserID.IsZero() {
+		query["userID"] = filter.UserID
+	}
+
+	if !filter.ActorID.IsZero() {
+		query["actorID"] = filter.ActorID
+	}
+
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+
+	if filter.RequestID != "" {
+		query["requestID"] = filter.RequestID
+	}
+
+	if !filter.Before.IsZero() {
+		query["_id"] = bson.M{"$lt": filter.Before}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditEvents
+	}
+
+	if limit > maxAuditEvents {
+		limit = maxAuditEvents
+	}
+
+	events, err := s.repository.GetAuditEvents(ctx, query, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "GetAuditEvents", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return events, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/logging"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuditLog_record(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	var (
+		rpstry  = mock_repository.NewMockAuditRepository(c)
+		audit   = &auditLog{repository: rpstry}
+		actorID = primitive.NewObjectID()
+		userID  = primitive.NewObjectID()
+	)
+
+	rpstry.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+			assert.Equal(t, domain.AuditCapsuleDeleted, event.Action)
+			assert.Equal(t, userID, event.UserID)
+			assert.Equal(t, actorID, event.ActorID)
+			assert.Equal(t, "abc123", event.RequestID)
+			assert.Equal(t, "127.0.0.1", event.IP)
+			assert.Equal(t, "curl/8.0", event.UserAgent)
+			assert.False(t, event.CreatedAt.IsZero())
+			return nil, errors.New("some error")
+		}).Times(1)
+
+	ctx := WithClient(logging.WithRequestID(context.Background(), "abc123"), Client{
+		IP:        "127.0.0.1",
+		UserAgent: "curl/8.0",
+		ActorID:   actorID,
+	})
+
+	// Failing to record the event is only logged.
+	audit.record(ctx, &domain.AuditEvent{Action: domain.AuditCapsuleDeleted, UserID: userID})
+}
+
+func TestAuditService_GetAuditEvents(t *testing.T) {
+	var (
+		userID  = primitive.NewObjectID()
+		actorID = primitive.NewObjectID()
+		before  = primitive.NewObjectID()
+	)
+
+	tests := []struct {
+		name          string
+		filter        domain.AuditFilter
+		expectedQuery bson.M
+		expectedLimit int64
+		repoError     error
+		expectedError error
+	}{
+		{
+			name:          "Everything",
+			filter:        domain.AuditFilter{},
+			expectedQuery: bson.M{},
+			expectedLimit: defaultAuditEvents,
+		},
+		{
+			name: "Filtered",
+			filter: domain.AuditFilter{
+				UserID:    userID,
+				ActorID:   actorID,
+				Action:    domain.AuditSignIn,
+				RequestID: "abc123",
+				Before:    before,
+				Limit:     10,
+			},
+			expectedQuery: bson.M{
+				"userID":    userID,
+				"actorID":   actorID,
+				"action":    domain.AuditSignIn,
+				"requestID": "abc123",
+				"_id":       bson.M{"$lt": before},
+			},
+			expectedLimit: 10,
+		},
+		{
+			name:          "Limit-Too-High",
+			filter:        domain.AuditFilter{Limit: 1000},
+			expectedQuery: bson.M{},
+			expectedLimit: maxAuditEvents,
+		},
+		{
+			name:          "DB-Failure",
+			filter:        domain.AuditFilter{},
+			expectedQuery: bson.M{},
+			expectedLimit: defaultAuditEvents,
+			repoError:     errors.New("some error"),
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockAuditRepository(c)
+				svc    = NewAuditService(rpstry)
+			)
+
+			rpstry.EXPECT().GetAuditEvents(gomock.Any(), test.expectedQuery, test.expectedLimit).
+				Return([]*domain.AuditEvent{}, test.repoError).Times(1)
+
+			_, err := svc.GetAuditEvents(context.Background(), test.filter)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestAuditService_GetUserAuditEvents(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	var (
+		rpstry  = mock_repository.NewMockAuditRepository(c)
+		svc     = NewAuditService(rpstry)
+		userID  = primitive.NewObjectID()
+		staffID = primitive.NewObjectID()
+	)
+
+	// Users only see the events about themselves, whatever the filter asks for.
+	rpstry.EXPECT().GetAuditEvents(gomock.Any(), bson.M{"userID": userID}, int64(defaultAuditEvents)).
+		Return([]*domain.AuditEvent{
+			{Action: domain.AuditAccountDisabled, UserID: userID, ActorID: staffID, IP: "192.0.2.2", UserAgent: "staff"},
+			{Action: domain.AuditSignIn, UserID: userID, ActorID: userID, IP: "192.0.2.1", UserAgent: "user"},
+			{Action: domain.AuditSignInFailed, UserID: userID, IP: "192.0.2.3", UserAgent: "unknown"},
+		}, nil).Times(1)
+
+	events, err := svc.GetUserAuditEvents(context.Background(), userID, domain.AuditFilter{
+		UserID:  primitive.NewObjectID(),
+		ActorID: primitive.NewObjectID(),
+	})
+	assert.NoError(t, err)
+
+	// The staff member's IP and user agent are left out, the user's own and those of sign-in attempts are kept.
+	assert.Equal(t, []*domain.AuditEvent{
+		{Action: domain.AuditAccountDisabled, UserID: userID, ActorID: staffID},
+		{Action: domain.AuditSignIn, UserID: userID, ActorID: userID, IP: "192.0.2.1", UserAgent: "user"},
+		{Action: domain.AuditSignInFailed, UserID: userID, IP: "192.0.2.3", UserAgent: "unknown"},
+	}, events)
+}
@@ -4,13 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"html"
+	"log/slog"
 	"reflect"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"time-capsule/config"
 	"time-capsule/internal/domain"
+	"time-capsule/internal/recurrence"
 	"time-capsule/internal/repository"
 	"time-capsule/internal/storage"
+	"time-capsule/internal/tracing"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -18,67 +26,248 @@ import (
 )
 
 const (
-	minMessageLength  = 5
-	minOpenAtInterval = 24 * time.Hour
-	maxUpdateInterval = 30 * time.Minute
+	maxSearchResults = 50
+	snippetRadius    = 60
+	// minStemLength keeps short terms, e.g. "is", from matching every word that starts with them.
+	minStemLength = 3
+
+	maxReminders = 5
 )
 
 var (
 	ErrInvalidTime      = errors.New("opening time cannot be before than now")
-	ErrShortMessage     = errors.New("message must be at least 5 characters long")
+	ErrShortMessage     = errors.New("message is too short")
 	ErrForbidden        = errors.New("not allowed")
 	ErrStorageFailure   = errors.New("something went wrong... try again later :(")
 	ErrEmptyUpdate      = errors.New("no changes to apply")
-	ErrOpenTimeTooEarly = fmt.Errorf("opening time must be at least %d hours from creation date", minOpenAtInterval/time.Hour)
-	ErrUpdateTooLate    = fmt.Errorf("updating the capsule is not allowed after %d minutes from creation", maxUpdateInterval/time.Minute)
+	ErrOpenTimeTooEarly = errors.New("opening time is too close to the creation date")
+	ErrUpdateTooLate    = errors.New("updating the capsule is no longer allowed")
+	ErrEmptySearchQuery = errors.New("search query cannot be empty")
+
+	ErrInvalidMode             = fmt.Errorf("mode must be either %q or %q", domain.CapsuleModeScheduled, domain.CapsuleModeInactivity)
+	ErrInvalidInactivityPeriod = errors.New("inactivity period is out of range")
+	ErrInvalidRecipients       = errors.New("recipients must be valid email addresses")
+	ErrInvalidReminders        = fmt.Errorf("reminders must be at most %d distinct days between 1 and %d", maxReminders, domain.MaxReminderDays)
+
+	ErrInvalidRecurrence = errors.New("recurrence must be a yearly, monthly or weekly schedule with an interval of 1-100, " +
+		"a count of at most 100 and an end date after the opening time (RRULE FREQ, INTERVAL, COUNT and UNTIL are supported)")
 )
 
+// CapsuleRules are the configurable limits capsules are held to.
+type CapsuleRules struct {
+	MinMessageLength int
+	// MinOpenDelay is how long after their creation capsules open at the earliest.
+	MinOpenDelay time.Duration
+	// EditWindow is how long after their creation capsules can be updated.
+	EditWindow        time.Duration
+	MaxRecipients     int
+	MinInactivityDays int
+	MaxInactivityDays int
+}
+
+// NewCapsuleRules returns the rules of the config.
+func NewCapsuleRules(cfg *config.Config) CapsuleRules {
+	return CapsuleRules{
+		MinMessageLength:  cfg.CapsuleMinMessageLength,
+		MinOpenDelay:      cfg.CapsuleMinOpenDelay,
+		EditWindow:        cfg.CapsuleEditWindow,
+		MaxRecipients:     cfg.CapsuleMaxRecipients,
+		MinInactivityDays: cfg.CapsuleMinInactivityDays,
+		MaxInactivityDays: cfg.CapsuleMaxInactivityDays,
+	}
+}
+
+// ruleError is the error of a broken rule, with a message stating the configured limit.
+// It unwraps to the error of the rule, e.g. ErrShortMessage.
+type ruleError struct {
+	err error
+	msg string
+}
+
+func (e *ruleError) Error() string {
+	return e.msg
+}
+
+func (e *ruleError) Unwrap() error {
+	return e.err
+}
+
+func (r CapsuleRules) errShortMessage() error {
+	return &ruleError{err: ErrShortMessage, msg: fmt.Sprintf("message must be at least %d characters long", r.MinMessageLength)}
+}
+
+func (r CapsuleRules) errOpenTimeTooEarly() error {
+	return &ruleError{err: ErrOpenTimeTooEarly,
+		msg: fmt.Sprintf("opening time must be at least %s from creation date", formatDuration(r.MinOpenDelay))}
+}
+
+func (r CapsuleRules) errUpdateTooLate() error {
+	return &ruleError{err: ErrUpdateTooLate,
+		msg: fmt.Sprintf("updating the capsule is not allowed after %s from creation", formatDuration(r.EditWindow))}
+}
+
+func (r CapsuleRules) errInvalidInactivityPeriod() error {
+	return &ruleError{err: ErrInvalidInactivityPeriod,
+		msg: fmt.Sprintf("inactivity period must be between %d and %d days", r.MinInactivityDays, r.MaxInactivityDays)}
+}
+
+func (r CapsuleRules) errInvalidRecipients() error {
+	return &ruleError{err: ErrInvalidRecipients,
+		msg: fmt.Sprintf("recipients must be valid email addresses, at most %d", r.MaxRecipients)}
+}
+
+// formatDuration formats the duration without its zero trailing units, e.g. 24h rather than 24h0m0s.
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+
+	return s
+}
+
+func (r CapsuleRules) validInactivityDays(days int) bool {
+	return days >= r.MinInactivityDays && days <= r.MaxInactivityDays
+}
+
 type capsuleService struct {
 	repository repository.CapsuleRepository
-	storage    storage.Storage
+	// outboxRepository holds the emails rendered for the capsules, which carry their messages.
+	outboxRepository repository.OutboxRepository
+	storage          storage.Storage
+	audit            *auditLog
+	rules            CapsuleRules
 }
 
-func NewCapsuleService(repository repository.CapsuleRepository, storage storage.Storage) CapsuleService {
+func NewCapsuleService(repository repository.CapsuleRepository, outboxRepository repository.OutboxRepository,
+	auditRepository repository.AuditRepository, storage storage.Storage, rules CapsuleRules) CapsuleService {
 	return &capsuleService{
-		repository: repository,
-		storage:    storage,
+		repository:       repository,
+		outboxRepository: outboxRepository,
+		storage:          storage,
+		audit:            &auditLog{repository: auditRepository},
+		rules:            rules,
 	}
 }
 
 func (s *capsuleService) CreateCapsule(ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) (*domain.Capsule, error) {
-	if len(input.Message) < minMessageLength {
-		return nil, ErrShortMessage
+	ctx, span := tracing.Start(ctx, "CapsuleService.CreateCapsule")
+	defer span.End()
+
+	if len(input.Message) < s.rules.MinMessageLength {
+		return nil, s.rules.errShortMessage()
+	}
+
+	switch input.Mode {
+	case "", domain.CapsuleModeScheduled:
+		input.Mode = ""
+	case domain.CapsuleModeInactivity:
+		if !s.rules.validInactivityDays(input.InactivityDays) {
+			return nil, s.rules.errInvalidInactivityPeriod()
+		}
+
+		if input.Recurrence != nil {
+			return nil, ErrInvalidRecurrence
+		}
+
+		input.OpenAt = time.Now().UTC().AddDate(0, 0, input.InactivityDays)
+	default:
+		return nil, ErrInvalidMode
 	}
 
 	if input.OpenAt.UTC().Before(time.Now().UTC()) || input.OpenAt.UTC().Equal(time.Now().UTC()) {
 		return nil, ErrInvalidTime
 	}
 
-	if input.OpenAt.UTC().Sub(time.Now().UTC()) < minOpenAtInterval {
-		return nil, ErrOpenTimeTooEarly
+	if input.OpenAt.UTC().Sub(time.Now().UTC()) < s.rules.MinOpenDelay {
+		return nil, s.rules.errOpenTimeTooEarly()
+	}
+
+	tags, err := normalizeTags(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tags) > maxTagsPerCapsule {
+		return nil, ErrTooManyTags
+	}
+
+	recipients, ok := normalizeRecipients(input.Recipients, s.rules.MaxRecipients)
+	if !ok {
+		return nil, s.rules.errInvalidRecipients()
+	}
+
+	reminders, ok := normalizeReminders(input.Reminders)
+	if !ok {
+		return nil, ErrInvalidReminders
 	}
 
 	toInsert := &domain.Capsule{
-		UserID:    userID,
-		Message:   input.Message,
-		Images:    []string{},
-		OpenAt:    input.OpenAt.UTC(),
-		CreatedAt: time.Now().UTC(),
+		UserID:     userID,
+		Message:    input.Message,
+		Images:     []string{},
+		OpenAt:     input.OpenAt.UTC(),
+		CreatedAt:  time.Now().UTC(),
+		Tags:       tags,
+		Recipients: recipients,
+		Reminders:  reminders,
+	}
+
+	if input.Mode == domain.CapsuleModeInactivity {
+		toInsert.Mode = input.Mode
+		toInsert.InactivityDays = input.InactivityDays
+	}
+
+	if input.Recurrence != nil {
+		toInsert.Recurrence, err = recurrence.Normalize(input.Recurrence, toInsert.OpenAt)
+		if err != nil {
+			return nil, ErrInvalidRecurrence
+		}
+
+		toInsert.NextOccurrenceAt = &toInsert.OpenAt
 	}
 
 	res, err := s.repository.InsertCapsule(ctx, toInsert)
 	if err != nil {
-		log.Println("CreateCapsule", err)
+		slog.ErrorContext(ctx, "CreateCapsule", "error", err)
 		return nil, ErrDBFailure
 	}
 
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action:  domain.AuditCapsuleCreated,
+		UserID:  userID,
+		Details: map[string]string{"capsuleID": res.ID.Hex()},
+	})
+
 	return res, nil
 }
 
-func (s *capsuleService) GetAllCapsules(ctx context.Context, userID primitive.ObjectID) ([]*domain.Capsule, error) {
-	capsules, err := s.repository.GetCapsules(ctx, bson.M{"userID": userID})
+func (s *capsuleService) GetAllCapsules(ctx context.Context, userID primitive.ObjectID, filter domain.CapsuleFilter) ([]*domain.Capsule, error) {
+	ctx, span := tracing.Start(ctx, "CapsuleService.GetAllCapsules")
+	defer span.End()
+
+	query := bson.M{"userID": userID}
+
+	if filter.Tag != "" {
+		tag, ok := normalizeTag(filter.Tag)
+		if !ok {
+			return nil, ErrInvalidTag
+		}
+
+		query["tags"] = tag
+	}
+
+	if !filter.CollectionID.IsZero() {
+		query["collections"] = filter.CollectionID
+	}
+
+	capsules, err := s.repository.GetCapsules(ctx, query)
 	if err != nil {
-		log.Println("GetAllCapsules", err)
+		slog.ErrorContext(ctx, "GetAllCapsules", "error", err)
 		return nil, ErrDBFailure
 	}
 
@@ -86,6 +275,9 @@ func (s *capsuleService) GetAllCapsules(ctx context.Context, userID primitive.Ob
 }
 
 func (s *capsuleService) GetCapsuleByID(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) (*domain.Capsule, error) {
+	ctx, span := tracing.Start(ctx, "CapsuleService.GetCapsuleByID")
+	defer span.End()
+
 	capsule, err := s.repository.GetCapsule(ctx, bson.M{"_id": id})
 
 	if err != nil {
@@ -93,7 +285,7 @@ func (s *capsuleService) GetCapsuleByID(ctx context.Context, userID primitive.Ob
 			return nil, ErrNotFound
 		}
 
-		log.Println("GetCapsuleByID", err)
+		slog.ErrorContext(ctx, "GetCapsuleByID", "error", err)
 		return nil, ErrDBFailure
 	}
 
@@ -105,6 +297,9 @@ func (s *capsuleService) GetCapsuleByID(ctx context.Context, userID primitive.Ob
 }
 
 func (s *capsuleService) UpdateCapsule(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) error {
+	ctx, span := tracing.Start(ctx, "CapsuleService.UpdateCapsule")
+	defer span.End()
+
 	if reflect.DeepEqual(update, domain.UpdateCapsuleDTO{}) {
 		return ErrEmptyUpdate
 	}
@@ -114,62 +309,109 @@ func (s *capsuleService) UpdateCapsule(ctx context.Context, userID primitive.Obj
 		return err
 	}
 
-	if time.Now().UTC().Sub(capsule.CreatedAt) > maxUpdateInterval {
-		return ErrUpdateTooLate
+	if time.Now().UTC().Sub(capsule.CreatedAt) > s.rules.EditWindow {
+		return s.rules.errUpdateTooLate()
 	}
 
 	updateArgs := bson.M{}
 
 	if update.Message != "" {
-		if len(update.Message) < minMessageLength {
-			return ErrShortMessage
+		if len(update.Message) < s.rules.MinMessageLength {
+			return s.rules.errShortMessage()
 		}
 
 		updateArgs["message"] = update.Message
 	}
 
 	if !update.OpenAt.IsZero() {
+		if capsule.Mode == domain.CapsuleModeInactivity {
+			return ErrInvalidMode
+		}
+
 		if update.OpenAt.UTC().Before(time.Now().UTC()) || update.OpenAt.Equal(time.Now().UTC()) {
 			return ErrInvalidTime
 		}
 
-		if update.OpenAt.UTC().Sub(capsule.CreatedAt) < minOpenAtInterval {
-			return ErrOpenTimeTooEarly
+		if update.OpenAt.UTC().Sub(capsule.CreatedAt) < s.rules.MinOpenDelay {
+			return s.rules.errOpenTimeTooEarly()
+		}
+
+		if capsule.Recurrence != nil {
+			if _, err = recurrence.Normalize(capsule.Recurrence, update.OpenAt.UTC()); err != nil {
+				return ErrInvalidRecurrence
+			}
+
+			updateArgs["nextOccurrenceAt"] = update.OpenAt
 		}
 
 		updateArgs["openAt"] = update.OpenAt
 	}
 
 	if err = s.repository.UpdateCapsule(ctx, id, bson.M{"$set": updateArgs}); err != nil {
-		log.Println("UpdateCapsule", err)
+		slog.ErrorContext(ctx, "UpdateCapsule", "error", err)
 		return ErrDBFailure
 	}
 
+	fields := make([]string, 0, len(updateArgs))
+	for _, field := range []string{"message", "openAt"} {
+		if _, ok := updateArgs[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditCapsuleUpdated,
+		UserID: userID,
+		Details: map[string]string{
+			"capsuleID": id.Hex(),
+			"fields":    strings.Join(fields, ","),
+		},
+	})
+
 	return nil
 }
 
+// DeleteCapsule deletes the capsule with its images and the emails rendered for it, so that the pending ones
+// aren't sent and the message isn't kept in them.
 func (s *capsuleService) DeleteCapsule(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) error {
+	ctx, span := tracing.Start(ctx, "CapsuleService.DeleteCapsule")
+	defer span.End()
+
 	capsule, err := s.GetCapsuleByID(ctx, userID, id)
 	if err != nil {
 		return err
 	}
 
+	if _, err = s.outboxRepository.DeleteOutboxEntries(ctx, bson.M{"capsuleID": id}); err != nil {
+		slog.ErrorContext(ctx, "DeleteCapsule", "error", err)
+		return ErrDBFailure
+	}
+
 	for _, img := range capsule.Images {
 		if err = s.storage.Delete(ctx, img); err != nil {
-			log.Println("DeleteCapsule", err)
+			slog.ErrorContext(ctx, "DeleteCapsule", "error", err)
 			return ErrStorageFailure
 		}
 	}
 
 	if err = s.repository.DeleteCapsule(ctx, id); err != nil {
-		log.Println("DeleteCapsule", err)
+		slog.ErrorContext(ctx, "DeleteCapsule", "error", err)
 		return ErrDBFailure
 	}
 
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action:  domain.AuditCapsuleDeleted,
+		UserID:  userID,
+		Details: map[string]string{"capsuleID": id.Hex()},
+	})
+
 	return nil
 }
 
 func (s *capsuleService) AddImage(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) error {
+	ctx, span := tracing.Start(ctx, "CapsuleService.AddImage")
+	defer span.End()
+
 	if _, err := s.GetCapsuleByID(ctx, userID, id); err != nil {
 		return err
 	}
@@ -179,14 +421,26 @@ func (s *capsuleService) AddImage(ctx context.Context, userID primitive.ObjectID
 			"images": image,
 		},
 	}); err != nil {
-		log.Println("AddImage", err)
+		slog.ErrorContext(ctx, "AddImage", "error", err)
 		return ErrDBFailure
 	}
 
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditImageAdded,
+		UserID: userID,
+		Details: map[string]string{
+			"capsuleID": id.Hex(),
+			"image":     image,
+		},
+	})
+
 	return nil
 }
 
 func (s *capsuleService) RemoveImage(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) error {
+	ctx, span := tracing.Start(ctx, "CapsuleService.RemoveImage")
+	defer span.End()
+
 	if _, err := s.GetCapsuleByID(ctx, userID, id); err != nil {
 		return err
 	}
@@ -196,9 +450,219 @@ func (s *capsuleService) RemoveImage(ctx context.Context, userID primitive.Objec
 			"images": image,
 		},
 	}); err != nil {
-		log.Println("RemoveImage", err)
+		slog.ErrorContext(ctx, "RemoveImage", "error", err)
 		return ErrDBFailure
 	}
 
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditImageRemoved,
+		UserID: userID,
+		Details: map[string]string{
+			"capsuleID": id.Hex(),
+			"image":     image,
+		},
+	})
+
 	return nil
 }
+
+// SearchCapsules runs a full-text search over the messages of the user's opened capsules.
+// Sealed capsules are never matched, so their content can't leak through snippets.
+func (s *capsuleService) SearchCapsules(ctx context.Context, userID primitive.ObjectID, query string) ([]*domain.CapsuleSearchResult, error) {
+	ctx, span := tracing.Start(ctx, "CapsuleService.SearchCapsules")
+	defer span.End()
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	results, err := s.repository.SearchCapsules(ctx, bson.M{
+		"userID": userID,
+		"openAt": bson.M{
+			"$lte": time.Now().UTC(),
+		},
+	}, query, maxSearchResults)
+	if err != nil {
+		slog.ErrorContext(ctx, "SearchCapsules", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	terms := searchTerms(query)
+	for _, res := range results {
+		res.Snippet = highlight(res.Capsule.Message, terms)
+	}
+
+	return results, nil
+}
+
+// normalizeRecipients validates and lower-cases the recipient emails, dropping duplicates.
+func normalizeRecipients(recipients []string, maxRecipients int) ([]string, bool) {
+	if len(recipients) == 0 {
+		return nil, true
+	}
+
+	var (
+		res  = make([]string, 0, len(recipients))
+		seen = make(map[string]struct{}, len(recipients))
+	)
+
+	for _, email := range recipients {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if !emailValidation(email) {
+			return nil, false
+		}
+
+		if _, ok := seen[email]; ok {
+			continue
+		}
+
+		seen[email] = struct{}{}
+		res = append(res, email)
+	}
+
+	return res, len(res) <= maxRecipients
+}
+
+// normalizeReminders validates the reminder days and sorts them from the earliest reminder
+// to the latest, dropping duplicates. A nil slice is kept as is, it stands for the default reminders.
+func normalizeReminders(days []int) ([]int, bool) {
+	if days == nil {
+		return nil, true
+	}
+
+	res := make([]int, 0, len(days))
+
+	for _, d := range days {
+		if d < 1 || d > domain.MaxReminderDays {
+			return nil, false
+		}
+
+		if slices.Contains(res, d) {
+			continue
+		}
+
+		res = append(res, d)
+	}
+
+	slices.Sort(res)
+	slices.Reverse(res)
+
+	return res, len(res) <= maxReminders
+}
+
+// searchTerms extracts the plain words of a text search query,
+// dropping negated terms and phrase quotes.
+func searchTerms(query string) []string {
+	var terms []string
+
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+
+		field = strings.Trim(field, `"`)
+		if field != "" {
+			terms = append(terms, strings.ToLower(field))
+		}
+	}
+
+	return terms
+}
+
+// stemSuffixes are the endings cut off search terms, longest first, so that highlight also marks
+// the other forms of a word the text search matched by its stem, e.g. "graduated" for "graduation".
+var stemSuffixes = []string{"ations", "ation", "ings", "ing", "ness", "ment", "ions", "ion", "ers", "er", "ed", "es", "ly", "s", "e", "y"}
+
+// stem cuts the first of stemSuffixes the term ends with, unless the stem would get too short.
+func stem(term string) string {
+	for _, suffix := range stemSuffixes {
+		if s, ok := strings.CutSuffix(term, suffix); ok && utf8.RuneCountInString(s) >= minStemLength {
+			return s
+		}
+	}
+
+	return term
+}
+
+// highlight cuts a window of the message around the first matched word and wraps
+// every match inside it in <mark> tags. The rest of the text is HTML-escaped.
+// A word matches when it starts with the stem of a term, the way the text search matches it.
+func highlight(message string, terms []string) string {
+	var (
+		text  = []rune(message)
+		marks = make([]bool, len(text))
+		first = -1
+		stems = make([]string, len(terms))
+	)
+
+	for i, term := range terms {
+		stems[i] = stem(term)
+	}
+
+	for i := 0; i < len(text); {
+		if !isWordRune(text[i]) {
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(text) && isWordRune(text[j]) {
+			j++
+		}
+
+		word := strings.ToLower(string(text[i:j]))
+
+		for _, s := range stems {
+			if !strings.HasPrefix(word, s) {
+				continue
+			}
+
+			for k := i; k < j; k++ {
+				marks[k] = true
+			}
+
+			if first == -1 {
+				first = i
+			}
+
+			break
+		}
+
+		i = j
+	}
+
+	start, end := 0, len(text)
+	if first != -1 {
+		start = max(0, first-snippetRadius)
+	}
+	end = min(end, start+2*snippetRadius)
+
+	var sb strings.Builder
+
+	if start > 0 {
+		sb.WriteString("…")
+	}
+
+	for i := start; i < end; i++ {
+		if marks[i] && (i == start || !marks[i-1]) {
+			sb.WriteString("<mark>")
+		}
+
+		sb.WriteString(html.EscapeString(string(text[i])))
+
+		if marks[i] && (i == end-1 || !marks[i+1]) {
+			sb.WriteString("</mark>")
+		}
+	}
+
+	if end < len(text) {
+		sb.WriteString("…")
+	}
+
+	return sb.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -19,6 +19,16 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
+// testCapsuleRules are the rules of the services under test, the default ones.
+var testCapsuleRules = CapsuleRules{
+	MinMessageLength:  5,
+	MinOpenDelay:      24 * time.Hour,
+	EditWindow:        30 * time.Minute,
+	MaxRecipients:     10,
+	MinInactivityDays: 7,
+	MaxInactivityDays: 3650,
+}
+
 func TestCapsuleService_CreateCapsule(t *testing.T) {
 	type mockBehavior func(r *mock_repository.MockCapsuleRepository, ctx context.Context,
 		userID primitive.ObjectID, input domain.CreateCapsuleDTO)
@@ -37,9 +47,9 @@ func TestCapsuleService_CreateCapsule(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
-				r.EXPECT().InsertCapsule(ctx, &domain.Capsule{
+				r.EXPECT().InsertCapsule(gomock.Any(), &domain.Capsule{
 					Message:   "some message",
-					OpenAt:    time.Now().UTC().Add(minOpenAtInterval),
+					OpenAt:    time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 					Images:    []string{},
 					CreatedAt: time.Now().UTC(),
 				}).Return(&domain.Capsule{}, nil).Times(1)
@@ -48,7 +58,7 @@ func TestCapsuleService_CreateCapsule(t *testing.T) {
 			userID:        primitive.NilObjectID,
 			input: domain.CreateCapsuleDTO{
 				Message: "some message",
-				OpenAt:  time.Now().Add(minOpenAtInterval),
+				OpenAt:  time.Now().Add(testCapsuleRules.MinOpenDelay),
 			},
 		},
 		{
@@ -58,7 +68,7 @@ func TestCapsuleService_CreateCapsule(t *testing.T) {
 			expectedError: ErrShortMessage,
 			userID:        primitive.NewObjectID(),
 			input: domain.CreateCapsuleDTO{
-				Message: strings.Repeat("a", minMessageLength-1),
+				Message: strings.Repeat("a", testCapsuleRules.MinMessageLength-1),
 			},
 		},
 		{
@@ -80,15 +90,141 @@ func TestCapsuleService_CreateCapsule(t *testing.T) {
 			userID:        primitive.NilObjectID,
 			input: domain.CreateCapsuleDTO{
 				Message: "some message",
-				OpenAt:  time.Now().Add(minOpenAtInterval - 1),
+				OpenAt:  time.Now().Add(testCapsuleRules.MinOpenDelay - 1),
+			},
+		},
+		{
+			name: "OK-Recurring",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
+				openAt := time.Now().UTC().Add(testCapsuleRules.MinOpenDelay)
+
+				r.EXPECT().InsertCapsule(gomock.Any(), &domain.Capsule{
+					Message:   "happy birthday",
+					OpenAt:    openAt,
+					Images:    []string{},
+					CreatedAt: time.Now().UTC(),
+					Recurrence: &domain.Recurrence{
+						Frequency: "yearly",
+						Interval:  1,
+						Count:     10,
+					},
+					NextOccurrenceAt: &openAt,
+				}).Return(&domain.Capsule{}, nil).Times(1)
+			},
+			expectedError: nil,
+			userID:        primitive.NilObjectID,
+			input: domain.CreateCapsuleDTO{
+				Message:    "happy birthday",
+				OpenAt:     time.Now().Add(testCapsuleRules.MinOpenDelay),
+				Recurrence: &domain.Recurrence{RRule: "FREQ=YEARLY;COUNT=10"},
+			},
+		},
+		{
+			name: "Invalid-Recurrence",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
+			},
+			expectedError: ErrInvalidRecurrence,
+			userID:        primitive.NilObjectID,
+			input: domain.CreateCapsuleDTO{
+				Message:    "happy birthday",
+				OpenAt:     time.Now().Add(testCapsuleRules.MinOpenDelay),
+				Recurrence: &domain.Recurrence{Frequency: "hourly"},
+			},
+		},
+		{
+			name: "OK-Inactivity",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
+				r.EXPECT().InsertCapsule(gomock.Any(), &domain.Capsule{
+					Message:        "if you are reading this",
+					OpenAt:         time.Now().UTC().AddDate(0, 0, 30),
+					Images:         []string{},
+					CreatedAt:      time.Now().UTC(),
+					Recipients:     []string{"foo@example.com"},
+					Mode:           domain.CapsuleModeInactivity,
+					InactivityDays: 30,
+				}).Return(&domain.Capsule{}, nil).Times(1)
+			},
+			expectedError: nil,
+			userID:        primitive.NilObjectID,
+			input: domain.CreateCapsuleDTO{
+				Message:        "if you are reading this",
+				Mode:           domain.CapsuleModeInactivity,
+				InactivityDays: 30,
+				Recipients:     []string{" Foo@Example.com", "foo@example.com"},
+			},
+		},
+		{
+			name: "Invalid-Mode",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
+			},
+			expectedError: ErrInvalidMode,
+			userID:        primitive.NilObjectID,
+			input: domain.CreateCapsuleDTO{
+				Message: "some message",
+				Mode:    "whenever",
+			},
+		},
+		{
+			name: "Invalid-Inactivity-Period",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
+			},
+			expectedError: ErrInvalidInactivityPeriod,
+			userID:        primitive.NilObjectID,
+			input: domain.CreateCapsuleDTO{
+				Message:        "some message",
+				Mode:           domain.CapsuleModeInactivity,
+				InactivityDays: testCapsuleRules.MinInactivityDays - 1,
+			},
+		},
+		{
+			name: "OK-Reminders",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
+				r.EXPECT().InsertCapsule(gomock.Any(), &domain.Capsule{
+					Message:   "some message",
+					OpenAt:    time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
+					Images:    []string{},
+					CreatedAt: time.Now().UTC(),
+					Reminders: []int{14, 7, 1},
+				}).Return(&domain.Capsule{}, nil).Times(1)
+			},
+			expectedError: nil,
+			userID:        primitive.NilObjectID,
+			input: domain.CreateCapsuleDTO{
+				Message:   "some message",
+				OpenAt:    time.Now().Add(testCapsuleRules.MinOpenDelay),
+				Reminders: []int{1, 14, 7, 1},
+			},
+		},
+		{
+			name: "Invalid-Reminders",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
+			},
+			expectedError: ErrInvalidReminders,
+			userID:        primitive.NilObjectID,
+			input: domain.CreateCapsuleDTO{
+				Message:   "some message",
+				OpenAt:    time.Now().Add(testCapsuleRules.MinOpenDelay),
+				Reminders: []int{domain.MaxReminderDays + 1},
+			},
+		},
+		{
+			name: "Invalid-Recipients",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
+			},
+			expectedError: ErrInvalidRecipients,
+			userID:        primitive.NilObjectID,
+			input: domain.CreateCapsuleDTO{
+				Message:    "some message",
+				OpenAt:     time.Now().Add(testCapsuleRules.MinOpenDelay),
+				Recipients: []string{"not-an-email"},
 			},
 		},
 		{
 			name: "Creating-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, input domain.CreateCapsuleDTO) {
-				r.EXPECT().InsertCapsule(ctx, &domain.Capsule{
+				r.EXPECT().InsertCapsule(gomock.Any(), &domain.Capsule{
 					Message:   "some message",
-					OpenAt:    time.Now().UTC().Add(minOpenAtInterval + 1*time.Minute),
+					OpenAt:    time.Now().UTC().Add(testCapsuleRules.MinOpenDelay + 1*time.Minute),
 					Images:    []string{},
 					CreatedAt: time.Now().UTC(),
 				}).Return(nil, errors.New("some error")).Times(1)
@@ -97,7 +233,7 @@ func TestCapsuleService_CreateCapsule(t *testing.T) {
 			userID:        primitive.NilObjectID,
 			input: domain.CreateCapsuleDTO{
 				Message: "some message",
-				OpenAt:  time.Now().UTC().Add(minOpenAtInterval + 1*time.Minute),
+				OpenAt:  time.Now().UTC().Add(testCapsuleRules.MinOpenDelay + 1*time.Minute),
 			},
 		},
 	}
@@ -109,32 +245,108 @@ func TestCapsuleService_CreateCapsule(t *testing.T) {
 
 			var (
 				rpstry = mock_repository.NewMockCapsuleRepository(c)
-				svc    = NewCapsuleService(rpstry, nil)
+				audit  = mock_repository.NewMockAuditRepository(c)
+				svc    = NewCapsuleService(rpstry, nil, audit, nil, testCapsuleRules)
 				ctx    = context.Background()
 			)
 
 			test.mockBehavior(rpstry, ctx, test.userID, test.input)
 
+			if test.expectedError == nil {
+				audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditCapsuleCreated, event.Action)
+						return event, nil
+					}).Times(1)
+			}
+
 			_, err := svc.CreateCapsule(ctx, test.userID, test.input)
-			assert.Equal(t, test.expectedError, err)
+			assert.ErrorIs(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestCapsuleRules(t *testing.T) {
+	rules := CapsuleRules{
+		MinMessageLength:  10,
+		MinOpenDelay:      36 * time.Hour,
+		EditWindow:        90 * time.Minute,
+		MaxRecipients:     1,
+		MinInactivityDays: 30,
+		MaxInactivityDays: 60,
+	}
+
+	tests := []struct {
+		name            string
+		err             error
+		expectedError   error
+		expectedMessage string
+	}{
+		{
+			name:            "Short-Message",
+			err:             rules.errShortMessage(),
+			expectedError:   ErrShortMessage,
+			expectedMessage: "message must be at least 10 characters long",
+		},
+		{
+			name:            "Open-Time-Too-Early",
+			err:             rules.errOpenTimeTooEarly(),
+			expectedError:   ErrOpenTimeTooEarly,
+			expectedMessage: "opening time must be at least 36h from creation date",
+		},
+		{
+			name:            "Update-Too-Late",
+			err:             rules.errUpdateTooLate(),
+			expectedError:   ErrUpdateTooLate,
+			expectedMessage: "updating the capsule is not allowed after 1h30m from creation",
+		},
+		{
+			name:            "Invalid-Inactivity-Period",
+			err:             rules.errInvalidInactivityPeriod(),
+			expectedError:   ErrInvalidInactivityPeriod,
+			expectedMessage: "inactivity period must be between 30 and 60 days",
+		},
+		{
+			name:            "Invalid-Recipients",
+			err:             rules.errInvalidRecipients(),
+			expectedError:   ErrInvalidRecipients,
+			expectedMessage: "recipients must be valid email addresses, at most 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.ErrorIs(t, test.err, test.expectedError)
+			assert.EqualError(t, test.err, test.expectedMessage)
 		})
 	}
+
+	svc := NewCapsuleService(nil, nil, nil, nil, rules)
+
+	_, err := svc.CreateCapsule(context.Background(), primitive.NewObjectID(), domain.CreateCapsuleDTO{
+		Message: "Hello!",
+		OpenAt:  time.Now().Add(48 * time.Hour),
+	})
+	assert.EqualError(t, err, "message must be at least 10 characters long")
 }
 
 func TestCapsuleService_GetAllCapsules(t *testing.T) {
 	type mockBehavior func(r *mock_repository.MockCapsuleRepository, ctx context.Context,
 		userID primitive.ObjectID)
 
+	collectionID := primitive.NewObjectID()
+
 	tests := []struct {
 		name          string
 		mockBehavior  mockBehavior
 		expectedError error
 		userID        primitive.ObjectID
+		filter        domain.CapsuleFilter
 	}{
 		{
 			name: "OK",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
-				r.EXPECT().GetCapsules(ctx, bson.M{
+				r.EXPECT().GetCapsules(gomock.Any(), bson.M{
 					"userID": userID,
 				}).Return([]*domain.Capsule{
 					{
@@ -145,10 +357,35 @@ func TestCapsuleService_GetAllCapsules(t *testing.T) {
 			expectedError: nil,
 			userID:        primitive.NewObjectID(),
 		},
+		{
+			name: "OK-Filtered",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().GetCapsules(gomock.Any(), bson.M{
+					"userID":      userID,
+					"tags":        "kids",
+					"collections": collectionID,
+				}).Return([]*domain.Capsule{}, nil).Times(1)
+			},
+			expectedError: nil,
+			userID:        primitive.NewObjectID(),
+			filter: domain.CapsuleFilter{
+				Tag:          " Kids ",
+				CollectionID: collectionID,
+			},
+		},
+		{
+			name:          "Invalid-Tag",
+			mockBehavior:  func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {},
+			expectedError: ErrInvalidTag,
+			userID:        primitive.NewObjectID(),
+			filter: domain.CapsuleFilter{
+				Tag: "#$%",
+			},
+		},
 		{
 			name: "Retrieving-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
-				r.EXPECT().GetCapsules(ctx, bson.M{
+				r.EXPECT().GetCapsules(gomock.Any(), bson.M{
 					"userID": userID,
 				}).Return(nil, errors.New("some error")).Times(1)
 			},
@@ -164,13 +401,13 @@ func TestCapsuleService_GetAllCapsules(t *testing.T) {
 
 			var (
 				rpstry = mock_repository.NewMockCapsuleRepository(c)
-				svc    = NewCapsuleService(rpstry, nil)
+				svc    = NewCapsuleService(rpstry, nil, nil, nil, testCapsuleRules)
 				ctx    = context.Background()
 			)
 
 			test.mockBehavior(rpstry, ctx, test.userID)
 
-			_, err := svc.GetAllCapsules(ctx, test.userID)
+			_, err := svc.GetAllCapsules(ctx, test.userID, test.filter)
 			assert.Equal(t, test.expectedError, err)
 		})
 	}
@@ -190,7 +427,7 @@ func TestCapsuleService_GetCapsuleByID(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID}, nil).Times(1)
 			},
@@ -201,7 +438,7 @@ func TestCapsuleService_GetCapsuleByID(t *testing.T) {
 		{
 			name: "Forbidden",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: primitive.NewObjectID()}, nil).Times(1)
 			},
@@ -212,7 +449,7 @@ func TestCapsuleService_GetCapsuleByID(t *testing.T) {
 		{
 			name: "Retrieving-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(nil, errors.New("some error")).Times(1)
 			},
@@ -223,7 +460,7 @@ func TestCapsuleService_GetCapsuleByID(t *testing.T) {
 		{
 			name: "Retrieving-DB-NotFound",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(nil, mongo.ErrNoDocuments).Times(1)
 			},
@@ -240,7 +477,7 @@ func TestCapsuleService_GetCapsuleByID(t *testing.T) {
 
 			var (
 				rpstry = mock_repository.NewMockCapsuleRepository(c)
-				svc    = NewCapsuleService(rpstry, nil)
+				svc    = NewCapsuleService(rpstry, nil, nil, nil, testCapsuleRules)
 				ctx    = context.Background()
 			)
 
@@ -271,13 +508,13 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID, CreatedAt: time.Now().UTC()}, nil).Times(1)
 
-				r.EXPECT().UpdateCapsule(ctx, id, bson.M{"$set": bson.M{
+				r.EXPECT().UpdateCapsule(gomock.Any(), id, bson.M{"$set": bson.M{
 					"message": "some message",
-					"openAt":  time.Now().UTC().Add(minOpenAtInterval),
+					"openAt":  time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 				}}).Return(nil).Times(1)
 			},
 			expectedError: nil,
@@ -285,17 +522,17 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 			capsuleID:     primitive.NewObjectID(),
 			update: domain.UpdateCapsuleDTO{
 				Message: "some message",
-				OpenAt:  time.Now().UTC().Add(minOpenAtInterval),
+				OpenAt:  time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 			},
 		},
 		{
 			name: "OK-Only-Message",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID, CreatedAt: time.Now().UTC()}, nil).Times(1)
 
-				r.EXPECT().UpdateCapsule(ctx, id, bson.M{"$set": bson.M{
+				r.EXPECT().UpdateCapsule(gomock.Any(), id, bson.M{"$set": bson.M{
 					"message": "some message",
 				}}).Return(nil).Times(1)
 			},
@@ -309,19 +546,19 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 		{
 			name: "OK-Only-OpenAt",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID, CreatedAt: time.Now().UTC()}, nil).Times(1)
 
-				r.EXPECT().UpdateCapsule(ctx, id, bson.M{"$set": bson.M{
-					"openAt": time.Now().UTC().Add(minOpenAtInterval),
+				r.EXPECT().UpdateCapsule(gomock.Any(), id, bson.M{"$set": bson.M{
+					"openAt": time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 				}}).Return(nil).Times(1)
 			},
 			expectedError: nil,
 			userID:        primitive.NewObjectID(),
 			capsuleID:     primitive.NewObjectID(),
 			update: domain.UpdateCapsuleDTO{
-				OpenAt: time.Now().UTC().Add(minOpenAtInterval),
+				OpenAt: time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 			},
 		},
 		{
@@ -335,7 +572,7 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 		{
 			name: "Forbidden",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: primitive.NewObjectID(), CreatedAt: time.Now().UTC()}, nil).Times(1)
 			},
@@ -344,13 +581,13 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 			capsuleID:     primitive.NewObjectID(),
 			update: domain.UpdateCapsuleDTO{
 				Message: "some message",
-				OpenAt:  time.Now().UTC().Add(minOpenAtInterval),
+				OpenAt:  time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 			},
 		},
 		{
 			name: "Retrieving-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(nil, errors.New("some error")).Times(1)
 			},
@@ -359,13 +596,13 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 			capsuleID:     primitive.NewObjectID(),
 			update: domain.UpdateCapsuleDTO{
 				Message: "some message",
-				OpenAt:  time.Now().UTC().Add(minOpenAtInterval),
+				OpenAt:  time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 			},
 		},
 		{
 			name: "Retrieving-DB-NotFound",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(nil, mongo.ErrNoDocuments).Times(1)
 			},
@@ -374,28 +611,28 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 			capsuleID:     primitive.NewObjectID(),
 			update: domain.UpdateCapsuleDTO{
 				Message: "some message",
-				OpenAt:  time.Now().UTC().Add(minOpenAtInterval),
+				OpenAt:  time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 			},
 		},
 		{
 			name: "Update-Too-Late",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
-				}).Return(&domain.Capsule{UserID: userID, CreatedAt: time.Now().UTC().Add(-1 * (maxUpdateInterval + 1))}, nil).Times(1)
+				}).Return(&domain.Capsule{UserID: userID, CreatedAt: time.Now().UTC().Add(-1 * (testCapsuleRules.EditWindow + 1))}, nil).Times(1)
 			},
 			expectedError: ErrUpdateTooLate,
 			userID:        primitive.NewObjectID(),
 			capsuleID:     primitive.NewObjectID(),
 			update: domain.UpdateCapsuleDTO{
 				Message: "some message",
-				OpenAt:  time.Now().UTC().Add(minOpenAtInterval),
+				OpenAt:  time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 			},
 		},
 		{
 			name: "Message-Too-Short",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID, CreatedAt: time.Now().UTC()}, nil).Times(1)
 			},
@@ -403,13 +640,13 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 			userID:        primitive.NewObjectID(),
 			capsuleID:     primitive.NewObjectID(),
 			update: domain.UpdateCapsuleDTO{
-				Message: strings.Repeat("a", minMessageLength-1),
+				Message: strings.Repeat("a", testCapsuleRules.MinMessageLength-1),
 			},
 		},
 		{
 			name: "OpenAt-Invalid-Time",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID, CreatedAt: time.Now().UTC()}, nil).Times(1)
 			},
@@ -423,7 +660,7 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 		{
 			name: "OpenAt-Too-Early",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID, CreatedAt: time.Now().UTC()}, nil).Times(1)
 			},
@@ -431,19 +668,19 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 			userID:        primitive.NewObjectID(),
 			capsuleID:     primitive.NewObjectID(),
 			update: domain.UpdateCapsuleDTO{
-				OpenAt: time.Now().UTC().Add(minOpenAtInterval - 1),
+				OpenAt: time.Now().UTC().Add(testCapsuleRules.MinOpenDelay - 1),
 			},
 		},
 		{
 			name: "Updating-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID, CreatedAt: time.Now().UTC()}, nil).Times(1)
 
-				r.EXPECT().UpdateCapsule(ctx, id, bson.M{"$set": bson.M{
+				r.EXPECT().UpdateCapsule(gomock.Any(), id, bson.M{"$set": bson.M{
 					"message": "some message",
-					"openAt":  time.Now().UTC().Add(minOpenAtInterval),
+					"openAt":  time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 				}}).Return(errors.New("some error")).Times(1)
 			},
 			expectedError: ErrDBFailure,
@@ -451,7 +688,7 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 			capsuleID:     primitive.NewObjectID(),
 			update: domain.UpdateCapsuleDTO{
 				Message: "some message",
-				OpenAt:  time.Now().UTC().Add(minOpenAtInterval),
+				OpenAt:  time.Now().UTC().Add(testCapsuleRules.MinOpenDelay),
 			},
 		},
 	}
@@ -463,14 +700,23 @@ func TestCapsuleService_UpdateCapsule(t *testing.T) {
 
 			var (
 				rpstry = mock_repository.NewMockCapsuleRepository(c)
-				svc    = NewCapsuleService(rpstry, nil)
+				audit  = mock_repository.NewMockAuditRepository(c)
+				svc    = NewCapsuleService(rpstry, nil, audit, nil, testCapsuleRules)
 				ctx    = context.Background()
 			)
 
 			test.mockBehavior(rpstry, ctx, test.userID, test.capsuleID, test.update)
 
+			if test.expectedError == nil {
+				audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditCapsuleUpdated, event.Action)
+						return event, nil
+					}).Times(1)
+			}
+
 			err := svc.UpdateCapsule(ctx, test.userID, test.capsuleID, test.update)
-			assert.Equal(t, test.expectedError, err)
+			assert.ErrorIs(t, err, test.expectedError)
 		})
 	}
 }
@@ -481,9 +727,16 @@ func TestCapsuleService_DeleteCapsule(t *testing.T) {
 
 	type storageMockBehavior func(s *mock_storage.MockStorage, ctx context.Context, image string)
 
+	type outboxMockBehavior func(o *mock_repository.MockOutboxRepository, id primitive.ObjectID)
+
+	deletesOutbox := func(o *mock_repository.MockOutboxRepository, id primitive.ObjectID) {
+		o.EXPECT().DeleteOutboxEntries(gomock.Any(), bson.M{"capsuleID": id}).Return(int64(1), nil).Times(1)
+	}
+
 	tests := []struct {
 		name                string
 		mockBehavior        mockBehavior
+		outboxMockBehavior  outboxMockBehavior
 		storageMockBehavior storageMockBehavior
 		expectedError       error
 		userID              primitive.ObjectID
@@ -492,17 +745,18 @@ func TestCapsuleService_DeleteCapsule(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{
 					UserID: userID,
 					Images: []string{"123.jpg"},
 				}, nil).Times(1)
 
-				r.EXPECT().DeleteCapsule(ctx, id).Return(nil).Times(1)
+				r.EXPECT().DeleteCapsule(gomock.Any(), id).Return(nil).Times(1)
 			},
+			outboxMockBehavior: deletesOutbox,
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, image string) {
-				s.EXPECT().Delete(ctx, image).Return(nil)
+				s.EXPECT().Delete(gomock.Any(), image).Return(nil)
 			},
 			expectedError: nil,
 			userID:        primitive.NewObjectID(),
@@ -511,10 +765,11 @@ func TestCapsuleService_DeleteCapsule(t *testing.T) {
 		{
 			name: "Forbidden",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: primitive.NewObjectID()}, nil).Times(1)
 			},
+			outboxMockBehavior:  func(o *mock_repository.MockOutboxRepository, id primitive.ObjectID) {},
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, image string) {},
 			expectedError:       ErrForbidden,
 			userID:              primitive.NewObjectID(),
@@ -523,10 +778,11 @@ func TestCapsuleService_DeleteCapsule(t *testing.T) {
 		{
 			name: "Retrieving-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(nil, errors.New("some error")).Times(1)
 			},
+			outboxMockBehavior:  func(o *mock_repository.MockOutboxRepository, id primitive.ObjectID) {},
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, image string) {},
 			expectedError:       ErrDBFailure,
 			userID:              primitive.NewObjectID(),
@@ -535,27 +791,47 @@ func TestCapsuleService_DeleteCapsule(t *testing.T) {
 		{
 			name: "Retrieving-DB-NotFound",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(nil, mongo.ErrNoDocuments).Times(1)
 			},
+			outboxMockBehavior:  func(o *mock_repository.MockOutboxRepository, id primitive.ObjectID) {},
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, image string) {},
 			expectedError:       ErrNotFound,
 			userID:              primitive.NewObjectID(),
 			capsuleID:           primitive.NewObjectID(),
 		},
+		{
+			name: "Deleting-Outbox-DB-Failure",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
+					"_id": id,
+				}).Return(&domain.Capsule{
+					UserID: userID,
+					Images: []string{"123.jpg"},
+				}, nil).Times(1)
+			},
+			outboxMockBehavior: func(o *mock_repository.MockOutboxRepository, id primitive.ObjectID) {
+				o.EXPECT().DeleteOutboxEntries(gomock.Any(), bson.M{"capsuleID": id}).Return(int64(0), errors.New("some error")).Times(1)
+			},
+			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, image string) {},
+			expectedError:       ErrDBFailure,
+			userID:              primitive.NewObjectID(),
+			capsuleID:           primitive.NewObjectID(),
+		},
 		{
 			name: "Storage-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{
 					UserID: userID,
 					Images: []string{"123.jpg"},
 				}, nil).Times(1)
 			},
+			outboxMockBehavior: deletesOutbox,
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, image string) {
-				s.EXPECT().Delete(ctx, image).Return(errors.New("some error"))
+				s.EXPECT().Delete(gomock.Any(), image).Return(errors.New("some error"))
 			},
 			expectedError: ErrStorageFailure,
 			userID:        primitive.NewObjectID(),
@@ -564,17 +840,18 @@ func TestCapsuleService_DeleteCapsule(t *testing.T) {
 		{
 			name: "Deleting-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{
 					UserID: userID,
 					Images: []string{"123.jpg"},
 				}, nil).Times(1)
 
-				r.EXPECT().DeleteCapsule(ctx, id).Return(errors.New("some error")).Times(1)
+				r.EXPECT().DeleteCapsule(gomock.Any(), id).Return(errors.New("some error")).Times(1)
 			},
+			outboxMockBehavior: deletesOutbox,
 			storageMockBehavior: func(s *mock_storage.MockStorage, ctx context.Context, image string) {
-				s.EXPECT().Delete(ctx, image).Return(nil)
+				s.EXPECT().Delete(gomock.Any(), image).Return(nil)
 			},
 			expectedError: ErrDBFailure,
 			userID:        primitive.NewObjectID(),
@@ -589,12 +866,23 @@ func TestCapsuleService_DeleteCapsule(t *testing.T) {
 
 			var (
 				rpstry = mock_repository.NewMockCapsuleRepository(c)
+				outbox = mock_repository.NewMockOutboxRepository(c)
 				strge  = mock_storage.NewMockStorage(c)
-				svc    = NewCapsuleService(rpstry, strge)
+				audit  = mock_repository.NewMockAuditRepository(c)
+				svc    = NewCapsuleService(rpstry, outbox, audit, strge, testCapsuleRules)
 				ctx    = context.Background()
 			)
 
 			test.mockBehavior(rpstry, ctx, test.userID, test.capsuleID)
+			test.outboxMockBehavior(outbox, test.capsuleID)
+
+			if test.expectedError == nil {
+				audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditCapsuleDeleted, event.Action)
+						return event, nil
+					}).Times(1)
+			}
 			test.storageMockBehavior(strge, ctx, "123.jpg")
 
 			err := svc.DeleteCapsule(ctx, test.userID, test.capsuleID)
@@ -618,11 +906,11 @@ func TestCapsuleService_AddImage(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID}, nil).Times(1)
 
-				r.EXPECT().UpdateCapsule(ctx, id, bson.M{
+				r.EXPECT().UpdateCapsule(gomock.Any(), id, bson.M{
 					"$push": bson.M{
 						"images": image,
 					},
@@ -636,7 +924,7 @@ func TestCapsuleService_AddImage(t *testing.T) {
 		{
 			name: "Forbidden",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: primitive.NewObjectID()}, nil).Times(1)
 			},
@@ -647,7 +935,7 @@ func TestCapsuleService_AddImage(t *testing.T) {
 		{
 			name: "Retrieving-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(nil, errors.New("some error")).Times(1)
 			},
@@ -658,7 +946,7 @@ func TestCapsuleService_AddImage(t *testing.T) {
 		{
 			name: "Retrieving-DB-NotFound",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(nil, mongo.ErrNoDocuments).Times(1)
 			},
@@ -669,11 +957,11 @@ func TestCapsuleService_AddImage(t *testing.T) {
 		{
 			name: "Updating-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID}, nil).Times(1)
 
-				r.EXPECT().UpdateCapsule(ctx, id, bson.M{
+				r.EXPECT().UpdateCapsule(gomock.Any(), id, bson.M{
 					"$push": bson.M{
 						"images": image,
 					},
@@ -692,12 +980,21 @@ func TestCapsuleService_AddImage(t *testing.T) {
 
 			var (
 				rpstry = mock_repository.NewMockCapsuleRepository(c)
-				svc    = NewCapsuleService(rpstry, nil)
+				audit  = mock_repository.NewMockAuditRepository(c)
+				svc    = NewCapsuleService(rpstry, nil, audit, nil, testCapsuleRules)
 				ctx    = context.Background()
 			)
 
 			test.mockBehavior(rpstry, ctx, test.userID, test.capsuleID, test.image)
 
+			if test.expectedError == nil {
+				audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditImageAdded, event.Action)
+						return event, nil
+					}).Times(1)
+			}
+
 			err := svc.AddImage(ctx, test.userID, test.capsuleID, test.image)
 			assert.Equal(t, test.expectedError, err)
 		})
@@ -719,11 +1016,11 @@ func TestCapsuleService_RemoveImage(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID}, nil).Times(1)
 
-				r.EXPECT().UpdateCapsule(ctx, id, bson.M{
+				r.EXPECT().UpdateCapsule(gomock.Any(), id, bson.M{
 					"$pull": bson.M{
 						"images": image,
 					},
@@ -737,7 +1034,7 @@ func TestCapsuleService_RemoveImage(t *testing.T) {
 		{
 			name: "Forbidden",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: primitive.NewObjectID()}, nil).Times(1)
 			},
@@ -748,7 +1045,7 @@ func TestCapsuleService_RemoveImage(t *testing.T) {
 		{
 			name: "Retrieving-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(nil, errors.New("some error")).Times(1)
 			},
@@ -759,7 +1056,7 @@ func TestCapsuleService_RemoveImage(t *testing.T) {
 		{
 			name: "Retrieving-DB-NotFound",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(nil, mongo.ErrNoDocuments).Times(1)
 			},
@@ -770,11 +1067,11 @@ func TestCapsuleService_RemoveImage(t *testing.T) {
 		{
 			name: "Updating-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) {
-				r.EXPECT().GetCapsule(ctx, bson.M{
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
 					"_id": id,
 				}).Return(&domain.Capsule{UserID: userID}, nil).Times(1)
 
-				r.EXPECT().UpdateCapsule(ctx, id, bson.M{
+				r.EXPECT().UpdateCapsule(gomock.Any(), id, bson.M{
 					"$pull": bson.M{
 						"images": image,
 					},
@@ -794,14 +1091,154 @@ func TestCapsuleService_RemoveImage(t *testing.T) {
 
 			var (
 				rpstry = mock_repository.NewMockCapsuleRepository(c)
-				svc    = NewCapsuleService(rpstry, nil)
+				audit  = mock_repository.NewMockAuditRepository(c)
+				svc    = NewCapsuleService(rpstry, nil, audit, nil, testCapsuleRules)
 				ctx    = context.Background()
 			)
 
 			test.mockBehavior(rpstry, ctx, test.userID, test.capsuleID, test.image)
 
+			if test.expectedError == nil {
+				audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditImageRemoved, event.Action)
+						return event, nil
+					}).Times(1)
+			}
+
 			err := svc.RemoveImage(ctx, test.userID, test.capsuleID, test.image)
 			assert.Equal(t, test.expectedError, err)
 		})
 	}
 }
+
+func TestCapsuleService_SearchCapsules(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockCapsuleRepository, ctx context.Context,
+		userID primitive.ObjectID, query string)
+
+	wayBack := time.Unix(0, 0)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return wayBack })
+	defer patches.Reset()
+
+	tests := []struct {
+		name            string
+		mockBehavior    mockBehavior
+		expectedError   error
+		expectedSnippet string
+		userID          primitive.ObjectID
+		query           string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, query string) {
+				r.EXPECT().SearchCapsules(gomock.Any(), bson.M{
+					"userID": userID,
+					"openAt": bson.M{
+						"$lte": time.Now().UTC(),
+					},
+				}, query, int64(maxSearchResults)).Return([]*domain.CapsuleSearchResult{
+					{
+						Capsule: &domain.Capsule{Message: "That one about the Graduation party"},
+						Score:   1,
+					},
+				}, nil).Times(1)
+			},
+			expectedError:   nil,
+			expectedSnippet: "That one about the <mark>Graduation</mark> party",
+			userID:          primitive.NewObjectID(),
+			query:           "graduation",
+		},
+		{
+			name: "Empty-Query",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, query string) {
+			},
+			expectedError: ErrEmptySearchQuery,
+			userID:        primitive.NewObjectID(),
+			query:         "   ",
+		},
+		{
+			name: "Searching-DB-Failure",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, query string) {
+				r.EXPECT().SearchCapsules(gomock.Any(), gomock.Any(), query, int64(maxSearchResults)).
+					Return(nil, errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+			userID:        primitive.NewObjectID(),
+			query:         "graduation",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockCapsuleRepository(c)
+				svc    = NewCapsuleService(rpstry, nil, nil, nil, testCapsuleRules)
+				ctx    = context.Background()
+			)
+
+			test.mockBehavior(rpstry, ctx, test.userID, test.query)
+
+			results, err := svc.SearchCapsules(ctx, test.userID, test.query)
+			assert.Equal(t, test.expectedError, err)
+
+			if err == nil {
+				assert.Equal(t, test.expectedSnippet, results[0].Snippet)
+			}
+		})
+	}
+}
+
+func TestCapsuleService_highlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		terms    []string
+		expected string
+	}{
+		{
+			name:     "Multiple-Terms",
+			message:  "Cake at the graduation, cake everywhere",
+			terms:    []string{"cake", "graduation"},
+			expected: "<mark>Cake</mark> at the <mark>graduation</mark>, <mark>cake</mark> everywhere",
+		},
+		{
+			name:     "Escapes-HTML",
+			message:  "<b>graduation</b>",
+			terms:    []string{"graduation"},
+			expected: "&lt;b&gt;<mark>graduation</mark>&lt;/b&gt;",
+		},
+		{
+			name:     "Window-Around-Match",
+			message:  strings.Repeat("a", 2*snippetRadius) + " graduation " + strings.Repeat("b", 2*snippetRadius),
+			terms:    []string{"graduation"},
+			expected: "…" + strings.Repeat("a", snippetRadius-1) + " <mark>graduation</mark> " + strings.Repeat("b", snippetRadius-len("graduation")-1) + "…",
+		},
+		{
+			name:     "Stemmed-Match",
+			message:  "She graduated, the graduates cheered",
+			terms:    []string{"graduation"},
+			expected: "She <mark>graduated</mark>, the <mark>graduates</mark> cheered",
+		},
+		{
+			name:     "Short-Term",
+			message:  "This is it",
+			terms:    []string{"is"},
+			expected: "This <mark>is</mark> it",
+		},
+		{
+			name:     "No-Match",
+			message:  "concatenate",
+			terms:    []string{"cat"},
+			expected: "concatenate",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, highlight(test.message, test.terms))
+		})
+	}
+}
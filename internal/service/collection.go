@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxCollectionNameLength = 64
+
+var (
+	ErrInvalidCollectionName = errors.New("collection name must be between 1 and 64 characters long")
+	ErrCollectionDuplicate   = errors.New("collection with this name already exists")
+)
+
+type collectionService struct {
+	repository        repository.CollectionRepository
+	capsuleRepository repository.CapsuleRepository
+}
+
+func NewCollectionService(repository repository.CollectionRepository, capsuleRepository repository.CapsuleRepository) CollectionService {
+	return &collectionService{
+		repository:        repository,
+		capsuleRepository: capsuleRepository,
+	}
+}
+
+func (s *collectionService) CreateCollection(ctx context.Context, userID primitive.ObjectID, input domain.CreateCollectionDTO) (*domain.Collection, error) {
+	name, ok := normalizeCollectionName(input.Name)
+	if !ok {
+		return nil, ErrInvalidCollectionName
+	}
+
+	toInsert := &domain.Collection{
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	res, err := s.repository.InsertCollection(ctx, toInsert)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrCollectionDuplicate
+		}
+
+		slog.ErrorContext(ctx, "CreateCollection", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return res, nil
+}
+
+func (s *collectionService) GetAllCollections(ctx context.Context, userID primitive.ObjectID) ([]*domain.Collection, error) {
+	collections, err := s.repository.GetCollections(ctx, bson.M{"userID": userID})
+	if err != nil {
+		slog.ErrorContext(ctx, "GetAllCollections", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return collections, nil
+}
+
+func (s *collectionService) GetCollectionByID(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) (*domain.Collection, error) {
+	collection, err := s.repository.GetCollection(ctx, bson.M{"_id": id})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+
+		slog.ErrorContext(ctx, "GetCollectionByID", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if collection.UserID != userID {
+		return nil, ErrForbidden
+	}
+
+	return collection, nil
+}
+
+func (s *collectionService) UpdateCollection(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCollectionDTO) error {
+	name, ok := normalizeCollectionName(update.Name)
+	if !ok {
+		return ErrInvalidCollectionName
+	}
+
+	if _, err := s.GetCollectionByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.repository.UpdateCollection(ctx, id, bson.M{
+		"$set": bson.M{
+			"name": name,
+		},
+	}); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrCollectionDuplicate
+		}
+
+		slog.ErrorContext(ctx, "UpdateCollection", "error", err)
+		return ErrDBFailure
+	}
+
+	return nil
+}
+
+// DeleteCollection removes the collection and detaches it from its capsules.
+// The capsules themselves are kept.
+func (s *collectionService) DeleteCollection(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) error {
+	if _, err := s.GetCollectionByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if _, err := s.capsuleRepository.UpdateCapsules(ctx, bson.M{
+		"userID":      userID,
+		"collections": id,
+	}, bson.M{
+		"$pull": bson.M{
+			"collections": id,
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "DeleteCollection", "error", err)
+		return ErrDBFailure
+	}
+
+	if err := s.repository.DeleteCollection(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "DeleteCollection", "error", err)
+		return ErrDBFailure
+	}
+
+	return nil
+}
+
+func (s *collectionService) AddCapsule(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, capsuleID primitive.ObjectID) error {
+	return s.updateCapsuleCollections(ctx, userID, id, capsuleID, "$addToSet")
+}
+
+func (s *collectionService) RemoveCapsule(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, capsuleID primitive.ObjectID) error {
+	return s.updateCapsuleCollections(ctx, userID, id, capsuleID, "$pull")
+}
+
+func (s *collectionService) updateCapsuleCollections(ctx context.Context, userID, id, capsuleID primitive.ObjectID, operator string) error {
+	if _, err := s.GetCollectionByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	matched, err := s.capsuleRepository.UpdateCapsules(ctx, bson.M{
+		"_id":    capsuleID,
+		"userID": userID,
+	}, bson.M{
+		operator: bson.M{
+			"collections": id,
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "updateCapsuleCollections", "error", err)
+		return ErrDBFailure
+	}
+
+	if matched == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func normalizeCollectionName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	length := utf8.RuneCountInString(name)
+
+	return name, length > 0 && length <= maxCollectionNameLength
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCollectionService_CreateCollection(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockCollectionRepository, ctx context.Context,
+		userID primitive.ObjectID)
+
+	wayBack := time.Unix(0, 0)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return wayBack })
+	defer patches.Reset()
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+		userID        primitive.ObjectID
+		input         domain.CreateCollectionDTO
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockCollectionRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().InsertCollection(ctx, &domain.Collection{
+					UserID:    userID,
+					Name:      "Kids",
+					CreatedAt: time.Now().UTC(),
+				}).Return(&domain.Collection{}, nil).Times(1)
+			},
+			expectedError: nil,
+			userID:        primitive.NewObjectID(),
+			input:         domain.CreateCollectionDTO{Name: " Kids "},
+		},
+		{
+			name:          "Invalid-Name",
+			mockBehavior:  func(r *mock_repository.MockCollectionRepository, ctx context.Context, userID primitive.ObjectID) {},
+			expectedError: ErrInvalidCollectionName,
+			userID:        primitive.NewObjectID(),
+			input:         domain.CreateCollectionDTO{Name: "   "},
+		},
+		{
+			name: "Duplicate",
+			mockBehavior: func(r *mock_repository.MockCollectionRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().InsertCollection(ctx, gomock.Any()).Return(nil, mongo.WriteException{
+					WriteErrors: []mongo.WriteError{{Code: 11000}},
+				}).Times(1)
+			},
+			expectedError: ErrCollectionDuplicate,
+			userID:        primitive.NewObjectID(),
+			input:         domain.CreateCollectionDTO{Name: "Kids"},
+		},
+		{
+			name: "Creating-DB-Failure",
+			mockBehavior: func(r *mock_repository.MockCollectionRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().InsertCollection(ctx, gomock.Any()).Return(nil, errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+			userID:        primitive.NewObjectID(),
+			input:         domain.CreateCollectionDTO{Name: "Kids"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockCollectionRepository(c)
+				svc    = NewCollectionService(rpstry, nil)
+				ctx    = context.Background()
+			)
+
+			test.mockBehavior(rpstry, ctx, test.userID)
+
+			_, err := svc.CreateCollection(ctx, test.userID, test.input)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestCollectionService_DeleteCollection(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockCollectionRepository, cr *mock_repository.MockCapsuleRepository,
+		ctx context.Context, userID, id primitive.ObjectID)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+		userID        primitive.ObjectID
+		collectionID  primitive.ObjectID
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockCollectionRepository, cr *mock_repository.MockCapsuleRepository, ctx context.Context, userID, id primitive.ObjectID) {
+				r.EXPECT().GetCollection(ctx, bson.M{"_id": id}).Return(&domain.Collection{UserID: userID}, nil).Times(1)
+
+				cr.EXPECT().UpdateCapsules(ctx, bson.M{
+					"userID":      userID,
+					"collections": id,
+				}, bson.M{
+					"$pull": bson.M{
+						"collections": id,
+					},
+				}).Return(int64(1), nil).Times(1)
+
+				r.EXPECT().DeleteCollection(ctx, id).Return(nil).Times(1)
+			},
+			expectedError: nil,
+			userID:        primitive.NewObjectID(),
+			collectionID:  primitive.NewObjectID(),
+		},
+		{
+			name: "Not-Found",
+			mockBehavior: func(r *mock_repository.MockCollectionRepository, cr *mock_repository.MockCapsuleRepository, ctx context.Context, userID, id primitive.ObjectID) {
+				r.EXPECT().GetCollection(ctx, bson.M{"_id": id}).Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			expectedError: ErrNotFound,
+			userID:        primitive.NewObjectID(),
+			collectionID:  primitive.NewObjectID(),
+		},
+		{
+			name: "Forbidden",
+			mockBehavior: func(r *mock_repository.MockCollectionRepository, cr *mock_repository.MockCapsuleRepository, ctx context.Context, userID, id primitive.ObjectID) {
+				r.EXPECT().GetCollection(ctx, bson.M{"_id": id}).Return(&domain.Collection{UserID: primitive.NewObjectID()}, nil).Times(1)
+			},
+			expectedError: ErrForbidden,
+			userID:        primitive.NewObjectID(),
+			collectionID:  primitive.NewObjectID(),
+		},
+		{
+			name: "Deleting-DB-Failure",
+			mockBehavior: func(r *mock_repository.MockCollectionRepository, cr *mock_repository.MockCapsuleRepository, ctx context.Context, userID, id primitive.ObjectID) {
+				r.EXPECT().GetCollection(ctx, bson.M{"_id": id}).Return(&domain.Collection{UserID: userID}, nil).Times(1)
+				cr.EXPECT().UpdateCapsules(ctx, gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+				r.EXPECT().DeleteCollection(ctx, id).Return(errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+			userID:        primitive.NewObjectID(),
+			collectionID:  primitive.NewObjectID(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry    = mock_repository.NewMockCollectionRepository(c)
+				capRpstry = mock_repository.NewMockCapsuleRepository(c)
+				svc       = NewCollectionService(rpstry, capRpstry)
+				ctx       = context.Background()
+			)
+
+			test.mockBehavior(rpstry, capRpstry, ctx, test.userID, test.collectionID)
+
+			err := svc.DeleteCollection(ctx, test.userID, test.collectionID)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestCollectionService_AddCapsule(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockCollectionRepository, cr *mock_repository.MockCapsuleRepository,
+		ctx context.Context, userID, id, capsuleID primitive.ObjectID)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+		userID        primitive.ObjectID
+		collectionID  primitive.ObjectID
+		capsuleID     primitive.ObjectID
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockCollectionRepository, cr *mock_repository.MockCapsuleRepository, ctx context.Context, userID, id, capsuleID primitive.ObjectID) {
+				r.EXPECT().GetCollection(ctx, bson.M{"_id": id}).Return(&domain.Collection{UserID: userID}, nil).Times(1)
+
+				cr.EXPECT().UpdateCapsules(ctx, bson.M{
+					"_id":    capsuleID,
+					"userID": userID,
+				}, bson.M{
+					"$addToSet": bson.M{
+						"collections": id,
+					},
+				}).Return(int64(1), nil).Times(1)
+			},
+			expectedError: nil,
+			userID:        primitive.NewObjectID(),
+			collectionID:  primitive.NewObjectID(),
+			capsuleID:     primitive.NewObjectID(),
+		},
+		{
+			name: "Capsule-Not-Found",
+			mockBehavior: func(r *mock_repository.MockCollectionRepository, cr *mock_repository.MockCapsuleRepository, ctx context.Context, userID, id, capsuleID primitive.ObjectID) {
+				r.EXPECT().GetCollection(ctx, bson.M{"_id": id}).Return(&domain.Collection{UserID: userID}, nil).Times(1)
+				cr.EXPECT().UpdateCapsules(ctx, gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+			},
+			expectedError: ErrNotFound,
+			userID:        primitive.NewObjectID(),
+			collectionID:  primitive.NewObjectID(),
+			capsuleID:     primitive.NewObjectID(),
+		},
+		{
+			name: "Updating-DB-Failure",
+			mockBehavior: func(r *mock_repository.MockCollectionRepository, cr *mock_repository.MockCapsuleRepository, ctx context.Context, userID, id, capsuleID primitive.ObjectID) {
+				r.EXPECT().GetCollection(ctx, bson.M{"_id": id}).Return(&domain.Collection{UserID: userID}, nil).Times(1)
+				cr.EXPECT().UpdateCapsules(ctx, gomock.Any(), gomock.Any()).Return(int64(0), errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+			userID:        primitive.NewObjectID(),
+			collectionID:  primitive.NewObjectID(),
+			capsuleID:     primitive.NewObjectID(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry    = mock_repository.NewMockCollectionRepository(c)
+				capRpstry = mock_repository.NewMockCapsuleRepository(c)
+				svc       = NewCollectionService(rpstry, capRpstry)
+				ctx       = context.Background()
+			)
+
+			test.mockBehavior(rpstry, capRpstry, ctx, test.userID, test.collectionID, test.capsuleID)
+
+			err := svc.AddCapsule(ctx, test.userID, test.collectionID, test.capsuleID)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/events"
+	"time-capsule/internal/export"
+	"time-capsule/internal/metrics"
+	"time-capsule/internal/repository"
+	"time-capsule/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	exportDownloadPath      = "/api/v1/exports/download"
+	exportDownloadTokenSize = 32
+	// exportTTL is how long the archive of an export can be downloaded.
+	exportTTL = 7 * 24 * time.Hour
+
+	exportLease       = 10 * time.Minute
+	exportRetryDelay  = time.Minute
+	maxExportAttempts = 3
+)
+
+var (
+	ErrExportInProgress     = errors.New("an export is already in progress")
+	ErrInvalidDownloadToken = errors.New("invalid or expired download link")
+)
+
+type exportService struct {
+	repository             repository.ExportRepository
+	userRepository         repository.UserRepository
+	capsuleRepository      repository.CapsuleRepository
+	collectionRepository   repository.CollectionRepository
+	notificationRepository repository.NotificationRepository
+	storage                storage.Storage
+	broker                 events.Broker
+	audit                  *auditLog
+	// publicURL is the base URL of the service, download links point to it.
+	publicURL string
+	// includeSealed exports the content of capsules that haven't opened yet.
+	includeSealed bool
+}
+
+func NewExportService(repository repository.ExportRepository, userRepository repository.UserRepository,
+	capsuleRepository repository.CapsuleRepository, collectionRepository repository.CollectionRepository,
+	notificationRepository repository.NotificationRepository, auditRepository repository.AuditRepository,
+	storage storage.Storage, broker events.Broker, publicURL string, includeSealed bool) ExportService {
+	return &exportService{
+		repository:             repository,
+		userRepository:         userRepository,
+		capsuleRepository:      capsuleRepository,
+		collectionRepository:   collectionRepository,
+		notificationRepository: notificationRepository,
+		storage:                storage,
+		broker:                 broker,
+		audit:                  &auditLog{repository: auditRepository},
+		publicURL:              publicURL,
+		includeSealed:          includeSealed,
+	}
+}
+
+// RequestExport starts exporting the user's data. The user is notified with a download link once it's ready.
+func (s *exportService) RequestExport(ctx context.Context, userID primitive.ObjectID) (*domain.Export, error) {
+	if _, err := s.repository.GetExport(ctx, bson.M{
+		"userID": userID,
+		"status": domain.ExportStatusPending,
+	}); err == nil {
+		return nil, ErrExportInProgress
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		slog.ErrorContext(ctx, "RequestExport", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	now := time.Now().UTC()
+
+	res, err := s.repository.InsertExport(ctx, &domain.Export{
+		UserID:        userID,
+		Status:        domain.ExportStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "RequestExport", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditExportRequested,
+		UserID: userID,
+	})
+
+	return res, nil
+}
+
+func (s *exportService) GetExports(ctx context.Context, userID primitive.ObjectID) ([]*domain.Export, error) {
+	exports, err := s.repository.GetExports(ctx, bson.M{"userID": userID})
+	if err != nil {
+		slog.ErrorContext(ctx, "GetExports", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return exports, nil
+}
+
+// DownloadExport returns the archive of the export the download link was sent for.
+func (s *exportService) DownloadExport(ctx context.Context, token string) (*domain.File, error) {
+	if token == "" {
+		return nil, ErrInvalidDownloadToken
+	}
+
+	e, err := s.repository.GetExport(ctx, bson.M{
+		"downloadTokenHash": hashToken(token),
+		"status":            domain.ExportStatusReady,
+		"expiresAt":         bson.M{"$gt": time.Now().UTC()},
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidDownloadToken
+		}
+
+		slog.ErrorContext(ctx, "DownloadExport", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	file, err := s.storage.Get(ctx, e.File)
+	if err != nil {
+		slog.ErrorContext(ctx, "DownloadExport", "error", err)
+		return nil, ErrStorageFailure
+	}
+
+	return file, nil
+}
+
+// BuildExports builds the archives of the pending exports. Exports that fail to build are retried
+// after exportRetryDelay, up to maxExportAttempts times.
+func (s *exportService) BuildExports(ctx context.Context, now time.Time) error {
+	var errs []error
+
+	for {
+		e, err := s.repository.ClaimExport(ctx, now, exportLease)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				break
+			}
+
+			errs = append(errs, err)
+			break
+		}
+
+		if err = s.buildExport(ctx, e, now); err == nil {
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("export %s: %w", e.ID.Hex(), err))
+
+		update := bson.M{"nextAttemptAt": now.Add(exportRetryDelay)}
+		if e.Attempts >= maxExportAttempts {
+			update = bson.M{"status": domain.ExportStatusFailed}
+		}
+
+		if err = s.repository.UpdateExport(ctx, e.ID, bson.M{"$set": update}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// buildExport uploads the archive of the export and notifies the user with the link to download it.
+func (s *exportService) buildExport(ctx context.Context, e *domain.Export, now time.Time) error {
+	user, err := s.userRepository.GetUser(ctx, bson.M{"_id": e.UserID})
+	if err != nil {
+		return err
+	}
+
+	capsules, err := s.capsuleRepository.GetCapsules(ctx, bson.M{"userID": e.UserID})
+	if err != nil {
+		return err
+	}
+
+	collections, err := s.collectionRepository.GetCollections(ctx, bson.M{"userID": e.UserID})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = export.Write(ctx, &buf, s.storage, export.Data{
+		User:        user,
+		Capsules:    capsules,
+		Collections: collections,
+	}, now, s.includeSealed); err != nil {
+		return err
+	}
+
+	file := domain.File{
+		Bytes: buf.Bytes(),
+		Name:  fmt.Sprintf("export-%s.zip", e.ID.Hex()),
+		Size:  int64(buf.Len()),
+	}
+
+	if err = s.storage.Upload(ctx, file); err != nil {
+		return err
+	}
+
+	token, err := randomHex(exportDownloadTokenSize)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := now.Add(exportTTL)
+
+	if err = s.repository.UpdateExport(ctx, e.ID, bson.M{
+		"$set": bson.M{
+			"status":            domain.ExportStatusReady,
+			"file":              file.Name,
+			"size":              file.Size,
+			"downloadTokenHash": hashToken(token),
+			"completedAt":       now,
+			"expiresAt":         expiresAt,
+		},
+	}); err != nil {
+		return err
+	}
+
+	notification := &domain.Notification{
+		Key:       fmt.Sprintf("%s:%s", domain.NotificationExportReady, e.ID.Hex()),
+		UserID:    e.UserID,
+		Type:      domain.NotificationExportReady,
+		URL:       strings.TrimSuffix(s.publicURL, "/") + exportDownloadPath + "?token=" + token,
+		CreatedAt: now,
+	}
+
+	// The export is ready either way, the user can still find it in the list of exports.
+	_, err = s.notificationRepository.InsertNotification(ctx, notification)
+	metrics.ObserveNotification(metrics.ChannelInApp, err)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to store a notification", "key", notification.Key, "error", err)
+		return nil
+	}
+
+	s.broker.Publish(e.UserID, events.Event{
+		Type: events.TypeNotification,
+		Data: notification,
+	})
+
+	return nil
+}
+
+// ExpireExports deletes the archives of the exports whose download link expired.
+func (s *exportService) ExpireExports(ctx context.Context, now time.Time) error {
+	exports, err := s.repository.GetExports(ctx, bson.M{
+		"status":    domain.ExportStatusReady,
+		"expiresAt": bson.M{"$lte": now},
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, e := range exports {
+		if err = s.storage.Delete(ctx, e.File); err != nil {
+			errs = append(errs, fmt.Errorf("export %s: %w", e.ID.Hex(), err))
+			continue
+		}
+
+		if err = s.repository.UpdateExport(ctx, e.ID, bson.M{
+			"$set": bson.M{
+				"status": domain.ExportStatusExpired,
+			},
+			"$unset": bson.M{
+				"file":              "",
+				"downloadTokenHash": "",
+			},
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("export %s: %w", e.ID.Hex(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/events"
+	mock_repository "time-capsule/internal/repository/mocks"
+	mock_storage "time-capsule/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func TestExportService_RequestExport(t *testing.T) {
+	type mocks struct {
+		exports *mock_repository.MockExportRepository
+		audit   *mock_repository.MockAuditRepository
+	}
+
+	var (
+		userID        = primitive.NewObjectID()
+		pendingFilter = bson.M{"userID": userID, "status": domain.ExportStatusPending}
+	)
+
+	tests := []struct {
+		name          string
+		mockBehavior  func(m mocks)
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(m mocks) {
+				m.exports.EXPECT().GetExport(gomock.Any(), pendingFilter).Return(nil, mongo.ErrNoDocuments).Times(1)
+				m.exports.EXPECT().InsertExport(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, e *domain.Export) (*domain.Export, error) {
+						assert.Equal(t, userID, e.UserID)
+						assert.Equal(t, domain.ExportStatusPending, e.Status)
+						return e, nil
+					}).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditExportRequested, event.Action)
+						return event, nil
+					}).Times(1)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "In-Progress",
+			mockBehavior: func(m mocks) {
+				m.exports.EXPECT().GetExport(gomock.Any(), pendingFilter).Return(&domain.Export{}, nil).Times(1)
+			},
+			expectedError: ErrExportInProgress,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(m mocks) {
+				m.exports.EXPECT().GetExport(gomock.Any(), pendingFilter).Return(nil, errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				m = mocks{
+					exports: mock_repository.NewMockExportRepository(c),
+					audit:   mock_repository.NewMockAuditRepository(c),
+				}
+				svc = NewExportService(m.exports, nil, nil, nil, nil, m.audit, nil, nil, "", false)
+			)
+
+			test.mockBehavior(m)
+
+			_, err := svc.RequestExport(context.Background(), userID)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestExportService_DownloadExport(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockBehavior  func(e *mock_repository.MockExportRepository, s *mock_storage.MockStorage)
+		token         string
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(e *mock_repository.MockExportRepository, s *mock_storage.MockStorage) {
+				e.EXPECT().GetExport(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, filter bson.M) (*domain.Export, error) {
+						assert.Equal(t, hashToken("some-token"), filter["downloadTokenHash"])
+						assert.Equal(t, domain.ExportStatusReady, filter["status"])
+						return &domain.Export{File: "export.zip"}, nil
+					}).Times(1)
+				s.EXPECT().Get(gomock.Any(), "export.zip").Return(&domain.File{Name: "export.zip"}, nil).Times(1)
+			},
+			token:         "some-token",
+			expectedError: nil,
+		},
+		{
+			name: "Expired",
+			mockBehavior: func(e *mock_repository.MockExportRepository, s *mock_storage.MockStorage) {
+				e.EXPECT().GetExport(gomock.Any(), gomock.Any()).Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			token:         "some-token",
+			expectedError: ErrInvalidDownloadToken,
+		},
+		{
+			name:          "Empty-Token",
+			mockBehavior:  func(e *mock_repository.MockExportRepository, s *mock_storage.MockStorage) {},
+			expectedError: ErrInvalidDownloadToken,
+		},
+		{
+			name: "Storage-Failure",
+			mockBehavior: func(e *mock_repository.MockExportRepository, s *mock_storage.MockStorage) {
+				e.EXPECT().GetExport(gomock.Any(), gomock.Any()).Return(&domain.Export{File: "export.zip"}, nil).Times(1)
+				s.EXPECT().Get(gomock.Any(), "export.zip").Return(nil, errors.New("some error")).Times(1)
+			},
+			token:         "some-token",
+			expectedError: ErrStorageFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				exports = mock_repository.NewMockExportRepository(c)
+				strg    = mock_storage.NewMockStorage(c)
+				svc     = NewExportService(exports, nil, nil, nil, nil, nil, strg, nil, "", false)
+			)
+
+			test.mockBehavior(exports, strg)
+
+			_, err := svc.DownloadExport(context.Background(), test.token)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestExportService_BuildExports(t *testing.T) {
+	type mocks struct {
+		exports       *mock_repository.MockExportRepository
+		users         *mock_repository.MockUserRepository
+		capsules      *mock_repository.MockCapsuleRepository
+		collections   *mock_repository.MockCollectionRepository
+		notifications *mock_repository.MockNotificationRepository
+		storage       *mock_storage.MockStorage
+	}
+
+	var (
+		now      = time.Now().UTC()
+		userID   = primitive.NewObjectID()
+		exportID = primitive.NewObjectID()
+	)
+
+	tests := []struct {
+		name         string
+		mockBehavior func(m mocks)
+		expectError  bool
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(m mocks) {
+				m.exports.EXPECT().ClaimExport(gomock.Any(), now, exportLease).
+					Return(&domain.Export{ID: exportID, UserID: userID, Attempts: 1}, nil).Times(1)
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(&domain.User{ID: userID}, nil).Times(1)
+				m.capsules.EXPECT().GetCapsules(gomock.Any(), bson.M{"userID": userID}).Return([]*domain.Capsule{{
+					Message: "Hello",
+					Images:  []string{"image"},
+					OpenAt:  now.Add(-time.Hour),
+				}}, nil).Times(1)
+				m.collections.EXPECT().GetCollections(gomock.Any(), bson.M{"userID": userID}).Return(nil, nil).Times(1)
+				m.storage.EXPECT().Get(gomock.Any(), "image").Return(&domain.File{Bytes: []byte("image")}, nil).Times(1)
+				m.storage.EXPECT().Upload(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, file domain.File) error {
+						assert.Equal(t, "export-"+exportID.Hex()+".zip", file.Name)
+						assert.Equal(t, int64(len(file.Bytes)), file.Size)
+						return nil
+					}).Times(1)
+				m.exports.EXPECT().UpdateExport(gomock.Any(), exportID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ primitive.ObjectID, update bson.M) error {
+						set := update["$set"].(bson.M)
+						assert.Equal(t, domain.ExportStatusReady, set["status"])
+						assert.Equal(t, now.Add(exportTTL), set["expiresAt"])
+						return nil
+					}).Times(1)
+				m.notifications.EXPECT().InsertNotification(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, n *domain.Notification) (*domain.Notification, error) {
+						assert.Equal(t, domain.NotificationExportReady, n.Type)
+						assert.True(t, strings.HasPrefix(n.URL, "https://time-capsule.example.com/api/v1/exports/download?token="))
+						return n, nil
+					}).Times(1)
+				m.exports.EXPECT().ClaimExport(gomock.Any(), now, exportLease).Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			expectError: false,
+		},
+		{
+			name: "Retry",
+			mockBehavior: func(m mocks) {
+				m.exports.EXPECT().ClaimExport(gomock.Any(), now, exportLease).
+					Return(&domain.Export{ID: exportID, UserID: userID, Attempts: 1}, nil).Times(1)
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(nil, errors.New("some error")).Times(1)
+				m.exports.EXPECT().UpdateExport(gomock.Any(), exportID, bson.M{
+					"$set": bson.M{"nextAttemptAt": now.Add(exportRetryDelay)},
+				}).Return(nil).Times(1)
+				m.exports.EXPECT().ClaimExport(gomock.Any(), now, exportLease).Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			expectError: true,
+		},
+		{
+			name: "Out-Of-Attempts",
+			mockBehavior: func(m mocks) {
+				m.exports.EXPECT().ClaimExport(gomock.Any(), now, exportLease).
+					Return(&domain.Export{ID: exportID, UserID: userID, Attempts: maxExportAttempts}, nil).Times(1)
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(nil, errors.New("some error")).Times(1)
+				m.exports.EXPECT().UpdateExport(gomock.Any(), exportID, bson.M{
+					"$set": bson.M{"status": domain.ExportStatusFailed},
+				}).Return(nil).Times(1)
+				m.exports.EXPECT().ClaimExport(gomock.Any(), now, exportLease).Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				m = mocks{
+					exports:       mock_repository.NewMockExportRepository(c),
+					users:         mock_repository.NewMockUserRepository(c),
+					capsules:      mock_repository.NewMockCapsuleRepository(c),
+					collections:   mock_repository.NewMockCollectionRepository(c),
+					notifications: mock_repository.NewMockNotificationRepository(c),
+					storage:       mock_storage.NewMockStorage(c),
+				}
+				broker = events.NewBroker()
+				svc    = NewExportService(m.exports, m.users, m.capsules, m.collections, m.notifications, nil,
+					m.storage, broker, "https://time-capsule.example.com/", false)
+			)
+
+			ch, unsubscribe := broker.Subscribe(userID)
+			defer unsubscribe()
+
+			test.mockBehavior(m)
+
+			err := svc.BuildExports(context.Background(), now)
+			assert.Equal(t, test.expectError, err != nil)
+
+			if !test.expectError {
+				select {
+				case event := <-ch:
+					assert.Equal(t, events.TypeNotification, event.Type)
+				default:
+					require.Fail(t, "the notification wasn't published")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/export"
+	"time-capsule/internal/recurrence"
+	"time-capsule/internal/repository"
+	"time-capsule/internal/storage"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	errSealedContentMissing = errors.New("the content of the sealed capsule wasn't exported")
+	errMissingOpenAt        = errors.New("opening time is required")
+	errCreatedInFuture      = errors.New("creation time cannot be in the future")
+	errCapsuleImported      = errors.New("the capsule was already imported")
+	errInvalidImageType     = errors.New("invalid file type")
+)
+
+// imageTypes are the types of the images that can be imported, the same as the ones that can be uploaded.
+var imageTypes = map[string]struct{}{
+	"image/jpeg": {},
+	"image/png":  {},
+}
+
+type importService struct {
+	capsuleRepository    repository.CapsuleRepository
+	collectionRepository repository.CollectionRepository
+	storage              storage.Storage
+	audit                *auditLog
+	rules                CapsuleRules
+}
+
+func NewImportService(capsuleRepository repository.CapsuleRepository, collectionRepository repository.CollectionRepository,
+	auditRepository repository.AuditRepository, storage storage.Storage, rules CapsuleRules) ImportService {
+	return &importService{
+		capsuleRepository:    capsuleRepository,
+		collectionRepository: collectionRepository,
+		storage:              storage,
+		audit:                &auditLog{repository: auditRepository},
+		rules:                rules,
+	}
+}
+
+// ImportCapsules imports the collections and capsules of an export archive into the user's account.
+// Unlike created capsules, imported ones keep their timestamps and whether they were opened.
+// Capsules keep their IDs when they're free, so importing the same archive twice doesn't duplicate them.
+func (s *importService) ImportCapsules(ctx context.Context, userID primitive.ObjectID, archive *export.Archive) (*domain.ImportReport, error) {
+	var (
+		now    = time.Now().UTC()
+		report = &domain.ImportReport{Errors: []domain.ImportError{}}
+	)
+
+	collections, err := s.importCollections(ctx, userID, archive.Manifest.Collections, report, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range archive.Manifest.Capsules {
+		capsule, err := s.importCapsule(ctx, userID, archive, c, collections, now)
+		if err != nil {
+			report.Errors = append(report.Errors, importError(domain.ImportItemCapsule, i, c.ID, err))
+			continue
+		}
+
+		report.Capsules++
+		report.Images += len(capsule.Images)
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditCapsulesImported,
+		UserID: userID,
+		Details: map[string]string{
+			"capsules":    strconv.Itoa(report.Capsules),
+			"collections": strconv.Itoa(report.Collections),
+			"errors":      strconv.Itoa(len(report.Errors)),
+		},
+	})
+
+	return report, nil
+}
+
+// importCollections creates the collections the user doesn't have yet, matching them by name.
+// It returns the IDs of the user's collections by the IDs they have in the manifest.
+func (s *importService) importCollections(ctx context.Context, userID primitive.ObjectID, collections []*domain.Collection,
+	report *domain.ImportReport, now time.Time) (map[primitive.ObjectID]primitive.ObjectID, error) {
+	existing, err := s.collectionRepository.GetCollections(ctx, bson.M{"userID": userID})
+	if err != nil {
+		slog.ErrorContext(ctx, "ImportCapsules", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	byName := make(map[string]primitive.ObjectID, len(existing))
+	for _, c := range existing {
+		byName[c.Name] = c.ID
+	}
+
+	ids := make(map[primitive.ObjectID]primitive.ObjectID, len(collections))
+
+	for i, c := range collections {
+		if c == nil {
+			continue
+		}
+
+		name, ok := normalizeCollectionName(c.Name)
+		if !ok {
+			report.Errors = append(report.Errors, importError(domain.ImportItemCollection, i, c.ID, ErrInvalidCollectionName))
+			continue
+		}
+
+		if id, ok := byName[name]; ok {
+			ids[c.ID] = id
+			continue
+		}
+
+		res, err := s.collectionRepository.InsertCollection(ctx, &domain.Collection{
+			UserID:    userID,
+			Name:      name,
+			CreatedAt: importedTime(c.CreatedAt, now),
+		})
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				err = ErrCollectionDuplicate
+			} else {
+				slog.ErrorContext(ctx, "ImportCapsules", "error", err)
+				err = ErrDBFailure
+			}
+
+			report.Errors = append(report.Errors, importError(domain.ImportItemCollection, i, c.ID, err))
+			continue
+		}
+
+		byName[name] = res.ID
+		ids[c.ID] = res.ID
+		report.Collections++
+	}
+
+	return ids, nil
+}
+
+func (s *importService) importCapsule(ctx context.Context, userID primitive.ObjectID, archive *export.Archive, c export.Capsule,
+	collections map[primitive.ObjectID]primitive.ObjectID, now time.Time) (*domain.Capsule, error) {
+	capsule, err := importedCapsule(s.rules, userID, c, collections, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.ID.IsZero() {
+		existing, err := s.capsuleRepository.GetCapsule(ctx, bson.M{"_id": c.ID})
+		switch {
+		case err == nil && existing.UserID == userID:
+			return nil, errCapsuleImported
+		case err == nil:
+			// The archive was already imported into another account, this copy gets a new ID.
+		case errors.Is(err, mongo.ErrNoDocuments):
+			capsule.ID = c.ID
+		default:
+			slog.ErrorContext(ctx, "ImportCapsules", "error", err)
+			return nil, ErrDBFailure
+		}
+	}
+
+	for _, name := range c.Files {
+		image, err := s.importImage(ctx, archive, name)
+		if err != nil {
+			s.deleteImages(ctx, capsule.Images)
+			return nil, err
+		}
+
+		capsule.Images = append(capsule.Images, image)
+	}
+
+	if _, err = s.capsuleRepository.InsertCapsule(ctx, capsule); err != nil {
+		s.deleteImages(ctx, capsule.Images)
+
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errCapsuleImported
+		}
+
+		slog.ErrorContext(ctx, "ImportCapsules", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return capsule, nil
+}
+
+// importImage uploads the image at name in the archive, returning its name in the storage.
+func (s *importService) importImage(ctx context.Context, archive *export.Archive, name string) (string, error) {
+	b, err := archive.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("image %s: %w", name, err)
+	}
+
+	if _, ok := imageTypes[http.DetectContentType(b)]; !ok {
+		return "", fmt.Errorf("image %s: %w", name, errInvalidImageType)
+	}
+
+	file := domain.File{
+		Bytes: b,
+		Name:  primitive.NewObjectID().Hex(),
+		Size:  int64(len(b)),
+	}
+
+	if err = s.storage.Upload(ctx, file); err != nil {
+		slog.ErrorContext(ctx, "ImportCapsules", "error", err)
+		return "", ErrStorageFailure
+	}
+
+	return file.Name, nil
+}
+
+// deleteImages deletes the images uploaded for a capsule that failed to import.
+func (s *importService) deleteImages(ctx context.Context, images []string) {
+	for _, image := range images {
+		if err := s.storage.Delete(ctx, image); err != nil {
+			slog.ErrorContext(ctx, "ImportCapsules", "error", err)
+		}
+	}
+}
+
+// importedCapsule validates the exported capsule and builds the capsule to insert, without its images.
+// Capsules are validated like created ones, except for their timestamps: they can already be open.
+// Opening a capsule emails its recipients, so capsules that are still to open do so MinOpenDelay from now
+// at the earliest, like created ones. Otherwise importing a capsule that's due would send it right away.
+func importedCapsule(rules CapsuleRules, userID primitive.ObjectID, c export.Capsule, collections map[primitive.ObjectID]primitive.ObjectID,
+	now time.Time) (*domain.Capsule, error) {
+	if c.Sealed && c.Message == "" {
+		return nil, errSealedContentMissing
+	}
+
+	if len(c.Message) < rules.MinMessageLength {
+		return nil, rules.errShortMessage()
+	}
+
+	if c.OpenAt.IsZero() {
+		return nil, errMissingOpenAt
+	}
+
+	if c.CreatedAt.After(now) {
+		return nil, errCreatedInFuture
+	}
+
+	switch c.Mode {
+	case "", domain.CapsuleModeScheduled:
+	case domain.CapsuleModeInactivity:
+		if !rules.validInactivityDays(c.InactivityDays) {
+			return nil, rules.errInvalidInactivityPeriod()
+		}
+
+		if c.Recurrence != nil {
+			return nil, ErrInvalidRecurrence
+		}
+	default:
+		return nil, ErrInvalidMode
+	}
+
+	tags, err := normalizeTags(c.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tags) > maxTagsPerCapsule {
+		return nil, ErrTooManyTags
+	}
+
+	recipients, ok := normalizeRecipients(c.Recipients, rules.MaxRecipients)
+	if !ok {
+		return nil, rules.errInvalidRecipients()
+	}
+
+	reminders, ok := normalizeReminders(c.Reminders)
+	if !ok {
+		return nil, ErrInvalidReminders
+	}
+
+	capsule := &domain.Capsule{
+		UserID:     userID,
+		Message:    c.Message,
+		Images:     []string{},
+		OpenAt:     c.OpenAt.UTC(),
+		CreatedAt:  importedTime(c.CreatedAt, now),
+		Tags:       tags,
+		Recipients: recipients,
+		Reminders:  reminders,
+	}
+
+	if c.Mode == domain.CapsuleModeInactivity {
+		capsule.Mode = c.Mode
+		capsule.InactivityDays = c.InactivityDays
+	}
+
+	for _, id := range c.Collections {
+		if collectionID, ok := collections[id]; ok {
+			capsule.Collections = append(capsule.Collections, collectionID)
+		}
+	}
+
+	earliest := now.Add(rules.MinOpenDelay)
+
+	if c.Recurrence == nil {
+		// Capsules that were sealed when exported still get opened, even if their time already came.
+		capsule.Notified = !c.Sealed && !capsule.OpenAt.After(now)
+		if !capsule.Notified && capsule.OpenAt.Before(earliest) {
+			capsule.OpenAt = earliest
+		}
+
+		return capsule, nil
+	}
+
+	if capsule.Recurrence, err = recurrence.Normalize(c.Recurrence, capsule.OpenAt); err != nil {
+		return nil, ErrInvalidRecurrence
+	}
+
+	capsule.Occurrences = c.Occurrences
+
+	switch {
+	case c.NextOccurrenceAt != nil:
+		next := c.NextOccurrenceAt.UTC()
+		capsule.NextOccurrenceAt = &next
+	case c.Occurrences == 0:
+		capsule.NextOccurrenceAt = &capsule.OpenAt
+	default:
+		// The schedule is over.
+		capsule.Notified = true
+	}
+
+	if capsule.NextOccurrenceAt != nil && capsule.NextOccurrenceAt.Before(earliest) {
+		capsule.NextOccurrenceAt = &earliest
+	}
+
+	return capsule, nil
+}
+
+// importedTime returns t in UTC, or now if it's missing.
+func importedTime(t time.Time, now time.Time) time.Time {
+	if t.IsZero() || t.After(now) {
+		return now
+	}
+
+	return t.UTC()
+}
+
+func importError(item string, index int, id primitive.ObjectID, err error) domain.ImportError {
+	res := domain.ImportError{
+		Type:    item,
+		Index:   index,
+		Message: err.Error(),
+	}
+
+	if !id.IsZero() {
+		res.ID = id.Hex()
+	}
+
+	return res
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/export"
+	mock_repository "time-capsule/internal/repository/mocks"
+	mock_storage "time-capsule/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func TestImportService_ImportCapsules(t *testing.T) {
+	type mocks struct {
+		capsules    *mock_repository.MockCapsuleRepository
+		collections *mock_repository.MockCollectionRepository
+		audit       *mock_repository.MockAuditRepository
+		storage     *mock_storage.MockStorage
+	}
+
+	var (
+		userID       = primitive.NewObjectID()
+		capsuleID    = primitive.NewObjectID()
+		collectionID = primitive.NewObjectID()
+		createdAt    = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		openAt       = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	manifest := func(capsules ...export.Capsule) []byte {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+
+		f, err := w.Create(export.ManifestFile)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(f).Encode(export.Manifest{
+			Collections: []*domain.Collection{{ID: collectionID, Name: "Family"}},
+			Capsules:    capsules,
+		}))
+
+		f, err = w.Create("images/1.png")
+		require.NoError(t, err)
+		_, err = f.Write([]byte("\x89PNG\r\n\x1a\n"))
+		require.NoError(t, err)
+
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name           string
+		archive        []byte
+		mockBehavior   func(m mocks)
+		expectedReport *domain.ImportReport
+		expectedError  error
+	}{
+		{
+			name: "OK",
+			archive: manifest(export.Capsule{
+				Capsule: &domain.Capsule{
+					ID:          capsuleID,
+					Message:     "Hello, world!",
+					OpenAt:      openAt,
+					CreatedAt:   createdAt,
+					Collections: []primitive.ObjectID{collectionID},
+				},
+				Files: []string{"images/1.png"},
+			}),
+			mockBehavior: func(m mocks) {
+				m.collections.EXPECT().GetCollections(gomock.Any(), bson.M{"userID": userID}).Return(nil, nil).Times(1)
+				m.collections.EXPECT().InsertCollection(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, c *domain.Collection) (*domain.Collection, error) {
+						assert.Equal(t, "Family", c.Name)
+						c.ID = primitive.NilObjectID
+						return c, nil
+					}).Times(1)
+				m.capsules.EXPECT().GetCapsule(gomock.Any(), bson.M{"_id": capsuleID}).Return(nil, mongo.ErrNoDocuments).Times(1)
+				m.storage.EXPECT().Upload(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.capsules.EXPECT().InsertCapsule(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, c *domain.Capsule) (*domain.Capsule, error) {
+						assert.Equal(t, capsuleID, c.ID)
+						assert.Equal(t, userID, c.UserID)
+						assert.Equal(t, createdAt, c.CreatedAt)
+						assert.Equal(t, openAt, c.OpenAt)
+						assert.True(t, c.Notified)
+						assert.Len(t, c.Images, 1)
+						assert.Equal(t, []primitive.ObjectID{primitive.NilObjectID}, c.Collections)
+						return c, nil
+					}).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditCapsulesImported, event.Action)
+						assert.Equal(t, "1", event.Details["capsules"])
+						return event, nil
+					}).Times(1)
+			},
+			expectedReport: &domain.ImportReport{
+				Capsules:    1,
+				Collections: 1,
+				Images:      1,
+				Errors:      []domain.ImportError{},
+			},
+		},
+		{
+			name: "Item-Errors",
+			archive: manifest(
+				export.Capsule{
+					Capsule: &domain.Capsule{OpenAt: openAt},
+					Sealed:  true,
+				},
+				export.Capsule{
+					Capsule: &domain.Capsule{ID: capsuleID, Message: "Hello, world!", OpenAt: openAt},
+				},
+				export.Capsule{
+					Capsule: &domain.Capsule{Message: "Hello, world!", OpenAt: openAt},
+					Files:   []string{"images/2.png"},
+				},
+				export.Capsule{
+					Capsule: &domain.Capsule{Message: "Hello, world!", OpenAt: openAt},
+					Files:   []string{"images/1.png"},
+				},
+			),
+			mockBehavior: func(m mocks) {
+				m.collections.EXPECT().GetCollections(gomock.Any(), bson.M{"userID": userID}).
+					Return([]*domain.Collection{{ID: collectionID, Name: "Family"}}, nil).Times(1)
+				m.capsules.EXPECT().GetCapsule(gomock.Any(), bson.M{"_id": capsuleID}).
+					Return(&domain.Capsule{ID: capsuleID, UserID: userID}, nil).Times(1)
+				m.storage.EXPECT().Upload(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.capsules.EXPECT().InsertCapsule(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error")).Times(1)
+				m.storage.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			},
+			expectedReport: &domain.ImportReport{
+				Errors: []domain.ImportError{
+					{Type: domain.ImportItemCapsule, Index: 0, Message: errSealedContentMissing.Error()},
+					{Type: domain.ImportItemCapsule, Index: 1, ID: capsuleID.Hex(), Message: errCapsuleImported.Error()},
+					{Type: domain.ImportItemCapsule, Index: 2, Message: "image images/2.png: " + export.ErrFileNotFound.Error()},
+					{Type: domain.ImportItemCapsule, Index: 3, Message: ErrDBFailure.Error()},
+				},
+			},
+		},
+		{
+			name:    "DB-Failure",
+			archive: manifest(),
+			mockBehavior: func(m mocks) {
+				m.collections.EXPECT().GetCollections(gomock.Any(), bson.M{"userID": userID}).
+					Return(nil, errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				m = mocks{
+					capsules:    mock_repository.NewMockCapsuleRepository(c),
+					collections: mock_repository.NewMockCollectionRepository(c),
+					audit:       mock_repository.NewMockAuditRepository(c),
+					storage:     mock_storage.NewMockStorage(c),
+				}
+				svc = NewImportService(m.capsules, m.collections, m.audit, m.storage, testCapsuleRules)
+			)
+
+			test.mockBehavior(m)
+
+			archive, err := export.Read(test.archive)
+			require.NoError(t, err)
+
+			report, err := svc.ImportCapsules(context.Background(), userID, archive)
+			assert.Equal(t, test.expectedError, err)
+			assert.Equal(t, test.expectedReport, report)
+		})
+	}
+}
+
+func TestImportedCapsule(t *testing.T) {
+	var (
+		now      = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		past     = now.AddDate(-1, 0, 0)
+		next     = now.AddDate(1, 0, 0)
+		soon     = now.Add(time.Hour)
+		earliest = now.Add(testCapsuleRules.MinOpenDelay)
+	)
+
+	tests := []struct {
+		name                     string
+		capsule                  export.Capsule
+		expectedNotified         bool
+		expectedOpenAt           time.Time
+		expectedNextOccurrenceAt *time.Time
+		expectedError            error
+	}{
+		{
+			name:             "Opened",
+			capsule:          export.Capsule{Capsule: &domain.Capsule{Message: "Hello, world!", OpenAt: past}},
+			expectedNotified: true,
+			expectedOpenAt:   past,
+		},
+		{
+			// The capsule is due, it opens no sooner than a created one would.
+			name: "Sealed-When-Exported",
+			capsule: export.Capsule{
+				Capsule: &domain.Capsule{Message: "Hello, world!", OpenAt: past, Recipients: []string{"foo@example.com"}},
+				Sealed:  true,
+			},
+			expectedNotified: false,
+			expectedOpenAt:   earliest,
+		},
+		{
+			name:             "Upcoming",
+			capsule:          export.Capsule{Capsule: &domain.Capsule{Message: "Hello, world!", OpenAt: next}},
+			expectedNotified: false,
+			expectedOpenAt:   next,
+		},
+		{
+			name:             "Upcoming-Soon",
+			capsule:          export.Capsule{Capsule: &domain.Capsule{Message: "Hello, world!", OpenAt: soon}},
+			expectedNotified: false,
+			expectedOpenAt:   earliest,
+		},
+		{
+			name: "Recurring-Never-Opened",
+			capsule: export.Capsule{Capsule: &domain.Capsule{
+				Message:    "Hello, world!",
+				OpenAt:     next,
+				Recurrence: &domain.Recurrence{Frequency: "yearly", Count: 3},
+			}},
+			expectedNotified:         false,
+			expectedOpenAt:           next,
+			expectedNextOccurrenceAt: &next,
+		},
+		{
+			name: "Recurring-Due",
+			capsule: export.Capsule{Capsule: &domain.Capsule{
+				Message:          "Hello, world!",
+				OpenAt:           past,
+				Recurrence:       &domain.Recurrence{Frequency: "yearly", Count: 3},
+				Occurrences:      1,
+				NextOccurrenceAt: &now,
+			}},
+			expectedNotified:         false,
+			expectedOpenAt:           past,
+			expectedNextOccurrenceAt: &earliest,
+		},
+		{
+			name: "Recurring-Over",
+			capsule: export.Capsule{Capsule: &domain.Capsule{
+				Message:     "Hello, world!",
+				OpenAt:      past,
+				Recurrence:  &domain.Recurrence{Frequency: "yearly", Count: 1},
+				Occurrences: 1,
+			}},
+			expectedNotified: true,
+			expectedOpenAt:   past,
+		},
+		{
+			name: "Created-In-Future",
+			capsule: export.Capsule{Capsule: &domain.Capsule{
+				Message:   "Hello, world!",
+				OpenAt:    next,
+				CreatedAt: next,
+			}},
+			expectedError: errCreatedInFuture,
+		},
+		{
+			name:          "Missing-OpenAt",
+			capsule:       export.Capsule{Capsule: &domain.Capsule{Message: "Hello, world!"}},
+			expectedError: errMissingOpenAt,
+		},
+		{
+			name: "Invalid-Tag",
+			capsule: export.Capsule{Capsule: &domain.Capsule{
+				Message: "Hello, world!",
+				OpenAt:  next,
+				Tags:    []string{"#!"},
+			}},
+			expectedError: ErrInvalidTag,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			capsule, err := importedCapsule(testCapsuleRules, primitive.NewObjectID(), test.capsule, nil, now)
+			assert.Equal(t, test.expectedError, err)
+
+			if test.expectedError == nil {
+				assert.Equal(t, test.expectedNotified, capsule.Notified)
+				assert.Equal(t, test.expectedOpenAt, capsule.OpenAt)
+				assert.Equal(t, test.expectedNextOccurrenceAt, capsule.NextOccurrenceAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// failureWindow is how long failed sign-ins are remembered after the last one.
+	failureWindow = 15 * time.Minute
+
+	// After throttleThreshold failures, every further attempt has to wait for a delay
+	// that doubles with every failure, up to maxThrottleDelay.
+	throttleThreshold = 3
+	maxThrottleDelay  = time.Minute
+
+	// lockoutThreshold failures of an account lock it for lockoutDuration.
+	lockoutThreshold = 10
+	lockoutDuration  = 30 * time.Minute
+
+	unlockPath      = "/api/v1/unlock"
+	unlockTokenSize = 32
+)
+
+var (
+	ErrAccountLocked      = errors.New("account is temporarily locked after too many failed sign-in attempts, check your email to unlock it")
+	ErrSignInThrottled    = errors.New("too many failed sign-in attempts, try again later")
+	ErrInvalidUnlockToken = errors.New("invalid or expired unlock token")
+)
+
+func accountFailuresKey(userID primitive.ObjectID) string {
+	return "user:" + userID.Hex()
+}
+
+func ipFailuresKey(ip string) string {
+	return "ip:" + ip
+}
+
+// signInKeys returns the keys the failed sign-ins are counted under: the client's IP and the user's account, if known.
+func signInKeys(ctx context.Context, user *domain.User) []string {
+	var keys []string
+
+	if ip := clientFromContext(ctx).IP; ip != "" {
+		keys = append(keys, ipFailuresKey(ip))
+	}
+
+	if user != nil {
+		keys = append(keys, accountFailuresKey(user.ID))
+	}
+
+	return keys
+}
+
+// checkSignIn refuses the attempt while the user's account is locked, or while the client or the account
+// has to wait after its failed attempts.
+func (s *userService) checkSignIn(ctx context.Context, now time.Time, user *domain.User, email string, keys []string) error {
+	failures, err := s.signInFailures(ctx, now, keys)
+	if err != nil {
+		slog.ErrorContext(ctx, "checkSignIn", "error", err)
+		return ErrDBFailure
+	}
+
+	if user != nil {
+		f, ok := failures[accountFailuresKey(user.ID)]
+		if ok && f.LockedUntil != nil && now.Before(*f.LockedUntil) {
+			s.recordFailedSignIn(ctx, user.ID, email, "locked")
+			return ErrAccountLocked
+		}
+	}
+
+	for _, f := range failures {
+		if throttled(f, now) {
+			s.recordFailedSignIn(ctx, userIDOf(user), email, "throttled")
+			return ErrSignInThrottled
+		}
+	}
+
+	return nil
+}
+
+// failSignIn records the failed attempt and returns err, or ErrAccountLocked if the failure locked the account.
+func (s *userService) failSignIn(ctx context.Context, now time.Time, user *domain.User, email, reason string,
+	keys []string, err error) error {
+	locked, recordErr := s.recordSignInFailure(ctx, now, user, keys...)
+	if recordErr != nil {
+		slog.ErrorContext(ctx, "failSignIn", "error", recordErr)
+	}
+
+	s.recordFailedSignIn(ctx, userIDOf(user), email, reason)
+
+	if locked {
+		return ErrAccountLocked
+	}
+
+	return err
+}
+
+func (s *userService) recordFailedSignIn(ctx context.Context, userID primitive.ObjectID, email, reason string) {
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditSignInFailed,
+		UserID: userID,
+		Details: map[string]string{
+			"email":  email,
+			"reason": reason,
+		},
+	})
+}
+
+func userIDOf(user *domain.User) primitive.ObjectID {
+	if user == nil {
+		return primitive.NilObjectID
+	}
+
+	return user.ID
+}
+
+// signInFailures returns the live failure counters of the keys. Expired counters that weren't evicted yet are dropped.
+func (s *userService) signInFailures(ctx context.Context, now time.Time, keys []string) (map[string]*domain.SignInFailures, error) {
+	failures, err := s.signInRepository.GetSignInFailures(ctx, bson.M{
+		"_id": bson.M{"$in": keys},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	live := make(map[string]*domain.SignInFailures, len(failures))
+
+	for _, f := range failures {
+		if now.Before(f.ExpiresAt) {
+			live[f.Key] = f
+			continue
+		}
+
+		if _, err = s.signInRepository.DeleteSignInFailures(ctx, bson.M{
+			"_id":       f.Key,
+			"expiresAt": bson.M{"$lte": now},
+		}); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+	}
+
+	return live, nil
+}
+
+// throttled reports whether the next attempt has to wait after the recorded failures.
+func throttled(f *domain.SignInFailures, now time.Time) bool {
+	if f.Failures < throttleThreshold {
+		return false
+	}
+
+	delay := time.Second
+	for i := throttleThreshold; i < f.Failures && delay < maxThrottleDelay; i++ {
+		delay *= 2
+	}
+
+	return now.Before(f.LastFailureAt.Add(min(delay, maxThrottleDelay)))
+}
+
+// recordSignInFailure counts a failed sign-in for every key, and locks the user's account
+// once it reaches lockoutThreshold failures. It reports whether the account is locked.
+func (s *userService) recordSignInFailure(ctx context.Context, now time.Time, user *domain.User, keys ...string) (bool, error) {
+	locked := false
+
+	for _, key := range keys {
+		f, err := s.signInRepository.RecordSignInFailure(ctx, key, now, now.Add(failureWindow))
+		if err != nil {
+			return false, err
+		}
+
+		if user != nil && key == accountFailuresKey(user.ID) && f.Failures >= lockoutThreshold {
+			if err = s.lockAccount(ctx, now, user); err != nil {
+				return false, err
+			}
+
+			locked = true
+		}
+	}
+
+	return locked, nil
+}
+
+// lockAccount locks the account for lockoutDuration and emails the user a link to unlock it.
+// Concurrent failures lock the account and send the email once.
+func (s *userService) lockAccount(ctx context.Context, now time.Time, user *domain.User) error {
+	token, err := randomHex(unlockTokenSize)
+	if err != nil {
+		return err
+	}
+
+	until := now.Add(lockoutDuration)
+
+	matched, err := s.signInRepository.UpdateSignInFailures(ctx, bson.M{
+		"_id":         accountFailuresKey(user.ID),
+		"lockedUntil": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{
+			"lockedUntil":     until,
+			"userID":          user.ID,
+			"unlockTokenHash": hashToken(token),
+			"expiresAt":       until,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if matched == 0 {
+		return nil
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditAccountLocked,
+		UserID: user.ID,
+	})
+
+	if err = queueEmail(ctx, s.renderer, s.outboxRepository, user,
+		fmt.Sprintf("%s:%s:%d", mail.TemplateAccountLocked, user.ID.Hex(), until.Unix()),
+		mail.TemplateAccountLocked,
+		mail.AccountLockedData{
+			Username:  user.Username,
+			Until:     until,
+			UnlockURL: strings.TrimSuffix(s.publicURL, "/") + unlockPath + "?token=" + token,
+		},
+	); err != nil {
+		slog.ErrorContext(ctx, "lockAccount", "error", err)
+	}
+
+	return nil
+}
+
+// UnlockAccount unlocks the account locked after too many failed sign-ins, with the token emailed to the user.
+func (s *userService) UnlockAccount(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "UserService.UnlockAccount")
+	defer span.End()
+
+	if token == "" {
+		return ErrInvalidUnlockToken
+	}
+
+	f, err := s.signInRepository.DeleteSignInFailures(ctx, bson.M{
+		"unlockTokenHash": hashToken(token),
+		"lockedUntil":     bson.M{"$gt": time.Now().UTC()},
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrInvalidUnlockToken
+		}
+
+		slog.ErrorContext(ctx, "UnlockAccount", "error", err)
+		return ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditAccountUnlocked,
+		UserID: f.UserID,
+	})
+
+	return nil
+}
+
+// randomHex returns size random bytes, hex encoded.
+func randomHex(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken hashes the unlock tokens and recovery codes to store, so that a leaked database doesn't allow using them.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserService_UnlockAccount(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockSignInFailureRepository, a *mock_repository.MockAuditRepository,
+		ctx context.Context, token string)
+
+	wayBack := time.Unix(0, 0)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return wayBack })
+	defer patches.Reset()
+
+	userID := primitive.NewObjectID()
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		token         string
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockSignInFailureRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, token string) {
+				r.EXPECT().DeleteSignInFailures(gomock.Any(), bson.M{
+					"unlockTokenHash": hashToken(token),
+					"lockedUntil":     bson.M{"$gt": time.Now().UTC()},
+				}).Return(&domain.SignInFailures{UserID: userID}, nil).Times(1)
+				a.EXPECT().InsertAuditEvent(gomock.Any(), &domain.AuditEvent{
+					Action:    domain.AuditAccountUnlocked,
+					UserID:    userID,
+					CreatedAt: time.Now().UTC(),
+				}).Return(nil, nil).Times(1)
+			},
+			token:         "some-token",
+			expectedError: nil,
+		},
+		{
+			name: "Invalid-Token",
+			mockBehavior: func(r *mock_repository.MockSignInFailureRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, token string) {
+				r.EXPECT().DeleteSignInFailures(gomock.Any(), gomock.Any()).Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			token:         "some-token",
+			expectedError: ErrInvalidUnlockToken,
+		},
+		{
+			name: "Empty-Token",
+			mockBehavior: func(r *mock_repository.MockSignInFailureRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, token string) {
+			},
+			token:         "",
+			expectedError: ErrInvalidUnlockToken,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(r *mock_repository.MockSignInFailureRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, token string) {
+				r.EXPECT().DeleteSignInFailures(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error")).Times(1)
+			},
+			token:         "some-token",
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				signIns = mock_repository.NewMockSignInFailureRepository(c)
+				audit   = mock_repository.NewMockAuditRepository(c)
+				svc     = NewUserService(nil, nil, signIns, audit, nil, nil, nil, nil, "", testTokenTTL, bcrypt.MinCost)
+				ctx     = context.Background()
+			)
+
+			test.mockBehavior(signIns, audit, ctx, test.token)
+
+			err := svc.UnlockAccount(ctx, test.token)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func Test_throttled(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	tests := []struct {
+		name      string
+		failures  int
+		elapsed   time.Duration
+		throttled bool
+	}{
+		{name: "Below-Threshold", failures: throttleThreshold - 1, elapsed: 0, throttled: false},
+		{name: "Threshold", failures: throttleThreshold, elapsed: 500 * time.Millisecond, throttled: true},
+		{name: "Threshold-Waited", failures: throttleThreshold, elapsed: time.Second, throttled: false},
+		{name: "Doubling", failures: throttleThreshold + 2, elapsed: 3 * time.Second, throttled: true},
+		{name: "Doubling-Waited", failures: throttleThreshold + 2, elapsed: 4 * time.Second, throttled: false},
+		{name: "Capped", failures: 50, elapsed: maxThrottleDelay, throttled: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.throttled, throttled(&domain.SignInFailures{
+				Failures:      test.failures,
+				LastFailureAt: now.Add(-test.elapsed),
+			}, now))
+		})
+	}
+}
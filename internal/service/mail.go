@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxOutboxEntries = 100
+
+var (
+	ErrRenderFailure       = errors.New("failed to render the email")
+	ErrInvalidOutboxStatus = fmt.Errorf("status must be one of %q, %q or %q",
+		domain.OutboxStatusPending, domain.OutboxStatusSent, domain.OutboxStatusFailed)
+)
+
+type mailService struct {
+	renderer   mail.Renderer
+	repository repository.OutboxRepository
+	audit      *auditLog
+}
+
+func NewMailService(renderer mail.Renderer, repository repository.OutboxRepository,
+	auditRepository repository.AuditRepository) MailService {
+	return &mailService{
+		renderer:   renderer,
+		repository: repository,
+		audit:      &auditLog{repository: auditRepository},
+	}
+}
+
+// PreviewEmail renders the named email template with sample data.
+func (s *mailService) PreviewEmail(name, lang string) (*mail.Message, error) {
+	data, ok := mail.SampleData(name)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	msg, err := s.renderer.Render(name, lang, data)
+	if err != nil {
+		if errors.Is(err, mail.ErrUnknownTemplate) {
+			return nil, ErrNotFound
+		}
+
+		slog.Error("PreviewEmail", "error", err)
+		return nil, ErrRenderFailure
+	}
+
+	return msg, nil
+}
+
+// GetOutbox returns the latest outbox entries with the given status, the dead-lettered ones by default.
+func (s *mailService) GetOutbox(ctx context.Context, status string) ([]*domain.OutboxEntry, error) {
+	switch status {
+	case "":
+		status = domain.OutboxStatusFailed
+	case domain.OutboxStatusPending, domain.OutboxStatusSent, domain.OutboxStatusFailed:
+	default:
+		return nil, ErrInvalidOutboxStatus
+	}
+
+	entries, err := s.repository.GetOutboxEntries(ctx, bson.M{"status": status}, maxOutboxEntries)
+	if err != nil {
+		slog.ErrorContext(ctx, "GetOutbox", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return entries, nil
+}
+
+// ReplayOutboxEntry queues a dead-lettered email again, with a fresh set of attempts.
+func (s *mailService) ReplayOutboxEntry(ctx context.Context, id primitive.ObjectID) error {
+	matched, err := s.repository.UpdateOutboxEntries(ctx, bson.M{
+		"_id":    id,
+		"status": domain.OutboxStatusFailed,
+	}, bson.M{
+		"$set": bson.M{
+			"status":        domain.OutboxStatusPending,
+			"attempts":      0,
+			"nextAttemptAt": time.Now().UTC(),
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ReplayOutboxEntry", "error", err)
+		return ErrDBFailure
+	}
+
+	if matched == 0 {
+		return ErrNotFound
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action:  domain.AuditOutboxEntryReplayed,
+		Details: map[string]string{"outboxEntryID": id.Hex()},
+	})
+
+	return nil
+}
+
+// queueEmail renders the email in the user's language and adds it to the outbox, the worker delivers it.
+// An email with a key that's already queued is skipped.
+func queueEmail(ctx context.Context, renderer mail.Renderer, outbox repository.OutboxRepository,
+	user *domain.User, key, template string, data any) error {
+	msg, err := renderer.Render(template, user.Language, data)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	if _, err = outbox.InsertOutboxEntry(ctx, &domain.OutboxEntry{
+		Key:           key,
+		UserID:        user.ID,
+		Recipient:     user.Email,
+		Template:      template,
+		Subject:       msg.Subject,
+		HTML:          msg.HTML,
+		Text:          msg.Text,
+		Status:        domain.OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	return nil
+}
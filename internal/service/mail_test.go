@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMailService_PreviewEmail(t *testing.T) {
+	renderer, err := mail.NewRenderer("")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		template        string
+		lang            string
+		expectedSubject string
+		expectedError   error
+	}{
+		{
+			name:            "OK",
+			template:        mail.TemplateOpened,
+			lang:            "",
+			expectedSubject: "Time Capsule Opened!",
+			expectedError:   nil,
+		},
+		{
+			name:            "OK-Localized",
+			template:        mail.TemplateCheckInReminder,
+			lang:            "ru",
+			expectedSubject: "Напоминание: подтвердите, что вы на связи",
+			expectedError:   nil,
+		},
+		{
+			name:          "Unknown-Template",
+			template:      "unknown",
+			lang:          "en",
+			expectedError: ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := NewMailService(renderer, nil, nil)
+
+			msg, err := svc.PreviewEmail(test.template, test.lang)
+			assert.Equal(t, test.expectedError, err)
+
+			if err == nil {
+				assert.Equal(t, test.expectedSubject, msg.Subject)
+			}
+		})
+	}
+}
+
+func TestMailService_GetOutbox(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockOutboxRepository, ctx context.Context)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		status        string
+		expectedError error
+	}{
+		{
+			name: "OK-Default-Failed",
+			mockBehavior: func(r *mock_repository.MockOutboxRepository, ctx context.Context) {
+				r.EXPECT().GetOutboxEntries(ctx, bson.M{"status": domain.OutboxStatusFailed}, int64(maxOutboxEntries)).
+					Return([]*domain.OutboxEntry{}, nil).Times(1)
+			},
+			status:        "",
+			expectedError: nil,
+		},
+		{
+			name: "OK-Pending",
+			mockBehavior: func(r *mock_repository.MockOutboxRepository, ctx context.Context) {
+				r.EXPECT().GetOutboxEntries(ctx, bson.M{"status": domain.OutboxStatusPending}, int64(maxOutboxEntries)).
+					Return([]*domain.OutboxEntry{}, nil).Times(1)
+			},
+			status:        domain.OutboxStatusPending,
+			expectedError: nil,
+		},
+		{
+			name:          "Invalid-Status",
+			mockBehavior:  func(r *mock_repository.MockOutboxRepository, ctx context.Context) {},
+			status:        "lost",
+			expectedError: ErrInvalidOutboxStatus,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(r *mock_repository.MockOutboxRepository, ctx context.Context) {
+				r.EXPECT().GetOutboxEntries(ctx, gomock.Any(), gomock.Any()).Return(nil, errors.New("some error")).Times(1)
+			},
+			status:        domain.OutboxStatusSent,
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockOutboxRepository(c)
+				svc    = NewMailService(nil, rpstry, nil)
+				ctx    = context.Background()
+			)
+
+			test.mockBehavior(rpstry, ctx)
+
+			_, err := svc.GetOutbox(ctx, test.status)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestMailService_ReplayOutboxEntry(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockOutboxRepository, ctx context.Context, id primitive.ObjectID)
+
+	wayBack := time.Unix(0, 0)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return wayBack })
+	defer patches.Reset()
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockOutboxRepository, ctx context.Context, id primitive.ObjectID) {
+				r.EXPECT().UpdateOutboxEntries(ctx, bson.M{
+					"_id":    id,
+					"status": domain.OutboxStatusFailed,
+				}, bson.M{
+					"$set": bson.M{
+						"status":        domain.OutboxStatusPending,
+						"attempts":      0,
+						"nextAttemptAt": time.Now().UTC(),
+					},
+				}).Return(int64(1), nil).Times(1)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Not-Found",
+			mockBehavior: func(r *mock_repository.MockOutboxRepository, ctx context.Context, id primitive.ObjectID) {
+				r.EXPECT().UpdateOutboxEntries(ctx, gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+			},
+			expectedError: ErrNotFound,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(r *mock_repository.MockOutboxRepository, ctx context.Context, id primitive.ObjectID) {
+				r.EXPECT().UpdateOutboxEntries(ctx, gomock.Any(), gomock.Any()).Return(int64(0), errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockOutboxRepository(c)
+				audit  = mock_repository.NewMockAuditRepository(c)
+				svc    = NewMailService(nil, rpstry, audit)
+				ctx    = context.Background()
+				id     = primitive.NewObjectID()
+			)
+
+			test.mockBehavior(rpstry, ctx, id)
+
+			if test.expectedError == nil {
+				audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditOutboxEntryReplayed, event.Action)
+						assert.Equal(t, id.Hex(), event.Details["outboxEntryID"])
+						return event, nil
+					}).Times(1)
+			}
+
+			err := svc.ReplayOutboxEntry(ctx, id)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
@@ -7,7 +7,12 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 	domain "time-capsule/internal/domain"
+	events "time-capsule/internal/events"
+	export "time-capsule/internal/export"
+	jwks "time-capsule/internal/jwks"
+	mail "time-capsule/internal/mail"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
@@ -37,6 +42,65 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// AuthenticateUser mocks base method.
+func (m *MockUserService) AuthenticateUser(ctx context.Context, userID primitive.ObjectID) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateUser", ctx, userID)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateUser indicates an expected call of AuthenticateUser.
+func (mr *MockUserServiceMockRecorder) AuthenticateUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateUser", reflect.TypeOf((*MockUserService)(nil).AuthenticateUser), ctx, userID)
+}
+
+// CheckIn mocks base method.
+func (m *MockUserService) CheckIn(ctx context.Context, userID primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckIn", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckIn indicates an expected call of CheckIn.
+func (mr *MockUserServiceMockRecorder) CheckIn(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIn", reflect.TypeOf((*MockUserService)(nil).CheckIn), ctx, userID)
+}
+
+// CompleteOIDCSignIn mocks base method.
+func (m *MockUserService) CompleteOIDCSignIn(ctx context.Context, provider string, input domain.OIDCCallback) (*domain.SignInResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteOIDCSignIn", ctx, provider, input)
+	ret0, _ := ret[0].(*domain.SignInResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteOIDCSignIn indicates an expected call of CompleteOIDCSignIn.
+func (mr *MockUserServiceMockRecorder) CompleteOIDCSignIn(ctx, provider, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteOIDCSignIn", reflect.TypeOf((*MockUserService)(nil).CompleteOIDCSignIn), ctx, provider, input)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockUserService) ConfirmTOTP(ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO) (*domain.RecoveryCodes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, input)
+	ret0, _ := ret[0].(*domain.RecoveryCodes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockUserServiceMockRecorder) ConfirmTOTP(ctx, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockUserService)(nil).ConfirmTOTP), ctx, userID, input)
+}
+
 // CreateUser mocks base method.
 func (m *MockUserService) CreateUser(ctx context.Context, input domain.CreateUserDTO) (*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -52,11 +116,40 @@ func (mr *MockUserServiceMockRecorder) CreateUser(ctx, input interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserService)(nil).CreateUser), ctx, input)
 }
 
+// DisableTOTP mocks base method.
+func (m *MockUserService) DisableTOTP(ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockUserServiceMockRecorder) DisableTOTP(ctx, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockUserService)(nil).DisableTOTP), ctx, userID, input)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockUserService) EnrollTOTP(ctx context.Context, userID primitive.ObjectID) (*domain.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, userID)
+	ret0, _ := ret[0].(*domain.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockUserServiceMockRecorder) EnrollTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockUserService)(nil).EnrollTOTP), ctx, userID)
+}
+
 // GenerateToken mocks base method.
-func (m *MockUserService) GenerateToken(ctx context.Context, email, password string) (string, error) {
+func (m *MockUserService) GenerateToken(ctx context.Context, email, password string) (*domain.SignInResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GenerateToken", ctx, email, password)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(*domain.SignInResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -67,6 +160,35 @@ func (mr *MockUserServiceMockRecorder) GenerateToken(ctx, email, password interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockUserService)(nil).GenerateToken), ctx, email, password)
 }
 
+// GetProfile mocks base method.
+func (m *MockUserService) GetProfile(ctx context.Context, userID primitive.ObjectID) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", ctx, userID)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockUserServiceMockRecorder) GetProfile(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockUserService)(nil).GetProfile), ctx, userID)
+}
+
+// JWKS mocks base method.
+func (m *MockUserService) JWKS() jwks.Set {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(jwks.Set)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockUserServiceMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockUserService)(nil).JWKS))
+}
+
 // ParseToken mocks base method.
 func (m *MockUserService) ParseToken(accessToken string) (jwt.MapClaims, error) {
 	m.ctrl.T.Helper()
@@ -82,6 +204,107 @@ func (mr *MockUserServiceMockRecorder) ParseToken(accessToken interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockUserService)(nil).ParseToken), accessToken)
 }
 
+// RecordActivity mocks base method.
+func (m *MockUserService) RecordActivity(ctx context.Context, userID primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordActivity", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordActivity indicates an expected call of RecordActivity.
+func (mr *MockUserServiceMockRecorder) RecordActivity(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordActivity", reflect.TypeOf((*MockUserService)(nil).RecordActivity), ctx, userID)
+}
+
+// StartOIDCSignIn mocks base method.
+func (m *MockUserService) StartOIDCSignIn(ctx context.Context, provider string) (*domain.OIDCAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartOIDCSignIn", ctx, provider)
+	ret0, _ := ret[0].(*domain.OIDCAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartOIDCSignIn indicates an expected call of StartOIDCSignIn.
+func (mr *MockUserServiceMockRecorder) StartOIDCSignIn(ctx, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartOIDCSignIn", reflect.TypeOf((*MockUserService)(nil).StartOIDCSignIn), ctx, provider)
+}
+
+// UnlockAccount mocks base method.
+func (m *MockUserService) UnlockAccount(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockAccount", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockAccount indicates an expected call of UnlockAccount.
+func (mr *MockUserServiceMockRecorder) UnlockAccount(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockAccount", reflect.TypeOf((*MockUserService)(nil).UnlockAccount), ctx, token)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserService) UpdateProfile(ctx context.Context, userID primitive.ObjectID, input domain.UpdateProfileDTO) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, userID, input)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserServiceMockRecorder) UpdateProfile(ctx, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserService)(nil).UpdateProfile), ctx, userID, input)
+}
+
+// UpdateReminders mocks base method.
+func (m *MockUserService) UpdateReminders(ctx context.Context, userID primitive.ObjectID, input domain.UpdateRemindersDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReminders", ctx, userID, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReminders indicates an expected call of UpdateReminders.
+func (mr *MockUserServiceMockRecorder) UpdateReminders(ctx, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReminders", reflect.TypeOf((*MockUserService)(nil).UpdateReminders), ctx, userID, input)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockUserService) VerifyEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserServiceMockRecorder) VerifyEmail(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserService)(nil).VerifyEmail), ctx, token)
+}
+
+// VerifyMFA mocks base method.
+func (m *MockUserService) VerifyMFA(ctx context.Context, input domain.MFASignInDTO) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyMFA", ctx, input)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyMFA indicates an expected call of VerifyMFA.
+func (mr *MockUserServiceMockRecorder) VerifyMFA(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMFA", reflect.TypeOf((*MockUserService)(nil).VerifyMFA), ctx, input)
+}
+
 // MockCapsuleService is a mock of CapsuleService interface.
 type MockCapsuleService struct {
 	ctrl     *gomock.Controller
@@ -119,6 +342,20 @@ func (mr *MockCapsuleServiceMockRecorder) AddImage(ctx, userID, id, image interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddImage", reflect.TypeOf((*MockCapsuleService)(nil).AddImage), ctx, userID, id, image)
 }
 
+// AddTags mocks base method.
+func (m *MockCapsuleService) AddTags(ctx context.Context, userID, id primitive.ObjectID, tags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTags", ctx, userID, id, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTags indicates an expected call of AddTags.
+func (mr *MockCapsuleServiceMockRecorder) AddTags(ctx, userID, id, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTags", reflect.TypeOf((*MockCapsuleService)(nil).AddTags), ctx, userID, id, tags)
+}
+
 // CreateCapsule mocks base method.
 func (m *MockCapsuleService) CreateCapsule(ctx context.Context, userID primitive.ObjectID, capsule domain.CreateCapsuleDTO) (*domain.Capsule, error) {
 	m.ctrl.T.Helper()
@@ -149,18 +386,18 @@ func (mr *MockCapsuleServiceMockRecorder) DeleteCapsule(ctx, userID, id interfac
 }
 
 // GetAllCapsules mocks base method.
-func (m *MockCapsuleService) GetAllCapsules(ctx context.Context, userID primitive.ObjectID) ([]*domain.Capsule, error) {
+func (m *MockCapsuleService) GetAllCapsules(ctx context.Context, userID primitive.ObjectID, filter domain.CapsuleFilter) ([]*domain.Capsule, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllCapsules", ctx, userID)
+	ret := m.ctrl.Call(m, "GetAllCapsules", ctx, userID, filter)
 	ret0, _ := ret[0].([]*domain.Capsule)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllCapsules indicates an expected call of GetAllCapsules.
-func (mr *MockCapsuleServiceMockRecorder) GetAllCapsules(ctx, userID interface{}) *gomock.Call {
+func (mr *MockCapsuleServiceMockRecorder) GetAllCapsules(ctx, userID, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCapsules", reflect.TypeOf((*MockCapsuleService)(nil).GetAllCapsules), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCapsules", reflect.TypeOf((*MockCapsuleService)(nil).GetAllCapsules), ctx, userID, filter)
 }
 
 // GetCapsuleByID mocks base method.
@@ -178,6 +415,35 @@ func (mr *MockCapsuleServiceMockRecorder) GetCapsuleByID(ctx, userID, id interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCapsuleByID", reflect.TypeOf((*MockCapsuleService)(nil).GetCapsuleByID), ctx, userID, id)
 }
 
+// GetTags mocks base method.
+func (m *MockCapsuleService) GetTags(ctx context.Context, userID primitive.ObjectID) ([]*domain.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", ctx, userID)
+	ret0, _ := ret[0].([]*domain.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockCapsuleServiceMockRecorder) GetTags(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockCapsuleService)(nil).GetTags), ctx, userID)
+}
+
+// MergeTags mocks base method.
+func (m *MockCapsuleService) MergeTags(ctx context.Context, userID primitive.ObjectID, tags []string, into string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeTags", ctx, userID, tags, into)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeTags indicates an expected call of MergeTags.
+func (mr *MockCapsuleServiceMockRecorder) MergeTags(ctx, userID, tags, into interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeTags", reflect.TypeOf((*MockCapsuleService)(nil).MergeTags), ctx, userID, tags, into)
+}
+
 // RemoveImage mocks base method.
 func (m *MockCapsuleService) RemoveImage(ctx context.Context, userID, id primitive.ObjectID, image string) error {
 	m.ctrl.T.Helper()
@@ -192,6 +458,49 @@ func (mr *MockCapsuleServiceMockRecorder) RemoveImage(ctx, userID, id, image int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveImage", reflect.TypeOf((*MockCapsuleService)(nil).RemoveImage), ctx, userID, id, image)
 }
 
+// RemoveTag mocks base method.
+func (m *MockCapsuleService) RemoveTag(ctx context.Context, userID, id primitive.ObjectID, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTag", ctx, userID, id, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTag indicates an expected call of RemoveTag.
+func (mr *MockCapsuleServiceMockRecorder) RemoveTag(ctx, userID, id, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTag", reflect.TypeOf((*MockCapsuleService)(nil).RemoveTag), ctx, userID, id, tag)
+}
+
+// RenameTag mocks base method.
+func (m *MockCapsuleService) RenameTag(ctx context.Context, userID primitive.ObjectID, tag, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameTag", ctx, userID, tag, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameTag indicates an expected call of RenameTag.
+func (mr *MockCapsuleServiceMockRecorder) RenameTag(ctx, userID, tag, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameTag", reflect.TypeOf((*MockCapsuleService)(nil).RenameTag), ctx, userID, tag, name)
+}
+
+// SearchCapsules mocks base method.
+func (m *MockCapsuleService) SearchCapsules(ctx context.Context, userID primitive.ObjectID, query string) ([]*domain.CapsuleSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchCapsules", ctx, userID, query)
+	ret0, _ := ret[0].([]*domain.CapsuleSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchCapsules indicates an expected call of SearchCapsules.
+func (mr *MockCapsuleServiceMockRecorder) SearchCapsules(ctx, userID, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchCapsules", reflect.TypeOf((*MockCapsuleService)(nil).SearchCapsules), ctx, userID, query)
+}
+
 // UpdateCapsule mocks base method.
 func (m *MockCapsuleService) UpdateCapsule(ctx context.Context, userID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) error {
 	m.ctrl.T.Helper()
@@ -205,3 +514,762 @@ func (mr *MockCapsuleServiceMockRecorder) UpdateCapsule(ctx, userID, id, update
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCapsule", reflect.TypeOf((*MockCapsuleService)(nil).UpdateCapsule), ctx, userID, id, update)
 }
+
+// MockCollectionService is a mock of CollectionService interface.
+type MockCollectionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollectionServiceMockRecorder
+}
+
+// MockCollectionServiceMockRecorder is the mock recorder for MockCollectionService.
+type MockCollectionServiceMockRecorder struct {
+	mock *MockCollectionService
+}
+
+// NewMockCollectionService creates a new mock instance.
+func NewMockCollectionService(ctrl *gomock.Controller) *MockCollectionService {
+	mock := &MockCollectionService{ctrl: ctrl}
+	mock.recorder = &MockCollectionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollectionService) EXPECT() *MockCollectionServiceMockRecorder {
+	return m.recorder
+}
+
+// AddCapsule mocks base method.
+func (m *MockCollectionService) AddCapsule(ctx context.Context, userID, id, capsuleID primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCapsule", ctx, userID, id, capsuleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCapsule indicates an expected call of AddCapsule.
+func (mr *MockCollectionServiceMockRecorder) AddCapsule(ctx, userID, id, capsuleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCapsule", reflect.TypeOf((*MockCollectionService)(nil).AddCapsule), ctx, userID, id, capsuleID)
+}
+
+// CreateCollection mocks base method.
+func (m *MockCollectionService) CreateCollection(ctx context.Context, userID primitive.ObjectID, input domain.CreateCollectionDTO) (*domain.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCollection", ctx, userID, input)
+	ret0, _ := ret[0].(*domain.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCollection indicates an expected call of CreateCollection.
+func (mr *MockCollectionServiceMockRecorder) CreateCollection(ctx, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCollection", reflect.TypeOf((*MockCollectionService)(nil).CreateCollection), ctx, userID, input)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockCollectionService) DeleteCollection(ctx context.Context, userID, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockCollectionServiceMockRecorder) DeleteCollection(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockCollectionService)(nil).DeleteCollection), ctx, userID, id)
+}
+
+// GetAllCollections mocks base method.
+func (m *MockCollectionService) GetAllCollections(ctx context.Context, userID primitive.ObjectID) ([]*domain.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllCollections", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllCollections indicates an expected call of GetAllCollections.
+func (mr *MockCollectionServiceMockRecorder) GetAllCollections(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCollections", reflect.TypeOf((*MockCollectionService)(nil).GetAllCollections), ctx, userID)
+}
+
+// GetCollectionByID mocks base method.
+func (m *MockCollectionService) GetCollectionByID(ctx context.Context, userID, id primitive.ObjectID) (*domain.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollectionByID", ctx, userID, id)
+	ret0, _ := ret[0].(*domain.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollectionByID indicates an expected call of GetCollectionByID.
+func (mr *MockCollectionServiceMockRecorder) GetCollectionByID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollectionByID", reflect.TypeOf((*MockCollectionService)(nil).GetCollectionByID), ctx, userID, id)
+}
+
+// RemoveCapsule mocks base method.
+func (m *MockCollectionService) RemoveCapsule(ctx context.Context, userID, id, capsuleID primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCapsule", ctx, userID, id, capsuleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveCapsule indicates an expected call of RemoveCapsule.
+func (mr *MockCollectionServiceMockRecorder) RemoveCapsule(ctx, userID, id, capsuleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCapsule", reflect.TypeOf((*MockCollectionService)(nil).RemoveCapsule), ctx, userID, id, capsuleID)
+}
+
+// UpdateCollection mocks base method.
+func (m *MockCollectionService) UpdateCollection(ctx context.Context, userID, id primitive.ObjectID, update domain.UpdateCollectionDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCollection", ctx, userID, id, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCollection indicates an expected call of UpdateCollection.
+func (mr *MockCollectionServiceMockRecorder) UpdateCollection(ctx, userID, id, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCollection", reflect.TypeOf((*MockCollectionService)(nil).UpdateCollection), ctx, userID, id, update)
+}
+
+// MockMailService is a mock of MailService interface.
+type MockMailService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailServiceMockRecorder
+}
+
+// MockMailServiceMockRecorder is the mock recorder for MockMailService.
+type MockMailServiceMockRecorder struct {
+	mock *MockMailService
+}
+
+// NewMockMailService creates a new mock instance.
+func NewMockMailService(ctrl *gomock.Controller) *MockMailService {
+	mock := &MockMailService{ctrl: ctrl}
+	mock.recorder = &MockMailServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailService) EXPECT() *MockMailServiceMockRecorder {
+	return m.recorder
+}
+
+// GetOutbox mocks base method.
+func (m *MockMailService) GetOutbox(ctx context.Context, status string) ([]*domain.OutboxEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutbox", ctx, status)
+	ret0, _ := ret[0].([]*domain.OutboxEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutbox indicates an expected call of GetOutbox.
+func (mr *MockMailServiceMockRecorder) GetOutbox(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutbox", reflect.TypeOf((*MockMailService)(nil).GetOutbox), ctx, status)
+}
+
+// PreviewEmail mocks base method.
+func (m *MockMailService) PreviewEmail(name, lang string) (*mail.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewEmail", name, lang)
+	ret0, _ := ret[0].(*mail.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewEmail indicates an expected call of PreviewEmail.
+func (mr *MockMailServiceMockRecorder) PreviewEmail(name, lang interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewEmail", reflect.TypeOf((*MockMailService)(nil).PreviewEmail), name, lang)
+}
+
+// ReplayOutboxEntry mocks base method.
+func (m *MockMailService) ReplayOutboxEntry(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayOutboxEntry", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayOutboxEntry indicates an expected call of ReplayOutboxEntry.
+func (mr *MockMailServiceMockRecorder) ReplayOutboxEntry(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayOutboxEntry", reflect.TypeOf((*MockMailService)(nil).ReplayOutboxEntry), ctx, id)
+}
+
+// MockNotificationService is a mock of NotificationService interface.
+type MockNotificationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationServiceMockRecorder
+}
+
+// MockNotificationServiceMockRecorder is the mock recorder for MockNotificationService.
+type MockNotificationServiceMockRecorder struct {
+	mock *MockNotificationService
+}
+
+// NewMockNotificationService creates a new mock instance.
+func NewMockNotificationService(ctrl *gomock.Controller) *MockNotificationService {
+	mock := &MockNotificationService{ctrl: ctrl}
+	mock.recorder = &MockNotificationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationService) EXPECT() *MockNotificationServiceMockRecorder {
+	return m.recorder
+}
+
+// GetNotifications mocks base method.
+func (m *MockNotificationService) GetNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) (*domain.NotificationList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, userID, unreadOnly)
+	ret0, _ := ret[0].(*domain.NotificationList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockNotificationServiceMockRecorder) GetNotifications(ctx, userID, unreadOnly interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockNotificationService)(nil).GetNotifications), ctx, userID, unreadOnly)
+}
+
+// MarkNotificationsRead mocks base method.
+func (m *MockNotificationService) MarkNotificationsRead(ctx context.Context, userID primitive.ObjectID, input domain.MarkNotificationsReadDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationsRead", ctx, userID, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkNotificationsRead indicates an expected call of MarkNotificationsRead.
+func (mr *MockNotificationServiceMockRecorder) MarkNotificationsRead(ctx, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationsRead", reflect.TypeOf((*MockNotificationService)(nil).MarkNotificationsRead), ctx, userID, input)
+}
+
+// Subscribe mocks base method.
+func (m *MockNotificationService) Subscribe(userID primitive.ObjectID) (<-chan events.Event, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", userID)
+	ret0, _ := ret[0].(<-chan events.Event)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockNotificationServiceMockRecorder) Subscribe(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockNotificationService)(nil).Subscribe), userID)
+}
+
+// MockAccessTokenService is a mock of AccessTokenService interface.
+type MockAccessTokenService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessTokenServiceMockRecorder
+}
+
+// MockAccessTokenServiceMockRecorder is the mock recorder for MockAccessTokenService.
+type MockAccessTokenServiceMockRecorder struct {
+	mock *MockAccessTokenService
+}
+
+// NewMockAccessTokenService creates a new mock instance.
+func NewMockAccessTokenService(ctrl *gomock.Controller) *MockAccessTokenService {
+	mock := &MockAccessTokenService{ctrl: ctrl}
+	mock.recorder = &MockAccessTokenServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccessTokenService) EXPECT() *MockAccessTokenServiceMockRecorder {
+	return m.recorder
+}
+
+// AuthenticateAccessToken mocks base method.
+func (m *MockAccessTokenService) AuthenticateAccessToken(ctx context.Context, token string) (*domain.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAccessToken", ctx, token)
+	ret0, _ := ret[0].(*domain.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAccessToken indicates an expected call of AuthenticateAccessToken.
+func (mr *MockAccessTokenServiceMockRecorder) AuthenticateAccessToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAccessToken", reflect.TypeOf((*MockAccessTokenService)(nil).AuthenticateAccessToken), ctx, token)
+}
+
+// CreateAccessToken mocks base method.
+func (m *MockAccessTokenService) CreateAccessToken(ctx context.Context, userID primitive.ObjectID, input domain.CreateAccessTokenDTO) (*domain.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccessToken", ctx, userID, input)
+	ret0, _ := ret[0].(*domain.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccessToken indicates an expected call of CreateAccessToken.
+func (mr *MockAccessTokenServiceMockRecorder) CreateAccessToken(ctx, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockAccessTokenService)(nil).CreateAccessToken), ctx, userID, input)
+}
+
+// GetAccessTokens mocks base method.
+func (m *MockAccessTokenService) GetAccessTokens(ctx context.Context, userID primitive.ObjectID) ([]*domain.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokens", ctx, userID)
+	ret0, _ := ret[0].([]*domain.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokens indicates an expected call of GetAccessTokens.
+func (mr *MockAccessTokenServiceMockRecorder) GetAccessTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokens", reflect.TypeOf((*MockAccessTokenService)(nil).GetAccessTokens), ctx, userID)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockAccessTokenService) RevokeAccessToken(ctx context.Context, userID, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockAccessTokenServiceMockRecorder) RevokeAccessToken(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockAccessTokenService)(nil).RevokeAccessToken), ctx, userID, id)
+}
+
+// MockAccountService is a mock of AccountService interface.
+type MockAccountService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountServiceMockRecorder
+}
+
+// MockAccountServiceMockRecorder is the mock recorder for MockAccountService.
+type MockAccountServiceMockRecorder struct {
+	mock *MockAccountService
+}
+
+// NewMockAccountService creates a new mock instance.
+func NewMockAccountService(ctrl *gomock.Controller) *MockAccountService {
+	mock := &MockAccountService{ctrl: ctrl}
+	mock.recorder = &MockAccountServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountService) EXPECT() *MockAccountServiceMockRecorder {
+	return m.recorder
+}
+
+// DeleteAccount mocks base method.
+func (m *MockAccountService) DeleteAccount(ctx context.Context, userID primitive.ObjectID, input domain.DeleteAccountDTO) (*domain.AccountDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, userID, input)
+	ret0, _ := ret[0].(*domain.AccountDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockAccountServiceMockRecorder) DeleteAccount(ctx, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockAccountService)(nil).DeleteAccount), ctx, userID, input)
+}
+
+// PurgeAccounts mocks base method.
+func (m *MockAccountService) PurgeAccounts(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeAccounts", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeAccounts indicates an expected call of PurgeAccounts.
+func (mr *MockAccountServiceMockRecorder) PurgeAccounts(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeAccounts", reflect.TypeOf((*MockAccountService)(nil).PurgeAccounts), ctx, now)
+}
+
+// RestoreAccount mocks base method.
+func (m *MockAccountService) RestoreAccount(ctx context.Context, userID primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreAccount", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreAccount indicates an expected call of RestoreAccount.
+func (mr *MockAccountServiceMockRecorder) RestoreAccount(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreAccount", reflect.TypeOf((*MockAccountService)(nil).RestoreAccount), ctx, userID)
+}
+
+// MockExportService is a mock of ExportService interface.
+type MockExportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportServiceMockRecorder
+}
+
+// MockExportServiceMockRecorder is the mock recorder for MockExportService.
+type MockExportServiceMockRecorder struct {
+	mock *MockExportService
+}
+
+// NewMockExportService creates a new mock instance.
+func NewMockExportService(ctrl *gomock.Controller) *MockExportService {
+	mock := &MockExportService{ctrl: ctrl}
+	mock.recorder = &MockExportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportService) EXPECT() *MockExportServiceMockRecorder {
+	return m.recorder
+}
+
+// BuildExports mocks base method.
+func (m *MockExportService) BuildExports(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BuildExports", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BuildExports indicates an expected call of BuildExports.
+func (mr *MockExportServiceMockRecorder) BuildExports(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildExports", reflect.TypeOf((*MockExportService)(nil).BuildExports), ctx, now)
+}
+
+// DownloadExport mocks base method.
+func (m *MockExportService) DownloadExport(ctx context.Context, token string) (*domain.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadExport", ctx, token)
+	ret0, _ := ret[0].(*domain.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadExport indicates an expected call of DownloadExport.
+func (mr *MockExportServiceMockRecorder) DownloadExport(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadExport", reflect.TypeOf((*MockExportService)(nil).DownloadExport), ctx, token)
+}
+
+// ExpireExports mocks base method.
+func (m *MockExportService) ExpireExports(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireExports", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireExports indicates an expected call of ExpireExports.
+func (mr *MockExportServiceMockRecorder) ExpireExports(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireExports", reflect.TypeOf((*MockExportService)(nil).ExpireExports), ctx, now)
+}
+
+// GetExports mocks base method.
+func (m *MockExportService) GetExports(ctx context.Context, userID primitive.ObjectID) ([]*domain.Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExports", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExports indicates an expected call of GetExports.
+func (mr *MockExportServiceMockRecorder) GetExports(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExports", reflect.TypeOf((*MockExportService)(nil).GetExports), ctx, userID)
+}
+
+// RequestExport mocks base method.
+func (m *MockExportService) RequestExport(ctx context.Context, userID primitive.ObjectID) (*domain.Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestExport", ctx, userID)
+	ret0, _ := ret[0].(*domain.Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestExport indicates an expected call of RequestExport.
+func (mr *MockExportServiceMockRecorder) RequestExport(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestExport", reflect.TypeOf((*MockExportService)(nil).RequestExport), ctx, userID)
+}
+
+// MockImportService is a mock of ImportService interface.
+type MockImportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportServiceMockRecorder
+}
+
+// MockImportServiceMockRecorder is the mock recorder for MockImportService.
+type MockImportServiceMockRecorder struct {
+	mock *MockImportService
+}
+
+// NewMockImportService creates a new mock instance.
+func NewMockImportService(ctrl *gomock.Controller) *MockImportService {
+	mock := &MockImportService{ctrl: ctrl}
+	mock.recorder = &MockImportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImportService) EXPECT() *MockImportServiceMockRecorder {
+	return m.recorder
+}
+
+// ImportCapsules mocks base method.
+func (m *MockImportService) ImportCapsules(ctx context.Context, userID primitive.ObjectID, archive *export.Archive) (*domain.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportCapsules", ctx, userID, archive)
+	ret0, _ := ret[0].(*domain.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportCapsules indicates an expected call of ImportCapsules.
+func (mr *MockImportServiceMockRecorder) ImportCapsules(ctx, userID, archive interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportCapsules", reflect.TypeOf((*MockImportService)(nil).ImportCapsules), ctx, userID, archive)
+}
+
+// MockAdminService is a mock of AdminService interface.
+type MockAdminService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServiceMockRecorder
+}
+
+// MockAdminServiceMockRecorder is the mock recorder for MockAdminService.
+type MockAdminServiceMockRecorder struct {
+	mock *MockAdminService
+}
+
+// NewMockAdminService creates a new mock instance.
+func NewMockAdminService(ctrl *gomock.Controller) *MockAdminService {
+	mock := &MockAdminService{ctrl: ctrl}
+	mock.recorder = &MockAdminServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminService) EXPECT() *MockAdminServiceMockRecorder {
+	return m.recorder
+}
+
+// DisableUser mocks base method.
+func (m *MockAdminService) DisableUser(ctx context.Context, staffID, userID primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUser", ctx, staffID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableUser indicates an expected call of DisableUser.
+func (mr *MockAdminServiceMockRecorder) DisableUser(ctx, staffID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUser", reflect.TypeOf((*MockAdminService)(nil).DisableUser), ctx, staffID, userID)
+}
+
+// EnableUser mocks base method.
+func (m *MockAdminService) EnableUser(ctx context.Context, staffID, userID primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUser", ctx, staffID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableUser indicates an expected call of EnableUser.
+func (mr *MockAdminServiceMockRecorder) EnableUser(ctx, staffID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUser", reflect.TypeOf((*MockAdminService)(nil).EnableUser), ctx, staffID, userID)
+}
+
+// GetCapsuleMetadata mocks base method.
+func (m *MockAdminService) GetCapsuleMetadata(ctx context.Context, id primitive.ObjectID) (*domain.CapsuleMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCapsuleMetadata", ctx, id)
+	ret0, _ := ret[0].(*domain.CapsuleMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCapsuleMetadata indicates an expected call of GetCapsuleMetadata.
+func (mr *MockAdminServiceMockRecorder) GetCapsuleMetadata(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCapsuleMetadata", reflect.TypeOf((*MockAdminService)(nil).GetCapsuleMetadata), ctx, id)
+}
+
+// GetUser mocks base method.
+func (m *MockAdminService) GetUser(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, id)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockAdminServiceMockRecorder) GetUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockAdminService)(nil).GetUser), ctx, id)
+}
+
+// GetUserCapsules mocks base method.
+func (m *MockAdminService) GetUserCapsules(ctx context.Context, userID primitive.ObjectID) ([]*domain.CapsuleMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserCapsules", ctx, userID)
+	ret0, _ := ret[0].([]*domain.CapsuleMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserCapsules indicates an expected call of GetUserCapsules.
+func (mr *MockAdminServiceMockRecorder) GetUserCapsules(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCapsules", reflect.TypeOf((*MockAdminService)(nil).GetUserCapsules), ctx, userID)
+}
+
+// GetWorkerStatus mocks base method.
+func (m *MockAdminService) GetWorkerStatus(ctx context.Context) (*domain.WorkerStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkerStatus", ctx)
+	ret0, _ := ret[0].(*domain.WorkerStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkerStatus indicates an expected call of GetWorkerStatus.
+func (mr *MockAdminServiceMockRecorder) GetWorkerStatus(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkerStatus", reflect.TypeOf((*MockAdminService)(nil).GetWorkerStatus), ctx)
+}
+
+// RecordWorkerCycle mocks base method.
+func (m *MockAdminService) RecordWorkerCycle(startedAt, finishedAt time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordWorkerCycle", startedAt, finishedAt)
+}
+
+// RecordWorkerCycle indicates an expected call of RecordWorkerCycle.
+func (mr *MockAdminServiceMockRecorder) RecordWorkerCycle(startedAt, finishedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordWorkerCycle", reflect.TypeOf((*MockAdminService)(nil).RecordWorkerCycle), startedAt, finishedAt)
+}
+
+// ResendNotifications mocks base method.
+func (m *MockAdminService) ResendNotifications(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendNotifications", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendNotifications indicates an expected call of ResendNotifications.
+func (mr *MockAdminServiceMockRecorder) ResendNotifications(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendNotifications", reflect.TypeOf((*MockAdminService)(nil).ResendNotifications), ctx, id)
+}
+
+// SearchUsers mocks base method.
+func (m *MockAdminService) SearchUsers(ctx context.Context, query string) ([]*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, query)
+	ret0, _ := ret[0].([]*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockAdminServiceMockRecorder) SearchUsers(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockAdminService)(nil).SearchUsers), ctx, query)
+}
+
+// UpdateRole mocks base method.
+func (m *MockAdminService) UpdateRole(ctx context.Context, staffID, userID primitive.ObjectID, input domain.UpdateRoleDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, staffID, userID, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockAdminServiceMockRecorder) UpdateRole(ctx, staffID, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockAdminService)(nil).UpdateRole), ctx, staffID, userID, input)
+}
+
+// MockAuditService is a mock of AuditService interface.
+type MockAuditService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditServiceMockRecorder
+}
+
+// MockAuditServiceMockRecorder is the mock recorder for MockAuditService.
+type MockAuditServiceMockRecorder struct {
+	mock *MockAuditService
+}
+
+// NewMockAuditService creates a new mock instance.
+func NewMockAuditService(ctrl *gomock.Controller) *MockAuditService {
+	mock := &MockAuditService{ctrl: ctrl}
+	mock.recorder = &MockAuditServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditService) EXPECT() *MockAuditServiceMockRecorder {
+	return m.recorder
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockAuditService) GetAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, filter)
+	ret0, _ := ret[0].([]*domain.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockAuditServiceMockRecorder) GetAuditEvents(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockAuditService)(nil).GetAuditEvents), ctx, filter)
+}
+
+// GetUserAuditEvents mocks base method.
+func (m *MockAuditService) GetUserAuditEvents(ctx context.Context, userID primitive.ObjectID, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAuditEvents", ctx, userID, filter)
+	ret0, _ := ret[0].([]*domain.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAuditEvents indicates an expected call of GetUserAuditEvents.
+func (mr *MockAuditServiceMockRecorder) GetUserAuditEvents(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAuditEvents", reflect.TypeOf((*MockAuditService)(nil).GetUserAuditEvents), ctx, userID, filter)
+}
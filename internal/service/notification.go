@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/events"
+	"time-capsule/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxNotifications = 50
+
+type notificationService struct {
+	repository repository.NotificationRepository
+	broker     events.Broker
+}
+
+func NewNotificationService(repository repository.NotificationRepository, broker events.Broker) NotificationService {
+	return &notificationService{
+		repository: repository,
+		broker:     broker,
+	}
+}
+
+// GetNotifications returns the user's latest notifications, only the unread ones if unreadOnly is set,
+// along with the number of unread notifications.
+func (s *notificationService) GetNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) (*domain.NotificationList, error) {
+	unreadFilter := bson.M{
+		"userID": userID,
+		"readAt": bson.M{"$exists": false},
+	}
+
+	filter := bson.M{"userID": userID}
+	if unreadOnly {
+		filter = unreadFilter
+	}
+
+	notifications, err := s.repository.GetNotifications(ctx, filter, maxNotifications)
+	if err != nil {
+		slog.ErrorContext(ctx, "GetNotifications", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	unread, err := s.repository.CountNotifications(ctx, unreadFilter)
+	if err != nil {
+		slog.ErrorContext(ctx, "GetNotifications", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if notifications == nil {
+		notifications = []*domain.Notification{}
+	}
+
+	return &domain.NotificationList{
+		Notifications: notifications,
+		Unread:        unread,
+	}, nil
+}
+
+// MarkNotificationsRead marks the given notifications of the user as read, all of them if none are given,
+// and lets the user's other sessions know.
+func (s *notificationService) MarkNotificationsRead(ctx context.Context, userID primitive.ObjectID, input domain.MarkNotificationsReadDTO) error {
+	filter := bson.M{
+		"userID": userID,
+		"readAt": bson.M{"$exists": false},
+	}
+
+	if len(input.IDs) > 0 {
+		filter["_id"] = bson.M{"$in": input.IDs}
+	}
+
+	if _, err := s.repository.UpdateNotifications(ctx, filter, bson.M{
+		"$set": bson.M{
+			"readAt": time.Now().UTC(),
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "MarkNotificationsRead", "error", err)
+		return ErrDBFailure
+	}
+
+	if input.IDs == nil {
+		input.IDs = []primitive.ObjectID{}
+	}
+
+	s.broker.Publish(userID, events.Event{
+		Type: events.TypeNotificationsRead,
+		Data: input,
+	})
+
+	return nil
+}
+
+// Subscribe streams the user's events, e.g. new notifications, until unsubscribed.
+func (s *notificationService) Subscribe(userID primitive.ObjectID) (<-chan events.Event, func()) {
+	return s.broker.Subscribe(userID)
+}
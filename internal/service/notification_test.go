@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/events"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationService_GetNotifications(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockNotificationRepository, ctx context.Context, userID primitive.ObjectID)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		unreadOnly    bool
+		expected      *domain.NotificationList
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockNotificationRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().GetNotifications(ctx, bson.M{"userID": userID}, int64(maxNotifications)).
+					Return([]*domain.Notification{{Type: domain.NotificationCapsuleOpened}}, nil).Times(1)
+				r.EXPECT().CountNotifications(ctx, bson.M{
+					"userID": userID,
+					"readAt": bson.M{"$exists": false},
+				}).Return(int64(1), nil).Times(1)
+			},
+			unreadOnly: false,
+			expected: &domain.NotificationList{
+				Notifications: []*domain.Notification{{Type: domain.NotificationCapsuleOpened}},
+				Unread:        1,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "OK-Unread-Only-Empty",
+			mockBehavior: func(r *mock_repository.MockNotificationRepository, ctx context.Context, userID primitive.ObjectID) {
+				unread := bson.M{
+					"userID": userID,
+					"readAt": bson.M{"$exists": false},
+				}
+
+				r.EXPECT().GetNotifications(ctx, unread, int64(maxNotifications)).Return(nil, nil).Times(1)
+				r.EXPECT().CountNotifications(ctx, unread).Return(int64(0), nil).Times(1)
+			},
+			unreadOnly: true,
+			expected: &domain.NotificationList{
+				Notifications: []*domain.Notification{},
+				Unread:        0,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(r *mock_repository.MockNotificationRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().GetNotifications(ctx, gomock.Any(), gomock.Any()).Return(nil, errors.New("some error")).Times(1)
+			},
+			unreadOnly:    false,
+			expected:      nil,
+			expectedError: ErrDBFailure,
+		},
+		{
+			name: "DB-Failure-Count",
+			mockBehavior: func(r *mock_repository.MockNotificationRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().GetNotifications(ctx, gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				r.EXPECT().CountNotifications(ctx, gomock.Any()).Return(int64(0), errors.New("some error")).Times(1)
+			},
+			unreadOnly:    false,
+			expected:      nil,
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockNotificationRepository(c)
+				svc    = NewNotificationService(rpstry, events.NewBroker())
+				ctx    = context.Background()
+				userID = primitive.NewObjectID()
+			)
+
+			test.mockBehavior(rpstry, ctx, userID)
+
+			notifications, err := svc.GetNotifications(ctx, userID, test.unreadOnly)
+			assert.Equal(t, test.expectedError, err)
+			assert.Equal(t, test.expected, notifications)
+		})
+	}
+}
+
+func TestNotificationService_MarkNotificationsRead(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockNotificationRepository, ctx context.Context, userID primitive.ObjectID)
+
+	wayBack := time.Unix(0, 0)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return wayBack })
+	defer patches.Reset()
+
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		input         domain.MarkNotificationsReadDTO
+		expectedEvent *events.Event
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockNotificationRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().UpdateNotifications(ctx, bson.M{
+					"_id":    bson.M{"$in": []primitive.ObjectID{id}},
+					"userID": userID,
+					"readAt": bson.M{"$exists": false},
+				}, bson.M{
+					"$set": bson.M{
+						"readAt": time.Now().UTC(),
+					},
+				}).Return(int64(1), nil).Times(1)
+			},
+			input: domain.MarkNotificationsReadDTO{IDs: []primitive.ObjectID{id}},
+			expectedEvent: &events.Event{
+				Type: events.TypeNotificationsRead,
+				Data: domain.MarkNotificationsReadDTO{IDs: []primitive.ObjectID{id}},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "OK-All",
+			mockBehavior: func(r *mock_repository.MockNotificationRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().UpdateNotifications(ctx, bson.M{
+					"userID": userID,
+					"readAt": bson.M{"$exists": false},
+				}, gomock.Any()).Return(int64(3), nil).Times(1)
+			},
+			input: domain.MarkNotificationsReadDTO{},
+			expectedEvent: &events.Event{
+				Type: events.TypeNotificationsRead,
+				Data: domain.MarkNotificationsReadDTO{IDs: []primitive.ObjectID{}},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(r *mock_repository.MockNotificationRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().UpdateNotifications(ctx, gomock.Any(), gomock.Any()).Return(int64(0), errors.New("some error")).Times(1)
+			},
+			input:         domain.MarkNotificationsReadDTO{},
+			expectedEvent: nil,
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockNotificationRepository(c)
+				broker = events.NewBroker()
+				svc    = NewNotificationService(rpstry, broker)
+				ctx    = context.Background()
+				userID = primitive.NewObjectID()
+			)
+
+			stream, unsubscribe := svc.Subscribe(userID)
+			defer unsubscribe()
+
+			test.mockBehavior(rpstry, ctx, userID)
+
+			err := svc.MarkNotificationsRead(ctx, userID, test.input)
+			assert.Equal(t, test.expectedError, err)
+
+			if test.expectedEvent == nil {
+				assert.Empty(t, stream)
+				return
+			}
+
+			assert.Equal(t, *test.expectedEvent, <-stream)
+		})
+	}
+}
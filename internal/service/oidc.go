@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/oidc"
+	"time-capsule/internal/tracing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	oidcStatePurpose = "oidc_state"
+	oidcStateTTL     = 10 * time.Minute
+	oidcCallbackPath = "/api/v1/oidc/%s/callback"
+
+	// usernameAttempts is how many usernames are tried for users created by signing in with a provider.
+	usernameAttempts = 5
+)
+
+var (
+	ErrUnknownProvider  = errors.New("unknown identity provider")
+	ErrInvalidOIDCState = errors.New("invalid or expired sign-in state, start the sign-in again")
+	ErrOIDCDenied       = errors.New("sign-in was denied by the identity provider")
+	ErrOIDCFailure      = errors.New("failed to sign in with the identity provider")
+	ErrEmailNotVerified = errors.New("the identity provider hasn't verified the email address")
+)
+
+var usernameDisallowed = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// StartOIDCSignIn starts signing in with the identity provider. The state token binds the sign-in
+// to the client that started it: the client sends it back with the callback.
+func (s *userService) StartOIDCSignIn(ctx context.Context, provider string) (*domain.OIDCAuthorization, error) {
+	ctx, span := tracing.Start(ctx, "UserService.StartOIDCSignIn")
+	defer span.End()
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			slog.ErrorContext(ctx, "StartOIDCSignIn", "error", err)
+			return nil, ErrTokenCreationFailed
+		}
+
+		values[i] = value
+	}
+
+	var (
+		state, nonce, verifier = values[0], values[1], values[2]
+		expiresAt              = time.Now().UTC().Add(oidcStateTTL)
+	)
+
+	stateToken, err := s.keys.Sign(jwt.MapClaims{
+		"purpose":  oidcStatePurpose,
+		"provider": provider,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      expiresAt.Unix(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "StartOIDCSignIn", "error", err)
+		return nil, ErrTokenCreationFailed
+	}
+
+	url, err := p.AuthCodeURL(ctx, s.oidcRedirectURI(provider), state, nonce, verifier)
+	if err != nil {
+		slog.ErrorContext(ctx, "StartOIDCSignIn", "error", err)
+		return nil, ErrOIDCFailure
+	}
+
+	return &domain.OIDCAuthorization{
+		URL:        url,
+		StateToken: stateToken,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// CompleteOIDCSignIn signs in the user the identity provider redirected back. Users are found by their identity
+// at the provider, or else linked by their verified email, or else created.
+func (s *userService) CompleteOIDCSignIn(ctx context.Context, provider string, input domain.OIDCCallback) (*domain.SignInResult, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CompleteOIDCSignIn")
+	defer span.End()
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	if input.Error != "" {
+		return nil, ErrOIDCDenied
+	}
+
+	state, err := s.parseOIDCState(input.StateToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if state["provider"] != provider || input.State == "" ||
+		subtle.ConstantTimeCompare([]byte(input.State), []byte(state["state"])) != 1 {
+		return nil, ErrInvalidOIDCState
+	}
+
+	claims, err := p.Exchange(ctx, s.oidcRedirectURI(provider), input.Code, state["verifier"], state["nonce"])
+	if err != nil {
+		slog.ErrorContext(ctx, "CompleteOIDCSignIn", "error", err)
+		return nil, ErrOIDCFailure
+	}
+
+	user, err := s.oidcUser(ctx, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, map[string]string{"provider": provider})
+}
+
+// oidcUser returns the user with the identity, linking it to the user with its email or creating the user.
+func (s *userService) oidcUser(ctx context.Context, provider string, claims *oidc.Claims) (*domain.User, error) {
+	user, err := s.repository.GetUser(ctx, bson.M{
+		"identities": bson.M{
+			"$elemMatch": bson.M{"provider": provider, "subject": claims.Subject},
+		},
+	})
+	if err == nil {
+		return user, nil
+	}
+
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		slog.ErrorContext(ctx, "oidcUser", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	// Only verified emails are trusted to link accounts, or anyone could take over an account
+	// by claiming its email at a provider.
+	if !claims.EmailVerified || !emailValidation(claims.Email) {
+		return nil, ErrEmailNotVerified
+	}
+
+	identity := domain.Identity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		LinkedAt: time.Now().UTC(),
+	}
+
+	user, err = s.repository.GetUser(ctx, bson.M{
+		"email": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(claims.Email) + "$", Options: "i"},
+	})
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		slog.ErrorContext(ctx, "oidcUser", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if user == nil {
+		return s.createOIDCUser(ctx, claims, identity)
+	}
+
+	if err = s.repository.UpdateUser(ctx, user.ID, bson.M{
+		"$push": bson.M{
+			"identities": identity,
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "oidcUser", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action:  domain.AuditIdentityLinked,
+		UserID:  user.ID,
+		Details: map[string]string{"provider": provider},
+	})
+
+	user.Identities = append(user.Identities, identity)
+
+	return user, nil
+}
+
+// createOIDCUser creates the user signing in with a provider for the first time. They have no password,
+// their username is derived from their email.
+func (s *userService) createOIDCUser(ctx context.Context, claims *oidc.Claims, identity domain.Identity) (*domain.User, error) {
+	base := usernameDisallowed.ReplaceAllString(strings.Split(claims.Email, "@")[0], "")
+	if len(base) < 3 {
+		base = "user" + base
+	}
+
+	if len(base) > 24 {
+		base = base[:24]
+	}
+
+	username := base
+
+	for attempt := 0; attempt < usernameAttempts; attempt++ {
+		user, err := s.repository.InsertUser(ctx, &domain.User{
+			Username:     username,
+			Email:        claims.Email,
+			RegisteredAt: time.Now().UTC(),
+			Identities:   []domain.Identity{identity},
+		})
+		if err == nil {
+			return user, nil
+		}
+
+		if !mongo.IsDuplicateKeyError(err) || !strings.Contains(err.Error(), "username") {
+			slog.ErrorContext(ctx, "createOIDCUser", "error", err)
+			return nil, ErrDBFailure
+		}
+
+		suffix, err := randomHex(3)
+		if err != nil {
+			slog.ErrorContext(ctx, "createOIDCUser", "error", err)
+			return nil, ErrDBFailure
+		}
+
+		username = base + suffix
+	}
+
+	return nil, ErrUsernameDuplicate
+}
+
+func (s *userService) parseOIDCState(stateToken string) (map[string]string, error) {
+	token, err := s.keys.Parse(stateToken, jwt.MapClaims{})
+	if err != nil {
+		return nil, ErrInvalidOIDCState
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["purpose"] != oidcStatePurpose {
+		return nil, ErrInvalidOIDCState
+	}
+
+	state := make(map[string]string)
+	for _, key := range []string{"provider", "state", "nonce", "verifier"} {
+		value, _ := claims[key].(string)
+		state[key] = value
+	}
+
+	return state, nil
+}
+
+func (s *userService) oidcRedirectURI(provider string) string {
+	return strings.TrimSuffix(s.publicURL, "/") + fmt.Sprintf(oidcCallbackPath, provider)
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/oidc"
+	"time-capsule/internal/oidc/oidctest"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserService_StartOIDCSignIn(t *testing.T) {
+	server := oidctest.NewServer()
+	defer server.Close()
+
+	var (
+		provider = oidc.NewProvider(server.Config("mock"), http.DefaultClient)
+		svc      = NewUserService(nil, nil, nil, nil, nil, nil, newTestKeys(t), []*oidc.Provider{provider},
+			"https://time-capsule.example.com/", testTokenTTL, bcrypt.MinCost).(*userService)
+	)
+
+	_, err := svc.StartOIDCSignIn(context.Background(), "other")
+	assert.Equal(t, ErrUnknownProvider, err)
+
+	authorization, err := svc.StartOIDCSignIn(context.Background(), "mock")
+	require.NoError(t, err)
+
+	authURL, err := url.Parse(authorization.URL)
+	require.NoError(t, err)
+
+	state, err := svc.parseOIDCState(authorization.StateToken)
+	require.NoError(t, err)
+
+	query := authURL.Query()
+	assert.Equal(t, "https://time-capsule.example.com/api/v1/oidc/mock/callback", query.Get("redirect_uri"))
+	assert.Equal(t, state["state"], query.Get("state"))
+	assert.Equal(t, state["nonce"], query.Get("nonce"))
+	assert.Equal(t, oidc.Challenge(state["verifier"]), query.Get("code_challenge"))
+	assert.Equal(t, "mock", state["provider"])
+}
+
+func TestUserService_CompleteOIDCSignIn(t *testing.T) {
+	type mocks struct {
+		users   *mock_repository.MockUserRepository
+		signIns *mock_repository.MockSignInFailureRepository
+		audit   *mock_repository.MockAuditRepository
+	}
+
+	server := oidctest.NewServer()
+	defer server.Close()
+
+	var (
+		userID  = primitive.NewObjectID()
+		jwtKeys = newTestKeys(t)
+
+		identityFilter = bson.M{
+			"identities": bson.M{
+				"$elemMatch": bson.M{"provider": "mock", "subject": "some-subject"},
+			},
+		}
+		emailFilter = bson.M{
+			"email": primitive.Regex{Pattern: `^Foo@Example\.com$`, Options: "i"},
+		}
+		user = &domain.User{
+			ID:       userID,
+			Username: "foo",
+			Email:    "foo@example.com",
+		}
+	)
+
+	expectSignIn := func(m mocks) {
+		m.signIns.EXPECT().DeleteSignInFailures(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+		m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+				assert.Equal(t, domain.AuditSignIn, event.Action)
+				assert.Equal(t, "mock", event.Details["provider"])
+				return event, nil
+			}).Times(1)
+	}
+
+	tests := []struct {
+		name          string
+		mockBehavior  func(m mocks)
+		emailVerified bool
+		callback      func(callback domain.OIDCCallback) domain.OIDCCallback
+		expectedError error
+	}{
+		{
+			name: "OK-Existing-Identity",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), identityFilter).Return(user, nil).Times(1)
+				expectSignIn(m)
+			},
+			emailVerified: true,
+		},
+		{
+			name: "OK-Link-By-Email",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), identityFilter).Return(nil, mongo.ErrNoDocuments).Times(1)
+				m.users.EXPECT().GetUser(gomock.Any(), emailFilter).Return(user, nil).Times(1)
+				m.users.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).Return(nil).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditIdentityLinked, event.Action)
+						return event, nil
+					}).Times(1)
+				expectSignIn(m)
+			},
+			emailVerified: true,
+		},
+		{
+			name: "OK-Create",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), identityFilter).Return(nil, mongo.ErrNoDocuments).Times(1)
+				m.users.EXPECT().GetUser(gomock.Any(), emailFilter).Return(nil, mongo.ErrNoDocuments).Times(1)
+				m.users.EXPECT().InsertUser(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, user *domain.User) (*domain.User, error) {
+						assert.Equal(t, "Foo", user.Username)
+						assert.Equal(t, "Foo@Example.com", user.Email)
+						assert.Empty(t, user.PasswordHash)
+						assert.Equal(t, "some-subject", user.Identities[0].Subject)
+
+						user.ID = userID
+						return user, nil
+					}).Times(1)
+				expectSignIn(m)
+			},
+			emailVerified: true,
+		},
+		{
+			name: "Unverified-Email",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), identityFilter).Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			emailVerified: false,
+			expectedError: ErrEmailNotVerified,
+		},
+		{
+			name:          "Wrong-State",
+			mockBehavior:  func(m mocks) {},
+			emailVerified: true,
+			callback: func(callback domain.OIDCCallback) domain.OIDCCallback {
+				callback.State = "other-state"
+				return callback
+			},
+			expectedError: ErrInvalidOIDCState,
+		},
+		{
+			name:          "Missing-State-Token",
+			mockBehavior:  func(m mocks) {},
+			emailVerified: true,
+			callback: func(callback domain.OIDCCallback) domain.OIDCCallback {
+				callback.StateToken = ""
+				return callback
+			},
+			expectedError: ErrInvalidOIDCState,
+		},
+		{
+			name:          "Denied",
+			mockBehavior:  func(m mocks) {},
+			emailVerified: true,
+			callback: func(callback domain.OIDCCallback) domain.OIDCCallback {
+				return domain.OIDCCallback{Error: "access_denied"}
+			},
+			expectedError: ErrOIDCDenied,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), identityFilter).Return(nil, errors.New("some error")).Times(1)
+			},
+			emailVerified: true,
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				m = mocks{
+					users:   mock_repository.NewMockUserRepository(c),
+					signIns: mock_repository.NewMockSignInFailureRepository(c),
+					audit:   mock_repository.NewMockAuditRepository(c),
+				}
+				provider = oidc.NewProvider(server.Config("mock"), http.DefaultClient)
+				svc      = NewUserService(m.users, nil, m.signIns, m.audit, nil, nil, jwtKeys, []*oidc.Provider{provider},
+					"https://time-capsule.example.com", testTokenTTL, bcrypt.MinCost)
+				ctx = context.Background()
+			)
+
+			authorization, err := svc.StartOIDCSignIn(ctx, "mock")
+			require.NoError(t, err)
+
+			redirect, err := server.Authorize(authorization.URL, oidctest.User{
+				Subject:       "some-subject",
+				Email:         "Foo@Example.com",
+				EmailVerified: test.emailVerified,
+			})
+			require.NoError(t, err)
+
+			callback := domain.OIDCCallback{
+				Code:       redirect.Query().Get("code"),
+				State:      redirect.Query().Get("state"),
+				StateToken: authorization.StateToken,
+			}
+			if test.callback != nil {
+				callback = test.callback(callback)
+			}
+
+			test.mockBehavior(m)
+
+			result, err := svc.CompleteOIDCSignIn(ctx, "mock", callback)
+			assert.Equal(t, test.expectedError, err)
+
+			if err == nil {
+				assert.NotEmpty(t, result.Token)
+			}
+		})
+	}
+}
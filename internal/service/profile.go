@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxDisplayNameLength = 50
+
+	verifyEmailPath            = "/api/v1/verify-email"
+	emailVerificationTokenSize = 32
+	emailVerificationTTL       = 24 * time.Hour
+)
+
+var (
+	ErrInvalidDisplayName       = fmt.Errorf("display name must be at most %d characters long", maxDisplayNameLength)
+	ErrInvalidTimezone          = errors.New("timezone must be an IANA time zone, e.g. \"Europe/Berlin\"")
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+)
+
+// GetProfile returns the user's own account.
+func (s *userService) GetProfile(ctx context.Context, userID primitive.ObjectID) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetProfile")
+	defer span.End()
+
+	return s.getUser(ctx, userID)
+}
+
+// UpdateProfile updates the user's profile. A new email isn't used until the user verifies it
+// with the link sent to it, the current one keeps working meanwhile.
+func (s *userService) UpdateProfile(ctx context.Context, userID primitive.ObjectID, input domain.UpdateProfileDTO) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateProfile")
+	defer span.End()
+
+	if reflect.DeepEqual(input, domain.UpdateProfileDTO{}) {
+		return nil, ErrEmptyUpdate
+	}
+
+	set := bson.M{}
+
+	if input.Username != "" {
+		if !usernameValidation(input.Username) {
+			return nil, ErrInvalidUsername
+		}
+
+		set["username"] = input.Username
+	}
+
+	if input.DisplayName != "" {
+		name := strings.TrimSpace(input.DisplayName)
+		if name == "" || utf8.RuneCountInString(name) > maxDisplayNameLength {
+			return nil, ErrInvalidDisplayName
+		}
+
+		set["displayName"] = name
+	}
+
+	if input.Timezone != "" {
+		if !timezoneValidation(input.Timezone) {
+			return nil, ErrInvalidTimezone
+		}
+
+		set["timezone"] = input.Timezone
+	}
+
+	if input.Language != "" {
+		if !languageValidation(input.Language) {
+			return nil, ErrInvalidLanguage
+		}
+
+		set["language"] = input.Language
+	}
+
+	if input.Email != "" && !emailValidation(input.Email) {
+		return nil, ErrInvalidEmail
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var token string
+
+	if input.Email != "" && input.Email != user.Email {
+		if _, err = s.repository.GetUser(ctx, bson.M{"email": input.Email}); err == nil {
+			return nil, ErrEmailDuplicate
+		} else if !errors.Is(err, mongo.ErrNoDocuments) {
+			slog.ErrorContext(ctx, "UpdateProfile", "error", err)
+			return nil, ErrDBFailure
+		}
+
+		if token, err = randomHex(emailVerificationTokenSize); err != nil {
+			slog.ErrorContext(ctx, "UpdateProfile", "error", err)
+			return nil, ErrTokenCreationFailed
+		}
+
+		set["pendingEmail"] = input.Email
+		set["emailVerificationHash"] = hashToken(token)
+		set["emailVerificationExpiresAt"] = time.Now().UTC().Add(emailVerificationTTL)
+	}
+
+	if len(set) > 0 {
+		if err = s.repository.UpdateUser(ctx, userID, bson.M{"$set": set}); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, ErrUsernameDuplicate
+			}
+
+			slog.ErrorContext(ctx, "UpdateProfile", "error", err)
+			return nil, ErrDBFailure
+		}
+	}
+
+	if user, err = s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		if err = s.sendEmailVerification(ctx, user, token); err != nil {
+			slog.ErrorContext(ctx, "UpdateProfile", "error", err)
+			return nil, ErrRenderFailure
+		}
+	}
+
+	return user, nil
+}
+
+// sendEmailVerification emails the link to verify the pending email to the pending email itself.
+func (s *userService) sendEmailVerification(ctx context.Context, user *domain.User, token string) error {
+	recipient := *user
+	recipient.Email = user.PendingEmail
+
+	return queueEmail(ctx, s.renderer, s.outboxRepository, &recipient,
+		fmt.Sprintf("%s:%s:%s", mail.TemplateVerifyEmail, user.ID.Hex(), user.EmailVerificationHash),
+		mail.TemplateVerifyEmail,
+		mail.VerifyEmailData{
+			Username:  user.Username,
+			Email:     user.PendingEmail,
+			VerifyURL: strings.TrimSuffix(s.publicURL, "/") + verifyEmailPath + "?token=" + token,
+			ExpiresAt: *user.EmailVerificationExpiresAt,
+		},
+	)
+}
+
+// VerifyEmail replaces the user's email with the pending one, with the token sent to it.
+func (s *userService) VerifyEmail(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "UserService.VerifyEmail")
+	defer span.End()
+
+	if token == "" {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := s.repository.GetUser(ctx, bson.M{
+		"emailVerificationHash":      hashToken(token),
+		"emailVerificationExpiresAt": bson.M{"$gt": time.Now().UTC()},
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrInvalidVerificationToken
+		}
+
+		slog.ErrorContext(ctx, "VerifyEmail", "error", err)
+		return ErrDBFailure
+	}
+
+	if err = s.repository.UpdateUser(ctx, user.ID, bson.M{
+		"$set": bson.M{
+			"email": user.PendingEmail,
+		},
+		"$unset": bson.M{
+			"pendingEmail":               "",
+			"emailVerificationHash":      "",
+			"emailVerificationExpiresAt": "",
+		},
+	}); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrEmailDuplicate
+		}
+
+		slog.ErrorContext(ctx, "VerifyEmail", "error", err)
+		return ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditEmailChanged,
+		UserID: user.ID,
+	})
+
+	return nil
+}
+
+func timezoneValidation(timezone string) bool {
+	if timezone == "Local" {
+		return false
+	}
+
+	_, err := time.LoadLocation(timezone)
+	return err == nil
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	type mocks struct {
+		users  *mock_repository.MockUserRepository
+		outbox *mock_repository.MockOutboxRepository
+	}
+
+	renderer, err := mail.NewRenderer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		userID       = primitive.NewObjectID()
+		expiresAt    = time.Now().UTC().Add(emailVerificationTTL)
+		duplicateErr = mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "username dup key"}}}
+	)
+
+	tests := []struct {
+		name          string
+		mockBehavior  func(m mocks)
+		input         domain.UpdateProfileDTO
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID, Username: "foo"}, nil).Times(1)
+				m.users.EXPECT().UpdateUser(gomock.Any(), userID, bson.M{
+					"$set": bson.M{
+						"displayName": "John Doe",
+						"timezone":    "Europe/Berlin",
+						"language":    "ru",
+					},
+				}).Return(nil).Times(1)
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID, Username: "foo", DisplayName: "John Doe"}, nil).Times(1)
+			},
+			input: domain.UpdateProfileDTO{
+				DisplayName: "  John Doe ",
+				Timezone:    "Europe/Berlin",
+				Language:    "ru",
+			},
+			expectedError: nil,
+		},
+		{
+			name: "OK-Email",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID, Username: "foo", Email: "foo@example.com"}, nil).Times(1)
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": "bar@example.com"}).
+					Return(nil, mongo.ErrNoDocuments).Times(1)
+				m.users.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ primitive.ObjectID, update bson.M) error {
+						set := update["$set"].(bson.M)
+						assert.Equal(t, "bar@example.com", set["pendingEmail"])
+						assert.NotEmpty(t, set["emailVerificationHash"])
+						return nil
+					}).Times(1)
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(&domain.User{
+					ID:                         userID,
+					Username:                   "foo",
+					Email:                      "foo@example.com",
+					PendingEmail:               "bar@example.com",
+					EmailVerificationHash:      "some-hash",
+					EmailVerificationExpiresAt: &expiresAt,
+				}, nil).Times(1)
+				m.outbox.EXPECT().InsertOutboxEntry(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, entry *domain.OutboxEntry) (*domain.OutboxEntry, error) {
+						assert.Equal(t, "bar@example.com", entry.Recipient)
+						assert.Equal(t, mail.TemplateVerifyEmail, entry.Template)
+						assert.Contains(t, entry.Text, "https://time-capsule.example.com/api/v1/verify-email?token=")
+						return entry, nil
+					}).Times(1)
+			},
+			input:         domain.UpdateProfileDTO{Email: "bar@example.com"},
+			expectedError: nil,
+		},
+		{
+			name: "Email-Duplicate",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID, Email: "foo@example.com"}, nil).Times(1)
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": "bar@example.com"}).
+					Return(&domain.User{ID: primitive.NewObjectID()}, nil).Times(1)
+			},
+			input:         domain.UpdateProfileDTO{Email: "bar@example.com"},
+			expectedError: ErrEmailDuplicate,
+		},
+		{
+			name: "Username-Duplicate",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).
+					Return(&domain.User{ID: userID, Username: "foo"}, nil).Times(1)
+				m.users.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).Return(duplicateErr).Times(1)
+			},
+			input:         domain.UpdateProfileDTO{Username: "bar"},
+			expectedError: ErrUsernameDuplicate,
+		},
+		{
+			name:          "Invalid-Timezone",
+			mockBehavior:  func(m mocks) {},
+			input:         domain.UpdateProfileDTO{Timezone: "Mars/Olympus"},
+			expectedError: ErrInvalidTimezone,
+		},
+		{
+			name:          "Invalid-Display-Name",
+			mockBehavior:  func(m mocks) {},
+			input:         domain.UpdateProfileDTO{DisplayName: "   "},
+			expectedError: ErrInvalidDisplayName,
+		},
+		{
+			name:          "Invalid-Email",
+			mockBehavior:  func(m mocks) {},
+			input:         domain.UpdateProfileDTO{Email: "foo"},
+			expectedError: ErrInvalidEmail,
+		},
+		{
+			name:          "Empty-Update",
+			mockBehavior:  func(m mocks) {},
+			input:         domain.UpdateProfileDTO{},
+			expectedError: ErrEmptyUpdate,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				m = mocks{
+					users:  mock_repository.NewMockUserRepository(c),
+					outbox: mock_repository.NewMockOutboxRepository(c),
+				}
+				svc = NewUserService(m.users, nil, nil, nil, m.outbox, renderer, nil, nil, "https://time-capsule.example.com/", testTokenTTL, bcrypt.MinCost)
+			)
+
+			test.mockBehavior(m)
+
+			_, err := svc.UpdateProfile(context.Background(), userID, test.input)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestUserService_VerifyEmail(t *testing.T) {
+	type mocks struct {
+		users *mock_repository.MockUserRepository
+		audit *mock_repository.MockAuditRepository
+	}
+
+	var (
+		userID = primitive.NewObjectID()
+		token  = "some-token"
+	)
+
+	tests := []struct {
+		name          string
+		mockBehavior  func(m mocks)
+		token         string
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, filter bson.M) (*domain.User, error) {
+						assert.Equal(t, hashToken(token), filter["emailVerificationHash"])
+						return &domain.User{ID: userID, PendingEmail: "bar@example.com"}, nil
+					}).Times(1)
+				m.users.EXPECT().UpdateUser(gomock.Any(), userID, bson.M{
+					"$set": bson.M{
+						"email": "bar@example.com",
+					},
+					"$unset": bson.M{
+						"pendingEmail":               "",
+						"emailVerificationHash":      "",
+						"emailVerificationExpiresAt": "",
+					},
+				}).Return(nil).Times(1)
+				m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+						assert.Equal(t, domain.AuditEmailChanged, event.Action)
+						return event, nil
+					}).Times(1)
+			},
+			token:         token,
+			expectedError: nil,
+		},
+		{
+			name: "Invalid-Token",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), gomock.Any()).Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			token:         token,
+			expectedError: ErrInvalidVerificationToken,
+		},
+		{
+			name:          "Empty-Token",
+			mockBehavior:  func(m mocks) {},
+			expectedError: ErrInvalidVerificationToken,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(m mocks) {
+				m.users.EXPECT().GetUser(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error")).Times(1)
+			},
+			token:         token,
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				m = mocks{
+					users: mock_repository.NewMockUserRepository(c),
+					audit: mock_repository.NewMockAuditRepository(c),
+				}
+				svc = NewUserService(m.users, nil, nil, m.audit, nil, nil, nil, nil, "", testTokenTTL, bcrypt.MinCost)
+			)
+
+			test.mockBehavior(m)
+
+			err := svc.VerifyEmail(context.Background(), test.token)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
@@ -3,8 +3,17 @@ package service
 import (
 	"context"
 	"errors"
+	"net/http"
+	"time"
 
+	"time-capsule/config"
 	"time-capsule/internal/domain"
+	"time-capsule/internal/events"
+	"time-capsule/internal/export"
+	"time-capsule/internal/jwks"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/oidc"
+	"time-capsule/internal/ratelimit"
 	"time-capsule/internal/repository"
 	"time-capsule/internal/storage"
 
@@ -12,6 +21,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// oidcTimeout bounds the requests to identity providers.
+const oidcTimeout = 10 * time.Second
+
 var (
 	ErrDBFailure = errors.New("something went wrong... try again later :(")
 	ErrNotFound  = errors.New("not found")
@@ -20,27 +32,151 @@ var (
 type Service struct {
 	UserService
 	CapsuleService
+	CollectionService
+	MailService
+	NotificationService
+	AccessTokenService
+	AccountService
+	ExportService
+	ImportService
+	AdminService
+	AuditService
 }
 
-func NewService(repository *repository.Repository, storage storage.Storage) *Service {
+func NewService(cfg *config.Config, repository *repository.Repository, storage storage.Storage,
+	renderer mail.Renderer, broker events.Broker, keys *jwks.KeySet, limiter *ratelimit.Limiter) *Service {
+	providers := make([]*oidc.Provider, 0, len(cfg.OIDCProviders))
+	for _, provider := range cfg.OIDCProviders {
+		providers = append(providers, oidc.NewProvider(provider, &http.Client{Timeout: oidcTimeout}))
+	}
+
+	rules := NewCapsuleRules(cfg)
+
+	capsuleService := NewCapsuleService(repository.CapsuleRepository, repository.OutboxRepository, repository.AuditRepository,
+		storage, rules)
+
 	return &Service{
-		UserService:    NewUserService(repository.UserRepository),
-		CapsuleService: NewCapsuleService(repository.CapsuleRepository, storage),
+		UserService: NewUserService(repository.UserRepository, repository.CapsuleRepository,
+			repository.SignInFailureRepository, repository.AuditRepository, repository.OutboxRepository, renderer, keys, providers, cfg.PublicURL,
+			cfg.TokenTTL, cfg.BcryptCost),
+		CapsuleService:      capsuleService,
+		CollectionService:   NewCollectionService(repository.CollectionRepository, repository.CapsuleRepository),
+		MailService:         NewMailService(renderer, repository.OutboxRepository, repository.AuditRepository),
+		NotificationService: NewNotificationService(repository.NotificationRepository, broker),
+		AccessTokenService:  NewAccessTokenService(repository.AccessTokenRepository, repository.AuditRepository),
+		AccountService: NewAccountService(repository.UserRepository, repository.CapsuleRepository,
+			repository.CollectionRepository, repository.AccessTokenRepository, repository.ExportRepository,
+			repository.OutboxRepository, repository.NotificationRepository, repository.SignInFailureRepository,
+			repository.AuditRepository, capsuleService, storage, limiter),
+		ExportService: NewExportService(repository.ExportRepository, repository.UserRepository, repository.CapsuleRepository,
+			repository.CollectionRepository, repository.NotificationRepository, repository.AuditRepository, storage, broker,
+			cfg.PublicURL, cfg.ExportSealedCapsules),
+		ImportService: NewImportService(repository.CapsuleRepository, repository.CollectionRepository,
+			repository.AuditRepository, storage, rules),
+		AdminService: NewAdminService(repository.UserRepository, repository.CapsuleRepository, repository.OutboxRepository,
+			repository.ExportRepository, repository.AuditRepository),
+		AuditService: NewAuditService(repository.AuditRepository),
 	}
 }
 
 type UserService interface {
 	CreateUser(ctx context.Context, input domain.CreateUserDTO) (*domain.User, error)
-	GenerateToken(ctx context.Context, email, password string) (string, error)
+	GenerateToken(ctx context.Context, email, password string) (*domain.SignInResult, error)
+	VerifyMFA(ctx context.Context, input domain.MFASignInDTO) (string, error)
 	ParseToken(accessToken string) (jwt.MapClaims, error)
+	AuthenticateUser(ctx context.Context, userID primitive.ObjectID) (*domain.User, error)
+	JWKS() jwks.Set
+	GetProfile(ctx context.Context, userID primitive.ObjectID) (*domain.User, error)
+	UpdateProfile(ctx context.Context, userID primitive.ObjectID, input domain.UpdateProfileDTO) (*domain.User, error)
+	VerifyEmail(ctx context.Context, token string) error
+	StartOIDCSignIn(ctx context.Context, provider string) (*domain.OIDCAuthorization, error)
+	CompleteOIDCSignIn(ctx context.Context, provider string, input domain.OIDCCallback) (*domain.SignInResult, error)
+	CheckIn(ctx context.Context, userID primitive.ObjectID) error
+	RecordActivity(ctx context.Context, userID primitive.ObjectID) error
+	UnlockAccount(ctx context.Context, token string) error
+	EnrollTOTP(ctx context.Context, userID primitive.ObjectID) (*domain.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO) (*domain.RecoveryCodes, error)
+	DisableTOTP(ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO) error
+	UpdateReminders(ctx context.Context, userID primitive.ObjectID, input domain.UpdateRemindersDTO) error
 }
 
 type CapsuleService interface {
 	CreateCapsule(ctx context.Context, userID primitive.ObjectID, capsule domain.CreateCapsuleDTO) (*domain.Capsule, error)
-	GetAllCapsules(ctx context.Context, userID primitive.ObjectID) ([]*domain.Capsule, error)
+	GetAllCapsules(ctx context.Context, userID primitive.ObjectID, filter domain.CapsuleFilter) ([]*domain.Capsule, error)
 	GetCapsuleByID(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) (*domain.Capsule, error)
 	UpdateCapsule(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCapsuleDTO) error
 	DeleteCapsule(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) error
 	AddImage(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) error
 	RemoveImage(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, image string) error
+	SearchCapsules(ctx context.Context, userID primitive.ObjectID, query string) ([]*domain.CapsuleSearchResult, error)
+	AddTags(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, tags []string) error
+	RemoveTag(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, tag string) error
+	GetTags(ctx context.Context, userID primitive.ObjectID) ([]*domain.TagCount, error)
+	RenameTag(ctx context.Context, userID primitive.ObjectID, tag, name string) error
+	MergeTags(ctx context.Context, userID primitive.ObjectID, tags []string, into string) error
+}
+
+type CollectionService interface {
+	CreateCollection(ctx context.Context, userID primitive.ObjectID, input domain.CreateCollectionDTO) (*domain.Collection, error)
+	GetAllCollections(ctx context.Context, userID primitive.ObjectID) ([]*domain.Collection, error)
+	GetCollectionByID(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) (*domain.Collection, error)
+	UpdateCollection(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, update domain.UpdateCollectionDTO) error
+	DeleteCollection(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) error
+	AddCapsule(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, capsuleID primitive.ObjectID) error
+	RemoveCapsule(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, capsuleID primitive.ObjectID) error
+}
+
+type MailService interface {
+	PreviewEmail(name, lang string) (*mail.Message, error)
+	GetOutbox(ctx context.Context, status string) ([]*domain.OutboxEntry, error)
+	ReplayOutboxEntry(ctx context.Context, id primitive.ObjectID) error
+}
+
+type NotificationService interface {
+	GetNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) (*domain.NotificationList, error)
+	MarkNotificationsRead(ctx context.Context, userID primitive.ObjectID, input domain.MarkNotificationsReadDTO) error
+	Subscribe(userID primitive.ObjectID) (<-chan events.Event, func())
+}
+
+type AccessTokenService interface {
+	CreateAccessToken(ctx context.Context, userID primitive.ObjectID, input domain.CreateAccessTokenDTO) (*domain.AccessToken, error)
+	GetAccessTokens(ctx context.Context, userID primitive.ObjectID) ([]*domain.AccessToken, error)
+	RevokeAccessToken(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) error
+	AuthenticateAccessToken(ctx context.Context, token string) (*domain.AccessToken, error)
+}
+
+type AccountService interface {
+	DeleteAccount(ctx context.Context, userID primitive.ObjectID, input domain.DeleteAccountDTO) (*domain.AccountDeletion, error)
+	RestoreAccount(ctx context.Context, userID primitive.ObjectID) error
+	PurgeAccounts(ctx context.Context, now time.Time) error
+}
+
+type ExportService interface {
+	RequestExport(ctx context.Context, userID primitive.ObjectID) (*domain.Export, error)
+	GetExports(ctx context.Context, userID primitive.ObjectID) ([]*domain.Export, error)
+	DownloadExport(ctx context.Context, token string) (*domain.File, error)
+	BuildExports(ctx context.Context, now time.Time) error
+	ExpireExports(ctx context.Context, now time.Time) error
+}
+
+type ImportService interface {
+	ImportCapsules(ctx context.Context, userID primitive.ObjectID, archive *export.Archive) (*domain.ImportReport, error)
+}
+
+type AdminService interface {
+	SearchUsers(ctx context.Context, query string) ([]*domain.User, error)
+	GetUser(ctx context.Context, id primitive.ObjectID) (*domain.User, error)
+	GetUserCapsules(ctx context.Context, userID primitive.ObjectID) ([]*domain.CapsuleMetadata, error)
+	GetCapsuleMetadata(ctx context.Context, id primitive.ObjectID) (*domain.CapsuleMetadata, error)
+	ResendNotifications(ctx context.Context, id primitive.ObjectID) error
+	DisableUser(ctx context.Context, staffID, userID primitive.ObjectID) error
+	EnableUser(ctx context.Context, staffID, userID primitive.ObjectID) error
+	UpdateRole(ctx context.Context, staffID, userID primitive.ObjectID, input domain.UpdateRoleDTO) error
+	RecordWorkerCycle(startedAt, finishedAt time.Time)
+	GetWorkerStatus(ctx context.Context) (*domain.WorkerStatus, error)
+}
+
+type AuditService interface {
+	GetUserAuditEvents(ctx context.Context, userID primitive.ObjectID, filter domain.AuditFilter) ([]*domain.AuditEvent, error)
+	GetAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error)
 }
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxTagsPerCapsule = 20
+
+	tagRegex = `^[\p{L}\p{N} _-]{1,32}$`
+)
+
+var (
+	ErrInvalidTag  = errors.New("tags must be between 1 and 32 characters long and can only contain letters, digits, spaces, hyphens and underscores")
+	ErrTooManyTags = fmt.Errorf("a capsule cannot have more than %d tags", maxTagsPerCapsule)
+)
+
+func (s *capsuleService) AddTags(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, tags []string) error {
+	ctx, span := tracing.Start(ctx, "CapsuleService.AddTags")
+	defer span.End()
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return ErrEmptyUpdate
+	}
+
+	capsule, err := s.GetCapsuleByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	merged, _ := normalizeTags(append(capsule.Tags, tags...))
+	if len(merged) > maxTagsPerCapsule {
+		return ErrTooManyTags
+	}
+
+	if err = s.repository.UpdateCapsule(ctx, id, bson.M{
+		"$addToSet": bson.M{
+			"tags": bson.M{"$each": tags},
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "AddTags", "error", err)
+		return ErrDBFailure
+	}
+
+	return nil
+}
+
+func (s *capsuleService) RemoveTag(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID, tag string) error {
+	ctx, span := tracing.Start(ctx, "CapsuleService.RemoveTag")
+	defer span.End()
+
+	tag, ok := normalizeTag(tag)
+	if !ok {
+		return ErrInvalidTag
+	}
+
+	if _, err := s.GetCapsuleByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.repository.UpdateCapsule(ctx, id, bson.M{
+		"$pull": bson.M{
+			"tags": tag,
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "RemoveTag", "error", err)
+		return ErrDBFailure
+	}
+
+	return nil
+}
+
+func (s *capsuleService) GetTags(ctx context.Context, userID primitive.ObjectID) ([]*domain.TagCount, error) {
+	ctx, span := tracing.Start(ctx, "CapsuleService.GetTags")
+	defer span.End()
+
+	tags, err := s.repository.CountTags(ctx, bson.M{"userID": userID})
+	if err != nil {
+		slog.ErrorContext(ctx, "GetTags", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return tags, nil
+}
+
+// RenameTag renames the tag on every capsule of the user.
+// Renaming to a tag that is already in use merges the two.
+func (s *capsuleService) RenameTag(ctx context.Context, userID primitive.ObjectID, tag, name string) error {
+	ctx, span := tracing.Start(ctx, "CapsuleService.RenameTag")
+	defer span.End()
+
+	return s.MergeTags(ctx, userID, []string{tag}, name)
+}
+
+// MergeTags replaces each of the given tags with into on every capsule of the user.
+func (s *capsuleService) MergeTags(ctx context.Context, userID primitive.ObjectID, tags []string, into string) error {
+	ctx, span := tracing.Start(ctx, "CapsuleService.MergeTags")
+	defer span.End()
+
+	into, ok := normalizeTag(into)
+	if !ok {
+		return ErrInvalidTag
+	}
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	sources := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag != into {
+			sources = append(sources, tag)
+		}
+	}
+
+	if len(sources) == 0 {
+		return ErrEmptyUpdate
+	}
+
+	filter := bson.M{
+		"userID": userID,
+		"tags":   bson.M{"$in": sources},
+	}
+
+	matched, err := s.repository.UpdateCapsules(ctx, filter, bson.M{
+		"$addToSet": bson.M{
+			"tags": into,
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "MergeTags", "error", err)
+		return ErrDBFailure
+	}
+
+	if matched == 0 {
+		return ErrNotFound
+	}
+
+	if _, err = s.repository.UpdateCapsules(ctx, filter, bson.M{
+		"$pull": bson.M{
+			"tags": bson.M{"$in": sources},
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "MergeTags", "error", err)
+		return ErrDBFailure
+	}
+
+	return nil
+}
+
+// normalizeTags validates and lower-cases the tags, dropping duplicates.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	var (
+		res  = make([]string, 0, len(tags))
+		seen = make(map[string]struct{}, len(tags))
+	)
+
+	for _, tag := range tags {
+		tag, ok := normalizeTag(tag)
+		if !ok {
+			return nil, ErrInvalidTag
+		}
+
+		if _, ok = seen[tag]; ok {
+			continue
+		}
+
+		seen[tag] = struct{}{}
+		res = append(res, tag)
+	}
+
+	return res, nil
+}
+
+func normalizeTag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+
+	res, _ := regexp.MatchString(tagRegex, tag)
+	return tag, res
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"time-capsule/internal/domain"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCapsuleService_AddTags(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockCapsuleRepository, ctx context.Context,
+		userID primitive.ObjectID, id primitive.ObjectID)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+		userID        primitive.ObjectID
+		capsuleID     primitive.ObjectID
+		tags          []string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
+					"_id": id,
+				}).Return(&domain.Capsule{UserID: userID, Tags: []string{"kids"}}, nil).Times(1)
+
+				r.EXPECT().UpdateCapsule(gomock.Any(), id, bson.M{
+					"$addToSet": bson.M{
+						"tags": bson.M{"$each": []string{"kids", "work anniversaries"}},
+					},
+				}).Return(nil).Times(1)
+			},
+			expectedError: nil,
+			userID:        primitive.NewObjectID(),
+			capsuleID:     primitive.NewObjectID(),
+			tags:          []string{"Kids", " Work anniversaries ", "kids"},
+		},
+		{
+			name: "Invalid-Tag",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
+			},
+			expectedError: ErrInvalidTag,
+			userID:        primitive.NewObjectID(),
+			capsuleID:     primitive.NewObjectID(),
+			tags:          []string{"<script>"},
+		},
+		{
+			name: "Empty-Tags",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
+			},
+			expectedError: ErrEmptyUpdate,
+			userID:        primitive.NewObjectID(),
+			capsuleID:     primitive.NewObjectID(),
+			tags:          []string{},
+		},
+		{
+			name: "Too-Many-Tags",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
+				tags := make([]string, maxTagsPerCapsule)
+				for i := range tags {
+					tags[i] = primitive.NewObjectID().Hex()
+				}
+
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
+					"_id": id,
+				}).Return(&domain.Capsule{UserID: userID, Tags: tags}, nil).Times(1)
+			},
+			expectedError: ErrTooManyTags,
+			userID:        primitive.NewObjectID(),
+			capsuleID:     primitive.NewObjectID(),
+			tags:          []string{"kids"},
+		},
+		{
+			name: "Forbidden",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
+					"_id": id,
+				}).Return(&domain.Capsule{UserID: primitive.NewObjectID()}, nil).Times(1)
+			},
+			expectedError: ErrForbidden,
+			userID:        primitive.NewObjectID(),
+			capsuleID:     primitive.NewObjectID(),
+			tags:          []string{"kids"},
+		},
+		{
+			name: "Updating-DB-Failure",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) {
+				r.EXPECT().GetCapsule(gomock.Any(), bson.M{
+					"_id": id,
+				}).Return(&domain.Capsule{UserID: userID}, nil).Times(1)
+
+				r.EXPECT().UpdateCapsule(gomock.Any(), id, gomock.Any()).Return(errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+			userID:        primitive.NewObjectID(),
+			capsuleID:     primitive.NewObjectID(),
+			tags:          []string{"kids"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockCapsuleRepository(c)
+				svc    = NewCapsuleService(rpstry, nil, nil, nil, testCapsuleRules)
+				ctx    = context.Background()
+			)
+
+			test.mockBehavior(rpstry, ctx, test.userID, test.capsuleID)
+
+			err := svc.AddTags(ctx, test.userID, test.capsuleID, test.tags)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestCapsuleService_MergeTags(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockCapsuleRepository, ctx context.Context,
+		userID primitive.ObjectID)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+		userID        primitive.ObjectID
+		tags          []string
+		into          string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
+				filter := bson.M{
+					"userID": userID,
+					"tags":   bson.M{"$in": []string{"children"}},
+				}
+
+				r.EXPECT().UpdateCapsules(gomock.Any(), filter, bson.M{
+					"$addToSet": bson.M{
+						"tags": "kids",
+					},
+				}).Return(int64(2), nil).Times(1)
+
+				r.EXPECT().UpdateCapsules(gomock.Any(), filter, bson.M{
+					"$pull": bson.M{
+						"tags": bson.M{"$in": []string{"children"}},
+					},
+				}).Return(int64(2), nil).Times(1)
+			},
+			expectedError: nil,
+			userID:        primitive.NewObjectID(),
+			tags:          []string{"Children", "kids"},
+			into:          "kids",
+		},
+		{
+			name:          "Invalid-Target",
+			mockBehavior:  func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {},
+			expectedError: ErrInvalidTag,
+			userID:        primitive.NewObjectID(),
+			tags:          []string{"children"},
+			into:          "",
+		},
+		{
+			name:          "Nothing-To-Merge",
+			mockBehavior:  func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {},
+			expectedError: ErrEmptyUpdate,
+			userID:        primitive.NewObjectID(),
+			tags:          []string{"kids"},
+			into:          "Kids",
+		},
+		{
+			name: "Tag-Not-Found",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().UpdateCapsules(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+			},
+			expectedError: ErrNotFound,
+			userID:        primitive.NewObjectID(),
+			tags:          []string{"children"},
+			into:          "kids",
+		},
+		{
+			name: "Updating-DB-Failure",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().UpdateCapsules(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+				r.EXPECT().UpdateCapsules(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+			userID:        primitive.NewObjectID(),
+			tags:          []string{"children"},
+			into:          "kids",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockCapsuleRepository(c)
+				svc    = NewCapsuleService(rpstry, nil, nil, nil, testCapsuleRules)
+				ctx    = context.Background()
+			)
+
+			test.mockBehavior(rpstry, ctx, test.userID)
+
+			err := svc.MergeTags(ctx, test.userID, test.tags, test.into)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestCapsuleService_GetTags(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockCapsuleRepository, ctx context.Context,
+		userID primitive.ObjectID)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+		userID        primitive.ObjectID
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().CountTags(gomock.Any(), bson.M{"userID": userID}).
+					Return([]*domain.TagCount{{Name: "kids", Count: 3}}, nil).Times(1)
+			},
+			expectedError: nil,
+			userID:        primitive.NewObjectID(),
+		},
+		{
+			name: "Retrieving-DB-Failure",
+			mockBehavior: func(r *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().CountTags(gomock.Any(), bson.M{"userID": userID}).
+					Return(nil, errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+			userID:        primitive.NewObjectID(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockCapsuleRepository(c)
+				svc    = NewCapsuleService(rpstry, nil, nil, nil, testCapsuleRules)
+				ctx    = context.Background()
+			)
+
+			test.mockBehavior(rpstry, ctx, test.userID)
+
+			_, err := svc.GetTags(ctx, test.userID)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// AccessTokenPrefix starts every personal access token, which tells them apart from JWTs.
+	AccessTokenPrefix = "tc_pat_"
+
+	accessTokenSize          = 32
+	accessTokenDisplayLength = len(AccessTokenPrefix) + 6
+	maxAccessTokenNameLength = 64
+	maxAccessTokens          = 50
+
+	// lastUsedResolution limits how often using a token writes its last-used timestamp.
+	lastUsedResolution = time.Minute
+)
+
+var (
+	ErrInvalidTokenName    = errors.New("token name must be between 1 and 64 characters long")
+	ErrInvalidScopes       = errors.New("scopes must be some of " + strings.Join(domain.Scopes, ", "))
+	ErrInvalidTokenExpiry  = errors.New("token expiry must be in the future")
+	ErrTooManyAccessTokens = errors.New("too many access tokens, revoke some first")
+)
+
+type accessTokenService struct {
+	repository repository.AccessTokenRepository
+	audit      *auditLog
+}
+
+func NewAccessTokenService(repository repository.AccessTokenRepository, auditRepository repository.AuditRepository) AccessTokenService {
+	return &accessTokenService{
+		repository: repository,
+		audit:      &auditLog{repository: auditRepository},
+	}
+}
+
+// CreateAccessToken creates a personal access token. The returned token is the only time it's visible,
+// only its hash is stored.
+func (s *accessTokenService) CreateAccessToken(ctx context.Context, userID primitive.ObjectID, input domain.CreateAccessTokenDTO) (*domain.AccessToken, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxAccessTokenNameLength {
+		return nil, ErrInvalidTokenName
+	}
+
+	scopes, ok := normalizeScopes(input.Scopes)
+	if !ok {
+		return nil, ErrInvalidScopes
+	}
+
+	now := time.Now().UTC()
+
+	var expiresAt *time.Time
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(now) {
+			return nil, ErrInvalidTokenExpiry
+		}
+
+		expiry := input.ExpiresAt.UTC()
+		expiresAt = &expiry
+	}
+
+	tokens, err := s.repository.GetAccessTokens(ctx, bson.M{"userID": userID})
+	if err != nil {
+		slog.ErrorContext(ctx, "CreateAccessToken", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if len(tokens) >= maxAccessTokens {
+		return nil, ErrTooManyAccessTokens
+	}
+
+	secret, err := randomHex(accessTokenSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "CreateAccessToken", "error", err)
+		return nil, ErrTokenCreationFailed
+	}
+
+	token := AccessTokenPrefix + secret
+
+	res, err := s.repository.InsertAccessToken(ctx, &domain.AccessToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    token[:accessTokenDisplayLength],
+		Hash:      hashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "CreateAccessToken", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	res.Token = token
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditAccessTokenCreated,
+		UserID: userID,
+		Details: map[string]string{
+			"tokenID": res.ID.Hex(),
+			"scopes":  strings.Join(scopes, " "),
+		},
+	})
+
+	return res, nil
+}
+
+func (s *accessTokenService) GetAccessTokens(ctx context.Context, userID primitive.ObjectID) ([]*domain.AccessToken, error) {
+	tokens, err := s.repository.GetAccessTokens(ctx, bson.M{"userID": userID})
+	if err != nil {
+		slog.ErrorContext(ctx, "GetAccessTokens", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if tokens == nil {
+		tokens = []*domain.AccessToken{}
+	}
+
+	return tokens, nil
+}
+
+func (s *accessTokenService) RevokeAccessToken(ctx context.Context, userID primitive.ObjectID, id primitive.ObjectID) error {
+	deleted, err := s.repository.DeleteAccessToken(ctx, bson.M{
+		"_id":    id,
+		"userID": userID,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "RevokeAccessToken", "error", err)
+		return ErrDBFailure
+	}
+
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action:  domain.AuditAccessTokenRevoked,
+		UserID:  userID,
+		Details: map[string]string{"tokenID": id.Hex()},
+	})
+
+	return nil
+}
+
+// AuthenticateAccessToken returns the personal access token, if it's valid, and records that it was used.
+func (s *accessTokenService) AuthenticateAccessToken(ctx context.Context, token string) (*domain.AccessToken, error) {
+	if !strings.HasPrefix(token, AccessTokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	res, err := s.repository.GetAccessToken(ctx, bson.M{"hash": hashToken(token)})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidToken
+		}
+
+		slog.ErrorContext(ctx, "AuthenticateAccessToken", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	now := time.Now().UTC()
+
+	if res.ExpiresAt != nil && !res.ExpiresAt.After(now) {
+		return nil, ErrTokenExpired
+	}
+
+	if res.LastUsedAt == nil || now.Sub(*res.LastUsedAt) >= lastUsedResolution {
+		if err = s.repository.UpdateAccessToken(ctx, res.ID, bson.M{
+			"$set": bson.M{
+				"lastUsedAt": now,
+			},
+		}); err != nil {
+			slog.ErrorContext(ctx, "AuthenticateAccessToken", "error", err)
+		}
+
+		res.LastUsedAt = &now
+	}
+
+	return res, nil
+}
+
+// normalizeScopes deduplicates the scopes, which must be known and at least one.
+func normalizeScopes(scopes []string) ([]string, bool) {
+	if len(scopes) == 0 {
+		return nil, false
+	}
+
+	var normalized []string
+	for _, scope := range scopes {
+		if !slices.Contains(domain.Scopes, scope) {
+			return nil, false
+		}
+
+		if !slices.Contains(normalized, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+
+	return normalized, true
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAccessTokenService_CreateAccessToken(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+		ctx context.Context, userID primitive.ObjectID)
+
+	wayBack := time.Unix(0, 0)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return wayBack })
+	defer patches.Reset()
+
+	var (
+		userID = primitive.NewObjectID()
+		future = wayBack.Add(time.Hour)
+	)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		input         domain.CreateAccessTokenDTO
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().GetAccessTokens(ctx, bson.M{"userID": userID}).Return(nil, nil).Times(1)
+				r.EXPECT().InsertAccessToken(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, token *domain.AccessToken) (*domain.AccessToken, error) {
+						assert.Equal(t, "backup", token.Name)
+						assert.Equal(t, []string{domain.ScopeCapsulesRead}, token.Scopes)
+						assert.Equal(t, future.UTC(), *token.ExpiresAt)
+						assert.Len(t, token.Hash, 64)
+						assert.True(t, strings.HasPrefix(token.Prefix, AccessTokenPrefix))
+						assert.Empty(t, token.Token)
+						return token, nil
+					}).Times(1)
+				a.EXPECT().InsertAuditEvent(ctx, gomock.Any()).Return(nil, nil).Times(1)
+			},
+			input: domain.CreateAccessTokenDTO{
+				Name:      " backup ",
+				Scopes:    []string{domain.ScopeCapsulesRead, domain.ScopeCapsulesRead},
+				ExpiresAt: &future,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Invalid-Name",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, userID primitive.ObjectID) {
+			},
+			input: domain.CreateAccessTokenDTO{
+				Name:   " ",
+				Scopes: []string{domain.ScopeCapsulesRead},
+			},
+			expectedError: ErrInvalidTokenName,
+		},
+		{
+			name: "Invalid-Scopes",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, userID primitive.ObjectID) {
+			},
+			input: domain.CreateAccessTokenDTO{
+				Name:   "backup",
+				Scopes: []string{"admin"},
+			},
+			expectedError: ErrInvalidScopes,
+		},
+		{
+			name: "No-Scopes",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, userID primitive.ObjectID) {
+			},
+			input: domain.CreateAccessTokenDTO{
+				Name: "backup",
+			},
+			expectedError: ErrInvalidScopes,
+		},
+		{
+			name: "Expiry-In-Past",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, userID primitive.ObjectID) {
+			},
+			input: domain.CreateAccessTokenDTO{
+				Name:      "backup",
+				Scopes:    []string{domain.ScopeCapsulesRead},
+				ExpiresAt: &wayBack,
+			},
+			expectedError: ErrInvalidTokenExpiry,
+		},
+		{
+			name: "Too-Many-Tokens",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().GetAccessTokens(ctx, bson.M{"userID": userID}).
+					Return(make([]*domain.AccessToken, maxAccessTokens), nil).Times(1)
+			},
+			input: domain.CreateAccessTokenDTO{
+				Name:   "backup",
+				Scopes: []string{domain.ScopeCapsulesRead},
+			},
+			expectedError: ErrTooManyAccessTokens,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().GetAccessTokens(ctx, bson.M{"userID": userID}).Return(nil, nil).Times(1)
+				r.EXPECT().InsertAccessToken(ctx, gomock.Any()).Return(nil, errors.New("some error")).Times(1)
+			},
+			input: domain.CreateAccessTokenDTO{
+				Name:   "backup",
+				Scopes: []string{domain.ScopeCapsulesRead},
+			},
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				repo  = mock_repository.NewMockAccessTokenRepository(c)
+				audit = mock_repository.NewMockAuditRepository(c)
+				svc   = NewAccessTokenService(repo, audit)
+				ctx   = context.Background()
+			)
+
+			test.mockBehavior(repo, audit, ctx, userID)
+
+			token, err := svc.CreateAccessToken(ctx, userID, test.input)
+			assert.Equal(t, test.expectedError, err)
+
+			if err == nil {
+				assert.True(t, strings.HasPrefix(token.Token, token.Prefix))
+				assert.Equal(t, hashToken(token.Token), token.Hash)
+			}
+		})
+	}
+}
+
+func TestAccessTokenService_AuthenticateAccessToken(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockAccessTokenRepository, ctx context.Context, token string)
+
+	wayBack := time.Unix(0, 0).UTC()
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return wayBack })
+	defer patches.Reset()
+
+	var (
+		tokenID  = primitive.NewObjectID()
+		recently = wayBack.Add(-time.Second)
+		past     = wayBack.Add(-time.Hour)
+	)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		token         string
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, ctx context.Context, token string) {
+				r.EXPECT().GetAccessToken(ctx, bson.M{"hash": hashToken(token)}).
+					Return(&domain.AccessToken{ID: tokenID}, nil).Times(1)
+				r.EXPECT().UpdateAccessToken(ctx, tokenID, bson.M{
+					"$set": bson.M{"lastUsedAt": wayBack},
+				}).Return(nil).Times(1)
+			},
+			token:         "tc_pat_token",
+			expectedError: nil,
+		},
+		{
+			name: "OK-Recently-Used",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, ctx context.Context, token string) {
+				r.EXPECT().GetAccessToken(ctx, bson.M{"hash": hashToken(token)}).
+					Return(&domain.AccessToken{ID: tokenID, LastUsedAt: &recently}, nil).Times(1)
+			},
+			token:         "tc_pat_token",
+			expectedError: nil,
+		},
+		{
+			name: "Expired",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, ctx context.Context, token string) {
+				r.EXPECT().GetAccessToken(ctx, bson.M{"hash": hashToken(token)}).
+					Return(&domain.AccessToken{ID: tokenID, ExpiresAt: &past}, nil).Times(1)
+			},
+			token:         "tc_pat_token",
+			expectedError: ErrTokenExpired,
+		},
+		{
+			name: "Unknown",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, ctx context.Context, token string) {
+				r.EXPECT().GetAccessToken(ctx, bson.M{"hash": hashToken(token)}).
+					Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+			token:         "tc_pat_token",
+			expectedError: ErrInvalidToken,
+		},
+		{
+			name:          "Not-An-Access-Token",
+			mockBehavior:  func(r *mock_repository.MockAccessTokenRepository, ctx context.Context, token string) {},
+			token:         "token",
+			expectedError: ErrInvalidToken,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, ctx context.Context, token string) {
+				r.EXPECT().GetAccessToken(ctx, bson.M{"hash": hashToken(token)}).
+					Return(nil, errors.New("some error")).Times(1)
+			},
+			token:         "tc_pat_token",
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				repo = mock_repository.NewMockAccessTokenRepository(c)
+				svc  = NewAccessTokenService(repo, nil)
+				ctx  = context.Background()
+			)
+
+			test.mockBehavior(repo, ctx, test.token)
+
+			_, err := svc.AuthenticateAccessToken(ctx, test.token)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestAccessTokenService_RevokeAccessToken(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+		ctx context.Context, userID, id primitive.ObjectID)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, userID, id primitive.ObjectID) {
+				r.EXPECT().DeleteAccessToken(ctx, bson.M{"_id": id, "userID": userID}).Return(int64(1), nil).Times(1)
+				a.EXPECT().InsertAuditEvent(ctx, gomock.Any()).Return(nil, nil).Times(1)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Not-Found",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, userID, id primitive.ObjectID) {
+				r.EXPECT().DeleteAccessToken(ctx, bson.M{"_id": id, "userID": userID}).Return(int64(0), nil).Times(1)
+			},
+			expectedError: ErrNotFound,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(r *mock_repository.MockAccessTokenRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, userID, id primitive.ObjectID) {
+				r.EXPECT().DeleteAccessToken(ctx, gomock.Any()).Return(int64(0), errors.New("some error")).Times(1)
+			},
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				repo  = mock_repository.NewMockAccessTokenRepository(c)
+				audit = mock_repository.NewMockAuditRepository(c)
+				svc   = NewAccessTokenService(repo, audit)
+				ctx   = context.Background()
+
+				userID = primitive.NewObjectID()
+				id     = primitive.NewObjectID()
+			)
+
+			test.mockBehavior(repo, audit, ctx, userID, id)
+
+			err := svc.RevokeAccessToken(ctx, userID, id)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/totp"
+	"time-capsule/internal/tracing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	totpIssuer = "Time Capsule"
+
+	mfaTokenPurpose = "mfa"
+	mfaTokenTTL     = 5 * time.Minute
+
+	recoveryCodeCount = 10
+	recoveryCodeSize  = 5
+)
+
+var (
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTOTPNotEnrolled      = errors.New("start the two-factor authentication enrollment first")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor authentication code")
+)
+
+// EnrollTOTP generates the secret for the user's authenticator app. Two-factor authentication
+// is only enabled once the user confirms a code of the app with ConfirmTOTP.
+func (s *userService) EnrollTOTP(ctx context.Context, userID primitive.ObjectID) (*domain.TOTPEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "UserService.EnrollTOTP")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPSecret != "" {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		slog.ErrorContext(ctx, "EnrollTOTP", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if err = s.repository.UpdateUser(ctx, userID, bson.M{
+		"$set": bson.M{
+			"totpPendingSecret": secret,
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "EnrollTOTP", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return &domain.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the code matches the enrolled secret,
+// and returns the recovery codes.
+func (s *userService) ConfirmTOTP(ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO) (*domain.RecoveryCodes, error) {
+	ctx, span := tracing.Start(ctx, "UserService.ConfirmTOTP")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPSecret != "" {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	if user.TOTPPendingSecret == "" {
+		return nil, ErrTOTPNotEnrolled
+	}
+
+	counter, ok := totp.Validate(user.TOTPPendingSecret, normalizeCode(input.Code), time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		slog.ErrorContext(ctx, "ConfirmTOTP", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	if err = s.repository.UpdateUser(ctx, userID, bson.M{
+		"$set": bson.M{
+			"totpSecret":      user.TOTPPendingSecret,
+			"totpLastCounter": counter,
+			"recoveryCodes":   hashes,
+		},
+		"$unset": bson.M{
+			"totpPendingSecret": "",
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "ConfirmTOTP", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditTwoFactorEnabled,
+		UserID: userID,
+	})
+
+	return &domain.RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTOTP disables two-factor authentication, given a code of the authenticator app or a recovery code.
+func (s *userService) DisableTOTP(ctx context.Context, userID primitive.ObjectID, input domain.TwoFactorCodeDTO) error {
+	ctx, span := tracing.Start(ctx, "UserService.DisableTOTP")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.TOTPSecret == "" {
+		return ErrTwoFactorNotEnabled
+	}
+
+	var (
+		now  = time.Now().UTC()
+		keys = signInKeys(ctx, user)
+	)
+
+	if err = s.checkSignIn(ctx, now, user, user.Email, keys); err != nil {
+		return err
+	}
+
+	if _, err = s.verifySecondFactor(ctx, user, input.Code, now); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			return s.failSignIn(ctx, now, user, user.Email, "invalid_mfa_code", keys, err)
+		}
+
+		return err
+	}
+
+	if err = s.repository.UpdateUser(ctx, userID, bson.M{
+		"$unset": bson.M{
+			"totpSecret":        "",
+			"totpPendingSecret": "",
+			"totpLastCounter":   "",
+			"recoveryCodes":     "",
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "DisableTOTP", "error", err)
+		return ErrDBFailure
+	}
+
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action: domain.AuditTwoFactorDisabled,
+		UserID: userID,
+	})
+
+	return nil
+}
+
+// VerifyMFA completes a sign-in challenged for a second factor: given the challenge token from GenerateToken
+// and a code of the authenticator app or a recovery code, it issues the access token. Wrong codes count
+// as failed sign-ins.
+func (s *userService) VerifyMFA(ctx context.Context, input domain.MFASignInDTO) (string, error) {
+	ctx, span := tracing.Start(ctx, "UserService.VerifyMFA")
+	defer span.End()
+
+	userID, err := s.parseMFAToken(input.MFAToken)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", ErrInvalidToken
+		}
+
+		return "", err
+	}
+
+	if user.TOTPSecret == "" {
+		return "", ErrInvalidToken
+	}
+
+	var (
+		now  = time.Now().UTC()
+		keys = signInKeys(ctx, user)
+	)
+
+	if err = s.checkSignIn(ctx, now, user, user.Email, keys); err != nil {
+		return "", err
+	}
+
+	method, err := s.verifySecondFactor(ctx, user, input.Code, now)
+	if err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			return "", s.failSignIn(ctx, now, user, user.Email, "invalid_mfa_code", keys, err)
+		}
+
+		return "", err
+	}
+
+	return s.completeSignIn(ctx, user, map[string]string{"mfa": method})
+}
+
+// verifySecondFactor checks a code of the authenticator app, or else a recovery code, and consumes it,
+// so that it can't be used again. It returns which one was used. The code is only consumed if it's still
+// unused, so that of concurrent requests with the same code only one succeeds.
+func (s *userService) verifySecondFactor(ctx context.Context, user *domain.User, code string, now time.Time) (string, error) {
+	code = normalizeCode(code)
+
+	if counter, ok := totp.Validate(user.TOTPSecret, code, now); ok {
+		if counter <= user.TOTPLastCounter {
+			return "", ErrInvalidTwoFactorCode
+		}
+
+		// The counter is missing until the first code is used, $not matches it then.
+		matched, err := s.repository.UpdateUsers(ctx, bson.M{
+			"_id":             user.ID,
+			"totpLastCounter": bson.M{"$not": bson.M{"$gte": counter}},
+		}, bson.M{
+			"$set": bson.M{
+				"totpLastCounter": counter,
+			},
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "verifySecondFactor", "error", err)
+			return "", ErrDBFailure
+		}
+
+		if matched == 0 {
+			return "", ErrInvalidTwoFactorCode
+		}
+
+		return "totp", nil
+	}
+
+	hash := hashToken(code)
+	if code == "" || !slices.Contains(user.RecoveryCodes, hash) {
+		return "", ErrInvalidTwoFactorCode
+	}
+
+	matched, err := s.repository.UpdateUsers(ctx, bson.M{
+		"_id":           user.ID,
+		"recoveryCodes": hash,
+	}, bson.M{
+		"$pull": bson.M{
+			"recoveryCodes": hash,
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "verifySecondFactor", "error", err)
+		return "", ErrDBFailure
+	}
+
+	if matched == 0 {
+		return "", ErrInvalidTwoFactorCode
+	}
+
+	return "recovery_code", nil
+}
+
+func (s *userService) getUser(ctx context.Context, userID primitive.ObjectID) (*domain.User, error) {
+	user, err := s.repository.GetUser(ctx, bson.M{"_id": userID})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+
+		slog.ErrorContext(ctx, "GetUser", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	return user, nil
+}
+
+func (s *userService) parseMFAToken(mfaToken string) (primitive.ObjectID, error) {
+	token, err := s.keys.Parse(mfaToken, jwt.MapClaims{})
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return primitive.NilObjectID, ErrTokenExpired
+		}
+
+		return primitive.NilObjectID, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["purpose"] != mfaTokenPurpose {
+		return primitive.NilObjectID, ErrInvalidToken
+	}
+
+	id, _ := claims["userID"].(string)
+
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidToken
+	}
+
+	return userID, nil
+}
+
+// generateRecoveryCodes returns the recovery codes, formatted like "1a2b3-c4d5e", and the hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		code, err := randomHex(recoveryCodeSize)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes[i] = fmt.Sprintf("%s-%s", code[:recoveryCodeSize], code[recoveryCodeSize:])
+		hashes[i] = hashToken(code)
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeCode strips the spaces and dashes users may type in codes.
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+	mock_repository "time-capsule/internal/repository/mocks"
+	"time-capsule/internal/totp"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func TestUserService_ConfirmTOTP(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockUserRepository, a *mock_repository.MockAuditRepository,
+		ctx context.Context, user *domain.User)
+
+	userID := primitive.NewObjectID()
+
+	code, err := totp.Code(testTOTPSecret, totp.Counter(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		user          domain.User
+		code          string
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockUserRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, user *domain.User) {
+				r.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(user, nil).Times(1)
+				r.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ primitive.ObjectID, update bson.M) error {
+						set := update["$set"].(bson.M)
+						assert.Equal(t, testTOTPSecret, set["totpSecret"])
+						assert.Len(t, set["recoveryCodes"], recoveryCodeCount)
+						assert.Contains(t, update["$unset"], "totpPendingSecret")
+						return nil
+					}).Times(1)
+				a.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			},
+			user:          domain.User{ID: userID, TOTPPendingSecret: testTOTPSecret},
+			code:          code[:3] + " " + code[3:],
+			expectedError: nil,
+		},
+		{
+			name: "Already-Enabled",
+			mockBehavior: func(r *mock_repository.MockUserRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, user *domain.User) {
+				r.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(user, nil).Times(1)
+			},
+			user:          domain.User{ID: userID, TOTPSecret: testTOTPSecret},
+			code:          code,
+			expectedError: ErrTwoFactorEnabled,
+		},
+		{
+			name: "Not-Enrolled",
+			mockBehavior: func(r *mock_repository.MockUserRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, user *domain.User) {
+				r.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(user, nil).Times(1)
+			},
+			user:          domain.User{ID: userID},
+			code:          code,
+			expectedError: ErrTOTPNotEnrolled,
+		},
+		{
+			name: "Invalid-Code",
+			mockBehavior: func(r *mock_repository.MockUserRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, user *domain.User) {
+				r.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(user, nil).Times(1)
+			},
+			user:          domain.User{ID: userID, TOTPPendingSecret: testTOTPSecret},
+			code:          "abcdef",
+			expectedError: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(r *mock_repository.MockUserRepository, a *mock_repository.MockAuditRepository,
+				ctx context.Context, user *domain.User) {
+				r.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(nil, errors.New("some error")).Times(1)
+			},
+			user:          domain.User{ID: userID},
+			code:          code,
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				users = mock_repository.NewMockUserRepository(c)
+				audit = mock_repository.NewMockAuditRepository(c)
+				svc   = NewUserService(users, nil, nil, audit, nil, nil, nil, nil, "", testTokenTTL, bcrypt.MinCost)
+				ctx   = context.Background()
+			)
+
+			test.mockBehavior(users, audit, ctx, &test.user)
+
+			codes, err := svc.ConfirmTOTP(ctx, userID, domain.TwoFactorCodeDTO{Code: test.code})
+			assert.Equal(t, test.expectedError, err)
+
+			if err == nil {
+				assert.Len(t, codes.Codes, recoveryCodeCount)
+			}
+		})
+	}
+}
+
+func TestUserService_VerifyMFA(t *testing.T) {
+	type mocks struct {
+		users   *mock_repository.MockUserRepository
+		signIns *mock_repository.MockSignInFailureRepository
+		audit   *mock_repository.MockAuditRepository
+	}
+
+	type mockBehavior func(m mocks, ctx context.Context, user *domain.User)
+
+	jwtKeys := newTestKeys(t)
+
+	var (
+		userID       = primitive.NewObjectID()
+		ipKey        = ipFailuresKey("192.0.2.1")
+		accountKey   = accountFailuresKey(userID)
+		keys         = bson.M{"_id": bson.M{"$in": []string{ipKey, accountKey}}}
+		counter      = totp.Counter(time.Now())
+		recoveryCode = "1a2b3-c4d5e"
+
+		totpFilter     = bson.M{"_id": userID, "totpLastCounter": bson.M{"$not": bson.M{"$gte": counter}}}
+		recoveryFilter = bson.M{"_id": userID, "recoveryCodes": hashToken("1a2b3c4d5e")}
+	)
+
+	code, err := totp.Code(testTOTPSecret, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mfaToken, err := jwtKeys.Sign(jwt.MapClaims{
+		"userID":  userID,
+		"purpose": mfaTokenPurpose,
+		"exp":     time.Now().Add(mfaTokenTTL).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accessToken, err := jwtKeys.Sign(jwt.MapClaims{
+		"userID": userID,
+		"exp":    time.Now().Add(testTokenTTL).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectSignIn := func(m mocks, ctx context.Context, method string) {
+		m.signIns.EXPECT().DeleteSignInFailures(gomock.Any(), bson.M{"_id": accountKey}).Return(nil, nil).Times(1)
+		m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+				assert.Equal(t, domain.AuditSignIn, event.Action)
+				assert.Equal(t, method, event.Details["mfa"])
+				return event, nil
+			}).Times(1)
+	}
+
+	expectFailure := func(m mocks) {
+		m.signIns.EXPECT().RecordSignInFailure(gomock.Any(), ipKey, gomock.Any(), gomock.Any()).
+			Return(&domain.SignInFailures{Key: ipKey, Failures: 1}, nil).Times(1)
+		m.signIns.EXPECT().RecordSignInFailure(gomock.Any(), accountKey, gomock.Any(), gomock.Any()).
+			Return(&domain.SignInFailures{Key: accountKey, Failures: 1}, nil).Times(1)
+		m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+	}
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		mfaToken      string
+		code          string
+		expectedError error
+	}{
+		{
+			name: "OK-TOTP",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return(nil, nil).Times(1)
+				m.users.EXPECT().UpdateUsers(gomock.Any(), totpFilter, bson.M{
+					"$set": bson.M{"totpLastCounter": counter},
+				}).Return(int64(1), nil).Times(1)
+				expectSignIn(m, ctx, "totp")
+			},
+			mfaToken:      mfaToken,
+			code:          code,
+			expectedError: nil,
+		},
+		{
+			name: "OK-Recovery-Code",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return(nil, nil).Times(1)
+				m.users.EXPECT().UpdateUsers(gomock.Any(), recoveryFilter, bson.M{
+					"$pull": bson.M{"recoveryCodes": hashToken("1a2b3c4d5e")},
+				}).Return(int64(1), nil).Times(1)
+				expectSignIn(m, ctx, "recovery_code")
+			},
+			mfaToken:      mfaToken,
+			code:          "1A2B3-C4D5E",
+			expectedError: nil,
+		},
+		{
+			// Another request used the code between reading the user and consuming it.
+			name: "Concurrently-Used-Code",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return(nil, nil).Times(1)
+				m.users.EXPECT().UpdateUsers(gomock.Any(), totpFilter, gomock.Any()).Return(int64(0), nil).Times(1)
+				expectFailure(m)
+			},
+			mfaToken:      mfaToken,
+			code:          code,
+			expectedError: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Concurrently-Used-Recovery-Code",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return(nil, nil).Times(1)
+				m.users.EXPECT().UpdateUsers(gomock.Any(), recoveryFilter, gomock.Any()).Return(int64(0), nil).Times(1)
+				expectFailure(m)
+			},
+			mfaToken:      mfaToken,
+			code:          recoveryCode,
+			expectedError: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Replayed-Code",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User) {
+				user.TOTPLastCounter = counter + 1
+
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return(nil, nil).Times(1)
+				expectFailure(m)
+			},
+			mfaToken:      mfaToken,
+			code:          code,
+			expectedError: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Access-Token",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User) {
+			},
+			mfaToken:      accessToken,
+			code:          code,
+			expectedError: ErrInvalidToken,
+		},
+		{
+			name: "Invalid-Token",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User) {
+			},
+			mfaToken:      "foo",
+			code:          code,
+			expectedError: ErrInvalidToken,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				m = mocks{
+					users:   mock_repository.NewMockUserRepository(c),
+					signIns: mock_repository.NewMockSignInFailureRepository(c),
+					audit:   mock_repository.NewMockAuditRepository(c),
+				}
+				svc = NewUserService(m.users, nil, m.signIns, m.audit, nil, nil, jwtKeys, nil, "", testTokenTTL, bcrypt.MinCost)
+				ctx = WithClient(context.Background(), Client{IP: "192.0.2.1"})
+
+				user = &domain.User{
+					ID:            userID,
+					Email:         "foo@example.com",
+					TOTPSecret:    testTOTPSecret,
+					RecoveryCodes: []string{hashToken(normalizeCode(recoveryCode))},
+				}
+			)
+
+			test.mockBehavior(m, ctx, user)
+
+			token, err := svc.VerifyMFA(ctx, domain.MFASignInDTO{MFAToken: test.mfaToken, Code: test.code})
+			assert.Equal(t, test.expectedError, err)
+
+			if err == nil {
+				_, err = svc.ParseToken(token)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -3,28 +3,32 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
-	"log"
-	"os"
+	"log/slog"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"time-capsule/internal/domain"
+	"time-capsule/internal/jwks"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/oidc"
 	"time-capsule/internal/repository"
+	"time-capsule/internal/tracing"
 
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	bcryptCost = 10
-	tokenTTL   = 24 * time.Hour * 7
+	activityCheckInInterval = time.Hour
 
 	usernameRegex = `^[A-Za-z0-9]{3,30}$`
 	emailRegex    = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
+	languageRegex = `^[a-z]{2,3}(-[a-zA-Z0-9]{2,8})*$`
 )
 
 var (
@@ -38,19 +42,68 @@ var (
 	ErrInvalidUsername     = errors.New("username must be between 3 and 30 characters long and can only contain english alphabet letters (both lowercase and uppercase) and digits")
 	ErrInvalidPassword     = errors.New("password must be at least 8 characters long and include at least one uppercase letter and one digit")
 	ErrTokenExpired        = errors.New("token expired")
+	ErrInvalidLanguage     = errors.New("language must be a language tag, e.g. \"en\" or \"ru-RU\"")
 )
 
 type userService struct {
-	repository repository.UserRepository
+	repository        repository.UserRepository
+	capsuleRepository repository.CapsuleRepository
+	signInRepository  repository.SignInFailureRepository
+	outboxRepository  repository.OutboxRepository
+	renderer          mail.Renderer
+	audit             *auditLog
+	// keys sign the tokens the service issues and verify the ones it's given.
+	keys *jwks.KeySet
+	// providers are the identity providers users can sign in with, by name.
+	providers map[string]*oidc.Provider
+	// publicURL is the base URL of the service, links in emails point to it.
+	publicURL string
+	// tokenTTL is how long the issued access tokens are valid.
+	tokenTTL   time.Duration
+	bcryptCost int
+	// dummyPasswordHash is compared against when the account doesn't exist,
+	// so that the response time doesn't reveal which emails are registered.
+	dummyPasswordHash string
+
+	mu sync.Mutex
+	// lastActivity is when the recently active users last checked in, prunedAt when older check-ins were
+	// last forgotten.
+	lastActivity map[primitive.ObjectID]time.Time
+	prunedAt     time.Time
 }
 
-func NewUserService(repository repository.UserRepository) UserService {
+func NewUserService(repository repository.UserRepository, capsuleRepository repository.CapsuleRepository,
+	signInRepository repository.SignInFailureRepository, auditRepository repository.AuditRepository,
+	outboxRepository repository.OutboxRepository, renderer mail.Renderer, keys *jwks.KeySet, providers []*oidc.Provider,
+	publicURL string, tokenTTL time.Duration, bcryptCost int) UserService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	dummyHash, _ := hashPassword("dummy-password", bcryptCost)
+
 	return &userService{
-		repository: repository,
+		repository:        repository,
+		capsuleRepository: capsuleRepository,
+		signInRepository:  signInRepository,
+		outboxRepository:  outboxRepository,
+		renderer:          renderer,
+		audit:             &auditLog{repository: auditRepository},
+		keys:              keys,
+		providers:         byName,
+		publicURL:         publicURL,
+		tokenTTL:          tokenTTL,
+		bcryptCost:        bcryptCost,
+		dummyPasswordHash: dummyHash,
+		lastActivity:      make(map[primitive.ObjectID]time.Time),
 	}
 }
 
 func (s *userService) CreateUser(ctx context.Context, input domain.CreateUserDTO) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
 	if !usernameValidation(input.Username) {
 		return nil, ErrInvalidUsername
 	}
@@ -63,15 +116,20 @@ func (s *userService) CreateUser(ctx context.Context, input domain.CreateUserDTO
 		return nil, ErrInvalidPassword
 	}
 
+	if input.Language != "" && !languageValidation(input.Language) {
+		return nil, ErrInvalidLanguage
+	}
+
 	toInsert := &domain.User{
 		Username:     input.Username,
 		Email:        input.Email,
 		RegisteredAt: time.Now().UTC(),
+		Language:     input.Language,
 	}
 
-	hash, err := hashPassword(input.Password)
+	hash, err := hashPassword(input.Password, s.bcryptCost)
 	if err != nil {
-		log.Println("hashPassword", err)
+		slog.ErrorContext(ctx, "hashPassword", "error", err)
 		return nil, ErrPasswordHashFailure
 	}
 	toInsert.PasswordHash = hash
@@ -86,66 +144,262 @@ func (s *userService) CreateUser(ctx context.Context, input domain.CreateUserDTO
 			}
 		}
 
-		log.Println("CreateUser", err)
+		slog.ErrorContext(ctx, "CreateUser", "error", err)
 		return nil, ErrDBFailure
 	}
 
 	return res, nil
 }
 
-func (s *userService) GenerateToken(ctx context.Context, email, password string) (string, error) {
+// GenerateToken signs the user in. Failed attempts are counted per account and per IP: after a few of them
+// further attempts are delayed, and after lockoutThreshold the account is locked and the user gets an email to unlock it.
+// Users with two-factor authentication enabled get a challenge token to complete the sign-in with VerifyMFA.
+func (s *userService) GenerateToken(ctx context.Context, email, password string) (*domain.SignInResult, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GenerateToken")
+	defer span.End()
+
+	now := time.Now().UTC()
+
 	user, err := s.repository.GetUser(ctx, bson.M{"email": email})
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return "", ErrInvalidCredentials
-		}
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		slog.ErrorContext(ctx, "GetUser", "error", err)
+		return nil, ErrDBFailure
+	}
+
+	keys := signInKeys(ctx, user)
+
+	if err = s.checkSignIn(ctx, now, user, email, keys); err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		comparePasswords(password, s.dummyPasswordHash)
 
-		log.Println("GetUser", err)
-		return "", ErrDBFailure
+		return nil, s.failSignIn(ctx, now, nil, email, "unknown_email", keys, ErrInvalidCredentials)
 	}
 
 	if !comparePasswords(password, user.PasswordHash) {
-		return "", ErrInvalidCredentials
+		return nil, s.failSignIn(ctx, now, user, email, "invalid_password", keys, ErrInvalidCredentials)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"userID": user.ID,
-		"exp":    time.Now().UTC().Add(tokenTTL).Unix(),
-	})
+	return s.startSession(ctx, user, nil)
+}
+
+// startSession issues the access token of the user who proved who they are, or when they have
+// two-factor authentication enabled, the token of the challenge to complete the sign-in with.
+// Disabled accounts can't sign in.
+func (s *userService) startSession(ctx context.Context, user *domain.User, details map[string]string) (*domain.SignInResult, error) {
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
 
-	secret := os.Getenv("JWT_SECRET")
+	if user.TOTPSecret != "" {
+		mfaToken, err := s.keys.Sign(jwt.MapClaims{
+			"userID":  user.ID,
+			"purpose": mfaTokenPurpose,
+			"exp":     time.Now().UTC().Add(mfaTokenTTL).Unix(),
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "GenerateToken", "error", err)
+			return nil, ErrTokenCreationFailed
+		}
+
+		return &domain.SignInResult{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
 
-	signed, err := token.SignedString([]byte(secret))
+	token, err := s.completeSignIn(ctx, user, details)
 	if err != nil {
-		fmt.Println("GenerateToken", err)
+		return nil, err
+	}
+
+	return &domain.SignInResult{Token: token}, nil
+}
+
+// completeSignIn resets the failed sign-ins of the user's account and issues the access token.
+func (s *userService) completeSignIn(ctx context.Context, user *domain.User, details map[string]string) (string, error) {
+	if user.DisabledAt != nil {
+		return "", ErrAccountDisabled
+	}
+
+	if _, err := s.signInRepository.DeleteSignInFailures(ctx, bson.M{
+		"_id": accountFailuresKey(user.ID),
+	}); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		slog.ErrorContext(ctx, "completeSignIn", "error", err)
+	}
+
+	signed, err := s.keys.Sign(jwt.MapClaims{
+		"userID": user.ID,
+		"exp":    time.Now().UTC().Add(s.tokenTTL).Unix(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "GenerateToken", "error", err)
 		return "", ErrTokenCreationFailed
 	}
 
+	s.audit.record(ctx, &domain.AuditEvent{
+		Action:  domain.AuditSignIn,
+		UserID:  user.ID,
+		Details: details,
+	})
+
 	return signed, nil
 }
 
 func (s *userService) ParseToken(accessToken string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (interface{}, error) {
-		secret := os.Getenv("JWT_SECRET")
-		return []byte(secret), nil
-	})
+	token, err := s.keys.Parse(accessToken, jwt.MapClaims{})
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrTokenExpired
 		}
 
-		fmt.Println("ParseToken1", err)
+		slog.Debug("ParseToken", "error", err)
 		return nil, ErrInvalidToken
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+	// Tokens issued for a purpose, like MFA challenge tokens, aren't access tokens.
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid && claims["purpose"] == nil {
 		return claims, nil
 	}
 
-	fmt.Println("ParseToken2", err)
+	slog.Debug("ParseToken", "error", "not an access token")
 	return nil, ErrInvalidToken
 }
 
+// AuthenticateUser returns the user an access token was issued to. Tokens of deleted
+// and disabled accounts are rejected, even though they're still valid.
+func (s *userService) AuthenticateUser(ctx context.Context, userID primitive.ObjectID) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.AuthenticateUser")
+	defer span.End()
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+
+		return nil, err
+	}
+
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
+
+	return user, nil
+}
+
+// JWKS returns the public keys the service's tokens can be verified with.
+func (s *userService) JWKS() jwks.Set {
+	return s.keys.JWKS()
+}
+
+// CheckIn records that the user is alive and pushes the deadline
+// of every pending inactivity capsule of theirs forward. Capsules whose deadline already passed are left
+// to the worker, which may be opening them already.
+func (s *userService) CheckIn(ctx context.Context, userID primitive.ObjectID) error {
+	ctx, span := tracing.Start(ctx, "UserService.CheckIn")
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	if err := s.repository.UpdateUser(ctx, userID, bson.M{
+		"$set": bson.M{
+			"lastCheckInAt": now,
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "CheckIn", "error", err)
+		return ErrDBFailure
+	}
+
+	if _, err := s.capsuleRepository.UpdateCapsules(ctx, bson.M{
+		"userID":   userID,
+		"mode":     domain.CapsuleModeInactivity,
+		"notified": false,
+		"openAt":   bson.M{"$gt": now},
+	}, checkInPipeline(now)); err != nil {
+		slog.ErrorContext(ctx, "CheckIn", "error", err)
+		return ErrDBFailure
+	}
+
+	s.mu.Lock()
+	s.recordCheckIn(userID, now)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// checkInPipeline moves the deadline of an inactivity capsule to inactivityDays from now
+// and resets its check-in reminders.
+func checkInPipeline(now time.Time) bson.A {
+	return bson.A{
+		bson.M{
+			"$set": bson.M{
+				"openAt": bson.M{"$dateAdd": bson.M{"startDate": now, "unit": "day", "amount": "$inactivityDays"}},
+			},
+		},
+		bson.M{
+			"$unset": "checkInRemindersSent",
+		},
+	}
+}
+
+// recordCheckIn remembers when the user checked in. Check-ins older than activityCheckInInterval are forgotten,
+// at most once per interval, since the users are checked in again anyway. It must be called with mu held.
+func (s *userService) recordCheckIn(userID primitive.ObjectID, now time.Time) {
+	if now.Sub(s.prunedAt) >= activityCheckInInterval {
+		for id, at := range s.lastActivity {
+			if now.Sub(at) >= activityCheckInInterval {
+				delete(s.lastActivity, id)
+			}
+		}
+
+		s.prunedAt = now
+	}
+
+	s.lastActivity[userID] = now
+}
+
+// RecordActivity checks the user in on any authenticated request,
+// at most once per activityCheckInInterval.
+func (s *userService) RecordActivity(ctx context.Context, userID primitive.ObjectID) error {
+	ctx, span := tracing.Start(ctx, "UserService.RecordActivity")
+	defer span.End()
+
+	s.mu.Lock()
+	last, ok := s.lastActivity[userID]
+	s.mu.Unlock()
+
+	if ok && time.Now().UTC().Sub(last) < activityCheckInInterval {
+		return nil
+	}
+
+	return s.CheckIn(ctx, userID)
+}
+
+// UpdateReminders sets the user's default reminder days, used by capsules without their own reminders.
+func (s *userService) UpdateReminders(ctx context.Context, userID primitive.ObjectID, input domain.UpdateRemindersDTO) error {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateReminders")
+	defer span.End()
+
+	days, ok := normalizeReminders(input.Days)
+	if !ok {
+		return ErrInvalidReminders
+	}
+
+	if err := s.repository.UpdateUser(ctx, userID, bson.M{
+		"$set": bson.M{
+			"reminderDays": days,
+		},
+	}); err != nil {
+		slog.ErrorContext(ctx, "UpdateReminders", "error", err)
+		return ErrDBFailure
+	}
+
+	return nil
+}
+
 func passwordValidation(pw string) bool {
 	if len(pw) < 8 {
 		return false
@@ -179,7 +433,12 @@ func comparePasswords(pw, hash string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pw)) == nil
 }
 
-func hashPassword(pw string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(pw), bcryptCost)
+func hashPassword(pw string, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pw), cost)
 	return string(hash), err
 }
+
+func languageValidation(lang string) bool {
+	res, _ := regexp.Match(languageRegex, []byte(lang))
+	return res
+}
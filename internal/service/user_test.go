@@ -3,12 +3,13 @@ package service
 import (
 	"context"
 	"errors"
-	"os"
 	"strings"
 	"testing"
 	"time"
 
 	"time-capsule/internal/domain"
+	"time-capsule/internal/jwks"
+	"time-capsule/internal/mail"
 	mock_repository "time-capsule/internal/repository/mocks"
 
 	"github.com/agiledragon/gomonkey/v2"
@@ -46,9 +47,9 @@ func TestUserService_CreateUser(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, input domain.CreateUserDTO) {
-				hash, _ := hashPassword(input.Password)
+				hash, _ := hashPassword(input.Password, bcrypt.MinCost)
 
-				r.EXPECT().InsertUser(ctx, &domain.User{
+				r.EXPECT().InsertUser(gomock.Any(), &domain.User{
 					Username:     input.Username,
 					Email:        input.Email,
 					PasswordHash: hash,
@@ -95,6 +96,38 @@ func TestUserService_CreateUser(t *testing.T) {
 			},
 			expectedError: ErrInvalidEmail,
 		},
+		{
+			name: "OK-Language",
+			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, input domain.CreateUserDTO) {
+				hash, _ := hashPassword(input.Password, bcrypt.MinCost)
+
+				r.EXPECT().InsertUser(gomock.Any(), &domain.User{
+					Username:     input.Username,
+					Email:        input.Email,
+					PasswordHash: hash,
+					RegisteredAt: time.Now().UTC(),
+					Language:     "ru-RU",
+				}).Return(&domain.User{}, nil).Times(1)
+			},
+			input: domain.CreateUserDTO{
+				Username: "username123",
+				Email:    "foo@example.com",
+				Password: "Qwerty123",
+				Language: "ru-RU",
+			},
+			expectedError: nil,
+		},
+		{
+			name:         "Invalid-Language",
+			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, input domain.CreateUserDTO) {},
+			input: domain.CreateUserDTO{
+				Username: "username123",
+				Email:    "foo@example.com",
+				Password: "Qwerty123",
+				Language: "russian!",
+			},
+			expectedError: ErrInvalidLanguage,
+		},
 		{
 			name:         "Invalid-Password-Too-Short",
 			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, input domain.CreateUserDTO) {},
@@ -138,9 +171,9 @@ func TestUserService_CreateUser(t *testing.T) {
 		{
 			name: "Creating-DB-Duplicate-Email",
 			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, input domain.CreateUserDTO) {
-				hash, _ := hashPassword(input.Password)
+				hash, _ := hashPassword(input.Password, bcrypt.MinCost)
 
-				r.EXPECT().InsertUser(ctx, &domain.User{
+				r.EXPECT().InsertUser(gomock.Any(), &domain.User{
 					Username:     input.Username,
 					Email:        input.Email,
 					PasswordHash: hash,
@@ -157,9 +190,9 @@ func TestUserService_CreateUser(t *testing.T) {
 		{
 			name: "Creating-DB-Duplicate-Username",
 			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, input domain.CreateUserDTO) {
-				hash, _ := hashPassword(input.Password)
+				hash, _ := hashPassword(input.Password, bcrypt.MinCost)
 
-				r.EXPECT().InsertUser(ctx, &domain.User{
+				r.EXPECT().InsertUser(gomock.Any(), &domain.User{
 					Username:     input.Username,
 					Email:        input.Email,
 					PasswordHash: hash,
@@ -176,9 +209,9 @@ func TestUserService_CreateUser(t *testing.T) {
 		{
 			name: "Creating-DB-Failure",
 			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, input domain.CreateUserDTO) {
-				hash, _ := hashPassword(input.Password)
+				hash, _ := hashPassword(input.Password, bcrypt.MinCost)
 
-				r.EXPECT().InsertUser(ctx, &domain.User{
+				r.EXPECT().InsertUser(gomock.Any(), &domain.User{
 					Username:     input.Username,
 					Email:        input.Email,
 					PasswordHash: hash,
@@ -206,7 +239,7 @@ func TestUserService_CreateUser(t *testing.T) {
 
 			var (
 				rpstry = mock_repository.NewMockUserRepository(c)
-				svc    = NewUserService(rpstry)
+				svc    = NewUserService(rpstry, nil, nil, nil, nil, nil, nil, nil, "", testTokenTTL, bcrypt.MinCost)
 				ctx    = context.Background()
 			)
 
@@ -219,64 +252,205 @@ func TestUserService_CreateUser(t *testing.T) {
 }
 
 func TestUserService_GenerateToken(t *testing.T) {
-	type mockBehavior func(r *mock_repository.MockUserRepository, ctx context.Context,
-		email, password string)
+	type mocks struct {
+		users   *mock_repository.MockUserRepository
+		signIns *mock_repository.MockSignInFailureRepository
+		audit   *mock_repository.MockAuditRepository
+		outbox  *mock_repository.MockOutboxRepository
+	}
+
+	type mockBehavior func(m mocks, ctx context.Context, user *domain.User, password string)
+
+	wayBack := time.Unix(0, 0).UTC()
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return wayBack })
+	defer patches.Reset()
+
+	var (
+		now        = time.Now().UTC()
+		userID     = primitive.NewObjectID()
+		ipKey      = ipFailuresKey("192.0.2.1")
+		accountKey = accountFailuresKey(userID)
+		keys       = bson.M{"_id": bson.M{"$in": []string{ipKey, accountKey}}}
+		lockedTill = now.Add(time.Minute)
+	)
+
+	renderer, err := mail.NewRenderer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwtKeys := newTestKeys(t)
+
+	expectAudit := func(m mocks, action string) {
+		m.audit.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, event *domain.AuditEvent) (*domain.AuditEvent, error) {
+				assert.Equal(t, action, event.Action)
+				assert.Equal(t, "192.0.2.1", event.IP)
+				return event, nil
+			}).Times(1)
+	}
 
 	tests := []struct {
 		name          string
 		mockBehavior  mockBehavior
-		email         string
 		password      string
+		expectedMFA   bool
 		expectedError error
 	}{
 		{
 			name: "OK",
-			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, email, password string) {
-				hash, _ := hashPassword(password)
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User, password string) {
+				user.PasswordHash, _ = hashPassword(password, bcrypt.MinCost)
+
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": user.Email}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return(nil, nil).Times(1)
+				m.signIns.EXPECT().DeleteSignInFailures(gomock.Any(), bson.M{"_id": accountKey}).
+					Return(nil, mongo.ErrNoDocuments).Times(1)
+				expectAudit(m, domain.AuditSignIn)
+			},
+			password:      "Qwerty123",
+			expectedError: nil,
+		},
+		{
+			name: "OK-MFA-Required",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User, password string) {
+				user.PasswordHash, _ = hashPassword(password, bcrypt.MinCost)
+				user.TOTPSecret = "JBSWY3DPEHPK3PXP"
 
-				r.EXPECT().GetUser(ctx, bson.M{"email": email}).Return(&domain.User{PasswordHash: hash}, nil).Times(1)
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": user.Email}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return(nil, nil).Times(1)
+			},
+			password:      "Qwerty123",
+			expectedMFA:   true,
+			expectedError: nil,
+		},
+		{
+			name: "OK-Expired-Lock",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User, password string) {
+				user.PasswordHash, _ = hashPassword(password, bcrypt.MinCost)
+
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": user.Email}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return([]*domain.SignInFailures{{
+					Key:         accountKey,
+					Failures:    lockoutThreshold,
+					ExpiresAt:   now,
+					LockedUntil: &now,
+				}}, nil).Times(1)
+				m.signIns.EXPECT().DeleteSignInFailures(gomock.Any(), bson.M{
+					"_id":       accountKey,
+					"expiresAt": bson.M{"$lte": now},
+				}).Return(nil, nil).Times(1)
+				m.signIns.EXPECT().DeleteSignInFailures(gomock.Any(), bson.M{"_id": accountKey}).
+					Return(nil, mongo.ErrNoDocuments).Times(1)
+				expectAudit(m, domain.AuditSignIn)
 			},
-			email:         "foo@example.com",
 			password:      "Qwerty123",
 			expectedError: nil,
 		},
 		{
 			name: "Retrieving-DB-Failure",
-			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, email, password string) {
-				r.EXPECT().GetUser(ctx, bson.M{"email": email}).Return(nil, errors.New("some error")).Times(1)
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User, password string) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": user.Email}).Return(nil, errors.New("some error")).Times(1)
+			},
+			password:      "Qwerty123",
+			expectedError: ErrDBFailure,
+		},
+		{
+			name: "Failures-DB-Failure",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User, password string) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": user.Email}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return(nil, errors.New("some error")).Times(1)
 			},
-			email:         "foo@example.com",
 			password:      "Qwerty123",
 			expectedError: ErrDBFailure,
 		},
 		{
 			name: "Invalid-Credentials-Email",
-			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, email, password string) {
-				r.EXPECT().GetUser(ctx, bson.M{"email": email}).Return(nil, mongo.ErrNoDocuments).Times(1)
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User, password string) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": user.Email}).Return(nil, mongo.ErrNoDocuments).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), bson.M{"_id": bson.M{"$in": []string{ipKey}}}).Return(nil, nil).Times(1)
+				m.signIns.EXPECT().RecordSignInFailure(gomock.Any(), ipKey, now, now.Add(failureWindow)).
+					Return(&domain.SignInFailures{Key: ipKey, Failures: 1}, nil).Times(1)
+				expectAudit(m, domain.AuditSignInFailed)
 			},
-			email:         "foo@example.com",
 			password:      "Qwerty123",
 			expectedError: ErrInvalidCredentials,
 		},
 		{
 			name: "Invalid-Credentials-Password",
-			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, email, password string) {
-				r.EXPECT().GetUser(ctx, bson.M{"email": email}).Return(&domain.User{PasswordHash: "some_password"}, nil).Times(1)
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User, password string) {
+				user.PasswordHash = "some_password"
+
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": user.Email}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return(nil, nil).Times(1)
+				m.signIns.EXPECT().RecordSignInFailure(gomock.Any(), ipKey, now, now.Add(failureWindow)).
+					Return(&domain.SignInFailures{Key: ipKey, Failures: 1}, nil).Times(1)
+				m.signIns.EXPECT().RecordSignInFailure(gomock.Any(), accountKey, now, now.Add(failureWindow)).
+					Return(&domain.SignInFailures{Key: accountKey, Failures: 1}, nil).Times(1)
+				expectAudit(m, domain.AuditSignInFailed)
 			},
-			email:         "foo@example.com",
 			password:      "Qwerty123",
 			expectedError: ErrInvalidCredentials,
 		},
 		{
-			name: "Token-Generation-Failure",
-			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, email, password string) {
-				hash, _ := hashPassword(password)
-
-				r.EXPECT().GetUser(ctx, bson.M{"email": email}).Return(&domain.User{PasswordHash: hash}, nil).Times(1)
+			name: "Throttled",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User, password string) {
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": user.Email}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return([]*domain.SignInFailures{{
+					Key:           ipKey,
+					Failures:      throttleThreshold,
+					LastFailureAt: now,
+					ExpiresAt:     now.Add(failureWindow),
+				}}, nil).Times(1)
+				expectAudit(m, domain.AuditSignInFailed)
 			},
-			email:         "foo@example.com",
 			password:      "Qwerty123",
-			expectedError: nil,
+			expectedError: ErrSignInThrottled,
+		},
+		{
+			name: "Locked",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User, password string) {
+				user.PasswordHash, _ = hashPassword(password, bcrypt.MinCost)
+
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": user.Email}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return([]*domain.SignInFailures{{
+					Key:         accountKey,
+					Failures:    lockoutThreshold,
+					ExpiresAt:   lockedTill,
+					LockedUntil: &lockedTill,
+				}}, nil).Times(1)
+				expectAudit(m, domain.AuditSignInFailed)
+			},
+			password:      "Qwerty123",
+			expectedError: ErrAccountLocked,
+		},
+		{
+			name: "Lockout",
+			mockBehavior: func(m mocks, ctx context.Context, user *domain.User, password string) {
+				user.PasswordHash = "some_password"
+
+				m.users.EXPECT().GetUser(gomock.Any(), bson.M{"email": user.Email}).Return(user, nil).Times(1)
+				m.signIns.EXPECT().GetSignInFailures(gomock.Any(), keys).Return(nil, nil).Times(1)
+				m.signIns.EXPECT().RecordSignInFailure(gomock.Any(), ipKey, now, now.Add(failureWindow)).
+					Return(&domain.SignInFailures{Key: ipKey, Failures: 1}, nil).Times(1)
+				m.signIns.EXPECT().RecordSignInFailure(gomock.Any(), accountKey, now, now.Add(failureWindow)).
+					Return(&domain.SignInFailures{Key: accountKey, Failures: lockoutThreshold}, nil).Times(1)
+				m.signIns.EXPECT().UpdateSignInFailures(gomock.Any(), bson.M{
+					"_id":         accountKey,
+					"lockedUntil": bson.M{"$exists": false},
+				}, gomock.Any()).Return(int64(1), nil).Times(1)
+				m.outbox.EXPECT().InsertOutboxEntry(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, entry *domain.OutboxEntry) (*domain.OutboxEntry, error) {
+						assert.Equal(t, user.Email, entry.Recipient)
+						assert.Equal(t, mail.TemplateAccountLocked, entry.Template)
+						assert.Contains(t, entry.Text, "https://time-capsule.example.com/api/v1/unlock?token=")
+						return entry, nil
+					}).Times(1)
+				expectAudit(m, domain.AuditAccountLocked)
+				expectAudit(m, domain.AuditSignInFailed)
+			},
+			password:      "Qwerty123",
+			expectedError: ErrAccountLocked,
 		},
 	}
 
@@ -286,20 +460,39 @@ func TestUserService_GenerateToken(t *testing.T) {
 			defer c.Finish()
 
 			var (
-				rpstry = mock_repository.NewMockUserRepository(c)
-				svc    = NewUserService(rpstry)
-				ctx    = context.Background()
+				m = mocks{
+					users:   mock_repository.NewMockUserRepository(c),
+					signIns: mock_repository.NewMockSignInFailureRepository(c),
+					audit:   mock_repository.NewMockAuditRepository(c),
+					outbox:  mock_repository.NewMockOutboxRepository(c),
+				}
+				svc = NewUserService(m.users, nil, m.signIns, m.audit, m.outbox, renderer, jwtKeys, nil, "https://time-capsule.example.com/", testTokenTTL, bcrypt.MinCost)
+				ctx = WithClient(context.Background(), Client{IP: "192.0.2.1"})
+
+				user = &domain.User{
+					ID:       userID,
+					Username: "johndoe",
+					Email:    "foo@example.com",
+				}
 			)
 
-			test.mockBehavior(rpstry, ctx, test.email, test.password)
+			test.mockBehavior(m, ctx, user, test.password)
 
-			_, err := svc.GenerateToken(ctx, test.email, test.password)
+			result, err := svc.GenerateToken(ctx, user.Email, test.password)
 			assert.Equal(t, test.expectedError, err)
+
+			if err == nil {
+				assert.Equal(t, test.expectedMFA, result.MFARequired)
+				assert.Equal(t, test.expectedMFA, result.Token == "")
+				assert.Equal(t, test.expectedMFA, result.MFAToken != "")
+			}
 		})
 	}
 }
 
 func TestUserService_ParseToken(t *testing.T) {
+	keys := newTestKeys(t)
+
 	tests := []struct {
 		name          string
 		accessToken   func() string
@@ -308,15 +501,11 @@ func TestUserService_ParseToken(t *testing.T) {
 		{
 			name: "OK",
 			accessToken: func() string {
-				claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				token, _ := keys.Sign(jwt.MapClaims{
 					"userID": primitive.NilObjectID,
-					"exp":    time.Now().UTC().Add(tokenTTL).Unix(),
+					"exp":    time.Now().UTC().Add(testTokenTTL).Unix(),
 				})
 
-				secret := os.Getenv("JWT_SECRET")
-
-				token, _ := claims.SignedString([]byte(secret))
-
 				return token
 			},
 			expectedError: nil,
@@ -324,18 +513,42 @@ func TestUserService_ParseToken(t *testing.T) {
 		{
 			name: "Expired-Token",
 			accessToken: func() string {
-				claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				token, _ := keys.Sign(jwt.MapClaims{
 					"userID": primitive.NilObjectID,
 					"exp":    time.Now().UTC().Add(-1 * time.Minute).Unix(),
 				})
 
-				secret := os.Getenv("JWT_SECRET")
+				return token
+			},
+			expectedError: ErrTokenExpired,
+		},
+		{
+			name: "MFA-Token",
+			accessToken: func() string {
+				token, _ := keys.Sign(jwt.MapClaims{
+					"userID":  primitive.NilObjectID,
+					"purpose": mfaTokenPurpose,
+					"exp":     time.Now().UTC().Add(mfaTokenTTL).Unix(),
+				})
+
+				return token
+			},
+			expectedError: ErrInvalidToken,
+		},
+		{
+			name: "HMAC-Token",
+			accessToken: func() string {
+				claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+					"userID": primitive.NilObjectID,
+					"exp":    time.Now().UTC().Add(testTokenTTL).Unix(),
+				})
+				claims.Header["kid"] = keys.SigningKeyID()
 
-				token, _ := claims.SignedString([]byte(secret))
+				token, _ := claims.SignedString([]byte(""))
 
 				return token
 			},
-			expectedError: ErrTokenExpired,
+			expectedError: ErrInvalidToken,
 		},
 		{
 			name: "Invalid-Token",
@@ -348,10 +561,204 @@ func TestUserService_ParseToken(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			svc := NewUserService(nil)
+			svc := NewUserService(nil, nil, nil, nil, nil, nil, keys, nil, "", testTokenTTL, bcrypt.MinCost)
 
 			_, err := svc.ParseToken(test.accessToken())
 			assert.Equal(t, test.expectedError, err)
 		})
 	}
 }
+
+// testTokenTTL is how long the tokens issued by the services under test are valid.
+const testTokenTTL = 7 * 24 * time.Hour
+
+func newTestKeys(t *testing.T) *jwks.KeySet {
+	keys, err := jwks.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keys
+}
+
+func TestUserService_CheckIn(t *testing.T) {
+	type mockBehavior func(u *mock_repository.MockUserRepository, c *mock_repository.MockCapsuleRepository,
+		ctx context.Context, userID primitive.ObjectID)
+
+	wayBack := time.Unix(0, 0)
+	patches := gomonkey.ApplyFunc(time.Now, func() time.Time { return wayBack })
+	defer patches.Reset()
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		userID        primitive.ObjectID
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(u *mock_repository.MockUserRepository, c *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
+				u.EXPECT().UpdateUser(gomock.Any(), userID, bson.M{
+					"$set": bson.M{
+						"lastCheckInAt": time.Now().UTC(),
+					},
+				}).Return(nil).Times(1)
+
+				// Only the capsules whose deadline is still ahead are pushed back.
+				c.EXPECT().UpdateCapsules(gomock.Any(), bson.M{
+					"userID":   userID,
+					"mode":     domain.CapsuleModeInactivity,
+					"notified": false,
+					"openAt":   bson.M{"$gt": time.Now().UTC()},
+				}, checkInPipeline(time.Now().UTC())).Return(int64(1), nil).Times(1)
+			},
+			userID:        primitive.NewObjectID(),
+			expectedError: nil,
+		},
+		{
+			name: "Updating-User-DB-Failure",
+			mockBehavior: func(u *mock_repository.MockUserRepository, c *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
+				u.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).Return(errors.New("some error")).Times(1)
+			},
+			userID:        primitive.NewObjectID(),
+			expectedError: ErrDBFailure,
+		},
+		{
+			name: "Updating-Capsules-DB-Failure",
+			mockBehavior: func(u *mock_repository.MockUserRepository, c *mock_repository.MockCapsuleRepository, ctx context.Context, userID primitive.ObjectID) {
+				u.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).Return(nil).Times(1)
+				c.EXPECT().UpdateCapsules(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), errors.New("some error")).Times(1)
+			},
+			userID:        primitive.NewObjectID(),
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				userRepo    = mock_repository.NewMockUserRepository(c)
+				capsuleRepo = mock_repository.NewMockCapsuleRepository(c)
+				svc         = NewUserService(userRepo, capsuleRepo, nil, nil, nil, nil, nil, nil, "", testTokenTTL, bcrypt.MinCost)
+				ctx         = context.Background()
+			)
+
+			test.mockBehavior(userRepo, capsuleRepo, ctx, test.userID)
+
+			err := svc.CheckIn(ctx, test.userID)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestUserService_RecordActivity(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	var (
+		userRepo    = mock_repository.NewMockUserRepository(c)
+		capsuleRepo = mock_repository.NewMockCapsuleRepository(c)
+		svc         = NewUserService(userRepo, capsuleRepo, nil, nil, nil, nil, nil, nil, "", testTokenTTL, bcrypt.MinCost)
+		ctx         = context.Background()
+		userID      = primitive.NewObjectID()
+	)
+
+	userRepo.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).Return(nil).Times(1)
+	capsuleRepo.EXPECT().UpdateCapsules(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+
+	// Only the first activity within the interval checks the user in.
+	assert.NoError(t, svc.RecordActivity(ctx, userID))
+	assert.NoError(t, svc.RecordActivity(ctx, userID))
+}
+
+func TestUserService_recordCheckIn(t *testing.T) {
+	var (
+		svc      = NewUserService(nil, nil, nil, nil, nil, nil, nil, nil, "", testTokenTTL, bcrypt.MinCost).(*userService)
+		now      = time.Now().UTC()
+		inactive = primitive.NewObjectID()
+		active   = primitive.NewObjectID()
+		userID   = primitive.NewObjectID()
+	)
+
+	svc.lastActivity[inactive] = now.Add(-2 * activityCheckInInterval)
+	svc.lastActivity[active] = now.Add(-activityCheckInInterval / 2)
+
+	// The check-ins older than the interval are forgotten.
+	svc.recordCheckIn(userID, now)
+	assert.Equal(t, map[primitive.ObjectID]time.Time{
+		active: now.Add(-activityCheckInInterval / 2),
+		userID: now,
+	}, svc.lastActivity)
+}
+
+func TestUserService_UpdateReminders(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockUserRepository, ctx context.Context, userID primitive.ObjectID)
+
+	tests := []struct {
+		name          string
+		mockBehavior  mockBehavior
+		input         domain.UpdateRemindersDTO
+		expectedError error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().UpdateUser(gomock.Any(), userID, bson.M{
+					"$set": bson.M{
+						"reminderDays": []int{7, 3},
+					},
+				}).Return(nil).Times(1)
+			},
+			input:         domain.UpdateRemindersDTO{Days: []int{3, 7}},
+			expectedError: nil,
+		},
+		{
+			name: "OK-Default",
+			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().UpdateUser(gomock.Any(), userID, bson.M{
+					"$set": bson.M{
+						"reminderDays": []int(nil),
+					},
+				}).Return(nil).Times(1)
+			},
+			input:         domain.UpdateRemindersDTO{},
+			expectedError: nil,
+		},
+		{
+			name:          "Too-Many-Reminders",
+			mockBehavior:  func(r *mock_repository.MockUserRepository, ctx context.Context, userID primitive.ObjectID) {},
+			input:         domain.UpdateRemindersDTO{Days: []int{1, 2, 3, 4, 5, 6}},
+			expectedError: ErrInvalidReminders,
+		},
+		{
+			name: "DB-Failure",
+			mockBehavior: func(r *mock_repository.MockUserRepository, ctx context.Context, userID primitive.ObjectID) {
+				r.EXPECT().UpdateUser(gomock.Any(), userID, gomock.Any()).Return(errors.New("some error")).Times(1)
+			},
+			input:         domain.UpdateRemindersDTO{Days: []int{}},
+			expectedError: ErrDBFailure,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var (
+				rpstry = mock_repository.NewMockUserRepository(c)
+				svc    = NewUserService(rpstry, nil, nil, nil, nil, nil, nil, nil, "", testTokenTTL, bcrypt.MinCost)
+				ctx    = context.Background()
+				userID = primitive.NewObjectID()
+			)
+
+			test.mockBehavior(rpstry, ctx, userID)
+
+			err := svc.UpdateReminders(ctx, userID, test.input)
+			assert.Equal(t, test.expectedError, err)
+		})
+	}
+}
@@ -4,45 +4,64 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"log/slog"
 	"time"
 
 	"time-capsule/internal/domain"
+	"time-capsule/internal/metrics"
+	"time-capsule/internal/tracing"
 
 	"github.com/minio/minio-go/v7"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-const timeout = 5 * time.Second
-
 type MinioStorage struct {
 	client     *minio.Client
 	bucketName string
+	// timeout bounds each operation.
+	timeout time.Duration
 }
 
-func NewMinioStorage(client *minio.Client, bucketName string) Storage {
+func NewMinioStorage(client *minio.Client, bucketName string, timeout time.Duration) Storage {
 	return &MinioStorage{
 		client:     client,
 		bucketName: bucketName,
+		timeout:    timeout,
 	}
 }
 
 func (s *MinioStorage) Delete(ctx context.Context, fileName string) error {
 	opts := minio.RemoveObjectOptions{}
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	ctx, done := s.observe(ctx, "delete", fileName)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	return s.client.RemoveObject(
+	err := s.client.RemoveObject(
 		ctx,
 		s.bucketName,
 		fileName,
 		opts,
 	)
+
+	done(err)
+
+	slog.DebugContext(ctx, "deleted a file", "bucket", s.bucketName, "name", fileName, "error", err)
+
+	return err
 }
 
-func (s *MinioStorage) Get(ctx context.Context, fileName string) (*domain.File, error) {
+func (s *MinioStorage) Get(ctx context.Context, fileName string) (_ *domain.File, err error) {
 	opts := minio.GetObjectOptions{}
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	ctx, done := s.observe(ctx, "get", fileName)
+	defer func() {
+		done(err)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
 	obj, err := s.client.GetObject(ctx, s.bucketName, fileName, opts)
@@ -68,6 +87,8 @@ func (s *MinioStorage) Get(ctx context.Context, fileName string) (*domain.File,
 		Bytes: buffer,
 	}
 
+	slog.DebugContext(ctx, "got a file", "bucket", s.bucketName, "name", fileName, "size", objInfo.Size)
+
 	return file, nil
 }
 
@@ -76,7 +97,9 @@ func (s *MinioStorage) Upload(ctx context.Context, file domain.File) error {
 		ContentType: "image/png",
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	ctx, done := s.observe(ctx, "upload", file.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
 	reader := bytes.NewReader(file.Bytes)
@@ -90,5 +113,27 @@ func (s *MinioStorage) Upload(ctx context.Context, file domain.File) error {
 		opts,
 	)
 
+	done(err)
+
+	slog.DebugContext(ctx, "uploaded a file", "bucket", s.bucketName, "name", file.Name, "size", file.Size, "error", err)
+
 	return err
 }
+
+// observe starts the span of the operation on the file. The returned func ends it and records the operation in the metrics.
+func (s *MinioStorage) observe(ctx context.Context, operation, fileName string) (context.Context, func(error)) {
+	start := time.Now()
+
+	ctx, span := tracing.Start(ctx, "storage."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("storage.bucket", s.bucketName),
+			attribute.String("storage.object", fileName),
+		),
+	)
+
+	return ctx, func(err error) {
+		metrics.ObserveStorage(operation, start, err)
+		tracing.End(span, err)
+	}
+}
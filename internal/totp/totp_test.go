@@ -0,0 +1,94 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 secret of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// The last 6 digits of the RFC 6238 test vectors.
+	tests := []struct {
+		time     int64
+		expected string
+	}{
+		{time: 59, expected: "287082"},
+		{time: 1111111109, expected: "081804"},
+		{time: 1111111111, expected: "050471"},
+		{time: 1234567890, expected: "005924"},
+		{time: 2000000000, expected: "279037"},
+		{time: 20000000000, expected: "353130"},
+	}
+
+	for _, test := range tests {
+		code, err := Code(rfcSecret, Counter(time.Unix(test.time, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, code, test.time)
+	}
+
+	_, err := Code("not base32!", 1)
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	tests := []struct {
+		name    string
+		code    string
+		at      time.Time
+		counter int64
+		ok      bool
+	}{
+		{name: "Current", code: "050471", at: now, counter: Counter(now), ok: true},
+		{name: "Previous-Period", code: "050471", at: now.Add(Period), counter: Counter(now), ok: true},
+		{name: "Next-Period", code: "050471", at: now.Add(-Period), counter: Counter(now), ok: true},
+		{name: "Too-Old", code: "050471", at: now.Add(2 * Period), ok: false},
+		{name: "Wrong", code: "123456", at: now, ok: false},
+		{name: "Wrong-Length", code: "50471", at: now, ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			counter, ok := Validate(rfcSecret, test.code, test.at)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.counter, counter)
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	other, err := GenerateSecret()
+	require.NoError(t, err)
+
+	assert.Len(t, secret, 32)
+	assert.NotEqual(t, secret, other)
+
+	_, err = Code(secret, 1)
+	assert.NoError(t, err)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Time Capsule", "foo@example.com", "JBSWY3DPEHPK3PXP")
+
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Time Capsule:foo@example.com", u.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	assert.Equal(t, "Time Capsule", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+	assert.Equal(t, "30", u.Query().Get("period"))
+}
@@ -0,0 +1,90 @@
+// Package tracing sets up OpenTelemetry tracing. Spans are exported over OTLP, configured with the standard
+// OTEL_EXPORTER_OTLP_* variables, or written to stdout, and W3C trace context is propagated.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+const (
+	serviceName = "time-capsule"
+
+	instrumentationName = "time-capsule"
+)
+
+var ErrUnknownExporter = fmt.Errorf("tracing exporter must be one of %q, %q or %q", ExporterNone, ExporterOTLP, ExporterStdout)
+
+// Setup sets the global tracer provider exporting spans with the named exporter, stdout ones to w,
+// and the W3C trace context propagator. No spans are recorded with ExporterNone, or an empty name.
+// The returned func flushes the pending spans and shuts the provider down.
+func Setup(ctx context.Context, exporter string, w io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if exporter == "" || exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exp, err := NewExporter(ctx, exporter, w)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewProvider(sdktrace.WithBatcher(exp))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewExporter returns the named span exporter, writing to w for ExporterStdout.
+func NewExporter(ctx context.Context, exporter string, w io.Writer) (sdktrace.SpanExporter, error) {
+	switch exporter {
+	case ExporterOTLP:
+		return otlptracehttp.New(ctx)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(w))
+	default:
+		return nil, ErrUnknownExporter
+	}
+}
+
+// NewProvider returns a tracer provider of the service with the options, e.g. the span processor of an exporter.
+func NewProvider(opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(semconv.ServiceName(serviceName))
+
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, opts...)...)
+}
+
+// Start starts a span as a child of the span in ctx, if any, with the global tracer provider.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End records the error on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// record sets the global tracer provider to one recording the spans in memory for the test.
+func record(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	otel.SetTracerProvider(NewProvider(sdktrace.WithSyncer(exporter)))
+
+	return exporter
+}
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name          string
+		exporter      string
+		expectedSpans bool
+		expectedError error
+	}{
+		{name: "Default", exporter: ""},
+		{name: "None", exporter: ExporterNone},
+		{name: "Stdout", exporter: ExporterStdout, expectedSpans: true},
+		{name: "Unknown", exporter: "jaeger", expectedError: ErrUnknownExporter},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			previous := otel.GetTracerProvider()
+			defer otel.SetTracerProvider(previous)
+
+			var buf bytes.Buffer
+
+			shutdown, err := Setup(context.Background(), test.exporter, &buf)
+			assert.Equal(t, test.expectedError, err)
+
+			if test.expectedError != nil {
+				return
+			}
+
+			_, span := Start(context.Background(), "test")
+			span.End()
+
+			require.NoError(t, shutdown(context.Background()))
+			assert.Equal(t, test.expectedSpans, bytes.Contains(buf.Bytes(), []byte(`"Name":"test"`)))
+		})
+	}
+}
+
+func TestEnd(t *testing.T) {
+	exporter := record(t)
+
+	_, span := Start(context.Background(), "failed")
+	End(span, errors.New("some error"))
+
+	_, span = Start(context.Background(), "canceled")
+	End(span, context.Canceled)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "some error", spans[0].Status.Description)
+	assert.Len(t, spans[0].Events, 1)
+
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+}
+
+func TestMongoMonitor(t *testing.T) {
+	exporter := record(t)
+
+	var (
+		monitor   = MongoMonitor()
+		ctx, root = Start(context.Background(), "root")
+	)
+
+	monitor.Started(ctx, &event.CommandStartedEvent{CommandName: "find", DatabaseName: "capsules", RequestID: 1})
+	monitor.Started(ctx, &event.CommandStartedEvent{CommandName: "insert", DatabaseName: "capsules", RequestID: 2})
+	monitor.Failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", RequestID: 2},
+		Failure:              "duplicate key",
+	})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1},
+	})
+
+	root.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	insert, find := spans[0], spans[1]
+
+	assert.Equal(t, "mongodb.insert", insert.Name)
+	assert.Equal(t, codes.Error, insert.Status.Code)
+	assert.Equal(t, "duplicate key", insert.Status.Description)
+
+	assert.Equal(t, "mongodb.find", find.Name)
+	assert.Equal(t, codes.Unset, find.Status.Code)
+	assert.Equal(t, root.SpanContext().SpanID(), find.Parent.SpanID())
+	assert.Contains(t, find.Attributes, attribute.String("db.name", "capsules"))
+	assert.Contains(t, find.Attributes, attribute.String("db.system", "mongodb"))
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// checkInReminderDays are the number of days before an inactivity capsule's deadline
+// at which the owner is reminded to check in, from the earliest to the most urgent.
+var checkInReminderDays = []int{7, 3, 1}
+
+// sendCheckInReminders emails the owners of inactivity capsules whose deadline is approaching.
+// Every reached threshold is recorded on the capsule, so each reminder is sent at most once
+// until the owner checks in and the timer is reset.
+func (w *Worker) sendCheckInReminders(ctx context.Context, now time.Time) {
+	capsules, err := w.repository.GetCapsules(ctx, bson.M{
+		"mode":     domain.CapsuleModeInactivity,
+		"notified": false,
+		"openAt": bson.M{
+			"$gt":  now,
+			"$lte": now.AddDate(0, 0, checkInReminderDays[0]),
+		},
+	})
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to retrieve inactivity capsules", "error", err)
+		return
+	}
+
+	for _, capsule := range capsules {
+		reached, ok := dueReminder(checkInReminderDays, capsule.CheckInRemindersSent, capsule.OpenAt, now)
+		if !ok {
+			continue
+		}
+
+		user, err := w.getOwner(ctx, capsule)
+		if err != nil {
+			continue
+		}
+
+		// The deadline identifies the inactivity period, it moves on every check-in.
+		var (
+			key  = domain.OutboxKey(mail.TemplateCheckInReminder, capsule.ID.Hex(), capsule.OpenAt.Unix(), slices.Min(reached))
+			days = daysUntil(capsule.OpenAt, now)
+		)
+
+		_ = w.notify(ctx, user, capsule, bson.M{
+			"$addToSet": bson.M{
+				"checkInRemindersSent": bson.M{"$each": reached},
+			},
+		}, &domain.Notification{
+			Key:  key,
+			Type: domain.NotificationCheckInReminder,
+			Days: days,
+		}, outboxEmail{
+			key:      key,
+			to:       user.Email,
+			template: mail.TemplateCheckInReminder,
+			data: mail.CheckInReminderData{
+				Username: user.Username,
+				Days:     days,
+			},
+		})
+	}
+}
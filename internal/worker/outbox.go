@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/metrics"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// maxDeliveryAttempts is the number of attempts after which an email is dead-lettered.
+	maxDeliveryAttempts = 8
+
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = 6 * time.Hour
+
+	// claimLease is how long a claimed email is hidden from other workers while it's being sent.
+	claimLease = 5 * time.Minute
+
+	dispatchBatchSize = 50
+)
+
+// outboxEmail is an email to queue. key identifies the notification, so it's queued only once.
+type outboxEmail struct {
+	key      string
+	to       string
+	template string
+	data     any
+	images   []string
+}
+
+// enqueue renders the email in the user's language and stores it in the outbox.
+// An email with a key that's already queued is skipped.
+func (w *Worker) enqueue(ctx context.Context, user *domain.User, capsule *domain.Capsule, email outboxEmail) error {
+	msg, err := w.renderer.Render(email.template, user.Language, email.data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", email.template, err)
+	}
+
+	now := time.Now().UTC()
+
+	if _, err = w.repository.InsertOutboxEntry(ctx, &domain.OutboxEntry{
+		Key:           email.key,
+		UserID:        user.ID,
+		CapsuleID:     capsule.ID,
+		Recipient:     email.to,
+		Template:      email.template,
+		Subject:       msg.Subject,
+		HTML:          msg.HTML,
+		Text:          msg.Text,
+		Images:        email.images,
+		Status:        domain.OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("failed to queue %s: %w", email.key, err)
+	}
+
+	return nil
+}
+
+// dispatch delivers the due emails of the outbox. Failed deliveries are retried with an exponential
+// backoff, and dead-lettered after maxDeliveryAttempts.
+func (w *Worker) dispatch(ctx context.Context, now time.Time) {
+	for i := 0; i < dispatchBatchSize; i++ {
+		entry, err := w.repository.ClaimOutboxEntry(ctx, now, claimLease)
+		if err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				w.logger.ErrorContext(ctx, "failed to claim an email", "error", err)
+			}
+			return
+		}
+
+		if err = w.repository.UpdateOutboxEntry(ctx, entry.ID, w.deliver(ctx, entry, now)); err != nil {
+			w.logger.ErrorContext(ctx, "failed to update an email", "entryID", entry.ID.Hex(), "error", err)
+		}
+	}
+}
+
+// deliver sends the email and returns the update recording the outcome.
+func (w *Worker) deliver(ctx context.Context, entry *domain.OutboxEntry, now time.Time) bson.M {
+	err := w.sender.Send(ctx, []string{entry.Recipient}, &mail.Message{
+		Subject: entry.Subject,
+		HTML:    entry.HTML,
+		Text:    entry.Text,
+	}, w.thumbnails(ctx, entry.Images)...)
+
+	metrics.ObserveNotification(metrics.ChannelEmail, err)
+
+	if err == nil {
+		w.logger.DebugContext(ctx, "sent an email", "entryID", entry.ID.Hex(), "template", entry.Template)
+
+		return bson.M{
+			"$set": bson.M{
+				"status": domain.OutboxStatusSent,
+				"sentAt": now,
+			},
+			"$inc": bson.M{
+				"attempts": 1,
+			},
+			"$unset": bson.M{
+				"lastError": "",
+			},
+		}
+	}
+
+	attempts := entry.Attempts + 1
+
+	w.logger.WarnContext(ctx, "failed to send an email", "entryID", entry.ID.Hex(), "attempt", attempts, "error", err)
+
+	update := bson.M{
+		"lastError":     err.Error(),
+		"nextAttemptAt": now.Add(retryDelay(attempts)),
+	}
+
+	if attempts >= maxDeliveryAttempts {
+		update["status"] = domain.OutboxStatusFailed
+	}
+
+	return bson.M{
+		"$set": update,
+		"$inc": bson.M{
+			"attempts": 1,
+		},
+	}
+}
+
+// retryDelay returns the delay before the next attempt, doubling with every failed one.
+func retryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		expected time.Duration
+	}{
+		{
+			name:     "First-Attempt",
+			attempts: 1,
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "Doubling",
+			attempts: 3,
+			expected: 2 * time.Minute,
+		},
+		{
+			name:     "Below-Cap",
+			attempts: 10,
+			expected: 256 * time.Minute,
+		},
+		{
+			name:     "Capped",
+			attempts: 11,
+			expected: maxRetryDelay,
+		},
+		{
+			name:     "Far-Past-Cap",
+			attempts: 1000,
+			expected: maxRetryDelay,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, retryDelay(test.attempts))
+		})
+	}
+}
+
+func TestWorker_Deliver(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		attempts       int
+		sendErr        error
+		expectedUpdate bson.M
+	}{
+		{
+			name: "OK",
+			expectedUpdate: bson.M{
+				"$set": bson.M{
+					"status": domain.OutboxStatusSent,
+					"sentAt": now,
+				},
+				"$inc": bson.M{
+					"attempts": 1,
+				},
+				"$unset": bson.M{
+					"lastError": "",
+				},
+			},
+		},
+		{
+			name:    "Sending-Failure",
+			sendErr: errors.New("some error"),
+			expectedUpdate: bson.M{
+				"$set": bson.M{
+					"lastError":     "some error",
+					"nextAttemptAt": now.Add(baseRetryDelay),
+				},
+				"$inc": bson.M{
+					"attempts": 1,
+				},
+			},
+		},
+		{
+			name:     "Sending-Failure-Before-Last-Attempt",
+			attempts: maxDeliveryAttempts - 2,
+			sendErr:  errors.New("some error"),
+			expectedUpdate: bson.M{
+				"$set": bson.M{
+					"lastError":     "some error",
+					"nextAttemptAt": now.Add(retryDelay(maxDeliveryAttempts - 1)),
+				},
+				"$inc": bson.M{
+					"attempts": 1,
+				},
+			},
+		},
+		{
+			name:     "Sending-Failure-Last-Attempt",
+			attempts: maxDeliveryAttempts - 1,
+			sendErr:  errors.New("some error"),
+			expectedUpdate: bson.M{
+				"$set": bson.M{
+					"lastError":     "some error",
+					"nextAttemptAt": now.Add(retryDelay(maxDeliveryAttempts)),
+					"status":        domain.OutboxStatusFailed,
+				},
+				"$inc": bson.M{
+					"attempts": 1,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sender := &testSender{err: test.sendErr}
+			w, _ := newTestWorker(t, sender)
+
+			update := w.deliver(context.Background(), &domain.OutboxEntry{
+				ID:        primitive.NewObjectID(),
+				Recipient: "user@example.com",
+				Attempts:  test.attempts,
+			}, now)
+
+			assert.Equal(t, test.expectedUpdate, update)
+			assert.Equal(t, []string{"user@example.com"}, sender.sent)
+		})
+	}
+}
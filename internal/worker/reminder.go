@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"time-capsule/internal/domain"
+	"time-capsule/internal/mail"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultReminderDays apply to capsules when neither the capsule nor its owner configured reminders.
+var defaultReminderDays = []int{7, 1}
+
+// sendOpeningReminders emails the owners of capsules that open soon, without revealing their content.
+// Reminders are configured per capsule, falling back to the owner's defaults. Every reached reminder
+// is recorded on the capsule, so each one is sent at most once per occurrence.
+func (w *Worker) sendOpeningReminders(ctx context.Context, now time.Time) {
+	window := bson.M{
+		"$gt":  now,
+		"$lte": now.AddDate(0, 0, domain.MaxReminderDays),
+	}
+
+	capsules, err := w.repository.GetCapsules(ctx, bson.M{
+		"notified": false,
+		"mode":     bson.M{"$ne": domain.CapsuleModeInactivity},
+		"$or": bson.A{
+			bson.M{
+				"nextOccurrenceAt": bson.M{"$exists": false},
+				"openAt":           window,
+			},
+			bson.M{
+				"nextOccurrenceAt": window,
+			},
+		},
+	})
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to retrieve upcoming capsules", "error", err)
+		return
+	}
+
+	owners := make(map[primitive.ObjectID]*domain.User)
+
+	for _, capsule := range capsules {
+		user, ok := owners[capsule.UserID]
+		if !ok {
+			if user, err = w.getOwner(ctx, capsule); err != nil {
+				continue
+			}
+
+			owners[capsule.UserID] = user
+		}
+
+		openAt := capsule.OpenAt
+		if capsule.NextOccurrenceAt != nil {
+			openAt = *capsule.NextOccurrenceAt
+		}
+
+		reached, ok := dueReminder(reminderDays(user, capsule), capsule.RemindersSent, openAt, now)
+		if !ok {
+			continue
+		}
+
+		var (
+			key  = domain.OutboxKey(mail.TemplateReminder, capsule.ID.Hex(), capsule.Occurrences, slices.Min(reached))
+			days = daysUntil(openAt, now)
+		)
+
+		_ = w.notify(ctx, user, capsule, bson.M{
+			"$addToSet": bson.M{
+				"remindersSent": bson.M{"$each": reached},
+			},
+		}, &domain.Notification{
+			Key:  key,
+			Type: domain.NotificationCapsuleReminder,
+			Days: days,
+		}, outboxEmail{
+			key:      key,
+			to:       user.Email,
+			template: mail.TemplateReminder,
+			data: mail.ReminderData{
+				Username: user.Username,
+				Days:     days,
+				OpenAt:   openAt,
+			},
+		})
+	}
+}
+
+// reminderDays returns the reminders that apply to the capsule.
+func reminderDays(user *domain.User, capsule *domain.Capsule) []int {
+	switch {
+	case capsule.Reminders != nil:
+		return capsule.Reminders
+	case user.ReminderDays != nil:
+		return user.ReminderDays
+	default:
+		return defaultReminderDays
+	}
+}
+
+// dueReminder returns every reminder threshold, in days before the deadline, that has been reached,
+// and whether any of them has not been sent yet. Reaching several thresholds at once, e.g. for a capsule
+// created shortly before its opening, results in a single reminder.
+func dueReminder(days, sent []int, deadline, now time.Time) ([]int, bool) {
+	var (
+		reached []int
+		due     bool
+	)
+
+	for _, d := range days {
+		if deadline.Sub(now) > time.Duration(d)*24*time.Hour {
+			continue
+		}
+
+		reached = append(reached, d)
+
+		if !slices.Contains(sent, d) {
+			due = true
+		}
+	}
+
+	return reached, due
+}
+
+// daysUntil returns the number of started days left until the deadline.
+func daysUntil(deadline, now time.Time) int {
+	return int(math.Ceil(deadline.Sub(now).Hours() / 24))
+}
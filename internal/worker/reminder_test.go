@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDueReminder(t *testing.T) {
+	var (
+		now  = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		days = []int{7, 1}
+	)
+
+	tests := []struct {
+		name            string
+		sent            []int
+		deadline        time.Time
+		expectedReached []int
+		expectedDue     bool
+	}{
+		{
+			name:     "None-Reached",
+			deadline: now.AddDate(0, 0, 8),
+		},
+		{
+			name:            "First-Reached",
+			deadline:        now.AddDate(0, 0, 7),
+			expectedReached: []int{7},
+			expectedDue:     true,
+		},
+		{
+			name:            "First-Already-Sent",
+			sent:            []int{7},
+			deadline:        now.AddDate(0, 0, 5),
+			expectedReached: []int{7},
+		},
+		{
+			name:            "Second-Reached",
+			sent:            []int{7},
+			deadline:        now.Add(12 * time.Hour),
+			expectedReached: []int{7, 1},
+			expectedDue:     true,
+		},
+		{
+			name:            "All-Already-Sent",
+			sent:            []int{7, 1},
+			deadline:        now.Add(12 * time.Hour),
+			expectedReached: []int{7, 1},
+		},
+		{
+			name:            "All-Reached-At-Once",
+			deadline:        now.Add(12 * time.Hour),
+			expectedReached: []int{7, 1},
+			expectedDue:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reached, due := dueReminder(days, test.sent, test.deadline, now)
+
+			assert.Equal(t, test.expectedReached, reached)
+			assert.Equal(t, test.expectedDue, due)
+		})
+	}
+}
@@ -2,114 +2,334 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"net/smtp"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/events"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/metrics"
+	"time-capsule/internal/recurrence"
 	"time-capsule/internal/repository"
+	"time-capsule/internal/service"
+	"time-capsule/internal/storage"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const workerInterval = 5 * time.Second
+const (
+	maxThumbnails = 3
+	thumbnailSize = 480
+)
+
+var (
+	// errCapsuleChanged is returned by notify when the capsule's deadline moved since it was read.
+	errCapsuleChanged = errors.New("capsule changed since it was read")
+	// errOwnerInactive is returned by getOwner when the owner's account is disabled or scheduled for deletion.
+	errOwnerInactive = errors.New("owner's account is inactive")
+)
+
+type Worker struct {
+	cfg        *config.Config
+	repository *repository.Repository
+	storage    storage.Storage
+	renderer   mail.Renderer
+	sender     mail.Sender
+	broker     events.Broker
+	svc        *service.Service
+	logger     *slog.Logger
+
+	// heartbeat is when the worker started or last finished a cycle, in Unix nanoseconds.
+	heartbeat atomic.Int64
+}
+
+func New(cfg *config.Config, repository *repository.Repository, storage storage.Storage,
+	renderer mail.Renderer, sender mail.Sender, broker events.Broker, svc *service.Service) *Worker {
+	w := &Worker{
+		cfg:        cfg,
+		repository: repository,
+		storage:    storage,
+		renderer:   renderer,
+		sender:     sender,
+		broker:     broker,
+		svc:        svc,
+		logger:     slog.Default().With("component", "worker"),
+	}
+
+	w.heartbeat.Store(time.Now().UnixNano())
+
+	return w
+}
+
+// Heartbeat returns when the worker started or last finished a cycle, for readiness checks.
+func (w *Worker) Heartbeat() time.Time {
+	return time.Unix(0, w.heartbeat.Load())
+}
 
 // Run periodically checks for expired time capsules, retrieves the associated user information,
-// and sends an email notification to users when their capsules are opened.
-func Run(ctx context.Context, cfg *config.Config, repository *repository.Repository) {
+// and notifies users when their capsules are opened.
+// Recurring capsules are notified again on each occurrence of their schedule. Owners are reminded
+// of upcoming openings, and owners of inactivity capsules are reminded to check in before their deadline.
+// Notifications are queued in the outbox and delivered with retries, and stored in the owner's
+// in-app inbox, which is pushed to the owner's open event streams. Requested data exports are built,
+// and deleted accounts are purged once their grace period is over. Every cycle is reported in the admin worker status.
+func (w *Worker) Run(ctx context.Context) {
 	for {
-		time.Sleep(workerInterval) // Todo: Minute / Hour / Day ?
+		time.Sleep(w.cfg.WorkerInterval)
+
+		now := time.Now().UTC()
 
-		expiredCapsules, err := repository.GetCapsules(ctx, bson.M{
-			"openAt": bson.M{
-				"$lte": time.Now().UTC(),
+		w.sendCheckInReminders(ctx, now)
+		w.sendOpeningReminders(ctx, now)
+		w.openCapsules(ctx, now)
+		w.dispatch(ctx, now)
+		w.buildExports(ctx, now)
+		w.purgeAccounts(ctx, now)
+
+		finishedAt := time.Now().UTC()
+		w.svc.RecordWorkerCycle(now, finishedAt)
+		w.heartbeat.Store(finishedAt.UnixNano())
+		metrics.WorkerCycleDuration.Observe(finishedAt.Sub(now).Seconds())
+
+		w.logger.DebugContext(ctx, "finished a cycle", "duration", finishedAt.Sub(now))
+	}
+}
+
+// buildExports builds the requested data exports and deletes the expired ones.
+func (w *Worker) buildExports(ctx context.Context, now time.Time) {
+	if err := w.svc.BuildExports(ctx, now); err != nil {
+		w.logger.ErrorContext(ctx, "failed to build exports", "error", err)
+	}
+
+	if err := w.svc.ExpireExports(ctx, now); err != nil {
+		w.logger.ErrorContext(ctx, "failed to expire exports", "error", err)
+	}
+}
+
+// purgeAccounts purges the accounts whose deletion grace period is over.
+func (w *Worker) purgeAccounts(ctx context.Context, now time.Time) {
+	if err := w.svc.PurgeAccounts(ctx, now); err != nil {
+		w.logger.ErrorContext(ctx, "failed to purge deleted accounts", "error", err)
+	}
+}
+
+// openCapsules notifies the owners and recipients of the capsules that are due.
+func (w *Worker) openCapsules(ctx context.Context, now time.Time) {
+	expiredCapsules, err := w.repository.GetCapsules(ctx, bson.M{
+		"notified": false,
+		"$or": bson.A{
+			bson.M{
+				"nextOccurrenceAt": bson.M{"$exists": false},
+				"openAt":           bson.M{"$lte": now},
 			},
-			"notified": false,
-		})
-		if err != nil {
-			log.Printf("(worker) failed to retrieve capsules: %s\n", err)
-		}
+			bson.M{
+				"nextOccurrenceAt": bson.M{"$lte": now},
+			},
+		},
+	})
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to retrieve due capsules", "error", err)
+		return
+	}
 
-		fmt.Println("expired", expiredCapsules)
+	metrics.DueCapsules.Set(float64(len(expiredCapsules)))
 
-		if len(expiredCapsules) == 0 {
+	if len(expiredCapsules) > 0 {
+		w.logger.DebugContext(ctx, "opening capsules", "count", len(expiredCapsules))
+	}
+
+	for _, capsule := range expiredCapsules {
+		user, err := w.getOwner(ctx, capsule)
+		if err != nil {
 			continue
 		}
 
-		for _, capsule := range expiredCapsules {
-			user, err := repository.GetUser(ctx, bson.M{
-				"_id": capsule.UserID,
-			})
-			if err != nil {
-				fmt.Printf("(worker) failed to find user with id=%s: %s\n", user.ID.Hex(), err) // ? fatal
-				continue
-			}
-
-			// ? Change in the same style as the front-end design ?
-			body := fmt.Sprintf(`
-			<html>
-			<body style="font-family: Arial, sans-serif; background-color: #f7f7f7; margin: 0; padding: 0;">
-			<table align="center" border="0" cellpadding="0" cellspacing="0" width="100%s" style="max-width: 600px; margin: 20px auto; border-collapse: collapse; box-shadow: 0px 0px 10px rgba(0, 0, 0, 0.1);">
-				<tr>
-					<td style="background-color: #000; padding: 40px 20px; text-align: center;">
-						<h1 style="color: #ffffff; font-size: 28px;">💌 Your Time Capsule Has Been Opened</h1>
-					</td>
-				</tr>
-				<tr>
-					<td style="background-color: #ffffff; padding: 40px 40px;">
-						<p style="color: #333333; font-size: 18px; line-height: 1.5;">Dear %s,</p>
-						<p style="color: #333333; font-size: 18px; line-height: 1.5;">We're thrilled to share that the moment you've been waiting for has arrived. Your time capsule has been opened, revealing the cherished memories and heartfelt messages you've kept safe.</p>
-						<p style="color: #333333; font-size: 18px; line-height: 1.5;">Take your time to immerse yourself in the past and relive those beautiful moments. The past is a treasure trove of emotions, and we're honored to be a part of this journey with you.</p>
-						<p style="color: #333333; font-size: 18px; line-height: 1.5;">Thank you for sharing these memories with us. Here's to celebrating the richness of life and the stories that shape us.</p>
-					</td>
-				</tr>
-				</tr>
-			</table>
-			</body>
-			</html>
-            `, "%", user.Username)
-
-			if err = sendEmail(cfg, "Time Capsule Opened!", body, []string{user.Email}); err != nil {
-				log.Println(err)
-				continue
-			}
-
-			if err = repository.UpdateCapsule(ctx, capsule.ID, bson.M{
-				"$set": bson.M{
-					"notified": true,
+		var (
+			key                = domain.OutboxKey(mail.TemplateOpened, capsule.ID.Hex(), capsule.Occurrences)
+			images, contentIDs = w.thumbnailImages(capsule)
+			emails             = []outboxEmail{{
+				key:      key,
+				to:       user.Email,
+				template: mail.TemplateOpened,
+				data: mail.OpenedData{
+					Username: user.Username,
+					Images:   contentIDs,
 				},
-			}); err != nil {
-				log.Println(err)
-				continue
-			}
+				images: images,
+			}}
+		)
 
-			fmt.Println("email sent")
+		for _, recipient := range capsule.Recipients {
+			emails = append(emails, outboxEmail{
+				key:      domain.OutboxKey(mail.TemplateRecipient, capsule.ID.Hex(), capsule.Occurrences, recipient),
+				to:       recipient,
+				template: mail.TemplateRecipient,
+				data: mail.RecipientData{
+					Sender:  user.Username,
+					Message: capsule.Message,
+					Images:  contentIDs,
+				},
+				images: images,
+			})
 		}
+
+		_ = w.notify(ctx, user, capsule, nextOccurrence(capsule, now), &domain.Notification{
+			Key:  key,
+			Type: domain.NotificationCapsuleOpened,
+		}, emails...)
+	}
+}
+
+// getOwner retrieves the owner of the capsule. Owners whose account is disabled or scheduled for deletion
+// get errOwnerInactive, their capsules are left as they are until the account is back in use.
+func (w *Worker) getOwner(ctx context.Context, capsule *domain.Capsule) (*domain.User, error) {
+	user, err := w.repository.GetUser(ctx, bson.M{
+		"_id": capsule.UserID,
+	})
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to find the owner of a capsule", "userID", capsule.UserID.Hex(), "error", err)
+		return nil, err
+	}
+
+	if user.DisabledAt != nil || user.DeletionScheduledAt != nil {
+		w.logger.DebugContext(ctx, "skipping a capsule of an inactive account", "capsuleID", capsule.ID.Hex())
+		return nil, errOwnerInactive
 	}
+
+	return user, nil
 }
 
-func sendEmail(cfg *config.Config, subject, body string, to []string) error {
-	auth := smtp.PlainAuth(
-		"",
-		cfg.SMTPUsername,
-		cfg.SMTPPassword,
-		cfg.SMTPHost,
-	)
+// notify queues the emails about the capsule, in the owner's language, stores the owner's in-app notification
+// and then applies update to the capsule. The update records that the notification was handled, so it's only
+// applied once everything is stored. Otherwise the capsule is picked up again on the next cycle, and the emails
+// and the notification that were stored already are skipped. The notification is published once the capsule is updated,
+// and not at all if the capsule's deadline moved since it was read.
+func (w *Worker) notify(ctx context.Context, user *domain.User, capsule *domain.Capsule, update bson.M,
+	notification *domain.Notification, emails ...outboxEmail) error {
+	for _, email := range emails {
+		if err := w.enqueue(ctx, user, capsule, email); err != nil {
+			w.logger.ErrorContext(ctx, "failed to queue an email", "capsuleID", capsule.ID.Hex(), "error", err)
+			return err
+		}
+	}
+
+	notification.UserID = user.ID
+	notification.CapsuleID = capsule.ID
+	notification.CreatedAt = time.Now().UTC()
 
-	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	if _, err := w.repository.InsertNotification(ctx, notification); err != nil && !mongo.IsDuplicateKeyError(err) {
+		w.logger.ErrorContext(ctx, "failed to store a notification", "key", notification.Key, "error", err)
+		metrics.ObserveNotification(metrics.ChannelInApp, err)
+		return err
+	}
 
-	subject = "Subject:" + subject + "\n"
+	metrics.ObserveNotification(metrics.ChannelInApp, nil)
 
-	if err := smtp.SendMail(
-		fmt.Sprintf("%s:%s", cfg.SMTPHost, cfg.SMTPPort),
-		auth,
-		cfg.SMTPUsername,
-		to,
-		[]byte(subject+mime+body),
-	); err != nil {
-		return fmt.Errorf("(worker-email) failed to send an email: %s\n", err)
+	// The update only applies while the capsule's deadline is the one it was read with,
+	// so a check-in that pushed it back in the meantime isn't overwritten.
+	matched, err := w.repository.UpdateCapsules(ctx, bson.M{"_id": capsule.ID, "openAt": capsule.OpenAt}, update)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to update a capsule", "capsuleID", capsule.ID.Hex(), "error", err)
+		return err
 	}
+	if matched == 0 {
+		w.logger.WarnContext(ctx, "capsule changed since it was read", "capsuleID", capsule.ID.Hex())
+		return errCapsuleChanged
+	}
+
+	w.broker.Publish(user.ID, events.Event{
+		Type: events.TypeNotification,
+		Data: notification,
+	})
 
 	return nil
 }
+
+// thumbnailImages picks the capsule's images to attach as inline thumbnails, when enabled,
+// and the content IDs the email references them by.
+func (w *Worker) thumbnailImages(capsule *domain.Capsule) ([]string, []string) {
+	if !w.cfg.MailAttachThumbnails || len(capsule.Images) == 0 {
+		return nil, nil
+	}
+
+	images := capsule.Images[:min(len(capsule.Images), maxThumbnails)]
+
+	contentIDs := make([]string, len(images))
+	for i := range images {
+		contentIDs[i] = thumbnailContentID(i)
+	}
+
+	return images, contentIDs
+}
+
+// thumbnails builds the inline thumbnails of the images. Images that can't be loaded are skipped.
+func (w *Worker) thumbnails(ctx context.Context, images []string) []mail.Attachment {
+	var attachments []mail.Attachment
+
+	for i, image := range images {
+		file, err := w.storage.Get(ctx, image)
+		if err != nil {
+			w.logger.WarnContext(ctx, "failed to get an image", "image", image, "error", err)
+			continue
+		}
+
+		thumbnail, err := mail.Thumbnail(file.Bytes, thumbnailSize)
+		if err != nil {
+			w.logger.WarnContext(ctx, "failed to create a thumbnail", "image", image, "error", err)
+			continue
+		}
+
+		attachments = append(attachments, mail.Attachment{
+			ContentID:   thumbnailContentID(i),
+			Filename:    fmt.Sprintf("image-%d.jpg", i),
+			ContentType: "image/jpeg",
+			Data:        thumbnail,
+		})
+	}
+
+	return attachments
+}
+
+func thumbnailContentID(i int) string {
+	return fmt.Sprintf("image-%d@time-capsule", i)
+}
+
+// nextOccurrence builds the update applied after a capsule was notified. One-off capsules are
+// marked as notified, recurring ones move on to their next occurrence, skipping any that were missed.
+func nextOccurrence(capsule *domain.Capsule, now time.Time) bson.M {
+	if capsule.Recurrence == nil {
+		return bson.M{
+			"$set": bson.M{
+				"notified": true,
+			},
+		}
+	}
+
+	n, next, ok := recurrence.NextAfter(*capsule.Recurrence, capsule.OpenAt, capsule.Occurrences+1, now)
+	if !ok {
+		return bson.M{
+			"$set": bson.M{
+				"notified":    true,
+				"occurrences": n,
+			},
+			"$unset": bson.M{
+				"nextOccurrenceAt": "",
+			},
+		}
+	}
+
+	return bson.M{
+		"$set": bson.M{
+			"occurrences":      n,
+			"nextOccurrenceAt": next,
+		},
+		"$unset": bson.M{
+			"remindersSent": "",
+		},
+	}
+}
@@ -0,0 +1,253 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"time-capsule/config"
+	"time-capsule/internal/domain"
+	"time-capsule/internal/events"
+	"time-capsule/internal/mail"
+	"time-capsule/internal/recurrence"
+	"time-capsule/internal/repository"
+	mock_repository "time-capsule/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+type mocks struct {
+	users         *mock_repository.MockUserRepository
+	capsules      *mock_repository.MockCapsuleRepository
+	outbox        *mock_repository.MockOutboxRepository
+	notifications *mock_repository.MockNotificationRepository
+}
+
+// testSender records the recipients of the emails it's asked to send and fails with err.
+type testSender struct {
+	err  error
+	sent []string
+}
+
+func (s *testSender) Send(_ context.Context, to []string, _ *mail.Message, _ ...mail.Attachment) error {
+	s.sent = append(s.sent, to...)
+	return s.err
+}
+
+func newTestWorker(t *testing.T, sender mail.Sender) (*Worker, mocks) {
+	c := gomock.NewController(t)
+
+	m := mocks{
+		users:         mock_repository.NewMockUserRepository(c),
+		capsules:      mock_repository.NewMockCapsuleRepository(c),
+		outbox:        mock_repository.NewMockOutboxRepository(c),
+		notifications: mock_repository.NewMockNotificationRepository(c),
+	}
+
+	renderer, err := mail.NewRenderer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := events.NewBroker()
+	t.Cleanup(broker.Close)
+
+	return New(&config.Config{}, &repository.Repository{
+		UserRepository:         m.users,
+		CapsuleRepository:      m.capsules,
+		OutboxRepository:       m.outbox,
+		NotificationRepository: m.notifications,
+	}, nil, renderer, sender, broker, nil), m
+}
+
+func TestWorker_OpenCapsules(t *testing.T) {
+	var (
+		now       = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		openAt    = now.Add(-time.Minute)
+		userID    = primitive.NewObjectID()
+		capsuleID = primitive.NewObjectID()
+		capsule   = &domain.Capsule{ID: capsuleID, UserID: userID, OpenAt: openAt}
+	)
+
+	tests := []struct {
+		name         string
+		owner        *domain.User
+		mockBehavior func(m mocks)
+	}{
+		{
+			name:  "OK",
+			owner: &domain.User{ID: userID, Email: "owner@example.com"},
+			mockBehavior: func(m mocks) {
+				m.outbox.EXPECT().InsertOutboxEntry(gomock.Any(), gomock.Any()).Return(&domain.OutboxEntry{}, nil).Times(1)
+				m.notifications.EXPECT().InsertNotification(gomock.Any(), gomock.Any()).Return(&domain.Notification{}, nil).Times(1)
+				m.capsules.EXPECT().UpdateCapsules(gomock.Any(), bson.M{"_id": capsuleID, "openAt": openAt}, bson.M{
+					"$set": bson.M{
+						"notified": true,
+					},
+				}).Return(int64(1), nil).Times(1)
+			},
+		},
+		{
+			name:  "Capsule-Changed",
+			owner: &domain.User{ID: userID, Email: "owner@example.com"},
+			mockBehavior: func(m mocks) {
+				m.outbox.EXPECT().InsertOutboxEntry(gomock.Any(), gomock.Any()).Return(&domain.OutboxEntry{}, nil).Times(1)
+				m.notifications.EXPECT().InsertNotification(gomock.Any(), gomock.Any()).Return(&domain.Notification{}, nil).Times(1)
+				m.capsules.EXPECT().UpdateCapsules(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+			},
+		},
+		{
+			name:         "Owner-Disabled",
+			owner:        &domain.User{ID: userID, Email: "owner@example.com", DisabledAt: &now},
+			mockBehavior: func(m mocks) {},
+		},
+		{
+			name:         "Owner-Deletion-Scheduled",
+			owner:        &domain.User{ID: userID, Email: "owner@example.com", DeletionScheduledAt: &now},
+			mockBehavior: func(m mocks) {},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w, m := newTestWorker(t, &testSender{})
+
+			m.capsules.EXPECT().GetCapsules(gomock.Any(), gomock.Any()).Return([]*domain.Capsule{capsule}, nil).Times(1)
+			m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(test.owner, nil).Times(1)
+			test.mockBehavior(m)
+
+			w.openCapsules(context.Background(), now)
+		})
+	}
+}
+
+func TestWorker_GetOwner(t *testing.T) {
+	var (
+		now     = time.Now().UTC()
+		userID  = primitive.NewObjectID()
+		capsule = &domain.Capsule{ID: primitive.NewObjectID(), UserID: userID}
+	)
+
+	tests := []struct {
+		name          string
+		owner         *domain.User
+		getErr        error
+		expectedError error
+	}{
+		{
+			name:  "OK",
+			owner: &domain.User{ID: userID},
+		},
+		{
+			name:          "Disabled",
+			owner:         &domain.User{ID: userID, DisabledAt: &now},
+			expectedError: errOwnerInactive,
+		},
+		{
+			name:          "Deletion-Scheduled",
+			owner:         &domain.User{ID: userID, DeletionScheduledAt: &now},
+			expectedError: errOwnerInactive,
+		},
+		{
+			name:          "DB-Failure",
+			getErr:        errors.New("some error"),
+			expectedError: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w, m := newTestWorker(t, &testSender{})
+
+			m.users.EXPECT().GetUser(gomock.Any(), bson.M{"_id": userID}).Return(test.owner, test.getErr).Times(1)
+
+			user, err := w.getOwner(context.Background(), capsule)
+
+			assert.Equal(t, test.expectedError, err)
+			if test.expectedError == nil {
+				assert.Equal(t, test.owner, user)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	var (
+		openAt = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		yearly = &domain.Recurrence{Frequency: recurrence.Yearly}
+	)
+
+	tests := []struct {
+		name           string
+		capsule        *domain.Capsule
+		now            time.Time
+		expectedUpdate bson.M
+	}{
+		{
+			name:    "One-Time",
+			capsule: &domain.Capsule{OpenAt: openAt},
+			now:     openAt,
+			expectedUpdate: bson.M{
+				"$set": bson.M{
+					"notified": true,
+				},
+			},
+		},
+		{
+			name:    "Next",
+			capsule: &domain.Capsule{OpenAt: openAt, Recurrence: yearly},
+			now:     openAt.Add(time.Hour),
+			expectedUpdate: bson.M{
+				"$set": bson.M{
+					"occurrences":      1,
+					"nextOccurrenceAt": time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+				"$unset": bson.M{
+					"remindersSent": "",
+				},
+			},
+		},
+		{
+			name:    "Missed-Occurrences",
+			capsule: &domain.Capsule{OpenAt: openAt, Recurrence: yearly},
+			now:     time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+			expectedUpdate: bson.M{
+				"$set": bson.M{
+					"occurrences":      4,
+					"nextOccurrenceAt": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+				"$unset": bson.M{
+					"remindersSent": "",
+				},
+			},
+		},
+		{
+			name: "Exhausted",
+			capsule: &domain.Capsule{
+				OpenAt:      openAt,
+				Recurrence:  &domain.Recurrence{Frequency: recurrence.Yearly, Count: 3},
+				Occurrences: 1,
+			},
+			now: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+			expectedUpdate: bson.M{
+				"$set": bson.M{
+					"notified":    true,
+					"occurrences": 3,
+				},
+				"$unset": bson.M{
+					"nextOccurrenceAt": "",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedUpdate, nextOccurrence(test.capsule, test.now))
+		})
+	}
+}
@@ -3,16 +3,11 @@ package httpserver
 import (
 	"context"
 	"net/http"
-	"time"
 
 	"time-capsule/config"
 )
 
-const (
-	defaultReadTimeout    = 5 * time.Second
-	defaultWriteTimeout   = 5 * time.Second
-	defaultMaxHeaderBytes = 1 << 20
-)
+const defaultMaxHeaderBytes = 1 << 20
 
 type Server struct {
 	httpServer *http.Server
@@ -26,8 +21,8 @@ func (s *Server) Run(cfg *config.Config, handler http.Handler) error {
 	s.httpServer = &http.Server{
 		Addr:           ":" + cfg.HttpAddr,
 		Handler:        handler,
-		ReadTimeout:    defaultReadTimeout,
-		WriteTimeout:   defaultWriteTimeout,
+		ReadTimeout:    cfg.HTTPReadTimeout,
+		WriteTimeout:   cfg.HTTPWriteTimeout,
 		MaxHeaderBytes: defaultMaxHeaderBytes,
 	}
 
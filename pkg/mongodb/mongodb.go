@@ -6,11 +6,13 @@ import (
 
 	"time-capsule/config"
 
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func New(ctx context.Context, cfg *config.Config) (*mongo.Database, error) {
+// New connects to the database. The options are applied over the ones from the config, e.g. to monitor commands.
+func New(ctx context.Context, cfg *config.Config, opts ...*options.ClientOptions) (*mongo.Database, error) {
 	isAuth := false
 
 	mongoURI := fmt.Sprintf("mongodb://%s:%s", cfg.MongoHost, cfg.MongoPort)
@@ -27,7 +29,7 @@ func New(ctx context.Context, cfg *config.Config) (*mongo.Database, error) {
 		})
 	}
 
-	client, err := mongo.Connect(ctx, clientOpts)
+	client, err := mongo.Connect(ctx, append([]*options.ClientOptions{clientOpts}, opts...)...)
 	if err != nil {
 		return nil, fmt.Errorf("mongoDB connection failed: %s", err)
 	}
@@ -38,3 +40,30 @@ func New(ctx context.Context, cfg *config.Config) (*mongo.Database, error) {
 
 	return client.Database(cfg.MongoDBName), nil
 }
+
+// Monitors returns a command monitor passing the events to each of the monitors, the client takes only one.
+func Monitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
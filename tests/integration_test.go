@@ -15,6 +15,7 @@ const (
 	host = "localhost:8088"
 
 	basePath = "http://" + host + "/api/v1"
+	readyURL = "http://" + host + "/readyz"
 
 	attempts = 20
 )
@@ -44,12 +45,12 @@ func healthCheck(attempts int) error {
 	var err error
 
 	for i := 0; i < attempts; i++ {
-		err = Do(Get(basePath), Expect().Status().Equal(http.StatusNotFound))
+		err = Do(Get(readyURL), Expect().Status().Equal(http.StatusOK))
 		if err == nil {
 			return nil
 		}
 
-		log.Printf("Integration tests: url %s is not available, attempts left: %d\n", basePath, i+1)
+		log.Printf("Integration tests: url %s is not ready, attempts left: %d\n", readyURL, i+1)
 
 		time.Sleep(500 * time.Millisecond)
 	}